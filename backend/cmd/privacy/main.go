@@ -0,0 +1,115 @@
+// Command privacy answers customer data requests from the command line.
+//
+//	go run cmd/privacy/main.go -account <account-id> -email jane@example.com export > jane.json
+//	go run cmd/privacy/main.go -account <account-id> -phone "+1 555 0100" -confirm erase
+//
+// Both actions are written to the account's data subject request audit log.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	privacymodel "kyooar/internal/privacy/model"
+	gormprivacy "kyooar/internal/privacy/repository/gorm"
+	privacyservice "kyooar/internal/privacy/service"
+	"kyooar/internal/shared/config"
+	"kyooar/internal/shared/database"
+	sharedServices "kyooar/internal/shared/services"
+	"github.com/google/uuid"
+	"github.com/samber/do"
+)
+
+func main() {
+	accountIDStr := flag.String("account", "", "Account ID whose organizations are searched")
+	email := flag.String("email", "", "Customer email")
+	phone := flag.String("phone", "", "Customer phone number")
+	reason := flag.String("reason", "", "Reason recorded in the audit log, such as a ticket reference")
+	output := flag.String("out", "", "File to write the export to (defaults to stdout)")
+	confirm := flag.Bool("confirm", false, "Confirm an erasure; it cannot be undone")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s -account <id> (-email <email> | -phone <phone>) [flags] export|erase\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	accountID, err := uuid.Parse(*accountIDStr)
+	if err != nil {
+		log.Fatal("A valid -account ID is required")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load configuration:", err)
+	}
+
+	db, err := database.Initialize(cfg)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	injector := do.New()
+	do.ProvideValue(injector, cfg)
+	storage, err := sharedServices.NewStorage(injector)
+	if err != nil {
+		log.Fatal("Failed to open storage:", err)
+	}
+
+	service := privacyservice.NewPrivacyService(gormprivacy.NewPrivacyRepository(db), storage)
+	req := &privacymodel.SubjectRequest{
+		Email:  *email,
+		Phone:  *phone,
+		Reason: *reason,
+		Source: privacymodel.SourceCLI,
+	}
+	ctx := context.Background()
+
+	switch flag.Arg(0) {
+	case "export":
+		export, err := service.Export(ctx, accountID, req)
+		if err != nil {
+			log.Fatal("Export failed:", err)
+		}
+
+		out := os.Stdout
+		if *output != "" {
+			out, err = os.Create(*output)
+			if err != nil {
+				log.Fatal("Failed to create output file:", err)
+			}
+			defer out.Close()
+		}
+
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(export); err != nil {
+			log.Fatal("Failed to write export:", err)
+		}
+		fmt.Fprintf(os.Stderr, "✅ Exported %d feedback (request %s)\n", len(export.Feedbacks), export.RequestID)
+
+	case "erase":
+		if !*confirm {
+			log.Fatal("Erasure cannot be undone; pass -confirm to proceed")
+		}
+
+		result, err := service.Erase(ctx, accountID, req)
+		if err != nil {
+			log.Fatal("Erasure failed:", err)
+		}
+		fmt.Printf("✅ Anonymized %d feedback, deleted %d messages, %d photos, %d notes and %d activity entries (request %s)\n",
+			result.ErasedFeedbacks, result.DeletedMessages, result.DeletedAttachments, result.DeletedNotes, result.DeletedActivities, result.RequestID)
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
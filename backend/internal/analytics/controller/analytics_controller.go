@@ -346,6 +346,7 @@ func (c *AnalyticsController) GetProductInsights(ctx echo.Context) error {
 // @Param date_from query string false "Start date (YYYY-MM-DD)"
 // @Param date_to query string false "End date (YYYY-MM-DD)"
 // @Param product_id query string false "Filter by specific product ID"
+// @Param version_id query string false "Filter by questionnaire version ID"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
@@ -381,6 +382,9 @@ func (c *AnalyticsController) GetOrganizationChartData(ctx echo.Context) error {
 	if productID := ctx.QueryParam("product_id"); productID != "" {
 		filters["product_id"] = productID
 	}
+	if versionID := ctx.QueryParam("version_id"); versionID != "" {
+		filters["version_id"] = versionID
+	}
 
 	logger.Info("Getting organization chart data", logrus.Fields{
 		"organization_id":     organizationID,
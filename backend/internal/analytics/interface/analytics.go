@@ -12,6 +12,7 @@ type AnalyticsRepository interface {
 	GetQuestionChartData(ctx context.Context, questionID uuid.UUID, filters map[string]interface{}) (*models.ChartData, error)
 	GetOrganizationChartDataBatch(ctx context.Context, organizationID uuid.UUID, questionIDs []uuid.UUID, filters map[string]interface{}) (map[uuid.UUID]*models.ChartData, error)
 	GetFeedbackCounts(ctx context.Context, organizationID uuid.UUID) (*models.FeedbackCounts, error)
+	GetCompletionCounts(ctx context.Context, organizationID uuid.UUID, productID *uuid.UUID, since time.Time) (*models.CompletionCounts, error)
 	GetQRCodeMetrics(ctx context.Context, organizationID uuid.UUID) (*models.QRCodeMetrics, error)
 	GetProductRatingsAndCounts(ctx context.Context, organizationID uuid.UUID, productIDs []uuid.UUID) (map[uuid.UUID]models.ProductMetrics, error)
 }
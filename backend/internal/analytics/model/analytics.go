@@ -72,6 +72,7 @@ type DashboardMetrics struct {
 	ActiveQRCodes     int64             `json:"active_qr_codes"`
 	TotalQRScans      int64             `json:"total_qr_scans"`
 	ScansToday        int64             `json:"scans_today"`
+	UniqueScansToday  int64             `json:"unique_scans_today"`
 	CompletionRate    float64           `json:"completion_rate"`
 	
 	AverageResponseTime float64         `json:"average_response_time"`
@@ -88,7 +89,10 @@ type QRCodePerformance struct {
 	Location       string     `json:"location,omitempty"`
 	OrganizationID   uuid.UUID  `json:"organization_id"`
 	OrganizationName string     `json:"organization_name"`
+	// ScansCount and UniqueScans cover the scan events still retained;
+	// UniqueScans counts each visitor's device once.
 	ScansCount     int64      `json:"scans_count"`
+	UniqueScans    int64      `json:"unique_scans"`
 	FeedbackCount  int64      `json:"feedback_count"`
 	ConversionRate float64    `json:"conversion_rate"`
 	LastScan       *time.Time `json:"last_scan,omitempty"`
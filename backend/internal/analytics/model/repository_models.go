@@ -13,11 +13,25 @@ type FeedbackCounts struct {
 	Recent30Days int64 `gorm:"column:recent_30_days"`
 }
 
+type CompletionCounts struct {
+	Completed int64 `gorm:"column:completed"`
+	Abandoned int64 `gorm:"column:abandoned"`
+}
+
+func (c CompletionCounts) Rate() float64 {
+	started := c.Completed + c.Abandoned
+	if started == 0 {
+		return 0
+	}
+	return float64(c.Completed) / float64(started) * 100
+}
+
 type QRCodeMetrics struct {
 	TotalQRCodes int64 `gorm:"column:total_qr_codes"`
 	ActiveCount  int64 `gorm:"column:active_count"`
 	TotalScans   int64 `gorm:"column:total_scans"`
 	ScansToday   int64 `gorm:"column:scans_today"`
+	UniqueScansToday int64 `gorm:"column:unique_scans_today"`
 }
 
 type FeedbackWithQRCode struct {
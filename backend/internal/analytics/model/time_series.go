@@ -144,6 +144,7 @@ const (
 	MetricTypeSentimentScore    = "sentiment_score"
 	MetricTypeQuestionScore     = "question_score"
 	MetricTypeQRScanCount       = "qr_scan_count"
+	MetricTypeQRUniqueScans     = "qr_unique_scans"
 	MetricTypeConversionRate    = "conversion_rate"
 	MetricTypeResponseTime      = "response_time"
 	MetricTypeCustomerSatisfaction = "customer_satisfaction"
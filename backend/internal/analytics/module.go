@@ -31,6 +31,7 @@ func ProvideAnalyticsService(i *do.Injector) (analyticsinterface.AnalyticsServic
 	feedbackRepo := do.MustInvoke[feedbackinterface.FeedbackRepository](i)
 	productRepo := do.MustInvoke[productRepos.ProductRepository](i)
 	qrCodeRepo := do.MustInvoke[qrcodeinterface.QRCodeRepository](i)
+	scanEventRepo := do.MustInvoke[qrcodeinterface.ScanEventRepository](i)
 	organizationRepo := do.MustInvoke[organizationinterface.OrganizationRepository](i)
 
 	return analyticsservice.NewAnalyticsService(
@@ -38,6 +39,7 @@ func ProvideAnalyticsService(i *do.Injector) (analyticsinterface.AnalyticsServic
 		feedbackRepo,
 		productRepo,
 		qrCodeRepo,
+		scanEventRepo,
 		organizationRepo,
 	), nil
 }
@@ -48,6 +50,7 @@ func ProvideTimeSeriesService(i *do.Injector) (analyticsinterface.TimeSeriesServ
 	organizationService := do.MustInvoke[organizationinterface.OrganizationService](i)
 	analyticsService := do.MustInvoke[analyticsinterface.AnalyticsService](i)
 	questionService := do.MustInvoke[feedbackinterface.QuestionService](i)
+	scanEventRepo := do.MustInvoke[qrcodeinterface.ScanEventRepository](i)
 
 	return analyticsservice.NewTimeSeriesService(
 		timeSeriesRepo,
@@ -55,6 +58,7 @@ func ProvideTimeSeriesService(i *do.Injector) (analyticsinterface.TimeSeriesServ
 		organizationService,
 		analyticsService,
 		questionService,
+		scanEventRepo,
 	), nil
 }
 
@@ -39,6 +39,9 @@ func (r *AnalyticsRepository) GetQuestionChartData(ctx context.Context, question
 	if productID, ok := filters["product_id"]; ok {
 		query = query.Where("f.product_id = ?", productID)
 	}
+	if versionID, ok := filters["version_id"]; ok {
+		query = query.Where("f.questionnaire_version_id = ?", versionID)
+	}
 	
 	var aggregatedData []struct {
 		ResponseValue interface{} `gorm:"column:response_value"`
@@ -97,12 +100,36 @@ func (r *AnalyticsRepository) GetFeedbackCounts(ctx context.Context, organizatio
 			COUNT(CASE WHEN created_at >= ? AND created_at < ? THEN 1 END) as yesterday,
 			COUNT(CASE WHEN created_at >= ? THEN 1 END) as recent_30_days
 		`, todayStart, yesterdayStart, todayStart, thirtyDaysAgo).
-		Where("organization_id = ?", organizationID).
+		Where("organization_id = ? AND is_complete = ? AND is_quarantined = ? AND NOT excluded_from_analytics", organizationID, true, false).
 		Scan(&result).Error
 		
 	return &result, err
 }
 
+// GetCompletionCounts counts finished feedback against sessions that went
+// idle for longer than feedbackmodel.SessionAbandonAfter without finishing.
+func (r *AnalyticsRepository) GetCompletionCounts(ctx context.Context, organizationID uuid.UUID, productID *uuid.UUID, since time.Time) (*models.CompletionCounts, error) {
+	var result models.CompletionCounts
+	abandonedBefore := time.Now().Add(-feedbackmodel.SessionAbandonAfter)
+	
+	query := r.db.WithContext(ctx).
+		Model(&feedbackmodel.Feedback{}).
+		Select(`
+			COUNT(CASE WHEN is_complete = true THEN 1 END) as completed,
+			COUNT(CASE WHEN is_complete = false AND last_activity_at < ? THEN 1 END) as abandoned
+		`, abandonedBefore).
+		Where("organization_id = ? AND created_at >= ? AND is_quarantined = ? AND NOT excluded_from_analytics", organizationID, since, false)
+	
+	if productID != nil {
+		query = query.Where("product_id = ?", *productID)
+	}
+	
+	err := query.Scan(&result).Error
+	return &result, err
+}
+
+// GetQRCodeMetrics counts the organization's codes and their lifetime scans,
+// with today's scans taken from the scan events.
 func (r *AnalyticsRepository) GetQRCodeMetrics(ctx context.Context, organizationID uuid.UUID) (*models.QRCodeMetrics, error) {
 	var result models.QRCodeMetrics
 	todayStart := time.Now().Truncate(24 * time.Hour)
@@ -112,11 +139,22 @@ func (r *AnalyticsRepository) GetQRCodeMetrics(ctx context.Context, organization
 		Select(`
 			COUNT(*) as total_qr_codes,
 			COUNT(CASE WHEN is_active = true THEN 1 END) as active_count,
-			SUM(scans_count) as total_scans,
-			COUNT(CASE WHEN last_scanned_at >= ? THEN 1 END) as scans_today
-		`, todayStart).
+			COALESCE(SUM(scans_count), 0) as total_scans
+		`).
 		Where("organization_id = ?", organizationID).
 		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	
+	err = r.db.WithContext(ctx).
+		Model(&qrcodemodel.QRScanEvent{}).
+		Select(`
+			COUNT(*) as scans_today,
+			COUNT(DISTINCT visitor_hash) as unique_scans_today
+		`).
+		Where("organization_id = ? AND scanned_at >= ?", organizationID, todayStart).
+		Scan(&result).Error
 		
 	return &result, err
 }
@@ -140,7 +178,7 @@ func (r *AnalyticsRepository) GetProductRatingsAndCounts(ctx context.Context, or
 			COALESCE(AVG(overall_rating), 0) as average_rating,
 			COUNT(*) as feedback_count
 		`).
-		Where("organization_id = ? AND product_id IN ?", organizationID, productIDs).
+		Where("organization_id = ? AND product_id IN ? AND is_complete = ? AND is_quarantined = ? AND NOT excluded_from_analytics", organizationID, productIDs, true, false).
 		Group("product_id").
 		Scan(&results).Error
 		
@@ -19,6 +19,7 @@ import (
 	qrcodeinterface "kyooar/internal/qrcode/interface"
 	organizationinterface "kyooar/internal/organization/interface"
 	"kyooar/internal/shared/logger"
+	sharedModels "kyooar/internal/shared/models"
 	"github.com/sirupsen/logrus"
 )
 
@@ -27,6 +28,7 @@ type AnalyticsService struct {
 	feedbackRepo     feedbackinterface.FeedbackRepository
 	productRepo      menuRepos.ProductRepository
 	qrCodeRepo       qrcodeinterface.QRCodeRepository
+	scanEventRepo    qrcodeinterface.ScanEventRepository
 	organizationRepo organizationinterface.OrganizationRepository
 }
 
@@ -35,6 +37,7 @@ func NewAnalyticsService(
 	feedbackRepo feedbackinterface.FeedbackRepository,
 	productRepo menuRepos.ProductRepository,
 	qrCodeRepo qrcodeinterface.QRCodeRepository,
+	scanEventRepo qrcodeinterface.ScanEventRepository,
 	organizationRepo organizationinterface.OrganizationRepository,
 ) *AnalyticsService {
 	return &AnalyticsService{
@@ -42,6 +45,7 @@ func NewAnalyticsService(
 		feedbackRepo:     feedbackRepo,
 		productRepo:      productRepo,
 		qrCodeRepo:       qrCodeRepo,
+		scanEventRepo:    scanEventRepo,
 		organizationRepo: organizationRepo,
 	}
 }
@@ -72,17 +76,16 @@ func (s *AnalyticsService) GetDashboardMetrics(ctx context.Context, organization
 		metrics.ActiveQRCodes = qrMetrics.ActiveCount
 		metrics.TotalQRScans = qrMetrics.TotalScans
 		metrics.ScansToday = qrMetrics.ScansToday
+		metrics.UniqueScansToday = qrMetrics.UniqueScansToday
 	}
 	
-	if metrics.TotalQRScans > 0 && feedbackCounts != nil && feedbackCounts.Recent30Days > 0 {
-		rate := float64(feedbackCounts.Recent30Days) / float64(metrics.TotalQRScans) * 100
-		if rate > 100 {
-			metrics.CompletionRate = 100.0
-		} else {
-			metrics.CompletionRate = rate
-		}
+	completionCounts, err := s.analyticsRepo.GetCompletionCounts(ctx, organizationID, nil, time.Now().AddDate(0, 0, -30))
+	if err != nil {
+		logger.Error("Failed to get completion counts", err, logrus.Fields{
+			"organization_id": organizationID,
+		})
 	} else {
-		metrics.CompletionRate = 0.0
+		metrics.CompletionRate = completionCounts.Rate()
 	}
 	
 	feedback, err := s.feedbackRepo.FindByOrganizationIDForAnalytics(ctx, organizationID, 1000)
@@ -155,7 +158,12 @@ func (s *AnalyticsService) GetProductInsights(ctx context.Context, productID uui
 	insights.BestAspects = s.identifyBestAspects(questionMetrics)
 	insights.NeedsAttention = s.identifyNeedsAttention(questionMetrics)
 	
-	insights.CompletionRate = 100.0
+	completionCounts, err := s.analyticsRepo.GetCompletionCounts(ctx, product.OrganizationID, &productID, time.Now().AddDate(0, 0, -30))
+	if err != nil {
+		logger.Error("Failed to get completion counts", err, logrus.Fields{"product_id": productID})
+	} else {
+		insights.CompletionRate = completionCounts.Rate()
+	}
 	
 	if len(allFeedback) > 0 {
 		insights.LastFeedback = allFeedback[0].CreatedAt
@@ -265,6 +273,19 @@ func (s *AnalyticsService) aggregateQuestionMetrics(feedback []feedbackmodel.Fee
 			metric.ResponseCount++
 			
 			switch v := response.Answer.(type) {
+			case map[string]interface{}:
+				if metric.QuestionType == string(feedbackmodel.QuestionTypeMatrix) {
+					for row := range feedbackmodel.MatrixScores(v) {
+						metric.OptionDistribution[row]++
+					}
+				}
+				
+			case []interface{}:
+				ranking := feedbackmodel.RankingOrder(v)
+				if metric.QuestionType == string(feedbackmodel.QuestionTypeRanking) && len(ranking) > 0 {
+					metric.OptionDistribution[ranking[0]]++
+				}
+				
 			case float64:
 				if metric.AverageScore == nil {
 					avg := v
@@ -283,7 +304,16 @@ func (s *AnalyticsService) aggregateQuestionMetrics(feedback []feedbackmodel.Fee
 					}
 				}
 				
-				if v >= 4 {
+				if metric.QuestionType == string(feedbackmodel.QuestionTypeNPS) {
+					switch feedbackmodel.NPSCategory(v) {
+					case feedbackmodel.NPSPromoter:
+						metric.PositiveRate++
+					case feedbackmodel.NPSPassive:
+						metric.NeutralRate++
+					default:
+						metric.NegativeRate++
+					}
+				} else if v >= 4 {
 					metric.PositiveRate++
 				} else if v >= 3 {
 					metric.NeutralRate++
@@ -701,14 +731,20 @@ func (s *AnalyticsService) getQRCodePerformance(ctx context.Context, organizatio
 		feedbackCounts = make(map[uuid.UUID]int64)
 	}
 	
+	scanCounts, err := s.scanEventRepo.CountByQRCodeIDs(ctx, qrCodeIDs, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	
 	var performance []analyticsModels.QRCodePerformance
 	
 	for _, qr := range qrCodes {
 		feedbackCount := feedbackCounts[qr.ID]
+		scans := scanCounts[qr.ID]
 		
 		conversionRate := 0.0
-		if qr.ScansCount > 0 && feedbackCount > 0 {
-			rate := (float64(feedbackCount) / float64(qr.ScansCount)) * 100
+		if scans.Total > 0 && feedbackCount > 0 {
+			rate := (float64(feedbackCount) / float64(scans.Total)) * 100
 			if rate > 100 {
 				conversionRate = 100.0
 			} else {
@@ -721,7 +757,8 @@ func (s *AnalyticsService) getQRCodePerformance(ctx context.Context, organizatio
 			Label:          qr.Label,
 			OrganizationID:   qr.OrganizationID,
 			OrganizationName: organization.Name,
-			ScansCount:     int64(qr.ScansCount),
+			ScansCount:     scans.Total,
+			UniqueScans:    scans.Unique,
 			FeedbackCount:  feedbackCount,
 			ConversionRate: conversionRate,
 			LastScan:       qr.LastScannedAt,
@@ -754,7 +791,7 @@ func (s *AnalyticsService) GetOrganizationChartData(ctx context.Context, organiz
 		"feedback_filters": feedbackFilters,
 	})
 	
-	feedback, err := s.feedbackRepo.FindByOrganizationIDWithFilters(ctx, uuid.Nil, organizationID, 1, 10000, feedbackFilters)
+	feedback, err := s.feedbackRepo.FindByOrganizationIDWithFilters(ctx, uuid.Nil, organizationID, sharedModels.PageRequest{Page: 1, Limit: 10000}, feedbackFilters)
 	if err != nil {
 		logger.Error("Failed to fetch feedback data", err, logrus.Fields{
 			"organization_id": organizationID,
@@ -858,7 +895,7 @@ func (s *AnalyticsService) GetQuestionChartData(ctx context.Context, questionID
 	feedbackFilters := s.buildFeedbackFilters(filters)
 	
 	
-	allFeedback, err := s.feedbackRepo.FindByOrganizationIDWithFilters(ctx, uuid.Nil, uuid.Nil, 1, 10000, feedbackFilters)
+	allFeedback, err := s.feedbackRepo.FindByOrganizationIDWithFilters(ctx, uuid.Nil, uuid.Nil, sharedModels.PageRequest{Page: 1, Limit: 10000}, feedbackFilters)
 	if err != nil {
 		return nil, err
 	}
@@ -884,7 +921,9 @@ func (s *AnalyticsService) GetQuestionChartData(ctx context.Context, questionID
 
 
 func (s *AnalyticsService) buildFeedbackFilters(filters map[string]interface{}) feedbackmodel.FeedbackFilter {
-	feedbackFilters := feedbackmodel.FeedbackFilter{}
+	isComplete := true
+	isQuarantined := false
+	feedbackFilters := feedbackmodel.FeedbackFilter{IsComplete: &isComplete, IsQuarantined: &isQuarantined, AnalyticsOnly: true}
 	
 	if dateFrom, ok := filters["date_from"].(string); ok {
 		if parsed, err := time.Parse("2006-01-02", dateFrom); err == nil {
@@ -904,6 +943,12 @@ func (s *AnalyticsService) buildFeedbackFilters(filters map[string]interface{})
 		}
 	}
 	
+	if versionIDStr, ok := filters["version_id"].(string); ok {
+		if versionID, err := uuid.Parse(versionIDStr); err == nil {
+			feedbackFilters.QuestionnaireVersionID = &versionID
+		}
+	}
+	
 	return feedbackFilters
 }
 
@@ -931,6 +976,20 @@ func (s *AnalyticsService) aggregateQuestionResponses(questionID uuid.UUID, ques
 	case "text":
 		chartData.ChartType = "text_sentiment"
 		chartData.Data = s.aggregateTextResponses(responses)
+	case "nps":
+		chartData.ChartType = "nps"
+		chartData.Data = s.aggregateNPSResponses(responses)
+	case "matrix":
+		chartData.ChartType = "matrix"
+		chartData.Data = s.aggregateMatrixResponses(responses)
+	case "ranking":
+		chartData.ChartType = "ranking"
+		chartData.Data = s.aggregateRankingResponses(responses)
+	case "photo":
+		chartData.ChartType = "photo"
+		chartData.Data = map[string]interface{}{
+			"total": int64(len(responses)),
+		}
 	default:
 		chartData.ChartType = "rating"
 		chartData.Data = s.aggregateRatingResponses(responses)
@@ -1187,6 +1246,155 @@ func (s *AnalyticsService) aggregateYesNoResponses(responses []feedbackmodel.Res
 	}
 }
 
+func (s *AnalyticsService) aggregateNPSResponses(responses []feedbackmodel.Response) map[string]interface{} {
+	distribution := make(map[string]int64)
+	categories := map[string]int64{
+		feedbackmodel.NPSPromoter:  0,
+		feedbackmodel.NPSPassive:   0,
+		feedbackmodel.NPSDetractor: 0,
+	}
+	var total int64
+	var sum float64
+	
+	for _, response := range responses {
+		v, ok := response.Answer.(float64)
+		if !ok {
+			continue
+		}
+		distribution[fmt.Sprintf("%.0f", v)]++
+		categories[feedbackmodel.NPSCategory(v)]++
+		sum += v
+		total++
+	}
+	
+	percentages := make(map[string]float64)
+	for category, count := range categories {
+		if total > 0 {
+			percentages[category] = float64(count) / float64(total) * 100
+		}
+	}
+	
+	var average float64
+	if total > 0 {
+		average = sum / float64(total)
+	}
+	
+	return map[string]interface{}{
+		"scale":        10,
+		"distribution": distribution,
+		"categories":   categories,
+		"percentages":  percentages,
+		"nps":          feedbackmodel.NPSScore(categories[feedbackmodel.NPSPromoter], categories[feedbackmodel.NPSDetractor], total),
+		"average":      average,
+		"total":        total,
+	}
+}
+
+func (s *AnalyticsService) aggregateMatrixResponses(responses []feedbackmodel.Response) map[string]interface{} {
+	type rowStats struct {
+		sum          float64
+		count        int64
+		distribution map[string]int64
+	}
+	
+	stats := make(map[string]*rowStats)
+	var total int64
+	var overallSum float64
+	var overallCount int64
+	
+	for _, response := range responses {
+		scores := feedbackmodel.MatrixScores(response.Answer)
+		if len(scores) == 0 {
+			continue
+		}
+		total++
+		for row, score := range scores {
+			if stats[row] == nil {
+				stats[row] = &rowStats{distribution: make(map[string]int64)}
+			}
+			stats[row].sum += score
+			stats[row].count++
+			stats[row].distribution[fmt.Sprintf("%.0f", score)]++
+			overallSum += score
+			overallCount++
+		}
+	}
+	
+	rows := make(map[string]interface{}, len(stats))
+	for row, st := range stats {
+		rows[row] = map[string]interface{}{
+			"average":      st.sum / float64(st.count),
+			"count":        st.count,
+			"distribution": st.distribution,
+		}
+	}
+	
+	var average float64
+	if overallCount > 0 {
+		average = overallSum / float64(overallCount)
+	}
+	
+	return map[string]interface{}{
+		"rows":    rows,
+		"average": average,
+		"total":   total,
+	}
+}
+
+func (s *AnalyticsService) aggregateRankingResponses(responses []feedbackmodel.Response) map[string]interface{} {
+	type optionStats struct {
+		positionSum int
+		count       int64
+		firstPlace  int64
+		points      int
+	}
+	
+	stats := make(map[string]*optionStats)
+	var total int64
+	
+	for _, response := range responses {
+		ranking := feedbackmodel.RankingOrder(response.Answer)
+		if len(ranking) == 0 {
+			continue
+		}
+		total++
+		for i, option := range ranking {
+			if stats[option] == nil {
+				stats[option] = &optionStats{}
+			}
+			stats[option].positionSum += i + 1
+			stats[option].count++
+			// Borda count: first place earns one point per option ranked below it
+			stats[option].points += len(ranking) - i - 1
+			if i == 0 {
+				stats[option].firstPlace++
+			}
+		}
+	}
+	
+	options := make([]map[string]interface{}, 0, len(stats))
+	for option, st := range stats {
+		options = append(options, map[string]interface{}{
+			"option":           option,
+			"average_position": float64(st.positionSum) / float64(st.count),
+			"first_place":      st.firstPlace,
+			"points":           st.points,
+			"count":            st.count,
+		})
+	}
+	sort.Slice(options, func(i, j int) bool {
+		if options[i]["points"].(int) != options[j]["points"].(int) {
+			return options[i]["points"].(int) > options[j]["points"].(int)
+		}
+		return options[i]["option"].(string) < options[j]["option"].(string)
+	})
+	
+	return map[string]interface{}{
+		"options": options,
+		"total":   total,
+	}
+}
+
 func (s *AnalyticsService) analyzeSentiment(text string) float64 {
 	if strings.TrimSpace(text) == "" {
 		return 0.0
@@ -15,6 +15,7 @@ import (
 	feedbackmodel "kyooar/internal/feedback/model"
 	feedbackinterface "kyooar/internal/feedback/interface"
 	organizationinterface "kyooar/internal/organization/interface"
+	qrcodeinterface "kyooar/internal/qrcode/interface"
 
 	"github.com/google/uuid"
 	"github.com/grassmudhorses/vader-go/lexicon"
@@ -45,6 +46,7 @@ type TimeSeriesService struct {
 	organizationService organizationinterface.OrganizationService
 	analyticsService    analyticsinterface.AnalyticsService
 	questionService     feedbackinterface.QuestionService
+	scanEventRepo       qrcodeinterface.ScanEventRepository
 }
 
 func NewTimeSeriesService(
@@ -53,6 +55,7 @@ func NewTimeSeriesService(
 	organizationService organizationinterface.OrganizationService,
 	analyticsService analyticsinterface.AnalyticsService,
 	questionService feedbackinterface.QuestionService,
+	scanEventRepo qrcodeinterface.ScanEventRepository,
 ) *TimeSeriesService {
 	return &TimeSeriesService{
 		timeSeriesRepo:      timeSeriesRepo,
@@ -60,6 +63,7 @@ func NewTimeSeriesService(
 		organizationService: organizationService,
 		analyticsService:    analyticsService,
 		questionService:     questionService,
+		scanEventRepo:       scanEventRepo,
 	}
 }
 
@@ -248,6 +252,33 @@ func (s *TimeSeriesService) collectOrganizationMetrics(ctx context.Context, orga
 		})
 	}
 
+	scanDays, err := s.scanEventRepo.CountByDay(ctx, organizationID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, day := range scanDays {
+		metrics = append(metrics, models.TimeSeriesMetric{
+			AccountID:      accountID,
+			OrganizationID: organizationID,
+			MetricType:     models.MetricTypeQRScanCount,
+			MetricName:     "QR Code Scans",
+			Value:          float64(day.Total),
+			Count:          day.Total,
+			Timestamp:      day.Day,
+			Granularity:    models.GranularityDaily,
+		}, models.TimeSeriesMetric{
+			AccountID:      accountID,
+			OrganizationID: organizationID,
+			MetricType:     models.MetricTypeQRUniqueScans,
+			MetricName:     "Unique QR Code Scans",
+			Value:          float64(day.Unique),
+			Count:          day.Unique,
+			Timestamp:      day.Day,
+			Granularity:    models.GranularityDaily,
+		})
+	}
+
 	return metrics, nil
 }
 
@@ -364,6 +395,14 @@ func (s *TimeSeriesService) getMetricName(questionType string, questionTexts []s
 		return "Single Choice Questions"
 	case string(feedbackmodel.QuestionTypeMultiChoice):
 		return "Multiple Choice Questions"
+	case string(feedbackmodel.QuestionTypeNPS):
+		return "Net Promoter Score"
+	case string(feedbackmodel.QuestionTypeMatrix):
+		return "Matrix Questions"
+	case string(feedbackmodel.QuestionTypeRanking):
+		return "Ranking Questions"
+	case string(feedbackmodel.QuestionTypePhoto):
+		return "Photo Uploads"
 	default:
 		return "Other Questions"
 	}
@@ -558,6 +597,8 @@ func (s *TimeSeriesService) aggregatePeriodMetrics(metrics []models.TimeSeriesMe
 		} else if strings.Contains(*metrics[0].Metadata, `"question_type": "scale"`) {
 			value = average
 			fmt.Printf("DEBUG: Using average for scale question: %f\n", value)
+		} else if metadata := strings.ReplaceAll(*metrics[0].Metadata, " ", ""); strings.Contains(metadata, `"question_type":"nps"`) || strings.Contains(metadata, `"question_type":"matrix"`) {
+			value = average
 		}
 	}
 
@@ -1124,11 +1165,62 @@ func (s *TimeSeriesService) processQuestionByType(questionType string, responses
 		return float64(len(responses))
 	case string(feedbackmodel.QuestionTypeMultiChoice):
 		return float64(len(responses))
+	case string(feedbackmodel.QuestionTypeNPS):
+		return s.processNPSQuestion(responses)
+	case string(feedbackmodel.QuestionTypeMatrix):
+		return s.processMatrixQuestion(responses)
+	case string(feedbackmodel.QuestionTypeRanking):
+		return s.processRankingQuestion(responses)
 	default:
 		return float64(len(responses))
 	}
 }
 
+func (s *TimeSeriesService) processNPSQuestion(responses []any) float64 {
+	var promoters, detractors, total int64
+	for _, resp := range responses {
+		score, ok := resp.(float64)
+		if !ok {
+			continue
+		}
+		switch feedbackmodel.NPSCategory(score) {
+		case feedbackmodel.NPSPromoter:
+			promoters++
+		case feedbackmodel.NPSDetractor:
+			detractors++
+		}
+		total++
+	}
+	return feedbackmodel.NPSScore(promoters, detractors, total)
+}
+
+func (s *TimeSeriesService) processMatrixQuestion(responses []any) float64 {
+	var total float64
+	validCount := 0
+	for _, resp := range responses {
+		for _, score := range feedbackmodel.MatrixScores(resp) {
+			total += score
+			validCount++
+		}
+	}
+	if validCount > 0 {
+		return total / float64(validCount)
+	}
+	return 0
+}
+
+// processRankingQuestion has no single meaningful average, so the daily value
+// is the number of complete rankings submitted.
+func (s *TimeSeriesService) processRankingQuestion(responses []any) float64 {
+	count := 0
+	for _, resp := range responses {
+		if len(feedbackmodel.RankingOrder(resp)) > 0 {
+			count++
+		}
+	}
+	return float64(count)
+}
+
 func (s *TimeSeriesService) CleanupOldMetrics(ctx context.Context, retentionDays int) error {
 	cutoffDate := time.Now().AddDate(0, 0, -retentionDays)
 	return s.timeSeriesRepo.DeleteOldMetrics(ctx, cutoffDate)
@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	feedbackinterface "kyooar/internal/feedback/interface"
+	feedbackmodel "kyooar/internal/feedback/model"
+	"kyooar/internal/shared/logger"
+	"kyooar/internal/shared/middleware"
+	"kyooar/internal/shared/response"
+)
+
+type ExportController struct {
+	exportService feedbackinterface.ExportService
+	viewService   feedbackinterface.ViewService
+}
+
+func NewExportController(exportService feedbackinterface.ExportService, viewService feedbackinterface.ViewService) *ExportController {
+	return &ExportController{
+		exportService: exportService,
+		viewService:   viewService,
+	}
+}
+
+// @Summary Export feedback
+// @Description Export the feedback matching the explorer filters as CSV, XLSX or NDJSON, with one column per question. Small exports stream in the response; large ones, or any export with background=true, run in the background and return 202 with an export to poll for its download link. With view_id the saved view's filters and columns are used instead of the filter parameters. Requires the feedback explorer plan feature.
+// @Tags feedback
+// @Produce json
+// @Produce octet-stream
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param format query string false "csv (default), xlsx or ndjson"
+// @Param background query boolean false "Always run the export in the background"
+// @Param view_id query string false "Saved view to export"
+// @Param search query string false "Full-text search over customer fields and written answers"
+// @Param rating_min query int false "Minimum rating (1-5)"
+// @Param rating_max query int false "Maximum rating (1-5)"
+// @Param date_from query string false "Start date (YYYY-MM-DD format)"
+// @Param date_to query string false "End date (YYYY-MM-DD format)"
+// @Param product_id query string false "Filter by specific product ID"
+// @Param status query string false "Filter by workflow status"
+// @Param assignee_id query string false "Filter by assigned team member ID, or none"
+// @Param tags query string false "Comma separated tags the feedback must all carry"
+// @Success 200 {file} file
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 402 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/export [get]
+func (h *ExportController) Export(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	memberID, err := middleware.GetMemberID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	format := feedbackmodel.ExportFormat(c.QueryParam("format"))
+	if format == "" {
+		format = feedbackmodel.ExportFormatCSV
+	}
+
+	filters := feedbackFilterFromQuery(c)
+	var columns []string
+	if viewIDStr := c.QueryParam("view_id"); viewIDStr != "" {
+		viewID, err := uuid.Parse(viewIDStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid view ID")
+		}
+		view, viewFilters, err := h.viewService.ResolveView(ctx, accountID, organizationID, memberID, viewID)
+		if err != nil {
+			return response.Error(c, err)
+		}
+		filters = viewFilters
+		columns = view.Columns
+	}
+
+	plan, err := h.exportService.Prepare(ctx, accountID, organizationID, format, filters)
+	if err != nil {
+		return response.Error(c, err)
+	}
+	plan.Columns = columns
+
+	background, _ := strconv.ParseBool(c.QueryParam("background"))
+	if plan.Background || background {
+		export, err := h.exportService.StartBackground(ctx, plan, memberID)
+		if err != nil {
+			return response.Error(c, err)
+		}
+		return c.JSON(http.StatusAccepted, map[string]interface{}{
+			"success": true,
+			"data":    export,
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, format.ContentType())
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", plan.FileName))
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	if _, err := h.exportService.Write(ctx, plan, c.Response()); err != nil {
+		if !c.Response().Committed {
+			c.Response().Header().Del(echo.HeaderContentDisposition)
+			return response.Error(c, err)
+		}
+		// Headers are already sent, so the client sees a truncated file.
+		logger.Error("Feedback export interrupted", err, logrus.Fields{
+			"organization_id": organizationID,
+			"format":          format,
+		})
+	}
+	return nil
+}
+
+// @Summary List feedback exports
+// @Description List recent background exports of the organization's feedback, with download links for finished ones
+// @Tags feedback
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/exports [get]
+func (h *ExportController) ListExports(c echo.Context) error {
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	exports, err := h.exportService.ListExports(c.Request().Context(), accountID, organizationID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    exports,
+	})
+}
+
+// @Summary Get a feedback export
+// @Description Get the progress of a background export and, once completed, a short-lived download link
+// @Tags feedback
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param exportId path string true "Export ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/exports/{exportId} [get]
+func (h *ExportController) GetExport(c echo.Context) error {
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	exportID, err := uuid.Parse(c.Param("exportId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid export ID")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	export, err := h.exportService.GetExport(c.Request().Context(), accountID, organizationID, exportID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    export,
+	})
+}
@@ -1,8 +1,10 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,35 +14,50 @@ import (
 	"kyooar/internal/shared/logger"
 	"kyooar/internal/shared/middleware"
 	sharedModels "kyooar/internal/shared/models"
+	"kyooar/internal/shared/response"
 	"github.com/sirupsen/logrus"
 )
 
 type FeedbackController struct {
 	feedbackService feedbackinterface.FeedbackService
+	viewService     feedbackinterface.ViewService
 }
 
-func NewFeedbackController(feedbackService feedbackinterface.FeedbackService) *FeedbackController {
+func NewFeedbackController(feedbackService feedbackinterface.FeedbackService, viewService feedbackinterface.ViewService) *FeedbackController {
 	return &FeedbackController{
 		feedbackService: feedbackService,
+		viewService:     viewService,
 	}
 }
 
 // @Summary Get organization feedback with filters
-// @Description Get all feedback for a specific organization with pagination and optional filters
+// @Description Get all feedback for a specific organization with pagination and optional filters. With view_id the saved view's filters and sort order are used instead of the filter parameters.
 // @Tags feedback
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param organizationId path string true "Organization ID"
 // @Param page query int false "Page number (default: 1)"
+// @Param cursor query string false "next_cursor of the previous page; replaces page and stays fast on deep pages"
+// @Param include_total query boolean false "Include total and total_pages (default: true without a cursor, false with one)"
 // @Param limit query int false "Items per page (default: 20, max: 100)"
-// @Param search query string false "Search in comments, customer name, or email"
+// @Param view_id query string false "Saved view to list"
+// @Param sort query string false "newest (default), oldest, highest_rating, lowest_rating or product"
+// @Param search query string false "Full-text search over customer name, email, phone and written answers; results are ranked and include a highlighted snippet"
 // @Param rating_min query int false "Minimum rating (1-5)"
 // @Param rating_max query int false "Maximum rating (1-5)"
 // @Param date_from query string false "Start date (YYYY-MM-DD format)"
 // @Param date_to query string false "End date (YYYY-MM-DD format)"
 // @Param product_id query string false "Filter by specific product ID"
 // @Param is_complete query boolean false "Filter by completion status"
+// @Param version_id query string false "Filter by questionnaire version ID"
+// @Param is_quarantined query boolean false "Filter by quarantine status"
+// @Param device_id query string false "Filter by kiosk device ID"
+// @Param status query string false "Filter by workflow status (new, in_progress, resolved, dismissed)"
+// @Param assignee_id query string false "Filter by assigned team member ID, or none for unassigned feedback"
+// @Param tags query string false "Comma separated tags the feedback must all carry"
+// @Param is_imported query boolean false "Filter by whether the feedback was imported from a file"
+// @Param import_id query string false "Filter by the import that created the feedback"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
@@ -56,86 +73,103 @@ func (h *FeedbackController) GetByOrganization(c echo.Context) error {
 
 	accountID := middleware.GetResourceAccountID(c)
 
-	page, _ := strconv.Atoi(c.QueryParam("page"))
-	if page < 1 {
-		page = 1
+	pageReq := sharedModels.PageRequest{Cursor: c.QueryParam("cursor")}
+	pageReq.Page, _ = strconv.Atoi(c.QueryParam("page"))
+	if pageReq.Page < 1 {
+		pageReq.Page = 1
 	}
 
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	if limit < 1 || limit > 100 {
-		limit = 20
+	pageReq.Limit, _ = strconv.Atoi(c.QueryParam("limit"))
+	if pageReq.Limit < 1 || pageReq.Limit > 100 {
+		pageReq.Limit = 20
 	}
 
-	filters := feedbackmodel.FeedbackFilter{
-		Search: c.QueryParam("search"),
+	// Page number clients get the total unless they opt out. Cursor clients
+	// get it only when they ask, since counting is slow on large lists.
+	pageReq.IncludeTotal = pageReq.Cursor == ""
+	if includeTotal := c.QueryParam("include_total"); includeTotal != "" {
+		pageReq.IncludeTotal = includeTotal == "true"
 	}
 
-	if ratingMinStr := c.QueryParam("rating_min"); ratingMinStr != "" {
-		if ratingMin, err := strconv.Atoi(ratingMinStr); err == nil && ratingMin >= 1 && ratingMin <= 5 {
-			filters.RatingMin = &ratingMin
-		}
-	}
-	if ratingMaxStr := c.QueryParam("rating_max"); ratingMaxStr != "" {
-		if ratingMax, err := strconv.Atoi(ratingMaxStr); err == nil && ratingMax >= 1 && ratingMax <= 5 {
-			filters.RatingMax = &ratingMax
-		}
+	sort := feedbackmodel.FeedbackSort(c.QueryParam("sort"))
+	if sort != "" && !sort.IsValid() {
+		return echo.NewHTTPError(http.StatusBadRequest, "Sort must be one of newest, oldest, highest_rating, lowest_rating or product")
 	}
 
-	if dateFromStr := c.QueryParam("date_from"); dateFromStr != "" {
-		if dateFrom, err := time.Parse("2006-01-02", dateFromStr); err == nil {
-			filters.DateFrom = &dateFrom
+	filters := feedbackFilterFromQuery(c)
+
+	var view *feedbackmodel.FeedbackView
+	if viewIDStr := c.QueryParam("view_id"); viewIDStr != "" {
+		viewID, err := uuid.Parse(viewIDStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid view ID")
 		}
-	}
-	if dateToStr := c.QueryParam("date_to"); dateToStr != "" {
-		if dateTo, err := time.Parse("2006-01-02", dateToStr); err == nil {
-			filters.DateTo = &dateTo
+		memberID, err := middleware.GetMemberID(c)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
 		}
-	}
-
-	if productIDStr := c.QueryParam("product_id"); productIDStr != "" {
-		if productID, err := uuid.Parse(productIDStr); err == nil {
-			filters.ProductID = &productID
+		view, filters, err = h.viewService.ResolveView(ctx, accountID, organizationID, memberID, viewID)
+		if err != nil {
+			return response.Error(c, err)
 		}
-	}
-
-	if isCompleteStr := c.QueryParam("is_complete"); isCompleteStr != "" {
-		if isComplete, err := strconv.ParseBool(isCompleteStr); err == nil {
-			filters.IsComplete = &isComplete
+		if sort == "" {
+			sort = view.Sort
 		}
 	}
+	pageReq.Sort = string(sort)
 
-	hasFilters := filters.Search != "" || filters.RatingMin != nil || filters.RatingMax != nil ||
-		filters.DateFrom != nil || filters.DateTo != nil || filters.ProductID != nil || filters.IsComplete != nil
+	hasFilters := view != nil || sort != "" || filters.Search != "" || filters.RatingMin != nil || filters.RatingMax != nil ||
+		filters.DateFrom != nil || filters.DateTo != nil || filters.ProductID != nil || filters.IsComplete != nil ||
+		filters.QuestionnaireVersionID != nil || filters.IsQuarantined != nil ||
+		filters.KioskDeviceID != nil || filters.Status != nil || filters.AssigneeID != nil ||
+		filters.Unassigned || len(filters.Tags) > 0 || filters.IsImported != nil || filters.ImportID != nil
 
 	var feedbacks interface{}
 	if hasFilters {
-		feedbacks, err = h.feedbackService.GetByOrganizationIDWithFilters(ctx, accountID, organizationID, page, limit, filters)
+		feedbacks, err = h.feedbackService.GetByOrganizationIDWithFilters(ctx, accountID, organizationID, pageReq, filters)
 	} else {
-		feedbacks, err = h.feedbackService.GetByOrganizationID(ctx, accountID, organizationID, page, limit)
+		feedbacks, err = h.feedbackService.GetByOrganizationID(ctx, accountID, organizationID, pageReq)
 	}
 
+	if errors.Is(err, sharedModels.ErrInvalidCursor) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid cursor")
+	}
 	if err != nil {
 		logger.Error("Failed to get feedbacks", err, logrus.Fields{
 			"account_id":      accountID,
 			"organization_id": organizationID,
-			"page":            page,
-			"limit":           limit,
+			"page":            pageReq.Page,
+			"cursor":          pageReq.Cursor,
+			"limit":           pageReq.Limit,
 			"filters":         filters,
 		})
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to get feedbacks")
 	}
 
 	if response, ok := feedbacks.(*sharedModels.PageResponse[feedbackmodel.Feedback]); ok {
-		return c.JSON(http.StatusOK, map[string]interface{}{
+		meta := map[string]interface{}{
+			"limit":    response.Limit,
+			"has_more": response.HasMore,
+		}
+		if response.NextCursor != "" {
+			meta["next_cursor"] = response.NextCursor
+		}
+		if pageReq.Cursor == "" {
+			meta["page"] = response.Page
+		}
+		if pageReq.IncludeTotal {
+			meta["total"] = response.Total
+			meta["total_pages"] = response.TotalPages
+		}
+		result := map[string]interface{}{
 			"success": true,
 			"data":    response.Data,
-			"meta": map[string]interface{}{
-				"total":       response.Total,
-				"page":        response.Page,
-				"limit":       response.Limit,
-				"total_pages": response.TotalPages,
-			},
-		})
+			"meta":    meta,
+		}
+		if view != nil {
+			result["view"] = view
+		}
+		return c.JSON(http.StatusOK, result)
 	}
 
 	return echo.NewHTTPError(http.StatusInternalServerError, "Failed to process response")
@@ -176,4 +210,154 @@ func (h *FeedbackController) GetStats(c echo.Context) error {
 		"success": true,
 		"data":    stats,
 	})
-}
\ No newline at end of file
+}
+
+// @Summary Review quarantined feedback
+// @Description Approve quarantined feedback so it counts in analytics again, or reject it to keep it excluded
+// @Tags feedback
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param feedbackId path string true "Feedback ID"
+// @Param request body feedbackmodel.ReviewFeedbackRequest true "Review decision"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/{feedbackId}/review [post]
+func (h *FeedbackController) ReviewFeedback(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	feedbackID, err := uuid.Parse(c.Param("feedbackId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid feedback ID")
+	}
+
+	var req feedbackmodel.ReviewFeedbackRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+	if req.Action != feedbackmodel.ReviewActionApprove && req.Action != feedbackmodel.ReviewActionReject {
+		return echo.NewHTTPError(http.StatusBadRequest, "Action must be approve or reject")
+	}
+
+	reviewerID, err := middleware.GetMemberID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	feedback, err := h.feedbackService.Review(ctx, accountID, organizationID, feedbackID, reviewerID, &req)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    feedback,
+	})
+}
+
+// feedbackFilterFromQuery reads the feedback explorer filters from the query
+// string. Malformed values are ignored rather than rejected.
+func feedbackFilterFromQuery(c echo.Context) feedbackmodel.FeedbackFilter {
+	filters := feedbackmodel.FeedbackFilter{
+		Search: c.QueryParam("search"),
+	}
+
+	if ratingMinStr := c.QueryParam("rating_min"); ratingMinStr != "" {
+		if ratingMin, err := strconv.Atoi(ratingMinStr); err == nil && ratingMin >= 1 && ratingMin <= 5 {
+			filters.RatingMin = &ratingMin
+		}
+	}
+	if ratingMaxStr := c.QueryParam("rating_max"); ratingMaxStr != "" {
+		if ratingMax, err := strconv.Atoi(ratingMaxStr); err == nil && ratingMax >= 1 && ratingMax <= 5 {
+			filters.RatingMax = &ratingMax
+		}
+	}
+
+	if dateFromStr := c.QueryParam("date_from"); dateFromStr != "" {
+		if dateFrom, err := time.Parse("2006-01-02", dateFromStr); err == nil {
+			filters.DateFrom = &dateFrom
+		}
+	}
+	if dateToStr := c.QueryParam("date_to"); dateToStr != "" {
+		if dateTo, err := time.Parse("2006-01-02", dateToStr); err == nil {
+			filters.DateTo = &dateTo
+		}
+	}
+
+	if productIDStr := c.QueryParam("product_id"); productIDStr != "" {
+		if productID, err := uuid.Parse(productIDStr); err == nil {
+			filters.ProductID = &productID
+		}
+	}
+
+	if isCompleteStr := c.QueryParam("is_complete"); isCompleteStr != "" {
+		if isComplete, err := strconv.ParseBool(isCompleteStr); err == nil {
+			filters.IsComplete = &isComplete
+		}
+	}
+
+	if versionIDStr := c.QueryParam("version_id"); versionIDStr != "" {
+		if versionID, err := uuid.Parse(versionIDStr); err == nil {
+			filters.QuestionnaireVersionID = &versionID
+		}
+	}
+
+	if isQuarantinedStr := c.QueryParam("is_quarantined"); isQuarantinedStr != "" {
+		if isQuarantined, err := strconv.ParseBool(isQuarantinedStr); err == nil {
+			filters.IsQuarantined = &isQuarantined
+		}
+	}
+
+	if deviceIDStr := c.QueryParam("device_id"); deviceIDStr != "" {
+		if deviceID, err := uuid.Parse(deviceIDStr); err == nil {
+			filters.KioskDeviceID = &deviceID
+		}
+	}
+
+	if statusStr := c.QueryParam("status"); statusStr != "" {
+		if status := feedbackmodel.FeedbackStatus(statusStr); status.IsValid() {
+			filters.Status = &status
+		}
+	}
+
+	if assigneeIDStr := c.QueryParam("assignee_id"); assigneeIDStr == "none" {
+		filters.Unassigned = true
+	} else if assigneeIDStr != "" {
+		if assigneeID, err := uuid.Parse(assigneeIDStr); err == nil {
+			filters.AssigneeID = &assigneeID
+		}
+	}
+
+	if tagsStr := c.QueryParam("tags"); tagsStr != "" {
+		if tags, err := feedbackmodel.NormalizeTags(strings.Split(tagsStr, ",")); err == nil {
+			filters.Tags = tags
+		}
+	}
+
+	if isImportedStr := c.QueryParam("is_imported"); isImportedStr != "" {
+		if isImported, err := strconv.ParseBool(isImportedStr); err == nil {
+			filters.IsImported = &isImported
+		}
+	}
+
+	if importIDStr := c.QueryParam("import_id"); importIDStr != "" {
+		if importID, err := uuid.Parse(importIDStr); err == nil {
+			filters.ImportID = &importID
+		}
+	}
+
+	return filters
+}
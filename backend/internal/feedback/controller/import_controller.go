@@ -0,0 +1,228 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	feedbackinterface "kyooar/internal/feedback/interface"
+	feedbackmodel "kyooar/internal/feedback/model"
+	"kyooar/internal/shared/middleware"
+	"kyooar/internal/shared/response"
+)
+
+type ImportController struct {
+	importService feedbackinterface.ImportService
+}
+
+func NewImportController(importService feedbackinterface.ImportService) *ImportController {
+	return &ImportController{
+		importService: importService,
+	}
+}
+
+// @Summary Import feedback from CSV
+// @Description Import historical feedback from a CSV file. The mapping names the columns holding the product, submission date, overall rating, customer details and question answers. Multiple choice and ranking answers are separated by semicolons; matrix answers are JSON objects. With dry_run every row is checked and the errors are reported without importing anything. Otherwise the import is all or nothing and fails with the same report when any row has errors.
+// @Tags feedback
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param file formData file true "CSV file with a header row"
+// @Param mapping formData string true "Column mapping as JSON (feedbackmodel.ImportMapping)"
+// @Param dry_run formData boolean false "Only check the file"
+// @Param count_toward_limit formData boolean false "Count the imported feedback against the monthly plan limit"
+// @Param include_in_analytics formData boolean false "Include the imported feedback in analytics (default true)"
+// @Success 200 {object} map[string]interface{}
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 402 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/imports [post]
+func (h *ImportController) Import(c echo.Context) error {
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	memberID, err := middleware.GetMemberID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	// Leave room for the multipart envelope around the file itself
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, feedbackmodel.MaxImportFileSize+64*1024)
+
+	req := feedbackmodel.ImportFeedbackRequest{IncludeInAnalytics: true}
+	if err := json.Unmarshal([]byte(c.FormValue("mapping")), &req.Mapping); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid mapping")
+	}
+	for name, target := range map[string]*bool{
+		"dry_run":              &req.DryRun,
+		"count_toward_limit":   &req.CountTowardLimit,
+		"include_in_analytics": &req.IncludeInAnalytics,
+	} {
+		if value := c.FormValue(name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "Invalid "+name)
+			}
+			*target = parsed
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "A CSV file is required")
+	}
+	if fileHeader.Size > feedbackmodel.MaxImportFileSize {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "File is too large")
+	}
+	req.FileName = fileHeader.Filename
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Failed to read uploaded file")
+	}
+	defer file.Close()
+
+	report, err := h.importService.Import(c.Request().Context(), accountID, organizationID, memberID, file, req)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	status := http.StatusOK
+	if report.Import != nil {
+		status = http.StatusCreated
+	}
+	return c.JSON(status, map[string]interface{}{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// @Summary List feedback imports
+// @Description List the organization's feedback imports, newest first
+// @Tags feedback
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/imports [get]
+func (h *ImportController) ListImports(c echo.Context) error {
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	imports, err := h.importService.ListImports(c.Request().Context(), accountID, organizationID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    imports,
+	})
+}
+
+// @Summary Update a feedback import
+// @Description Include or exclude the feedback of an import in analytics
+// @Tags feedback
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param importId path string true "Import ID"
+// @Param request body feedbackmodel.UpdateImportRequest true "Import settings"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/imports/{importId} [put]
+func (h *ImportController) UpdateImport(c echo.Context) error {
+	organizationID, importID, err := importParams(c)
+	if err != nil {
+		return err
+	}
+
+	var req feedbackmodel.UpdateImportRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+	if req.IncludeInAnalytics == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "include_in_analytics is required")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	feedbackImport, err := h.importService.SetIncludeInAnalytics(c.Request().Context(), accountID, organizationID, importID, *req.IncludeInAnalytics)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    feedbackImport,
+	})
+}
+
+// @Summary Delete a feedback import
+// @Description Delete an import together with all the feedback it created
+// @Tags feedback
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param importId path string true "Import ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/imports/{importId} [delete]
+func (h *ImportController) DeleteImport(c echo.Context) error {
+	organizationID, importID, err := importParams(c)
+	if err != nil {
+		return err
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	if err := h.importService.DeleteImport(c.Request().Context(), accountID, organizationID, importID); err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Import deleted",
+	})
+}
+
+func importParams(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+	importID, err := uuid.Parse(c.Param("importId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid import ID")
+	}
+	return organizationID, importID, nil
+}
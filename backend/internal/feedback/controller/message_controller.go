@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	feedbackinterface "kyooar/internal/feedback/interface"
+	feedbackmodel "kyooar/internal/feedback/model"
+	"kyooar/internal/shared/middleware"
+	"kyooar/internal/shared/response"
+)
+
+type MessageController struct {
+	messageService feedbackinterface.MessageService
+}
+
+func NewMessageController(messageService feedbackinterface.MessageService) *MessageController {
+	return &MessageController{
+		messageService: messageService,
+	}
+}
+
+// @Summary List feedback messages
+// @Description List the conversation with the customer about a feedback, oldest first
+// @Tags feedback
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param feedbackId path string true "Feedback ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/{feedbackId}/messages [get]
+func (h *MessageController) ListMessages(c echo.Context) error {
+	organizationID, feedbackID, err := feedbackParams(c)
+	if err != nil {
+		return err
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	messages, err := h.messageService.ListMessages(c.Request().Context(), accountID, organizationID, feedbackID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    messages,
+	})
+}
+
+// @Summary Reply to a customer
+// @Description Email a reply to the customer who left the feedback. The email carries a link the customer can use to answer. A reply that cannot be delivered is kept with a failed status.
+// @Tags feedback
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param feedbackId path string true "Feedback ID"
+// @Param request body feedbackmodel.SendMessageRequest true "Message"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/{feedbackId}/messages [post]
+func (h *MessageController) SendMessage(c echo.Context) error {
+	organizationID, feedbackID, err := feedbackParams(c)
+	if err != nil {
+		return err
+	}
+
+	var req feedbackmodel.SendMessageRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	memberID, err := middleware.GetMemberID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	message, err := h.messageService.Reply(c.Request().Context(), accountID, organizationID, feedbackID, memberID, req.Body)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    message,
+	})
+}
+
+// @Summary Resend a reply
+// @Description Retry delivering a reply that failed to send, with a new reply link
+// @Tags feedback
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param feedbackId path string true "Feedback ID"
+// @Param messageId path string true "Message ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/{feedbackId}/messages/{messageId}/resend [post]
+func (h *MessageController) ResendMessage(c echo.Context) error {
+	organizationID, feedbackID, err := feedbackParams(c)
+	if err != nil {
+		return err
+	}
+
+	messageID, err := uuid.Parse(c.Param("messageId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid message ID")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	message, err := h.messageService.Resend(c.Request().Context(), accountID, organizationID, feedbackID, messageID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    message,
+	})
+}
+
+func feedbackParams(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	feedbackID, err := uuid.Parse(c.Param("feedbackId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid feedback ID")
+	}
+
+	return organizationID, feedbackID, nil
+}
@@ -1,10 +1,17 @@
 package controller
 
 import (
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	feedbackinterface "kyooar/internal/feedback/interface"
 	feedbackmodel "kyooar/internal/feedback/model"
+	kioskmodel "kyooar/internal/kiosk/model"
 	menuRepos "kyooar/internal/product/repositories"
 	"kyooar/internal/shared/errors"
 	"kyooar/internal/shared/logger"
@@ -13,39 +20,68 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Headers carrying the proof-of-work challenge issued by QR code validation.
+const (
+	challengeHeader         = "X-Feedback-Challenge"
+	challengeSolutionHeader = "X-Feedback-Challenge-Solution"
+)
+
+// qrTokenHeader carries the token from a rotating QR code's URL.
+const qrTokenHeader = "X-QR-Token"
+
+// Headers of the idempotent submission contract. Replayed is set when the
+// key was already used and the stored feedback is returned instead.
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+)
+
 type PublicController struct {
-	feedbackService   feedbackinterface.FeedbackService
+	feedbackService      feedbackinterface.FeedbackService
+	questionnaireService feedbackinterface.QuestionnaireService
+	attachmentService    feedbackinterface.AttachmentService
 	productRepo          menuRepos.ProductRepository
-	questionnaireRepo feedbackinterface.QuestionnaireRepository
-	questionRepo      feedbackinterface.QuestionRepository
+	questionnaireRepo    feedbackinterface.QuestionnaireRepository
+	questionRepo         feedbackinterface.QuestionRepository
+	messageService       feedbackinterface.MessageService
 }
 
 func NewPublicController(
 	feedbackService feedbackinterface.FeedbackService,
+	questionnaireService feedbackinterface.QuestionnaireService,
+	attachmentService feedbackinterface.AttachmentService,
 	productRepo menuRepos.ProductRepository,
 	questionnaireRepo feedbackinterface.QuestionnaireRepository,
 	questionRepo feedbackinterface.QuestionRepository,
+	messageService feedbackinterface.MessageService,
 ) *PublicController {
 	return &PublicController{
-		feedbackService:   feedbackService,
-		productRepo:       productRepo,
+		feedbackService:      feedbackService,
+		questionnaireService: questionnaireService,
+		attachmentService:    attachmentService,
+		productRepo:          productRepo,
 		questionnaireRepo: questionnaireRepo,
 		questionRepo:      questionRepo,
+		messageService:    messageService,
 	}
 }
 
 // @Summary Get questionnaire
-// @Description Get questionnaire for a specific product
+// @Description Get the active questionnaire for a product, falling back to the organization default, with its ordered questions and organization branding
 // @Tags public
 // @Accept json
 // @Produce json
 // @Param organizationId path string true "Organization ID"
 // @Param productId path string true "Product ID"
-// @Success 200 {object} map[string]interface{}
+// @Param lang query string false "Preferred language, overriding the Accept-Language header"
+// @Param Accept-Language header string false "Preferred languages"
+// @Success 200 {object} response.Response{data=feedbackmodel.PublicQuestionnaire}
 // @Failure 400 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
 // @Router /api/v1/public/questionnaire/{organizationId}/{productId} [get]
 func (h *PublicController) GetQuestionnaire(c echo.Context) error {
+	ctx := c.Request().Context()
 	organizationIDStr := c.Param("organizationId")
 	productIDStr := c.Param("productId")
 
@@ -59,11 +95,19 @@ func (h *PublicController) GetQuestionnaire(c echo.Context) error {
 		return response.Error(c, errors.BadRequest("Invalid product ID format"))
 	}
 
-	return response.Success(c, map[string]interface{}{
-		"organization_id": organizationID,
-		"product_id":      productID,
-		"message":         "Questionnaire endpoint - to be implemented",
-	})
+	questionnaire, err := h.questionnaireService.GetPublicQuestionnaire(ctx, organizationID, productID, requestedLanguages(c))
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return response.Error(c, appErr)
+		}
+		logger.Error("Failed to get public questionnaire", err, logrus.Fields{
+			"organization_id": organizationID,
+			"product_id":      productID,
+		})
+		return response.Error(c, errors.Internal("Failed to get questionnaire"))
+	}
+
+	return response.Success(c, questionnaire)
 }
 
 // @Summary Submit feedback
@@ -71,17 +115,27 @@ func (h *PublicController) GetQuestionnaire(c echo.Context) error {
 // @Tags public
 // @Accept json
 // @Produce json
-// @Param feedback body feedbackmodel.Feedback true "Feedback data"
+// @Param feedback body feedbackmodel.SubmitFeedbackRequest true "Feedback data"
+// @Param X-Feedback-Challenge header string false "Challenge token issued when the QR code was validated"
+// @Param X-Feedback-Challenge-Solution header string false "Solution to the challenge"
+// @Param Idempotency-Key header string false "Client generated key; retrying with the same key returns the stored feedback instead of creating another"
+// @Param X-Device-Token header string false "Token of a paired kiosk device"
+// @Param X-QR-Token header string false "Token from the URL of a rotating QR code"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 429 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/public/feedback [post]
 func (h *PublicController) SubmitFeedback(c echo.Context) error {
 	ctx := c.Request().Context()
-	var feedback feedbackmodel.Feedback
-	if err := c.Bind(&feedback); err != nil {
+	var req feedbackmodel.SubmitFeedbackRequest
+	if err := c.Bind(&req); err != nil {
 		return response.Error(c, errors.BadRequest("Invalid feedback data provided"))
 	}
+	feedback := req.Feedback()
 
 	deviceInfo := utils.ExtractDeviceInfo(c.Request())
 	feedback.DeviceInfo = feedbackmodel.DeviceInfo{
@@ -90,25 +144,80 @@ func (h *PublicController) SubmitFeedback(c echo.Context) error {
 		Platform:  deviceInfo.Platform,
 		Browser:   deviceInfo.Browser,
 	}
+	feedback.Challenge = c.Request().Header.Get(challengeHeader)
+	feedback.ChallengeSolution = c.Request().Header.Get(challengeSolutionHeader)
+	feedback.IdempotencyKey = c.Request().Header.Get(idempotencyKeyHeader)
+	feedback.DeviceToken = c.Request().Header.Get(kioskmodel.DeviceTokenHeader)
+	feedback.QRToken = c.Request().Header.Get(qrTokenHeader)
 
-	if err := h.feedbackService.Submit(ctx, &feedback); err != nil {
+	result, err := h.feedbackService.Submit(ctx, feedback)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return response.Error(c, appErr)
+		}
 		logger.Error("Failed to submit feedback", err, logrus.Fields{
-			"feedback": feedback,
+			"qr_code_id": feedback.QRCodeID,
+			"product_id": feedback.ProductID,
 		})
 		return response.Error(c, errors.Internal("Failed to process feedback submission"))
 	}
 
+	if result.Status == feedbackmodel.SubmissionStatusDuplicate {
+		c.Response().Header().Set(idempotentReplayedHeader, "true")
+	}
+
 	return response.Success(c, map[string]string{
 		"message": "Thank you for your feedback!",
 	})
 }
 
+// @Summary Submit queued feedback in a batch
+// @Description Submit feedback queued by an offline client. Every item needs its own idempotency_key and captured_at, so resending a batch after a lost response is safe. Items are processed independently and the response reports the outcome of each one. The whole batch must fit in what is left of the hourly submission limits; resent items that were already stored do not count.
+// @Tags public
+// @Accept json
+// @Produce json
+// @Param request body feedbackmodel.BatchFeedbackRequest true "Queued feedback"
+// @Param X-Device-Token header string false "Token of a paired kiosk device"
+// @Success 200 {object} response.Response{data=feedbackmodel.BatchFeedbackResponse}
+// @Failure 400 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/public/feedback/batch [post]
+func (h *PublicController) SubmitFeedbackBatch(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req feedbackmodel.BatchFeedbackRequest
+	if err := c.Bind(&req); err != nil {
+		return response.Error(c, errors.BadRequest("Invalid feedback data provided"))
+	}
+
+	deviceInfo := utils.ExtractDeviceInfo(c.Request())
+	result, err := h.feedbackService.SubmitBatch(ctx, req.Items, feedbackmodel.DeviceInfo{
+		UserAgent: deviceInfo.UserAgent,
+		IP:        deviceInfo.IP,
+		Platform:  deviceInfo.Platform,
+		Browser:   deviceInfo.Browser,
+	}, c.Request().Header.Get(kioskmodel.DeviceTokenHeader))
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return response.Error(c, appErr)
+		}
+		logger.Error("Failed to submit feedback batch", err, logrus.Fields{
+			"items": len(req.Items),
+		})
+		return response.Error(c, errors.Internal("Failed to process feedback submission"))
+	}
+
+	return response.Success(c, result)
+}
+
 // @Summary Get questions for a product
 // @Description Get all feedback questions for a specific product (public access for customer feedback)
 // @Tags public
 // @Produce json
 // @Param organizationId path string true "Organization ID"
 // @Param productId path string true "Product ID"
+// @Param lang query string false "Preferred language, overriding the Accept-Language header"
+// @Param Accept-Language header string false "Preferred languages"
 // @Success 200 {object} map[string]interface{} "Questions retrieved successfully"
 // @Failure 400 {object} response.Response "Invalid request"
 // @Failure 404 {object} response.Response "Product not found"
@@ -135,9 +244,15 @@ func (h *PublicController) GetProductQuestions(c echo.Context) error {
 		return response.Error(c, errors.Internal("Failed to get questions"))
 	}
 
+	localized, language, err := h.questionnaireService.LocalizeQuestions(ctx, product.OrganizationID, questions, requestedLanguages(c))
+	if err != nil {
+		return response.Error(c, errors.NotFound("Organization"))
+	}
+
 	return response.Success(c, map[string]interface{}{
 		"product":   product,
-		"questions": questions,
+		"questions": localized,
+		"language":  language,
 	})
 }
 
@@ -181,4 +296,331 @@ func (h *PublicController) GetProductsWithQuestions(c echo.Context) error {
 	}
 
 	return response.Success(c, products)
-}
\ No newline at end of file
+}
+
+// @Summary Start feedback session
+// @Description Start a resumable feedback session when a QR code is scanned. The returned token is only shown once and is used to save answers and finalize the feedback.
+// @Tags public
+// @Accept json
+// @Produce json
+// @Param request body feedbackmodel.StartSessionRequest true "Session data"
+// @Param X-Device-Token header string false "Token of a paired kiosk device"
+// @Param X-QR-Token header string false "Token from the URL of a rotating QR code"
+// @Success 200 {object} response.Response{data=feedbackmodel.FeedbackSession}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/public/feedback/sessions [post]
+func (h *PublicController) StartSession(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req feedbackmodel.StartSessionRequest
+	if err := c.Bind(&req); err != nil {
+		return response.Error(c, errors.BadRequest("Invalid session data provided"))
+	}
+	req.DeviceToken = c.Request().Header.Get(kioskmodel.DeviceTokenHeader)
+	req.QRToken = c.Request().Header.Get(qrTokenHeader)
+
+	deviceInfo := utils.ExtractDeviceInfo(c.Request())
+	session, err := h.feedbackService.StartSession(ctx, &req, feedbackmodel.DeviceInfo{
+		UserAgent: deviceInfo.UserAgent,
+		IP:        deviceInfo.IP,
+		Platform:  deviceInfo.Platform,
+		Browser:   deviceInfo.Browser,
+	})
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return response.Error(c, appErr)
+		}
+		logger.Error("Failed to start feedback session", err, logrus.Fields{
+			"qr_code_id": req.QRCodeID,
+			"product_id": req.ProductID,
+		})
+		return response.Error(c, errors.Internal("Failed to start feedback session"))
+	}
+
+	return response.Success(c, session)
+}
+
+// @Summary Resume feedback session
+// @Description Get the answers saved so far in a feedback session
+// @Tags public
+// @Produce json
+// @Param token path string true "Session token"
+// @Success 200 {object} response.Response{data=feedbackmodel.FeedbackSession}
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/public/feedback/sessions/{token} [get]
+func (h *PublicController) GetSession(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	session, err := h.feedbackService.GetSession(ctx, c.Param("token"))
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return response.Error(c, appErr)
+		}
+		logger.Error("Failed to get feedback session", err, logrus.Fields{})
+		return response.Error(c, errors.Internal("Failed to get feedback session"))
+	}
+
+	return response.Success(c, session)
+}
+
+// @Summary Save answer
+// @Description Save or clear the answer to a single question in a feedback session
+// @Tags public
+// @Accept json
+// @Produce json
+// @Param token path string true "Session token"
+// @Param questionId path string true "Question ID"
+// @Param request body feedbackmodel.SaveAnswerRequest true "Answer"
+// @Success 200 {object} response.Response{data=feedbackmodel.FeedbackSession}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 410 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/public/feedback/sessions/{token}/answers/{questionId} [put]
+func (h *PublicController) SaveAnswer(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	questionID, err := uuid.Parse(c.Param("questionId"))
+	if err != nil {
+		return response.Error(c, errors.BadRequest("Invalid question ID"))
+	}
+
+	var req feedbackmodel.SaveAnswerRequest
+	if err := c.Bind(&req); err != nil {
+		return response.Error(c, errors.BadRequest("Invalid answer data provided"))
+	}
+
+	session, err := h.feedbackService.SaveAnswer(ctx, c.Param("token"), questionID, req.Answer)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return response.Error(c, appErr)
+		}
+		logger.Error("Failed to save answer", err, logrus.Fields{
+			"question_id": questionID,
+		})
+		return response.Error(c, errors.Internal("Failed to save answer"))
+	}
+
+	return response.Success(c, session)
+}
+
+// @Summary Complete feedback session
+// @Description Validate all saved answers and finalize the feedback
+// @Tags public
+// @Accept json
+// @Produce json
+// @Param token path string true "Session token"
+// @Param request body feedbackmodel.CompleteSessionRequest true "Customer details"
+// @Param X-Feedback-Challenge header string false "Challenge token issued when the QR code was validated"
+// @Param X-Feedback-Challenge-Solution header string false "Solution to the challenge"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 410 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/public/feedback/sessions/{token}/complete [post]
+func (h *PublicController) CompleteSession(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req feedbackmodel.CompleteSessionRequest
+	if err := c.Bind(&req); err != nil {
+		return response.Error(c, errors.BadRequest("Invalid feedback data provided"))
+	}
+	req.Challenge = c.Request().Header.Get(challengeHeader)
+	req.ChallengeSolution = c.Request().Header.Get(challengeSolutionHeader)
+
+	if _, err := h.feedbackService.CompleteSession(ctx, c.Param("token"), &req); err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return response.Error(c, appErr)
+		}
+		logger.Error("Failed to complete feedback session", err, logrus.Fields{})
+		return response.Error(c, errors.Internal("Failed to process feedback submission"))
+	}
+
+	return response.Success(c, map[string]string{
+		"message": "Thank you for your feedback!",
+	})
+}
+
+// @Summary Upload feedback photo
+// @Description Upload a photo for a photo question. The returned ID is used as the answer. JPEG, PNG and GIF files are accepted up to the configured size limit.
+// @Tags public
+// @Accept multipart/form-data
+// @Produce json
+// @Param qr_code_id formData string true "QR code ID"
+// @Param question_id formData string false "Question ID"
+// @Param file formData file true "Photo"
+// @Param X-Device-Token header string false "Token of a paired kiosk device"
+// @Param X-QR-Token header string false "Token from the URL of a rotating QR code"
+// @Success 200 {object} response.Response{data=feedbackmodel.FeedbackAttachment}
+// @Failure 400 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 415 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/public/feedback/attachments [post]
+func (h *PublicController) UploadAttachment(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	maxSize := h.attachmentService.MaxUploadSize()
+	// Leave room for the multipart envelope around the file itself
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxSize+64*1024)
+
+	qrCodeID, err := uuid.Parse(c.FormValue("qr_code_id"))
+	if err != nil {
+		return response.Error(c, errors.BadRequest("Invalid QR code ID"))
+	}
+
+	deviceInfo := utils.ExtractDeviceInfo(c.Request())
+	req := feedbackmodel.UploadAttachmentRequest{
+		QRCodeID:    qrCodeID,
+		DeviceToken: c.Request().Header.Get(kioskmodel.DeviceTokenHeader),
+		QRToken:     c.Request().Header.Get(qrTokenHeader),
+		DeviceInfo: feedbackmodel.DeviceInfo{
+			UserAgent: deviceInfo.UserAgent,
+			IP:        deviceInfo.IP,
+			Platform:  deviceInfo.Platform,
+			Browser:   deviceInfo.Browser,
+		},
+	}
+	if questionIDStr := c.FormValue("question_id"); questionIDStr != "" {
+		questionID, err := uuid.Parse(questionIDStr)
+		if err != nil {
+			return response.Error(c, errors.BadRequest("Invalid question ID"))
+		}
+		req.QuestionID = &questionID
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return response.Error(c, errors.BadRequest("A photo file is required"))
+	}
+	if fileHeader.Size > maxSize {
+		return response.Error(c, errors.New("FILE_TOO_LARGE", "Photo is too large", http.StatusRequestEntityTooLarge))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return response.Error(c, errors.BadRequest("Failed to read uploaded file"))
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentService.Upload(ctx, &req, file)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return response.Error(c, appErr)
+		}
+		logger.Error("Failed to upload attachment", err, logrus.Fields{
+			"qr_code_id": qrCodeID,
+		})
+		return response.Error(c, errors.Internal("Failed to upload photo"))
+	}
+
+	return response.Success(c, attachment)
+}
+
+// @Summary Get reply thread
+// @Description Get the conversation opened by a reply link emailed to the customer
+// @Tags public
+// @Produce json
+// @Param token path string true "Reply token"
+// @Success 200 {object} response.Response{data=feedbackmodel.PublicThread}
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/public/feedback/replies/{token} [get]
+func (h *PublicController) GetReplyThread(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	thread, err := h.messageService.GetPublicThread(ctx, c.Param("token"))
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return response.Error(c, appErr)
+		}
+		logger.Error("Failed to get reply thread", err, logrus.Fields{})
+		return response.Error(c, errors.Internal("Failed to get conversation"))
+	}
+
+	return response.Success(c, thread)
+}
+
+// @Summary Answer a reply
+// @Description Send the customer's answer to a reply, using the link from the reply email
+// @Tags public
+// @Accept json
+// @Produce json
+// @Param token path string true "Reply token"
+// @Param request body feedbackmodel.SendMessageRequest true "Message"
+// @Success 200 {object} response.Response{data=feedbackmodel.PublicThread}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 410 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/public/feedback/replies/{token} [post]
+func (h *PublicController) PostReply(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req feedbackmodel.SendMessageRequest
+	if err := c.Bind(&req); err != nil {
+		return response.Error(c, errors.BadRequest("Invalid message data provided"))
+	}
+
+	thread, err := h.messageService.ReplyAsCustomer(ctx, c.Param("token"), req.Body)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return response.Error(c, appErr)
+		}
+		logger.Error("Failed to save customer reply", err, logrus.Fields{})
+		return response.Error(c, errors.Internal("Failed to send message"))
+	}
+
+	return response.Success(c, thread)
+}
+
+// @Summary Get file
+// @Description Serve a stored file through a signed, expiring URL
+// @Tags public
+// @Produce octet-stream
+// @Param key path string true "File key"
+// @Param expires query int true "Expiry timestamp"
+// @Param signature query string true "URL signature"
+// @Success 200 {file} file
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/public/files/{key} [get]
+func (h *PublicController) ServeFile(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	expires, err := strconv.ParseInt(c.QueryParam("expires"), 10, 64)
+	if err != nil {
+		return response.Error(c, errors.Forbidden("access this file"))
+	}
+
+	file, contentType, err := h.attachmentService.OpenSigned(ctx, c.Param("*"), expires, c.QueryParam("signature"))
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return response.Error(c, appErr)
+		}
+		return response.Error(c, errors.Internal("Failed to read file"))
+	}
+	defer file.Close()
+
+	c.Response().Header().Set("Cache-Control", "private, max-age=300")
+	if !strings.HasPrefix(contentType, "image/") {
+		c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(c.Param("*"))))
+	}
+	return c.Stream(http.StatusOK, contentType, file)
+}
+
+// requestedLanguages returns the caller's preferred languages, with the lang
+// query parameter taking precedence over the Accept-Language header.
+func requestedLanguages(c echo.Context) []string {
+	languages := feedbackmodel.ParseAcceptLanguage(c.Request().Header.Get("Accept-Language"))
+	if lang := c.QueryParam("lang"); lang != "" {
+		languages = append([]string{lang}, languages...)
+	}
+	return languages
+}
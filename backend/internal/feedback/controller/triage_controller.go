@@ -0,0 +1,267 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	feedbackinterface "kyooar/internal/feedback/interface"
+	feedbackmodel "kyooar/internal/feedback/model"
+	"kyooar/internal/shared/middleware"
+	"kyooar/internal/shared/response"
+)
+
+type TriageController struct {
+	triageService feedbackinterface.TriageService
+}
+
+func NewTriageController(triageService feedbackinterface.TriageService) *TriageController {
+	return &TriageController{
+		triageService: triageService,
+	}
+}
+
+// @Summary Update feedback status
+// @Description Move feedback through the follow-up workflow: new, in_progress, resolved or dismissed
+// @Tags feedback
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param feedbackId path string true "Feedback ID"
+// @Param request body feedbackmodel.UpdateStatusRequest true "New status"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/{feedbackId}/status [put]
+func (h *TriageController) UpdateStatus(c echo.Context) error {
+	organizationID, feedbackID, err := feedbackParams(c)
+	if err != nil {
+		return err
+	}
+
+	var req feedbackmodel.UpdateStatusRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	actorID, err := middleware.GetMemberID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	feedback, err := h.triageService.UpdateStatus(c.Request().Context(), accountID, organizationID, feedbackID, actorID, req.Status)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    feedback,
+	})
+}
+
+// @Summary Assign feedback
+// @Description Assign feedback to a team member, or clear the assignment with a null assignee_id
+// @Tags feedback
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param feedbackId path string true "Feedback ID"
+// @Param request body feedbackmodel.AssignFeedbackRequest true "Team member to assign"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/{feedbackId}/assignee [put]
+func (h *TriageController) Assign(c echo.Context) error {
+	organizationID, feedbackID, err := feedbackParams(c)
+	if err != nil {
+		return err
+	}
+
+	var req feedbackmodel.AssignFeedbackRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	actorID, err := middleware.GetMemberID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	feedback, err := h.triageService.Assign(c.Request().Context(), accountID, organizationID, feedbackID, actorID, req.AssigneeID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    feedback,
+	})
+}
+
+// @Summary Update feedback tags
+// @Description Replace the tags on a feedback. Tags are lowercased and duplicates are dropped.
+// @Tags feedback
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param feedbackId path string true "Feedback ID"
+// @Param request body feedbackmodel.UpdateTagsRequest true "Tags"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/{feedbackId}/tags [put]
+func (h *TriageController) UpdateTags(c echo.Context) error {
+	organizationID, feedbackID, err := feedbackParams(c)
+	if err != nil {
+		return err
+	}
+
+	var req feedbackmodel.UpdateTagsRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	actorID, err := middleware.GetMemberID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	feedback, err := h.triageService.UpdateTags(c.Request().Context(), accountID, organizationID, feedbackID, actorID, req.Tags)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    feedback,
+	})
+}
+
+// @Summary List internal notes
+// @Description List the internal notes on a feedback, oldest first
+// @Tags feedback
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param feedbackId path string true "Feedback ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/{feedbackId}/notes [get]
+func (h *TriageController) ListNotes(c echo.Context) error {
+	organizationID, feedbackID, err := feedbackParams(c)
+	if err != nil {
+		return err
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	notes, err := h.triageService.ListNotes(c.Request().Context(), accountID, organizationID, feedbackID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    notes,
+	})
+}
+
+// @Summary Add an internal note
+// @Description Add a note to a feedback that is only visible to the team
+// @Tags feedback
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param feedbackId path string true "Feedback ID"
+// @Param request body feedbackmodel.CreateNoteRequest true "Note"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/{feedbackId}/notes [post]
+func (h *TriageController) AddNote(c echo.Context) error {
+	organizationID, feedbackID, err := feedbackParams(c)
+	if err != nil {
+		return err
+	}
+
+	var req feedbackmodel.CreateNoteRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	authorID, err := middleware.GetMemberID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	note, err := h.triageService.AddNote(c.Request().Context(), accountID, organizationID, feedbackID, authorID, req.Body)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    note,
+	})
+}
+
+// @Summary Get feedback activity
+// @Description List status changes, assignments, tag changes and notes on a feedback, newest first
+// @Tags feedback
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param feedbackId path string true "Feedback ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/{feedbackId}/activity [get]
+func (h *TriageController) ListActivity(c echo.Context) error {
+	organizationID, feedbackID, err := feedbackParams(c)
+	if err != nil {
+		return err
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	activity, err := h.triageService.ListActivity(c.Request().Context(), accountID, organizationID, feedbackID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    activity,
+	})
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	feedbackinterface "kyooar/internal/feedback/interface"
+	"kyooar/internal/shared/middleware"
+	"kyooar/internal/shared/response"
+)
+
+type VersionController struct {
+	versionService feedbackinterface.VersionService
+}
+
+func NewVersionController(versionService feedbackinterface.VersionService) *VersionController {
+	return &VersionController{
+		versionService: versionService,
+	}
+}
+
+// @Summary List questionnaire versions
+// @Description List the recorded versions of a product's questions, newest first
+// @Tags questions
+// @Produce json
+// @Param organizationId path string true "Organization ID"
+// @Param productId path string true "Product ID"
+// @Success 200 {object} map[string]interface{} "Versions retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Access denied"
+// @Failure 404 {object} map[string]interface{} "Product not found"
+// @Failure 500 {object} map[string]interface{} "Server error"
+// @Router /api/v1/organizations/{organizationId}/products/{productId}/questionnaire-versions [get]
+// @Security BearerAuth
+func (h *VersionController) ListVersions(c echo.Context) error {
+	accountID := middleware.GetResourceAccountID(c)
+
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid product ID")
+	}
+
+	versions, err := h.versionService.ListVersions(c.Request().Context(), accountID, productID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    versions,
+	})
+}
+
+// @Summary Get a questionnaire version
+// @Description Get the question snapshot recorded for a version
+// @Tags questions
+// @Produce json
+// @Param organizationId path string true "Organization ID"
+// @Param productId path string true "Product ID"
+// @Param version path int true "Version number"
+// @Success 200 {object} map[string]interface{} "Version retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Access denied"
+// @Failure 404 {object} map[string]interface{} "Version not found"
+// @Failure 500 {object} map[string]interface{} "Server error"
+// @Router /api/v1/organizations/{organizationId}/products/{productId}/questionnaire-versions/{version} [get]
+// @Security BearerAuth
+func (h *VersionController) GetVersion(c echo.Context) error {
+	accountID := middleware.GetResourceAccountID(c)
+
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid product ID")
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid version number")
+	}
+
+	result, err := h.versionService.GetVersion(c.Request().Context(), accountID, productID, version)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// @Summary Diff two questionnaire versions
+// @Description Compare two versions of a product's questions, listing added, removed and changed questions
+// @Tags questions
+// @Produce json
+// @Param organizationId path string true "Organization ID"
+// @Param productId path string true "Product ID"
+// @Param from query int true "Base version number"
+// @Param to query int true "Version number to compare against the base"
+// @Success 200 {object} map[string]interface{} "Diff computed successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Access denied"
+// @Failure 404 {object} map[string]interface{} "Version not found"
+// @Failure 500 {object} map[string]interface{} "Server error"
+// @Router /api/v1/organizations/{organizationId}/products/{productId}/questionnaire-versions/diff [get]
+// @Security BearerAuth
+func (h *VersionController) DiffVersions(c echo.Context) error {
+	accountID := middleware.GetResourceAccountID(c)
+
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid product ID")
+	}
+
+	from, err := strconv.Atoi(c.QueryParam("from"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid from version")
+	}
+
+	to, err := strconv.Atoi(c.QueryParam("to"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid to version")
+	}
+
+	diff, err := h.versionService.DiffVersions(c.Request().Context(), accountID, productID, from, to)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    diff,
+	})
+}
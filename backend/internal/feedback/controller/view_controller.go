@@ -0,0 +1,294 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	feedbackinterface "kyooar/internal/feedback/interface"
+	feedbackmodel "kyooar/internal/feedback/model"
+	"kyooar/internal/shared/middleware"
+	"kyooar/internal/shared/response"
+)
+
+type ViewController struct {
+	viewService feedbackinterface.ViewService
+}
+
+func NewViewController(viewService feedbackinterface.ViewService) *ViewController {
+	return &ViewController{
+		viewService: viewService,
+	}
+}
+
+// @Summary Create a feedback view
+// @Description Save a named feedback view with its filters, sort order and visible columns. A period such as this_week replaces the filter dates and is resolved whenever the view is used. Views are private unless shared with the team.
+// @Tags feedback
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param request body feedbackmodel.SaveFeedbackViewRequest true "View"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/views [post]
+func (h *ViewController) CreateView(c echo.Context) error {
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	var req feedbackmodel.SaveFeedbackViewRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	memberID, err := middleware.GetMemberID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	view, err := h.viewService.CreateView(c.Request().Context(), accountID, organizationID, memberID, &req)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    view,
+	})
+}
+
+// @Summary List feedback views
+// @Description List the team's shared views and your own, with your digest subscription to each
+// @Tags feedback
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/views [get]
+func (h *ViewController) ListViews(c echo.Context) error {
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	memberID, err := middleware.GetMemberID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	views, err := h.viewService.ListViews(c.Request().Context(), accountID, organizationID, memberID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    views,
+	})
+}
+
+// @Summary Get a feedback view
+// @Description Get a shared view or one of your own
+// @Tags feedback
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param viewId path string true "View ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/views/{viewId} [get]
+func (h *ViewController) GetView(c echo.Context) error {
+	organizationID, viewID, memberID, err := viewParams(c)
+	if err != nil {
+		return err
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	view, err := h.viewService.GetView(c.Request().Context(), accountID, organizationID, memberID, viewID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    view,
+	})
+}
+
+// @Summary Update a feedback view
+// @Description Replace the settings of a view you created. Unsharing a view ends other members' digest subscriptions to it.
+// @Tags feedback
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param viewId path string true "View ID"
+// @Param request body feedbackmodel.SaveFeedbackViewRequest true "View"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/views/{viewId} [put]
+func (h *ViewController) UpdateView(c echo.Context) error {
+	organizationID, viewID, memberID, err := viewParams(c)
+	if err != nil {
+		return err
+	}
+
+	var req feedbackmodel.SaveFeedbackViewRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	view, err := h.viewService.UpdateView(c.Request().Context(), accountID, organizationID, memberID, viewID, &req)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    view,
+	})
+}
+
+// @Summary Delete a feedback view
+// @Description Delete a view you created along with its digest subscriptions
+// @Tags feedback
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param viewId path string true "View ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/views/{viewId} [delete]
+func (h *ViewController) DeleteView(c echo.Context) error {
+	organizationID, viewID, memberID, err := viewParams(c)
+	if err != nil {
+		return err
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	if err := h.viewService.DeleteView(c.Request().Context(), accountID, organizationID, memberID, viewID); err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "View deleted",
+	})
+}
+
+// @Summary Subscribe to a feedback view
+// @Description Receive a daily or weekly (Monday) email digest of new feedback matching the view. Subscribing again changes the frequency.
+// @Tags feedback
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param viewId path string true "View ID"
+// @Param request body feedbackmodel.SubscribeViewRequest true "Digest frequency"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/views/{viewId}/subscription [put]
+func (h *ViewController) Subscribe(c echo.Context) error {
+	organizationID, viewID, memberID, err := viewParams(c)
+	if err != nil {
+		return err
+	}
+
+	var req feedbackmodel.SubscribeViewRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	subscription, err := h.viewService.Subscribe(c.Request().Context(), accountID, organizationID, memberID, viewID, req.Frequency)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    subscription,
+	})
+}
+
+// @Summary Unsubscribe from a feedback view
+// @Description Stop the email digest of a view
+// @Tags feedback
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param viewId path string true "View ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/feedback/views/{viewId}/subscription [delete]
+func (h *ViewController) Unsubscribe(c echo.Context) error {
+	organizationID, viewID, memberID, err := viewParams(c)
+	if err != nil {
+		return err
+	}
+
+	accountID := middleware.GetResourceAccountID(c)
+
+	if err := h.viewService.Unsubscribe(c.Request().Context(), accountID, organizationID, memberID, viewID); err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Unsubscribed from view",
+	})
+}
+
+func viewParams(c echo.Context) (uuid.UUID, uuid.UUID, uuid.UUID, error) {
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+	viewID, err := uuid.Parse(c.Param("viewId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid view ID")
+	}
+	memberID, err := middleware.GetMemberID(c)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+	return organizationID, viewID, memberID, nil
+}
@@ -0,0 +1,18 @@
+package feedbackinterface
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+)
+
+// AbuseGuard protects public submissions. Limit violations are rejected
+// outright, while suspicious bodies are quarantined for review.
+type AbuseGuard interface {
+	CheckLimits(ctx context.Context, qrCodeID uuid.UUID, deviceHash string) error
+	// CheckBatchLimits rejects a batch that does not fit in what is left of
+	// the hourly limits. pending counts the submissions per QR code.
+	CheckBatchLimits(ctx context.Context, pending map[uuid.UUID]int, deviceHash string) error
+	Screen(ctx context.Context, feedback *feedbackmodel.Feedback) error
+}
@@ -0,0 +1,34 @@
+package feedbackinterface
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+)
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *feedbackmodel.FeedbackAttachment) error
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]feedbackmodel.FeedbackAttachment, error)
+	CountByQRCodeSince(ctx context.Context, qrCodeID uuid.UUID, since time.Time) (int64, error)
+	CountByDeviceSince(ctx context.Context, deviceHash string, since time.Time) (int64, error)
+	// FindUnclaimedBefore returns up to limit uploads made before the time
+	// that were never attached to feedback.
+	FindUnclaimedBefore(ctx context.Context, before time.Time, limit int) ([]feedbackmodel.FeedbackAttachment, error)
+	// DeleteUnclaimed removes the uploads for good, skipping any attached to
+	// feedback in the meantime, and returns the ones it removed.
+	DeleteUnclaimed(ctx context.Context, ids []uuid.UUID) ([]feedbackmodel.FeedbackAttachment, error)
+}
+
+type AttachmentService interface {
+	Upload(ctx context.Context, req *feedbackmodel.UploadAttachmentRequest, file io.Reader) (*feedbackmodel.FeedbackAttachment, error)
+	ValidateForFeedback(ctx context.Context, feedback *feedbackmodel.Feedback) error
+	SignURLs(ctx context.Context, attachments []feedbackmodel.FeedbackAttachment)
+	OpenSigned(ctx context.Context, key string, expires int64, signature string) (io.ReadCloser, string, error)
+	MaxUploadSize() int64
+	// CleanupUnclaimed deletes photos that were never attached to feedback
+	// within the configured time.
+	CleanupUnclaimed(ctx context.Context) error
+}
@@ -0,0 +1,61 @@
+package feedbackinterface
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+)
+
+type ExportRepository interface {
+	Create(ctx context.Context, export *feedbackmodel.FeedbackExport) error
+	Update(ctx context.Context, export *feedbackmodel.FeedbackExport) error
+	FindByID(ctx context.Context, id uuid.UUID) (*feedbackmodel.FeedbackExport, error)
+	FindByOrganizationID(ctx context.Context, organizationID uuid.UUID, limit int) ([]feedbackmodel.FeedbackExport, error)
+	// FindExpired returns exports, finished or not, that expired before the
+	// given time.
+	FindExpired(ctx context.Context, before time.Time) ([]feedbackmodel.FeedbackExport, error)
+	// FailUnfinished marks exports still pending or running that were created
+	// before the given time as failed and returns how many it marked.
+	FailUnfinished(ctx context.Context, before time.Time, reason string) (int64, error)
+	// Delete removes the export record for good.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// FindQuestions returns the questions of the organization's products in
+	// product and display order, including deleted questions that older
+	// feedback may have answered.
+	FindQuestions(ctx context.Context, organizationID uuid.UUID, productID *uuid.UUID) ([]*feedbackmodel.Question, error)
+}
+
+type ExportService interface {
+	// Prepare checks access and counts the matching feedback so the caller
+	// can decide between streaming the export and running it in the background.
+	Prepare(ctx context.Context, accountID, organizationID uuid.UUID, format feedbackmodel.ExportFormat, filters feedbackmodel.FeedbackFilter) (*ExportPlan, error)
+	Write(ctx context.Context, plan *ExportPlan, w io.Writer) (int, error)
+	// StartBackground records the export and queues it for a background
+	// worker, refusing it when the queue is full.
+	StartBackground(ctx context.Context, plan *ExportPlan, requestedBy uuid.UUID) (*feedbackmodel.FeedbackExport, error)
+	GetExport(ctx context.Context, accountID, organizationID, exportID uuid.UUID) (*feedbackmodel.FeedbackExport, error)
+	ListExports(ctx context.Context, accountID, organizationID uuid.UUID) ([]feedbackmodel.FeedbackExport, error)
+	CleanupExpired(ctx context.Context) error
+	// Start fails the exports a previous run of the server left unfinished
+	// and starts the background workers.
+	Start(ctx context.Context) error
+	// Shutdown stops taking background exports and waits for the running
+	// ones, cancelling them once ctx is done.
+	Shutdown(ctx context.Context) error
+}
+
+// ExportPlan is a checked export request ready to be written.
+type ExportPlan struct {
+	OrganizationID uuid.UUID
+	Format         feedbackmodel.ExportFormat
+	Filters        feedbackmodel.FeedbackFilter
+	// Columns limits the export to the columns with these keys, in order.
+	Columns        []string
+	FileName       string
+	RowCount       int64
+	// Background is set when the export is too large to stream in the request.
+	Background bool
+}
@@ -11,11 +11,19 @@ import (
 
 type FeedbackRepository interface {
 	Create(ctx context.Context, feedback *feedbackmodel.Feedback) error
+	CreateSession(ctx context.Context, feedback *feedbackmodel.Feedback) error
+	CreateWithIdempotencyKey(ctx context.Context, feedback *feedbackmodel.Feedback) (bool, error)
+	CompleteSession(ctx context.Context, feedback *feedbackmodel.Feedback) error
+	FindByIdempotencyKey(ctx context.Context, qrCodeID uuid.UUID, key string) (*feedbackmodel.Feedback, error)
+	FindStoredIdempotencyKeys(ctx context.Context, qrCodeID uuid.UUID, keys []string) ([]string, error)
+	FindBySessionTokenHash(ctx context.Context, tokenHash string) (*feedbackmodel.Feedback, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*feedbackmodel.Feedback, error)
 	Update(ctx context.Context, feedback *feedbackmodel.Feedback) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	FindByOrganizationID(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, page, limit int) (*sharedModels.PageResponse[feedbackmodel.Feedback], error)
-	FindByOrganizationIDWithFilters(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, page, limit int, filters feedbackmodel.FeedbackFilter) (*sharedModels.PageResponse[feedbackmodel.Feedback], error)
+	FindByOrganizationID(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, req sharedModels.PageRequest) (*sharedModels.PageResponse[feedbackmodel.Feedback], error)
+	FindByOrganizationIDWithFilters(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, req sharedModels.PageRequest, filters feedbackmodel.FeedbackFilter) (*sharedModels.PageResponse[feedbackmodel.Feedback], error)
+	CountByOrganizationIDWithFilters(ctx context.Context, organizationID uuid.UUID, filters feedbackmodel.FeedbackFilter) (int64, error)
+	StreamByOrganizationIDWithFilters(ctx context.Context, organizationID uuid.UUID, filters feedbackmodel.FeedbackFilter, batchSize int, fn func(*feedbackmodel.Feedback) error) error
 	GetStatsByOrganization(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID) (*feedbackmodel.FeedbackStats, error)
 	FindByOrganizationIDForAnalytics(ctx context.Context, organizationID uuid.UUID, limit int) ([]feedbackmodel.Feedback, error)
 	FindByQuestionInPeriod(ctx context.Context, questionID uuid.UUID, startDate, endDate time.Time) ([]feedbackmodel.Feedback, error)
@@ -23,6 +31,9 @@ type FeedbackRepository interface {
 	CountByProductID(ctx context.Context, productID uuid.UUID) (int64, error)
 	CountByQRCodeID(ctx context.Context, qrCodeID uuid.UUID) (int64, error)
 	CountByQRCodeIDs(ctx context.Context, qrCodeIDs []uuid.UUID) (map[uuid.UUID]int64, error)
+	CountSubmissionsByQRCodeSince(ctx context.Context, qrCodeID uuid.UUID, since time.Time) (int64, error)
+	CountSubmissionsByDeviceSince(ctx context.Context, deviceHash string, since time.Time) (int64, error)
+	HasDuplicateBody(ctx context.Context, qrCodeID uuid.UUID, bodyHash, deviceHash string, since time.Time, excludeID uuid.UUID) (bool, error)
 	GetAverageRating(ctx context.Context, organizationID uuid.UUID, productID *uuid.UUID) (float64, error)
 	FindByProductID(ctx context.Context, productID uuid.UUID, req sharedModels.PageRequest) (*sharedModels.PageResponse[feedbackmodel.Feedback], error)
 	FindByProductIDForAnalytics(ctx context.Context, productID uuid.UUID, limit int) ([]feedbackmodel.Feedback, error)
@@ -30,10 +41,16 @@ type FeedbackRepository interface {
 }
 
 type FeedbackService interface {
-	Submit(ctx context.Context, feedback *feedbackmodel.Feedback) error
-	GetByOrganizationID(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, page, limit int) (*sharedModels.PageResponse[feedbackmodel.Feedback], error)
-	GetByOrganizationIDWithFilters(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, page, limit int, filters feedbackmodel.FeedbackFilter) (*sharedModels.PageResponse[feedbackmodel.Feedback], error)
+	Submit(ctx context.Context, feedback *feedbackmodel.Feedback) (*feedbackmodel.SubmissionResult, error)
+	SubmitBatch(ctx context.Context, items []feedbackmodel.BatchFeedbackItem, deviceInfo feedbackmodel.DeviceInfo, deviceToken string) (*feedbackmodel.BatchFeedbackResponse, error)
+	StartSession(ctx context.Context, req *feedbackmodel.StartSessionRequest, deviceInfo feedbackmodel.DeviceInfo) (*feedbackmodel.FeedbackSession, error)
+	GetSession(ctx context.Context, token string) (*feedbackmodel.FeedbackSession, error)
+	SaveAnswer(ctx context.Context, token string, questionID uuid.UUID, answer any) (*feedbackmodel.FeedbackSession, error)
+	CompleteSession(ctx context.Context, token string, req *feedbackmodel.CompleteSessionRequest) (*feedbackmodel.Feedback, error)
+	GetByOrganizationID(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, req sharedModels.PageRequest) (*sharedModels.PageResponse[feedbackmodel.Feedback], error)
+	GetByOrganizationIDWithFilters(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, req sharedModels.PageRequest, filters feedbackmodel.FeedbackFilter) (*sharedModels.PageResponse[feedbackmodel.Feedback], error)
 	GetStats(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID) (*feedbackmodel.FeedbackStats, error)
 	GetByOrganizationIDForAnalytics(ctx context.Context, organizationID uuid.UUID, limit int) ([]feedbackmodel.Feedback, error)
 	GetByQuestionInPeriod(ctx context.Context, questionID uuid.UUID, startDate, endDate time.Time) ([]feedbackmodel.Feedback, error)
+	Review(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, feedbackID uuid.UUID, reviewerID uuid.UUID, req *feedbackmodel.ReviewFeedbackRequest) (*feedbackmodel.Feedback, error)
 }
\ No newline at end of file
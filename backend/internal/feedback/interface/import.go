@@ -0,0 +1,29 @@
+package feedbackinterface
+
+import (
+	"context"
+	"io"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+)
+
+type ImportRepository interface {
+	// Create stores the import together with its feedback in one transaction.
+	Create(ctx context.Context, feedbackImport *feedbackmodel.FeedbackImport, feedbacks []*feedbackmodel.Feedback) error
+	FindByID(ctx context.Context, id uuid.UUID) (*feedbackmodel.FeedbackImport, error)
+	FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]feedbackmodel.FeedbackImport, error)
+	// SetIncludeInAnalytics updates the import and every feedback it created.
+	SetIncludeInAnalytics(ctx context.Context, id uuid.UUID, include bool) error
+	// Delete removes the import and every feedback it created.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type ImportService interface {
+	// Import reads a CSV file of historical feedback. On a dry run, or when
+	// any row has errors, nothing is stored and the report lists the errors.
+	Import(ctx context.Context, accountID, organizationID, requestedBy uuid.UUID, file io.Reader, req feedbackmodel.ImportFeedbackRequest) (*feedbackmodel.ImportReport, error)
+	ListImports(ctx context.Context, accountID, organizationID uuid.UUID) ([]feedbackmodel.FeedbackImport, error)
+	SetIncludeInAnalytics(ctx context.Context, accountID, organizationID, importID uuid.UUID, include bool) (*feedbackmodel.FeedbackImport, error)
+	DeleteImport(ctx context.Context, accountID, organizationID, importID uuid.UUID) error
+}
@@ -0,0 +1,24 @@
+package feedbackinterface
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+)
+
+type MessageRepository interface {
+	Create(ctx context.Context, message *feedbackmodel.FeedbackMessage) error
+	Update(ctx context.Context, message *feedbackmodel.FeedbackMessage) error
+	FindByID(ctx context.Context, id uuid.UUID) (*feedbackmodel.FeedbackMessage, error)
+	FindByFeedbackID(ctx context.Context, feedbackID uuid.UUID) ([]feedbackmodel.FeedbackMessage, error)
+	FindByReplyTokenHash(ctx context.Context, tokenHash string) (*feedbackmodel.FeedbackMessage, error)
+}
+
+type MessageService interface {
+	ListMessages(ctx context.Context, accountID, organizationID, feedbackID uuid.UUID) ([]feedbackmodel.FeedbackMessage, error)
+	Reply(ctx context.Context, accountID, organizationID, feedbackID, memberID uuid.UUID, body string) (*feedbackmodel.FeedbackMessage, error)
+	Resend(ctx context.Context, accountID, organizationID, feedbackID, messageID uuid.UUID) (*feedbackmodel.FeedbackMessage, error)
+	GetPublicThread(ctx context.Context, token string) (*feedbackmodel.PublicThread, error)
+	ReplyAsCustomer(ctx context.Context, token string, body string) (*feedbackmodel.PublicThread, error)
+}
@@ -15,6 +15,8 @@ type QuestionnaireRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 	FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]*feedbackmodel.Questionnaire, error)
 	DeactivateDefaultQuestionnaires(ctx context.Context, organizationID uuid.UUID) error
+	FindActiveByProductID(ctx context.Context, productID uuid.UUID) (*feedbackmodel.Questionnaire, error)
+	FindDefaultByOrganizationID(ctx context.Context, organizationID uuid.UUID) (*feedbackmodel.Questionnaire, error)
 	CreateQuestion(ctx context.Context, question *feedbackmodel.Question) error
 	FindQuestionByID(ctx context.Context, id uuid.UUID) (*feedbackmodel.Question, error)
 	UpdateQuestion(ctx context.Context, question *feedbackmodel.Question) error
@@ -34,4 +36,6 @@ type QuestionnaireService interface {
 	ReorderQuestions(ctx context.Context, accountID, questionnaireID uuid.UUID, questionIDs []uuid.UUID) error
 	GenerateQuestionsForProduct(ctx context.Context, accountID uuid.UUID, product *productModels.Product) ([]*feedbackmodel.GeneratedQuestion, error)
 	GenerateAndSaveQuestionnaireForProduct(ctx context.Context, accountID uuid.UUID, product *productModels.Product, name, description string, isDefault bool) (*feedbackmodel.Questionnaire, error)
+	GetPublicQuestionnaire(ctx context.Context, organizationID, productID uuid.UUID, languages []string) (*feedbackmodel.PublicQuestionnaire, error)
+	LocalizeQuestions(ctx context.Context, organizationID uuid.UUID, questions []*feedbackmodel.Question, languages []string) ([]*feedbackmodel.Question, string, error)
 }
\ No newline at end of file
@@ -0,0 +1,26 @@
+package feedbackinterface
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+)
+
+type TriageRepository interface {
+	// UpdateFeedback applies the column updates to the feedback and records
+	// the activity in the same transaction.
+	UpdateFeedback(ctx context.Context, feedbackID uuid.UUID, updates map[string]interface{}, activity *feedbackmodel.FeedbackActivity) error
+	CreateNote(ctx context.Context, note *feedbackmodel.FeedbackNote, activity *feedbackmodel.FeedbackActivity) error
+	FindNotesByFeedbackID(ctx context.Context, feedbackID uuid.UUID) ([]feedbackmodel.FeedbackNote, error)
+	FindActivityByFeedbackID(ctx context.Context, feedbackID uuid.UUID, limit int) ([]feedbackmodel.FeedbackActivity, error)
+}
+
+type TriageService interface {
+	UpdateStatus(ctx context.Context, accountID, organizationID, feedbackID, actorID uuid.UUID, status feedbackmodel.FeedbackStatus) (*feedbackmodel.Feedback, error)
+	Assign(ctx context.Context, accountID, organizationID, feedbackID, actorID uuid.UUID, assigneeID *uuid.UUID) (*feedbackmodel.Feedback, error)
+	UpdateTags(ctx context.Context, accountID, organizationID, feedbackID, actorID uuid.UUID, tags []string) (*feedbackmodel.Feedback, error)
+	AddNote(ctx context.Context, accountID, organizationID, feedbackID, authorID uuid.UUID, body string) (*feedbackmodel.FeedbackNote, error)
+	ListNotes(ctx context.Context, accountID, organizationID, feedbackID uuid.UUID) ([]feedbackmodel.FeedbackNote, error)
+	ListActivity(ctx context.Context, accountID, organizationID, feedbackID uuid.UUID) ([]feedbackmodel.FeedbackActivity, error)
+}
@@ -0,0 +1,23 @@
+package feedbackinterface
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+)
+
+type VersionRepository interface {
+	CreateIfChanged(ctx context.Context, version *feedbackmodel.QuestionnaireVersion) (*feedbackmodel.QuestionnaireVersion, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*feedbackmodel.QuestionnaireVersion, error)
+	FindByProductID(ctx context.Context, productID uuid.UUID) ([]feedbackmodel.QuestionnaireVersion, error)
+	FindByProductAndNumber(ctx context.Context, productID uuid.UUID, version int) (*feedbackmodel.QuestionnaireVersion, error)
+}
+
+type VersionService interface {
+	RecordVersion(ctx context.Context, productID uuid.UUID) (*feedbackmodel.QuestionnaireVersion, error)
+	CurrentVersion(ctx context.Context, organizationID, productID uuid.UUID, questions []feedbackmodel.Question) (*feedbackmodel.QuestionnaireVersion, error)
+	ListVersions(ctx context.Context, accountID, productID uuid.UUID) ([]feedbackmodel.QuestionnaireVersion, error)
+	GetVersion(ctx context.Context, accountID, productID uuid.UUID, version int) (*feedbackmodel.QuestionnaireVersion, error)
+	DiffVersions(ctx context.Context, accountID, productID uuid.UUID, from, to int) (*feedbackmodel.QuestionnaireVersionDiff, error)
+}
@@ -0,0 +1,46 @@
+package feedbackinterface
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+)
+
+type ViewRepository interface {
+	Create(ctx context.Context, view *feedbackmodel.FeedbackView) error
+	Update(ctx context.Context, view *feedbackmodel.FeedbackView) error
+	// Delete removes the view together with its digest subscriptions.
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, id uuid.UUID) (*feedbackmodel.FeedbackView, error)
+	// FindVisible returns the organization's shared views and the member's own.
+	FindVisible(ctx context.Context, organizationID, memberID uuid.UUID) ([]feedbackmodel.FeedbackView, error)
+
+	FindSubscription(ctx context.Context, viewID, memberID uuid.UUID) (*feedbackmodel.FeedbackViewSubscription, error)
+	FindSubscriptionsByMember(ctx context.Context, organizationID, memberID uuid.UUID) ([]feedbackmodel.FeedbackViewSubscription, error)
+	// FindSubscriptions returns every digest subscription with its view.
+	FindSubscriptions(ctx context.Context) ([]feedbackmodel.FeedbackViewSubscription, error)
+	SaveSubscription(ctx context.Context, subscription *feedbackmodel.FeedbackViewSubscription) error
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+	// DeleteOtherSubscriptions removes the subscriptions of everyone but the
+	// view's creator, for when a view stops being shared.
+	DeleteOtherSubscriptions(ctx context.Context, viewID, creatorID uuid.UUID) error
+	MarkDigestSent(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type ViewService interface {
+	CreateView(ctx context.Context, accountID, organizationID, memberID uuid.UUID, req *feedbackmodel.SaveFeedbackViewRequest) (*feedbackmodel.FeedbackView, error)
+	ListViews(ctx context.Context, accountID, organizationID, memberID uuid.UUID) ([]feedbackmodel.FeedbackView, error)
+	GetView(ctx context.Context, accountID, organizationID, memberID, viewID uuid.UUID) (*feedbackmodel.FeedbackView, error)
+	UpdateView(ctx context.Context, accountID, organizationID, memberID, viewID uuid.UUID, req *feedbackmodel.SaveFeedbackViewRequest) (*feedbackmodel.FeedbackView, error)
+	DeleteView(ctx context.Context, accountID, organizationID, memberID, viewID uuid.UUID) error
+	// ResolveView returns a view the member can see along with its filters
+	// as of now, ready for listing or exporting feedback.
+	ResolveView(ctx context.Context, accountID, organizationID, memberID, viewID uuid.UUID) (*feedbackmodel.FeedbackView, feedbackmodel.FeedbackFilter, error)
+	Subscribe(ctx context.Context, accountID, organizationID, memberID, viewID uuid.UUID, frequency feedbackmodel.DigestFrequency) (*feedbackmodel.FeedbackViewSubscription, error)
+	Unsubscribe(ctx context.Context, accountID, organizationID, memberID, viewID uuid.UUID) error
+	// SendDigests emails every subscription that is due a digest of the
+	// feedback matching its view since the previous one.
+	SendDigests(ctx context.Context) error
+}
@@ -0,0 +1,77 @@
+package feedbackmodel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+const (
+	QuarantineReasonHoneypot  = "honeypot"
+	QuarantineReasonDuplicate = "duplicate"
+	QuarantineReasonChallenge = "challenge_failed"
+)
+
+const (
+	ReviewActionApprove = "approve"
+	ReviewActionReject  = "reject"
+)
+
+type ReviewFeedbackRequest struct {
+	Action string `json:"action" validate:"required,oneof=approve reject"`
+}
+
+// Quarantine flags the feedback so it is kept but left out of analytics
+// until someone reviews it.
+func (f *Feedback) Quarantine(reason string) {
+	f.IsQuarantined = true
+	for _, existing := range f.QuarantineReasons {
+		if existing == reason {
+			return
+		}
+	}
+	f.QuarantineReasons = append(f.QuarantineReasons, reason)
+}
+
+// ComputeBodyHash fingerprints what the customer wrote, ignoring case and
+// surrounding whitespace, so replayed submissions can be spotted.
+func (f *Feedback) ComputeBodyHash() string {
+	responses, _ := json.Marshal(f.Responses)
+	body := strings.Join([]string{
+		f.ProductID.String(),
+		strings.ToLower(strings.TrimSpace(f.CustomerName)),
+		strings.ToLower(strings.TrimSpace(f.CustomerEmail)),
+		strings.TrimSpace(f.CustomerPhone),
+		strconv.Itoa(f.OverallRating),
+		strings.ToLower(string(responses)),
+	}, "\x00")
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// HasFreeText reports whether the customer wrote anything of their own.
+// Identical rating-only submissions are common from different customers, so
+// only bodies with free text are compared across devices.
+func (f *Feedback) HasFreeText() bool {
+	if strings.TrimSpace(f.CustomerName) != "" || strings.TrimSpace(f.CustomerEmail) != "" || strings.TrimSpace(f.CustomerPhone) != "" {
+		return true
+	}
+	for _, response := range f.Responses {
+		if response.QuestionType != QuestionTypeText {
+			continue
+		}
+		if text, ok := response.Answer.(string); ok && strings.TrimSpace(text) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// DeviceHash identifies a submitting device without storing more than the
+// request already exposes in DeviceInfo.
+func DeviceHash(info DeviceInfo) string {
+	sum := sha256.Sum256([]byte(info.IP + "\x00" + info.UserAgent))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,61 @@
+package feedbackmodel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// aggregateQuestionTypes are answered from a fixed scale or option list and
+// say nothing about who answered, so they survive anonymization.
+var aggregateQuestionTypes = map[QuestionType]bool{
+	QuestionTypeRating:       true,
+	QuestionTypeScale:        true,
+	QuestionTypeNPS:          true,
+	QuestionTypeYesNo:        true,
+	QuestionTypeSingleChoice: true,
+	QuestionTypeMultiChoice:  true,
+	QuestionTypeMatrix:       true,
+	QuestionTypeRanking:      true,
+}
+
+// Anonymize removes everything that could identify the customer while
+// keeping the ratings and choices that analytics are built from.
+func (f *Feedback) Anonymize(questionTypes map[uuid.UUID]QuestionType, now time.Time) {
+	f.StripPersonalData(now)
+	f.RemoveFreeText(questionTypes, now)
+}
+
+// StripPersonalData clears the customer's contact details and the network
+// details of the device they used. What they wrote is left untouched.
+func (f *Feedback) StripPersonalData(now time.Time) {
+	f.CustomerName = ""
+	f.CustomerEmail = ""
+	f.CustomerPhone = ""
+	f.DeviceInfo = DeviceInfo{
+		Platform: f.DeviceInfo.Platform,
+		Browser:  f.DeviceInfo.Browser,
+	}
+	f.DeviceHash = ""
+	f.BodyHash = ""
+	f.AnonymizedAt = &now
+}
+
+// RemoveFreeText clears free text and photo answers, as well as answers to
+// questions whose type is no longer known. questionTypes maps question IDs
+// to their current type.
+func (f *Feedback) RemoveFreeText(questionTypes map[uuid.UUID]QuestionType, now time.Time) {
+	responses := make(Responses, 0, len(f.Responses))
+	for _, response := range f.Responses {
+		questionType := response.QuestionType
+		if known, ok := questionTypes[response.QuestionID]; ok {
+			questionType = known
+		}
+		if !aggregateQuestionTypes[questionType] {
+			response.Answer = nil
+		}
+		responses = append(responses, response)
+	}
+	f.Responses = responses
+	f.TextRemovedAt = &now
+}
@@ -0,0 +1,54 @@
+package feedbackmodel
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	sharedModels "kyooar/internal/shared/models"
+)
+
+const (
+	ThumbnailMaxSize = 320
+	// MaxAttachmentPixels guards against decompression bombs.
+	MaxAttachmentPixels = 40_000_000
+)
+
+// ErrAttachmentClaimed is returned when saving feedback whose photo another
+// feedback claimed after the answers were validated.
+var ErrAttachmentClaimed = errors.New("photo already belongs to another feedback")
+
+var AllowedAttachmentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// FeedbackAttachment is a customer photo uploaded before the feedback is
+// submitted. It is linked to the feedback once a photo answer references it.
+type FeedbackAttachment struct {
+	sharedModels.BaseModel
+	OrganizationID uuid.UUID  `gorm:"not null;index" json:"organization_id"`
+	QRCodeID       uuid.UUID  `gorm:"not null" json:"qr_code_id"`
+	FeedbackID     *uuid.UUID `gorm:"index" json:"feedback_id,omitempty"`
+	QuestionID     *uuid.UUID `json:"question_id,omitempty"`
+	StorageKey     string     `gorm:"not null" json:"-"`
+	ThumbnailKey   string     `gorm:"not null" json:"-"`
+	ContentType    string     `gorm:"not null" json:"content_type"`
+	Size           int64      `json:"size"`
+	Width          int        `json:"width"`
+	Height         int        `json:"height"`
+	DeviceHash     string     `json:"-"`
+	URL            string     `gorm:"-" json:"url,omitempty"`
+	ThumbnailURL   string     `gorm:"-" json:"thumbnail_url,omitempty"`
+}
+
+type UploadAttachmentRequest struct {
+	QRCodeID   uuid.UUID  `form:"qr_code_id"`
+	QuestionID *uuid.UUID `form:"question_id"`
+	// DeviceToken identifies a paired kiosk, which is trusted without the
+	// token of a rotating code.
+	DeviceToken string `form:"-"`
+	// QRToken is the token from a rotating QR code's URL.
+	QRToken    string     `form:"-"`
+	DeviceInfo DeviceInfo `form:"-"`
+}
@@ -0,0 +1,247 @@
+package feedbackmodel
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+type DisplayRuleAction string
+
+const (
+	// DisplayRuleShowIf shows the question only when the condition holds.
+	// When a question has several show_if rules all of them must hold.
+	DisplayRuleShowIf DisplayRuleAction = "show_if"
+	// DisplayRuleSkipToEnd hides every question after this one when the condition holds.
+	DisplayRuleSkipToEnd DisplayRuleAction = "skip_to_end"
+)
+
+type RuleOperator string
+
+const (
+	RuleOperatorEquals         RuleOperator = "eq"
+	RuleOperatorNotEquals      RuleOperator = "neq"
+	RuleOperatorLessThan       RuleOperator = "lt"
+	RuleOperatorLessOrEqual    RuleOperator = "lte"
+	RuleOperatorGreaterThan    RuleOperator = "gt"
+	RuleOperatorGreaterOrEqual RuleOperator = "gte"
+	RuleOperatorIn             RuleOperator = "in"
+	RuleOperatorContains       RuleOperator = "contains"
+	RuleOperatorAnswered       RuleOperator = "answered"
+	RuleOperatorNotAnswered    RuleOperator = "not_answered"
+)
+
+// DisplayRule is a condition on the answer to an earlier question. QuestionID
+// defaults to the question the rule is attached to, which is how skip_to_end
+// rules usually refer to their own answer.
+type DisplayRule struct {
+	Action     DisplayRuleAction `json:"action"`
+	QuestionID *uuid.UUID        `json:"question_id,omitempty"`
+	Operator   RuleOperator      `json:"operator"`
+	Value      any               `json:"value,omitempty"`
+}
+
+type DisplayRules []DisplayRule
+
+func (r DisplayRules) Value() (driver.Value, error) {
+	if r == nil {
+		return json.Marshal(DisplayRules{})
+	}
+	return json.Marshal(r)
+}
+
+func (r *DisplayRules) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return json.Unmarshal([]byte("[]"), r)
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+// Source returns the question whose answer the rule inspects.
+func (r DisplayRule) Source(owner uuid.UUID) uuid.UUID {
+	if r.QuestionID == nil || *r.QuestionID == uuid.Nil {
+		return owner
+	}
+	return *r.QuestionID
+}
+
+func (r DisplayRule) Validate() error {
+	switch r.Action {
+	case DisplayRuleShowIf, DisplayRuleSkipToEnd:
+	default:
+		return fmt.Errorf("unknown rule action %q", r.Action)
+	}
+
+	switch r.Operator {
+	case RuleOperatorAnswered, RuleOperatorNotAnswered:
+		return nil
+	case RuleOperatorLessThan, RuleOperatorLessOrEqual, RuleOperatorGreaterThan, RuleOperatorGreaterOrEqual:
+		if _, ok := toFloat(r.Value); !ok {
+			return fmt.Errorf("operator %q requires a numeric value", r.Operator)
+		}
+	case RuleOperatorIn:
+		if _, ok := r.Value.([]any); !ok {
+			return fmt.Errorf("operator %q requires a list value", r.Operator)
+		}
+	case RuleOperatorEquals, RuleOperatorNotEquals, RuleOperatorContains:
+		if r.Value == nil {
+			return fmt.Errorf("operator %q requires a value", r.Operator)
+		}
+	default:
+		return fmt.Errorf("unknown rule operator %q", r.Operator)
+	}
+	return nil
+}
+
+// Matches evaluates the rule against a normalized answer. A nil answer means
+// the source question was not answered or was hidden.
+func (r DisplayRule) Matches(answer any) bool {
+	switch r.Operator {
+	case RuleOperatorAnswered:
+		return answer != nil
+	case RuleOperatorNotAnswered:
+		return answer == nil
+	}
+
+	if answer == nil {
+		return false
+	}
+
+	switch r.Operator {
+	case RuleOperatorEquals:
+		return valuesEqual(answer, r.Value)
+	case RuleOperatorNotEquals:
+		return !valuesEqual(answer, r.Value)
+	case RuleOperatorLessThan, RuleOperatorLessOrEqual, RuleOperatorGreaterThan, RuleOperatorGreaterOrEqual:
+		left, ok := toFloat(answer)
+		if !ok {
+			return false
+		}
+		right, ok := toFloat(r.Value)
+		if !ok {
+			return false
+		}
+		switch r.Operator {
+		case RuleOperatorLessThan:
+			return left < right
+		case RuleOperatorLessOrEqual:
+			return left <= right
+		case RuleOperatorGreaterThan:
+			return left > right
+		default:
+			return left >= right
+		}
+	case RuleOperatorIn:
+		values, _ := r.Value.([]any)
+		for _, value := range values {
+			if valuesEqual(answer, value) {
+				return true
+			}
+		}
+		return false
+	case RuleOperatorContains:
+		if choices, ok := answer.([]string); ok {
+			for _, choice := range choices {
+				if valuesEqual(choice, r.Value) {
+					return true
+				}
+			}
+			return false
+		}
+		return valuesEqual(answer, r.Value)
+	}
+	return false
+}
+
+// VisibleQuestions walks the questions in display order and returns the IDs
+// that should be shown for the given answers. Rules only see answers to
+// visible questions that come before the question being evaluated.
+func VisibleQuestions(questions []Question, answers map[uuid.UUID]any) map[uuid.UUID]bool {
+	ordered := make([]*Question, len(questions))
+	for i := range questions {
+		ordered[i] = &questions[i]
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].DisplayOrder != ordered[j].DisplayOrder {
+			return ordered[i].DisplayOrder < ordered[j].DisplayOrder
+		}
+		return bytes.Compare(ordered[i].ID[:], ordered[j].ID[:]) < 0
+	})
+
+	visible := make(map[uuid.UUID]bool, len(ordered))
+	seen := make(map[uuid.UUID]any, len(ordered))
+	skipping := false
+
+	for _, question := range ordered {
+		if skipping {
+			continue
+		}
+
+		shown := true
+		for _, rule := range question.DisplayRules {
+			if rule.Action != DisplayRuleShowIf {
+				continue
+			}
+			if !rule.Matches(seen[rule.Source(question.ID)]) {
+				shown = false
+				break
+			}
+		}
+		if !shown {
+			continue
+		}
+
+		visible[question.ID] = true
+		if answer, ok := answers[question.ID]; ok {
+			seen[question.ID] = answer
+		}
+
+		for _, rule := range question.DisplayRules {
+			if rule.Action == DisplayRuleSkipToEnd && rule.Matches(seen[rule.Source(question.ID)]) {
+				skipping = true
+				break
+			}
+		}
+	}
+
+	return visible
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func valuesEqual(left, right any) bool {
+	if l, ok := toFloat(left); ok {
+		r, ok := toFloat(right)
+		return ok && l == r
+	}
+	if l, ok := left.(bool); ok {
+		switch r := right.(type) {
+		case bool:
+			return l == r
+		case string:
+			return (l && (r == "yes" || r == "true")) || (!l && (r == "no" || r == "false"))
+		}
+		return false
+	}
+	if l, ok := left.(string); ok {
+		r, ok := right.(string)
+		return ok && l == r
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+package feedbackmodel
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	sharedModels "kyooar/internal/shared/models"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatXLSX   ExportFormat = "xlsx"
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+func (f ExportFormat) IsValid() bool {
+	switch f {
+	case ExportFormatCSV, ExportFormatXLSX, ExportFormatNDJSON:
+		return true
+	}
+	return false
+}
+
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case ExportFormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// FileName names the export after the organization and the day it was made.
+func (f ExportFormat) FileName(organizationName string, at time.Time) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, organizationName)
+	name = strings.Trim(name, "-")
+	if name == "" {
+		name = "feedback"
+	}
+	return fmt.Sprintf("%s-feedback-%s.%s", name, at.Format("2006-01-02"), f)
+}
+
+type ExportStatus string
+
+const (
+	ExportStatusPending   ExportStatus = "pending"
+	ExportStatusRunning   ExportStatus = "running"
+	ExportStatusCompleted ExportStatus = "completed"
+	ExportStatusFailed    ExportStatus = "failed"
+)
+
+// FeedbackExport is an export too large to stream in the request. It is
+// written to storage in the background and downloaded through a signed link
+// until it expires.
+type FeedbackExport struct {
+	sharedModels.BaseModel
+	OrganizationID uuid.UUID      `gorm:"not null" json:"organization_id"`
+	RequestedBy    uuid.UUID      `gorm:"not null" json:"requested_by"`
+	Format         ExportFormat   `gorm:"not null" json:"format"`
+	Filters        FeedbackFilter `gorm:"type:jsonb" json:"filters"`
+	Status         ExportStatus   `gorm:"not null" json:"status"`
+	RowCount       int            `json:"row_count"`
+	FileName       string         `json:"file_name"`
+	FileKey        string         `json:"-"`
+	Error          string         `json:"error,omitempty"`
+	CompletedAt    *time.Time     `json:"completed_at,omitempty"`
+	ExpiresAt      *time.Time     `json:"expires_at,omitempty"`
+	DownloadURL    string         `gorm:"-" json:"download_url,omitempty"`
+}
+
+func (f FeedbackFilter) Value() (driver.Value, error) { return json.Marshal(f) }
+func (f *FeedbackFilter) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return json.Unmarshal([]byte("{}"), f)
+	}
+	return json.Unmarshal(bytes, f)
+}
+
+// ExportColumn is one column of a tabular export. Key names the field in
+// NDJSON lines; Title is the header in CSV and XLSX files.
+type ExportColumn struct {
+	Key   string
+	Title string
+}
+
+var exportBaseColumns = []ExportColumn{
+	{Key: "id", Title: "ID"},
+	{Key: "created_at", Title: "Submitted at"},
+	{Key: "product", Title: "Product"},
+	{Key: "qr_code", Title: "QR code"},
+	{Key: "overall_rating", Title: "Overall rating"},
+	{Key: "customer_name", Title: "Customer name"},
+	{Key: "customer_email", Title: "Customer email"},
+	{Key: "customer_phone", Title: "Customer phone"},
+	{Key: "language", Title: "Language"},
+	{Key: "is_complete", Title: "Complete"},
+	{Key: "status", Title: "Status"},
+	{Key: "tags", Title: "Tags"},
+}
+
+// IsExportColumnKey reports whether key can name an export column: one of
+// the base columns or a question ID.
+func IsExportColumnKey(key string) bool {
+	for _, column := range exportBaseColumns {
+		if column.Key == key {
+			return true
+		}
+	}
+	_, err := uuid.Parse(key)
+	return err == nil
+}
+
+// ExportLayout flattens feedback into rows with one column per question.
+// Questions are matched by ID, so answers stay in the same column when a
+// question is reworded.
+type ExportLayout struct {
+	Columns   []ExportColumn
+	questions map[uuid.UUID]int
+	// selected holds the index of each column in a full row once Select has
+	// narrowed the layout.
+	selected []int
+}
+
+// NewExportLayout builds the columns for the given questions, which are
+// expected in product and display order. Titles are prefixed with the
+// product name when questions from several products are exported together.
+func NewExportLayout(questions []*Question, productNames map[uuid.UUID]string) *ExportLayout {
+	layout := &ExportLayout{
+		Columns:   append([]ExportColumn{}, exportBaseColumns...),
+		questions: make(map[uuid.UUID]int, len(questions)),
+	}
+
+	products := map[uuid.UUID]bool{}
+	for _, question := range questions {
+		products[question.ProductID] = true
+	}
+
+	for _, question := range questions {
+		if _, exists := layout.questions[question.ID]; exists {
+			continue
+		}
+		title := question.Text
+		if len(products) > 1 && productNames[question.ProductID] != "" {
+			title = productNames[question.ProductID] + ": " + question.Text
+		}
+		layout.questions[question.ID] = len(layout.Columns)
+		layout.Columns = append(layout.Columns, ExportColumn{Key: question.ID.String(), Title: title})
+	}
+	return layout
+}
+
+// Select narrows the layout to the columns with the given keys, in that
+// order. Keys of questions that are not part of the export are skipped.
+func (l *ExportLayout) Select(keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	index := make(map[string]int, len(l.Columns))
+	for i, column := range l.Columns {
+		index[column.Key] = i
+	}
+
+	columns := make([]ExportColumn, 0, len(keys))
+	selected := make([]int, 0, len(keys))
+	for _, key := range keys {
+		if i, ok := index[key]; ok {
+			columns = append(columns, l.Columns[i])
+			selected = append(selected, i)
+			delete(index, key)
+		}
+	}
+	l.Columns = columns
+	l.selected = selected
+}
+
+// Row returns the feedback's values in column order. Answers to questions
+// without a column, such as ones from another organization's product, are
+// dropped.
+func (l *ExportLayout) Row(feedback *Feedback) []any {
+	row := make([]any, len(exportBaseColumns)+len(l.questions))
+	row[0] = feedback.ID.String()
+	row[1] = feedback.CreatedAt.UTC().Format(time.RFC3339)
+	row[2] = feedback.Product.Name
+	row[3] = feedback.QRCode.Label
+	row[4] = feedback.OverallRating
+	row[5] = feedback.CustomerName
+	row[6] = feedback.CustomerEmail
+	row[7] = feedback.CustomerPhone
+	row[8] = feedback.Language
+	row[9] = feedback.IsComplete
+	row[10] = string(feedback.Status)
+	row[11] = strings.Join(feedback.Tags, ", ")
+
+	for _, response := range feedback.Responses {
+		if index, ok := l.questions[response.QuestionID]; ok {
+			row[index] = ExportAnswer(response.Answer)
+		}
+	}
+
+	if l.selected == nil {
+		return row
+	}
+	narrowed := make([]any, len(l.selected))
+	for i, index := range l.selected {
+		narrowed[i] = row[index]
+	}
+	return narrowed
+}
+
+// ExportAnswer turns an answer into a single cell value. Lists are joined
+// and anything structured, such as matrix answers, is written as JSON.
+func ExportAnswer(answer any) any {
+	switch value := answer.(type) {
+	case nil:
+		return nil
+	case string, bool, float64, int:
+		return value
+	case []any:
+		parts := make([]string, 0, len(value))
+		for _, item := range value {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return strings.Join(parts, ", ")
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(data)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	productModels "kyooar/internal/product/models"
 	qrcodemodel "kyooar/internal/qrcode/model"
 	organizationmodel "kyooar/internal/organization/model"
@@ -20,13 +21,54 @@ type Feedback struct {
 	Product        productModels.Product                  `json:"product,omitempty"`
 	QRCodeID       uuid.UUID                           `gorm:"not null" json:"qr_code_id"`
 	QRCode         qrcodemodel.QRCode                 `json:"qr_code,omitempty"`
+	QuestionnaireVersionID *uuid.UUID                 `json:"questionnaire_version_id,omitempty"`
 	CustomerName   string                              `json:"customer_name"`
 	CustomerEmail  string                              `json:"customer_email"`
 	CustomerPhone  string                              `json:"customer_phone"`
 	OverallRating  int                                 `json:"overall_rating"`
 	Responses      Responses                           `gorm:"type:jsonb" json:"responses"`
 	DeviceInfo     DeviceInfo                          `gorm:"type:jsonb" json:"device_info"`
+	Language       string                              `json:"language,omitempty"`
 	IsComplete     bool                                `gorm:"default:true" json:"is_complete"`
+	SessionTokenHash *string                           `json:"-"`
+	LastActivityAt *time.Time                          `json:"last_activity_at,omitempty"`
+	CompletedAt    *time.Time                          `json:"completed_at,omitempty"`
+	Attachments    []FeedbackAttachment                `gorm:"foreignKey:FeedbackID" json:"attachments,omitempty"`
+	IsQuarantined  bool                                `gorm:"default:false" json:"is_quarantined"`
+	QuarantineReasons pq.StringArray                   `gorm:"type:text[]" json:"quarantine_reasons,omitempty" swaggertype:"array,string"`
+	ReviewedAt     *time.Time                          `json:"reviewed_at,omitempty"`
+	ReviewedBy     *uuid.UUID                          `json:"reviewed_by,omitempty"`
+	DeviceHash     string                              `json:"-"`
+	BodyHash       string                              `json:"-"`
+	IdempotencyKey string                              `json:"-"`
+	KioskDeviceID  *uuid.UUID                          `json:"kiosk_device_id,omitempty"`
+	CapturedAt     *time.Time                          `json:"captured_at,omitempty"`
+	Status         FeedbackStatus                      `gorm:"default:new" json:"status"`
+	AssigneeID     *uuid.UUID                          `json:"assignee_id,omitempty"`
+	Tags           pq.StringArray                      `gorm:"type:text[]" json:"tags" swaggertype:"array,string"`
+	AnonymizedAt   *time.Time                          `json:"anonymized_at,omitempty"`
+	TextRemovedAt  *time.Time                          `json:"text_removed_at,omitempty"`
+	ImportID       *uuid.UUID                          `json:"import_id,omitempty"`
+	ExcludedFromAnalytics bool                         `gorm:"default:false" json:"excluded_from_analytics"`
+	// SearchLanguage is the text search configuration the row is indexed with.
+	SearchLanguage string                              `gorm:"default:simple" json:"-"`
+
+	// Search results only: how well the feedback matched and the matching text,
+	// HTML-escaped, with the search terms wrapped in <mark> tags.
+	SearchRank    float64 `gorm:"->;-:migration" json:"search_rank,omitempty"`
+	SearchSnippet string  `gorm:"->;-:migration" json:"search_snippet,omitempty"`
+
+	// Submission signals checked by the abuse guard and never stored.
+	Honeypot          string `gorm:"-" json:"website,omitempty"`
+	Challenge         string `gorm:"-" json:"-"`
+	ChallengeSolution string `gorm:"-" json:"-"`
+	// DeviceToken identifies a paired kiosk and is resolved to KioskDeviceID.
+	DeviceToken string `gorm:"-" json:"-"`
+	// QRToken is the token from a rotating QR code's URL.
+	QRToken string `gorm:"-" json:"-"`
+	// Queued marks feedback captured by an offline client and sent in a
+	// batch, which is held to the hourly limits once for the whole batch.
+	Queued bool `gorm:"-" json:"-"`
 }
 
 type Responses []Response
@@ -70,6 +112,8 @@ func (d *DeviceInfo) Scan(value interface{}) error {
 }
 
 type FeedbackFilter struct {
+	// Search is a web-style query: words are matched after stemming, "quoted
+	// phrases" match in order and -word excludes. Results are ranked by match.
 	Search     string     `json:"search,omitempty"`
 	RatingMin  *int       `json:"rating_min,omitempty"`
 	RatingMax  *int       `json:"rating_max,omitempty"`
@@ -77,6 +121,41 @@ type FeedbackFilter struct {
 	DateTo     *time.Time `json:"date_to,omitempty"`
 	ProductID  *uuid.UUID `json:"product_id,omitempty"`
 	IsComplete *bool      `json:"is_complete,omitempty"`
+	QuestionnaireVersionID *uuid.UUID `json:"questionnaire_version_id,omitempty"`
+	IsQuarantined *bool   `json:"is_quarantined,omitempty"`
+	KioskDeviceID *uuid.UUID `json:"kiosk_device_id,omitempty"`
+	Status     *FeedbackStatus `json:"status,omitempty"`
+	AssigneeID *uuid.UUID      `json:"assignee_id,omitempty"`
+	// Unassigned limits results to feedback nobody is assigned to.
+	Unassigned bool     `json:"unassigned,omitempty"`
+	// Tags limits results to feedback carrying every one of the tags.
+	Tags       []string `json:"tags,omitempty"`
+	IsImported *bool      `json:"is_imported,omitempty"`
+	ImportID   *uuid.UUID `json:"import_id,omitempty"`
+	// AnalyticsOnly leaves out imported feedback excluded from analytics.
+	AnalyticsOnly bool `json:"-"`
+}
+
+// FeedbackSort orders a feedback list. Lists default to newest first, or to
+// best match first when searching. Feedback with the same rating or product
+// is listed newest first.
+type FeedbackSort string
+
+const (
+	FeedbackSortNewest        FeedbackSort = "newest"
+	FeedbackSortOldest        FeedbackSort = "oldest"
+	FeedbackSortHighestRating FeedbackSort = "highest_rating"
+	FeedbackSortLowestRating  FeedbackSort = "lowest_rating"
+	// FeedbackSortProduct orders by product name.
+	FeedbackSortProduct FeedbackSort = "product"
+)
+
+func (s FeedbackSort) IsValid() bool {
+	switch s {
+	case FeedbackSortNewest, FeedbackSortOldest, FeedbackSortHighestRating, FeedbackSortLowestRating, FeedbackSortProduct:
+		return true
+	}
+	return false
 }
 
 type FeedbackStats struct {
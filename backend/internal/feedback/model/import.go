@@ -0,0 +1,367 @@
+package feedbackmodel
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	productModels "kyooar/internal/product/models"
+	sharedModels "kyooar/internal/shared/models"
+)
+
+const (
+	// MaxImportRows bounds how many feedback rows one file can import.
+	MaxImportRows = 10000
+	// MaxImportFileSize bounds the size of an uploaded import file in bytes.
+	MaxImportFileSize = 10 << 20
+)
+
+// FeedbackImport records a file of historical feedback imported into an
+// organization. Deleting it removes the feedback it created.
+type FeedbackImport struct {
+	sharedModels.BaseModel
+	OrganizationID     uuid.UUID     `gorm:"not null" json:"organization_id"`
+	RequestedBy        uuid.UUID     `gorm:"not null" json:"requested_by"`
+	FileName           string        `json:"file_name"`
+	Mapping            ImportMapping `gorm:"type:jsonb" json:"mapping"`
+	RowCount           int           `json:"row_count"`
+	CountTowardLimit   bool          `json:"count_toward_limit"`
+	IncludeInAnalytics bool          `gorm:"default:true" json:"include_in_analytics"`
+}
+
+type ImportDateFormat string
+
+const (
+	// ImportDateFormatISO accepts RFC 3339 timestamps and YYYY-MM-DD dates,
+	// optionally followed by a HH:MM or HH:MM:SS time.
+	ImportDateFormatISO ImportDateFormat = "iso"
+	// ImportDateFormatUS reads dates as MM/DD/YYYY.
+	ImportDateFormatUS ImportDateFormat = "us"
+	// ImportDateFormatEU reads dates as DD/MM/YYYY.
+	ImportDateFormatEU ImportDateFormat = "eu"
+)
+
+var importDateLayouts = map[ImportDateFormat][]string{
+	ImportDateFormatISO: {"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"},
+	ImportDateFormatUS:  {"01/02/2006 15:04:05", "01/02/2006 15:04", "01/02/2006", "1/2/2006 15:04:05", "1/2/2006 15:04", "1/2/2006"},
+	ImportDateFormatEU:  {"02/01/2006 15:04:05", "02/01/2006 15:04", "02/01/2006", "2/1/2006 15:04:05", "2/1/2006 15:04", "2/1/2006"},
+}
+
+// ImportMapping tells which CSV columns, named by their header, hold which
+// feedback fields. Every row belongs to ProductID, or to the product named
+// in ProductColumn when the file mixes products.
+type ImportMapping struct {
+	ProductID           *uuid.UUID       `json:"product_id,omitempty"`
+	ProductColumn       string           `json:"product_column,omitempty"`
+	QRCodeID            uuid.UUID        `json:"qr_code_id"`
+	CreatedAtColumn     string           `json:"created_at_column"`
+	DateFormat          ImportDateFormat `json:"date_format,omitempty"`
+	// Timezone is the IANA zone of timestamps without an offset. Defaults to UTC.
+	Timezone            string           `json:"timezone,omitempty"`
+	OverallRatingColumn string           `json:"overall_rating_column,omitempty"`
+	CustomerNameColumn  string           `json:"customer_name_column,omitempty"`
+	CustomerEmailColumn string           `json:"customer_email_column,omitempty"`
+	CustomerPhoneColumn string           `json:"customer_phone_column,omitempty"`
+	Questions           []ImportQuestionColumn `json:"questions,omitempty"`
+}
+
+type ImportQuestionColumn struct {
+	Column     string    `json:"column"`
+	QuestionID uuid.UUID `json:"question_id"`
+}
+
+func (m ImportMapping) Value() (driver.Value, error) { return json.Marshal(m) }
+func (m *ImportMapping) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return json.Unmarshal([]byte("{}"), m)
+	}
+	return json.Unmarshal(bytes, m)
+}
+
+type ImportFeedbackRequest struct {
+	FileName string
+	Mapping  ImportMapping
+	// DryRun checks every row and reports errors without importing anything.
+	DryRun             bool
+	CountTowardLimit   bool
+	IncludeInAnalytics bool
+}
+
+// ImportRowError points at a problem in the file. Row is the line number in
+// the file, counting the header as line 1, and is 0 for problems with the
+// mapping itself.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportReport struct {
+	DryRun    bool             `json:"dry_run"`
+	TotalRows int              `json:"total_rows"`
+	ValidRows int              `json:"valid_rows"`
+	Errors    []ImportRowError `json:"errors"`
+	// OlderThanRetention counts valid rows dated before the organization's
+	// retention period. Retention only applies to them once the period has
+	// passed since the import, and RetentionNotice explains this.
+	OlderThanRetention int             `json:"older_than_retention,omitempty"`
+	RetentionNotice    string          `json:"retention_notice,omitempty"`
+	Import             *FeedbackImport `json:"import,omitempty"`
+}
+
+// ImportLayout turns CSV records into feedback according to a mapping that
+// has been checked against the file header and the organization's products.
+type ImportLayout struct {
+	mapping   ImportMapping
+	location  *time.Location
+	columns   map[string]int
+	products  map[string]uuid.UUID
+	questions map[uuid.UUID]*Question
+}
+
+// NewImportLayout checks the mapping against the header, products and
+// questions and returns the problems found as row 0 errors.
+func NewImportLayout(mapping ImportMapping, header []string, products []productModels.Product, questions []*Question) (*ImportLayout, []ImportRowError) {
+	layout := &ImportLayout{
+		mapping:   mapping,
+		location:  time.UTC,
+		columns:   make(map[string]int, len(header)),
+		products:  make(map[string]uuid.UUID, len(products)*2),
+		questions: make(map[uuid.UUID]*Question, len(questions)),
+	}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if _, exists := layout.columns[name]; !exists {
+			layout.columns[name] = i
+		}
+	}
+	for _, product := range products {
+		layout.products[product.ID.String()] = product.ID
+		layout.products[strings.ToLower(strings.TrimSpace(product.Name))] = product.ID
+	}
+	for _, question := range questions {
+		layout.questions[question.ID] = question
+	}
+
+	var mappingErrors []ImportRowError
+	fail := func(column, message string) {
+		mappingErrors = append(mappingErrors, ImportRowError{Column: column, Message: message})
+	}
+	requireColumn := func(column string) {
+		if _, ok := layout.columns[column]; column != "" && !ok {
+			fail(column, "Column not found in the file header")
+		}
+	}
+
+	switch {
+	case mapping.ProductID == nil && mapping.ProductColumn == "":
+		fail("", "Either a product or a product column is required")
+	case mapping.ProductID != nil && mapping.ProductColumn != "":
+		fail("", "Set either a product or a product column, not both")
+	case mapping.ProductID != nil:
+		if _, ok := layout.products[mapping.ProductID.String()]; !ok {
+			fail("", "Product not found in this organization")
+		}
+	}
+	requireColumn(mapping.ProductColumn)
+
+	if mapping.QRCodeID == uuid.Nil {
+		fail("", "A QR code is required")
+	}
+
+	if mapping.CreatedAtColumn == "" {
+		fail("", "A submission date column is required")
+	}
+	requireColumn(mapping.CreatedAtColumn)
+	if mapping.DateFormat == "" {
+		layout.mapping.DateFormat = ImportDateFormatISO
+	} else if _, ok := importDateLayouts[mapping.DateFormat]; !ok {
+		fail("", "Date format must be one of iso, us or eu")
+	}
+	if mapping.Timezone != "" {
+		location, err := time.LoadLocation(mapping.Timezone)
+		if err != nil {
+			fail("", fmt.Sprintf("Unknown timezone %q", mapping.Timezone))
+		} else {
+			layout.location = location
+		}
+	}
+
+	requireColumn(mapping.OverallRatingColumn)
+	requireColumn(mapping.CustomerNameColumn)
+	requireColumn(mapping.CustomerEmailColumn)
+	requireColumn(mapping.CustomerPhoneColumn)
+
+	mappedQuestions := make(map[uuid.UUID]bool, len(mapping.Questions))
+	for _, column := range mapping.Questions {
+		requireColumn(column.Column)
+		question, ok := layout.questions[column.QuestionID]
+		if !ok {
+			fail(column.Column, "Question not found in this organization")
+			continue
+		}
+		if question.Type == QuestionTypePhoto {
+			fail(column.Column, "Photo questions cannot be imported")
+		}
+		if mappedQuestions[column.QuestionID] {
+			fail(column.Column, "Question is mapped to more than one column")
+		}
+		mappedQuestions[column.QuestionID] = true
+	}
+
+	return layout, mappingErrors
+}
+
+// Parse builds the feedback for one record. row is its line number in the file.
+// Empty cells are treated as unanswered; required questions are not enforced
+// since historical data is often incomplete.
+func (l *ImportLayout) Parse(row int, record []string) (*Feedback, []ImportRowError) {
+	var rowErrors []ImportRowError
+	fail := func(column, message string) {
+		rowErrors = append(rowErrors, ImportRowError{Row: row, Column: column, Message: message})
+	}
+
+	feedback := &Feedback{
+		QRCodeID:   l.mapping.QRCodeID,
+		IsComplete: true,
+		Status:     FeedbackStatusResolved,
+		Responses:  Responses{},
+	}
+
+	if l.mapping.ProductID != nil {
+		feedback.ProductID = *l.mapping.ProductID
+	} else {
+		value := l.cell(record, l.mapping.ProductColumn)
+		productID, ok := l.products[strings.ToLower(value)]
+		if !ok {
+			fail(l.mapping.ProductColumn, fmt.Sprintf("Unknown product %q", value))
+		}
+		feedback.ProductID = productID
+	}
+
+	createdAt, err := l.parseTime(l.cell(record, l.mapping.CreatedAtColumn))
+	if err != nil {
+		fail(l.mapping.CreatedAtColumn, err.Error())
+	} else {
+		feedback.CreatedAt = createdAt
+		feedback.CompletedAt = &createdAt
+	}
+
+	if value := l.cell(record, l.mapping.OverallRatingColumn); value != "" {
+		rating, err := strconv.Atoi(value)
+		if err != nil || rating < defaultRatingMin || rating > defaultRatingMax {
+			fail(l.mapping.OverallRatingColumn, fmt.Sprintf("Overall rating must be a whole number between %d and %d", defaultRatingMin, defaultRatingMax))
+		}
+		feedback.OverallRating = rating
+	}
+
+	feedback.CustomerName = l.cell(record, l.mapping.CustomerNameColumn)
+	feedback.CustomerEmail = l.cell(record, l.mapping.CustomerEmailColumn)
+	feedback.CustomerPhone = l.cell(record, l.mapping.CustomerPhoneColumn)
+
+	for _, column := range l.mapping.Questions {
+		value := l.cell(record, column.Column)
+		if value == "" {
+			continue
+		}
+		question := l.questions[column.QuestionID]
+		if question.ProductID != feedback.ProductID {
+			if feedback.ProductID != uuid.Nil {
+				fail(column.Column, "Question belongs to another product than the row")
+			}
+			continue
+		}
+		answer, answerErr := question.NormalizeAnswer(importAnswer(question, value))
+		if answerErr != nil {
+			fail(column.Column, answerErr.Message)
+			continue
+		}
+		feedback.Responses = append(feedback.Responses, Response{
+			QuestionID:   question.ID,
+			QuestionText: question.Text,
+			QuestionType: question.Type,
+			Answer:       answer,
+		})
+	}
+
+	return feedback, rowErrors
+}
+
+func (l *ImportLayout) cell(record []string, column string) string {
+	index, ok := l.columns[column]
+	if column == "" || !ok || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+func (l *ImportLayout) parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("Submission date is required")
+	}
+
+	parsed, err := time.Time{}, fmt.Errorf("%q is not a valid %s date", value, l.mapping.DateFormat)
+	if l.mapping.DateFormat == ImportDateFormatISO {
+		if t, parseErr := time.Parse(time.RFC3339, value); parseErr == nil {
+			parsed, err = t, nil
+		}
+	}
+	for _, layout := range importDateLayouts[l.mapping.DateFormat] {
+		if err == nil {
+			break
+		}
+		if t, parseErr := time.ParseInLocation(layout, value, l.location); parseErr == nil {
+			parsed, err = t, nil
+		}
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if parsed.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("Submission date is in the future")
+	}
+	return parsed, nil
+}
+
+// importAnswer converts a cell to the shape NormalizeAnswer expects for the
+// question. Lists are separated by semicolons and matrix answers are JSON
+// objects of row to score.
+func importAnswer(question *Question, value string) any {
+	switch question.Type {
+	case QuestionTypeRating, QuestionTypeScale, QuestionTypeNPS:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return value
+		}
+		return number
+	case QuestionTypeMultiChoice, QuestionTypeRanking:
+		parts := strings.Split(value, ";")
+		items := make([]any, 0, len(parts))
+		for _, part := range parts {
+			if part = strings.TrimSpace(part); part != "" {
+				items = append(items, part)
+			}
+		}
+		return items
+	case QuestionTypeMatrix:
+		var scores map[string]any
+		if err := json.Unmarshal([]byte(value), &scores); err != nil {
+			return value
+		}
+		return scores
+	}
+	return value
+}
+
+type UpdateImportRequest struct {
+	IncludeInAnalytics *bool `json:"include_in_analytics"`
+}
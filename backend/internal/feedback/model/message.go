@@ -0,0 +1,73 @@
+package feedbackmodel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	sharedModels "kyooar/internal/shared/models"
+)
+
+type MessageDirection string
+
+const (
+	// MessageDirectionOutbound is a staff reply emailed to the customer.
+	MessageDirectionOutbound MessageDirection = "outbound"
+	// MessageDirectionInbound is a customer answer left through the reply link.
+	MessageDirectionInbound MessageDirection = "inbound"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending  DeliveryStatus = "pending"
+	DeliveryStatusSent     DeliveryStatus = "sent"
+	DeliveryStatusFailed   DeliveryStatus = "failed"
+	DeliveryStatusReceived DeliveryStatus = "received"
+)
+
+const (
+	// ReplyLinkTTL is how long the link in a staff reply accepts answers.
+	ReplyLinkTTL = 30 * 24 * time.Hour
+	// MaxMessageLength bounds the body of a single message.
+	MaxMessageLength = 5000
+)
+
+// FeedbackMessage is one message in the conversation about a feedback.
+// Every outbound message carries its own reply link, so links in older
+// emails keep working after later replies.
+type FeedbackMessage struct {
+	sharedModels.BaseModel
+	FeedbackID          uuid.UUID        `gorm:"not null" json:"feedback_id"`
+	OrganizationID      uuid.UUID        `gorm:"not null" json:"organization_id"`
+	Direction           MessageDirection `gorm:"not null" json:"direction"`
+	AuthorMemberID      *uuid.UUID       `json:"author_member_id,omitempty"`
+	Body                string           `gorm:"not null" json:"body"`
+	DeliveryStatus      DeliveryStatus   `gorm:"not null" json:"delivery_status"`
+	DeliveryError       string           `json:"delivery_error,omitempty"`
+	SentAt              *time.Time       `json:"sent_at,omitempty"`
+	ReplyTokenHash      *string          `json:"-"`
+	ReplyTokenExpiresAt *time.Time       `json:"-"`
+}
+
+type SendMessageRequest struct {
+	Body string `json:"body" validate:"required"`
+}
+
+// PublicMessage is a message as shown to the customer, without staff details.
+type PublicMessage struct {
+	Direction MessageDirection `json:"direction"`
+	Body      string           `json:"body"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+type PublicThread struct {
+	OrganizationName string          `json:"organization_name"`
+	CanReply         bool            `json:"can_reply"`
+	Messages         []PublicMessage `json:"messages"`
+}
+
+// GenerateReplyToken returns a random token for a customer reply link and
+// the hash that is stored in its place.
+func GenerateReplyToken() (string, string, error) {
+	return GenerateSessionToken()
+}
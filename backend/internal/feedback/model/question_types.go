@@ -0,0 +1,177 @@
+package feedbackmodel
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+const (
+	NPSPromoter  = "promoter"
+	NPSPassive   = "passive"
+	NPSDetractor = "detractor"
+)
+
+// NPSCategory buckets a 0-10 score the standard way: 9-10 promoters,
+// 7-8 passives and 0-6 detractors.
+func NPSCategory(score float64) string {
+	switch {
+	case score >= 9:
+		return NPSPromoter
+	case score >= 7:
+		return NPSPassive
+	default:
+		return NPSDetractor
+	}
+}
+
+// NPSScore returns the percentage of promoters minus the percentage of
+// detractors, ranging from -100 to 100.
+func NPSScore(promoters, detractors, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(promoters-detractors) / float64(total) * 100
+}
+
+// normalizeMatrix expects an object mapping each row (taken from Options) to a
+// score on the question's shared scale. Required matrix questions must have
+// every row rated.
+func (q *Question) normalizeMatrix(answer any) (any, *AnswerError) {
+	rows, ok := answer.(map[string]any)
+	if !ok {
+		return nil, q.answerError(AnswerErrorInvalidType, "Answer must rate each row")
+	}
+
+	scores := make(map[string]float64, len(rows))
+	for row, value := range rows {
+		key, ok := q.resolveOption(row)
+		if !ok {
+			return nil, q.answerError(AnswerErrorInvalidOption, fmt.Sprintf("%q is not a valid row", row))
+		}
+		if value == nil {
+			continue
+		}
+		score, answerErr := q.normalizeScore(value)
+		if answerErr != nil {
+			answerErr.Message = fmt.Sprintf("%s: %s", row, answerErr.Message)
+			return nil, answerErr
+		}
+		scores[key] = score
+	}
+
+	if q.IsRequired && len(scores) < len(q.Options) {
+		return nil, q.answerError(AnswerErrorRequired, "Every row must be rated")
+	}
+
+	return scores, nil
+}
+
+// normalizeRanking expects every option exactly once, most preferred first.
+func (q *Question) normalizeRanking(answer any) (any, *AnswerError) {
+	items, ok := answer.([]any)
+	if !ok {
+		return nil, q.answerError(AnswerErrorInvalidType, "Answer must be an ordered list of options")
+	}
+
+	ranking := make([]string, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		value, ok := item.(string)
+		if !ok {
+			return nil, q.answerError(AnswerErrorInvalidType, "Answer must be an ordered list of options")
+		}
+		key, ok := q.resolveOption(value)
+		if !ok {
+			return nil, q.answerError(AnswerErrorInvalidOption, fmt.Sprintf("%q is not a valid option", value))
+		}
+		if seen[key] {
+			return nil, q.answerError(AnswerErrorDuplicate, fmt.Sprintf("%q was ranked more than once", value))
+		}
+		seen[key] = true
+		ranking = append(ranking, key)
+	}
+
+	if len(q.Options) > 0 && len(ranking) != len(q.Options) {
+		return nil, q.answerError(AnswerErrorInvalidOption, "Every option must be ranked")
+	}
+
+	return ranking, nil
+}
+
+// MatrixScores reads a stored matrix answer, whether freshly normalized or
+// decoded from JSON.
+func MatrixScores(answer any) map[string]float64 {
+	switch v := answer.(type) {
+	case map[string]float64:
+		return v
+	case map[string]any:
+		scores := make(map[string]float64, len(v))
+		for row, value := range v {
+			if score, ok := value.(float64); ok {
+				scores[row] = score
+			}
+		}
+		return scores
+	}
+	return nil
+}
+
+// RankingOrder reads a stored ranking answer, whether freshly normalized or
+// decoded from JSON.
+func RankingOrder(answer any) []string {
+	switch v := answer.(type) {
+	case []string:
+		return v
+	case []any:
+		ranking := make([]string, 0, len(v))
+		for _, item := range v {
+			if value, ok := item.(string); ok {
+				ranking = append(ranking, value)
+			}
+		}
+		return ranking
+	}
+	return nil
+}
+
+// ValidateDefinition checks the type-specific settings of a question.
+func (q *Question) ValidateDefinition() error {
+	switch q.Type {
+	case QuestionTypeRating, QuestionTypeScale, QuestionTypeNPS, QuestionTypeSingleChoice,
+		QuestionTypeMultiChoice, QuestionTypeText, QuestionTypeYesNo, QuestionTypePhoto:
+	case QuestionTypeMatrix:
+		if len(q.Options) == 0 {
+			return fmt.Errorf("matrix questions need at least one row")
+		}
+	case QuestionTypeRanking:
+		if len(q.Options) < 2 {
+			return fmt.Errorf("ranking questions need at least two options")
+		}
+	default:
+		return fmt.Errorf("unsupported question type %q", q.Type)
+	}
+
+	if q.Type == QuestionTypeRating || q.Type == QuestionTypeScale || q.Type == QuestionTypeMatrix {
+		if min, max := q.Bounds(); min >= max {
+			return fmt.Errorf("min value must be lower than max value")
+		}
+	}
+	return nil
+}
+
+// PhotoAttachmentIDs returns the attachments referenced by photo answers.
+func (r Responses) PhotoAttachmentIDs() []uuid.UUID {
+	var ids []uuid.UUID
+	for _, response := range r {
+		if response.QuestionType != QuestionTypePhoto {
+			continue
+		}
+		if value, ok := response.Answer.(string); ok {
+			if id, err := uuid.Parse(value); err == nil {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
@@ -34,6 +34,9 @@ type Question struct {
 	MaxValue     *int                `json:"max_value"`
 	MinLabel     string              `json:"min_label"`
 	MaxLabel     string              `json:"max_label"`
+	DisplayRules DisplayRules        `gorm:"type:jsonb;default:'[]'" json:"display_rules"`
+	Translations QuestionTranslations `gorm:"type:jsonb;default:'{}'" json:"translations"`
+	OptionKeys   []string            `gorm:"-" json:"option_keys,omitempty"`
 }
 
 type QuestionType string
@@ -45,8 +48,21 @@ const (
 	QuestionTypeSingleChoice QuestionType = "single_choice"
 	QuestionTypeText         QuestionType = "text"
 	QuestionTypeYesNo        QuestionType = "yes_no"
+	QuestionTypeNPS          QuestionType = "nps"
+	QuestionTypeMatrix       QuestionType = "matrix"
+	QuestionTypeRanking      QuestionType = "ranking"
+	QuestionTypePhoto        QuestionType = "photo"
 )
 
+type PublicQuestionnaire struct {
+	Questionnaire *Questionnaire                     `json:"questionnaire,omitempty"`
+	Organization  organizationmodel.PublicBranding   `json:"organization"`
+	Product       *productModels.Product             `json:"product"`
+	Questions     []*Question                        `json:"questions"`
+	Language      string                             `json:"language"`
+	Languages     []string                           `json:"languages"`
+}
+
 type QuestionTemplate struct {
 	sharedModels.BaseModel
 	Category    string         `gorm:"not null" json:"category"`
@@ -102,6 +118,8 @@ type CreateQuestionRequest struct {
 	MaxValue   *int           `json:"max_value,omitempty"`
 	MinLabel   string         `json:"min_label,omitempty"`
 	MaxLabel   string         `json:"max_label,omitempty"`
+	DisplayRules DisplayRules  `json:"display_rules,omitempty"`
+	Translations QuestionTranslations `json:"translations,omitempty"`
 }
 
 type UpdateQuestionRequest struct {
@@ -113,6 +131,8 @@ type UpdateQuestionRequest struct {
 	MaxValue   *int           `json:"max_value,omitempty"`
 	MinLabel   string         `json:"min_label,omitempty"`
 	MaxLabel   string         `json:"max_label,omitempty"`
+	DisplayRules DisplayRules  `json:"display_rules,omitempty"`
+	Translations QuestionTranslations `json:"translations,omitempty"`
 }
 
 type BatchQuestionsRequest struct {
@@ -0,0 +1,98 @@
+package feedbackmodel
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// SessionAbandonAfter is how long an incomplete session may sit idle
+	// before it is counted as abandoned in completion rates.
+	SessionAbandonAfter = 30 * time.Minute
+	// SessionResumeWindow is how long a session token can be used to resume.
+	SessionResumeWindow = 24 * time.Hour
+)
+
+type StartSessionRequest struct {
+	QRCodeID  uuid.UUID `json:"qr_code_id" validate:"required"`
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	Language  string    `json:"language,omitempty"`
+
+	// DeviceToken identifies a paired kiosk, see Feedback.
+	DeviceToken string `json:"-"`
+	QRToken     string `json:"-"`
+}
+
+type SaveAnswerRequest struct {
+	Answer any `json:"answer"`
+}
+
+type CompleteSessionRequest struct {
+	CustomerName  string `json:"customer_name"`
+	CustomerEmail string `json:"customer_email"`
+	CustomerPhone string `json:"customer_phone"`
+	OverallRating int    `json:"overall_rating"`
+
+	// Honeypot and proof-of-work signals, see Feedback.
+	Website           string `json:"website,omitempty"`
+	Challenge         string `json:"-"`
+	ChallengeSolution string `json:"-"`
+}
+
+// FeedbackSession is what the holder of a session token sees of the
+// feedback: the answers so far, never the fields staff set while triaging.
+type FeedbackSession struct {
+	Token       string     `json:"token,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	FeedbackID  uuid.UUID  `json:"feedback_id"`
+	Responses   Responses  `json:"responses"`
+	Language    string     `json:"language,omitempty"`
+	IsComplete  bool       `json:"is_complete"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// GenerateSessionToken returns a random opaque token and the hash that is stored in its place.
+func GenerateSessionToken() (string, string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(bytes)
+	return token, HashSessionToken(token), nil
+}
+
+func HashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsSessionExpired reports whether an incomplete session can no longer be resumed.
+func (f *Feedback) IsSessionExpired(now time.Time) bool {
+	if f.LastActivityAt == nil {
+		return false
+	}
+	return now.Sub(*f.LastActivityAt) > SessionResumeWindow
+}
+
+// SetAnswer replaces the response for a question, or removes it when answer is nil.
+func (f *Feedback) SetAnswer(question *Question, answer any) {
+	responses := make(Responses, 0, len(f.Responses)+1)
+	for _, response := range f.Responses {
+		if response.QuestionID != question.ID {
+			responses = append(responses, response)
+		}
+	}
+	if answer != nil {
+		responses = append(responses, Response{
+			QuestionID:   question.ID,
+			QuestionText: question.Text,
+			QuestionType: question.Type,
+			Answer:       answer,
+		})
+	}
+	f.Responses = responses
+}
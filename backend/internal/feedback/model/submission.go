@@ -0,0 +1,92 @@
+package feedbackmodel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxBatchSize caps how many queued submissions one batch may carry.
+	MaxBatchSize = 100
+	// MaxIdempotencyKeyLength matches the idempotency_key column.
+	MaxIdempotencyKeyLength = 255
+	// CaptureClockSkew tolerates client clocks running slightly ahead.
+	CaptureClockSkew = 5 * time.Minute
+	// MaxCaptureAge bounds how long an offline client may hold on to feedback.
+	MaxCaptureAge = 30 * 24 * time.Hour
+)
+
+type SubmissionStatus string
+
+const (
+	SubmissionStatusCreated   SubmissionStatus = "created"
+	SubmissionStatusDuplicate SubmissionStatus = "duplicate"
+	SubmissionStatusFailed    SubmissionStatus = "failed"
+)
+
+// SubmissionResult reports the outcome of one submission. Duplicate means
+// the idempotency key was already used and FeedbackID is the stored feedback.
+type SubmissionResult struct {
+	Index          int              `json:"index"`
+	IdempotencyKey string           `json:"idempotency_key,omitempty"`
+	Status         SubmissionStatus `json:"status"`
+	FeedbackID     *uuid.UUID       `json:"feedback_id,omitempty"`
+	ErrorCode      string           `json:"error_code,omitempty"`
+	Error          string           `json:"error,omitempty"`
+	Details        any              `json:"details,omitempty"`
+}
+
+// SubmitFeedbackRequest is what a customer submits. It carries only the
+// fields a client may set; status, triage, retention and import fields are
+// owned by the server and start out empty.
+type SubmitFeedbackRequest struct {
+	QRCodeID      uuid.UUID  `json:"qr_code_id"`
+	ProductID     uuid.UUID  `json:"product_id"`
+	CustomerName  string     `json:"customer_name"`
+	CustomerEmail string     `json:"customer_email"`
+	CustomerPhone string     `json:"customer_phone"`
+	OverallRating int        `json:"overall_rating"`
+	Responses     Responses  `json:"responses"`
+	Language      string     `json:"language,omitempty"`
+	CapturedAt    *time.Time `json:"captured_at,omitempty"`
+	// Honeypot is a hidden form field that only bots fill in.
+	Honeypot string `json:"website,omitempty"`
+}
+
+// Feedback returns a new feedback holding the submitted fields.
+func (r *SubmitFeedbackRequest) Feedback() *Feedback {
+	return &Feedback{
+		QRCodeID:      r.QRCodeID,
+		ProductID:     r.ProductID,
+		CustomerName:  r.CustomerName,
+		CustomerEmail: r.CustomerEmail,
+		CustomerPhone: r.CustomerPhone,
+		OverallRating: r.OverallRating,
+		Responses:     r.Responses,
+		Language:      r.Language,
+		CapturedAt:    r.CapturedAt,
+		Honeypot:      r.Honeypot,
+	}
+}
+
+// BatchFeedbackItem is a feedback queued by an offline client. CapturedAt
+// and IdempotencyKey are required so that retried batches are safe.
+type BatchFeedbackItem struct {
+	SubmitFeedbackRequest
+	IdempotencyKey    string `json:"idempotency_key"`
+	Challenge         string `json:"challenge,omitempty"`
+	ChallengeSolution string `json:"challenge_solution,omitempty"`
+	QRToken           string `json:"qr_token,omitempty"`
+}
+
+type BatchFeedbackRequest struct {
+	Items []BatchFeedbackItem `json:"items"`
+}
+
+type BatchFeedbackResponse struct {
+	Results   []SubmissionResult `json:"results"`
+	Created   int                `json:"created"`
+	Duplicate int                `json:"duplicate"`
+	Failed    int                `json:"failed"`
+}
@@ -0,0 +1,236 @@
+package feedbackmodel
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// QuestionTranslation holds the wording of a question in one language.
+// Options maps each option key, the value stored in Question.Options, to
+// its translated label; keys without a label fall back to the key itself.
+type QuestionTranslation struct {
+	Text     string            `json:"text"`
+	Options  map[string]string `json:"options,omitempty"`
+	MinLabel string            `json:"min_label,omitempty"`
+	MaxLabel string            `json:"max_label,omitempty"`
+}
+
+// QuestionTranslations is keyed by lowercase language tag such as "es" or "pt-br".
+type QuestionTranslations map[string]QuestionTranslation
+
+func (t QuestionTranslations) Value() (driver.Value, error) {
+	if t == nil {
+		return json.Marshal(QuestionTranslations{})
+	}
+	return json.Marshal(t)
+}
+
+func (t *QuestionTranslations) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return json.Unmarshal([]byte("{}"), t)
+	}
+	return json.Unmarshal(bytes, t)
+}
+
+// Normalized returns the translations keyed by normalized language tags.
+func (t QuestionTranslations) Normalized() QuestionTranslations {
+	if t == nil {
+		return nil
+	}
+	normalized := make(QuestionTranslations, len(t))
+	for tag, translation := range t {
+		normalized[NormalizeLanguageTag(tag)] = translation
+	}
+	return normalized
+}
+
+// NormalizeLanguageTag lowercases a tag and uses "-" as the separator, so
+// "pt_BR" and "pt-BR" both become "pt-br".
+func NormalizeLanguageTag(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// ParseAcceptLanguage returns the languages of an Accept-Language header
+// ordered by preference. Wildcards and entries with q=0 are skipped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	var entries []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := NormalizeLanguageTag(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		entries = append(entries, weighted{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].quality > entries[j].quality
+	})
+
+	languages := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		languages = append(languages, entry.tag)
+	}
+	return languages
+}
+
+// AvailableLanguages lists the default language followed by every language
+// that at least one of the questions is translated into.
+func AvailableLanguages(questions []*Question, defaultLanguage string) []string {
+	defaultLanguage = NormalizeLanguageTag(defaultLanguage)
+	seen := map[string]bool{}
+	var languages []string
+	if defaultLanguage != "" {
+		seen[defaultLanguage] = true
+		languages = append(languages, defaultLanguage)
+	}
+
+	var translated []string
+	for _, question := range questions {
+		for tag := range question.Translations {
+			if !seen[tag] {
+				seen[tag] = true
+				translated = append(translated, tag)
+			}
+		}
+	}
+	sort.Strings(translated)
+	return append(languages, translated...)
+}
+
+// SelectLanguage picks the first preferred language that is available,
+// matching "es-mx" against "es" when only the base language exists, and
+// falls back to the default language.
+func SelectLanguage(preferred []string, available []string, defaultLanguage string) string {
+	supported := make(map[string]bool, len(available))
+	for _, tag := range available {
+		supported[tag] = true
+	}
+
+	for _, tag := range preferred {
+		tag = NormalizeLanguageTag(tag)
+		if supported[tag] {
+			return tag
+		}
+		if base, _, found := strings.Cut(tag, "-"); found && supported[base] {
+			return base
+		}
+	}
+	return NormalizeLanguageTag(defaultLanguage)
+}
+
+// Localize returns a copy of the question worded in the given language.
+// Options are replaced by their translated labels and the keys are kept in
+// OptionKeys; answers may use either, and are stored as keys.
+func (q *Question) Localize(language string) *Question {
+	localized := *q
+	localized.OptionKeys = q.Options
+
+	translation, ok := q.translation(language)
+	if !ok {
+		return &localized
+	}
+
+	if translation.Text != "" {
+		localized.Text = translation.Text
+	}
+	if translation.MinLabel != "" {
+		localized.MinLabel = translation.MinLabel
+	}
+	if translation.MaxLabel != "" {
+		localized.MaxLabel = translation.MaxLabel
+	}
+	if len(q.Options) > 0 && len(translation.Options) > 0 {
+		localized.Options = make([]string, len(q.Options))
+		for i, key := range q.Options {
+			localized.Options[i] = key
+			if label := translation.Options[key]; label != "" {
+				localized.Options[i] = label
+			}
+		}
+	}
+	return &localized
+}
+
+func (q *Question) translation(language string) (QuestionTranslation, bool) {
+	language = NormalizeLanguageTag(language)
+	if translation, ok := q.Translations[language]; ok {
+		return translation, true
+	}
+	if base, _, found := strings.Cut(language, "-"); found {
+		translation, ok := q.Translations[base]
+		return translation, ok
+	}
+	return QuestionTranslation{}, false
+}
+
+// resolveOption maps an answer to its option key. Translated labels are
+// accepted so that analytics always group by key regardless of the language
+// the customer answered in.
+func (q *Question) resolveOption(value string) (string, bool) {
+	if len(q.Options) == 0 {
+		return value, strings.TrimSpace(value) != ""
+	}
+	for _, option := range q.Options {
+		if option == value {
+			return option, true
+		}
+	}
+	for _, translation := range q.Translations {
+		for key, label := range translation.Options {
+			if label == value && q.isOptionKey(key) {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (q *Question) isOptionKey(value string) bool {
+	for _, option := range q.Options {
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTranslations checks that translations only label existing options.
+func (q *Question) ValidateTranslations() error {
+	for tag, translation := range q.Translations {
+		if tag == "" {
+			return fmt.Errorf("translations need a language tag such as \"es\" or \"pt-br\"")
+		}
+		for key := range translation.Options {
+			if !q.isOptionKey(key) {
+				return fmt.Errorf("translation %q labels unknown option %q", tag, key)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package feedbackmodel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	sharedModels "kyooar/internal/shared/models"
+)
+
+// FeedbackStatus is where a feedback stands in the follow-up workflow.
+type FeedbackStatus string
+
+const (
+	FeedbackStatusNew        FeedbackStatus = "new"
+	FeedbackStatusInProgress FeedbackStatus = "in_progress"
+	FeedbackStatusResolved   FeedbackStatus = "resolved"
+	FeedbackStatusDismissed  FeedbackStatus = "dismissed"
+)
+
+func (s FeedbackStatus) IsValid() bool {
+	switch s {
+	case FeedbackStatusNew, FeedbackStatusInProgress, FeedbackStatusResolved, FeedbackStatusDismissed:
+		return true
+	}
+	return false
+}
+
+const (
+	MaxTags              = 20
+	MaxTagLength         = 50
+	MaxNoteLength        = 5000
+	DefaultActivityLimit = 100
+)
+
+type ActivityAction string
+
+const (
+	ActivityStatusChanged ActivityAction = "status_changed"
+	ActivityAssigned      ActivityAction = "assigned"
+	ActivityUnassigned    ActivityAction = "unassigned"
+	ActivityTagsChanged   ActivityAction = "tags_changed"
+	ActivityNoteAdded     ActivityAction = "note_added"
+)
+
+// FeedbackNote is an internal note left by staff. Notes are never shown to
+// the customer.
+type FeedbackNote struct {
+	sharedModels.BaseModel
+	FeedbackID     uuid.UUID `gorm:"not null" json:"feedback_id"`
+	OrganizationID uuid.UUID `gorm:"not null" json:"organization_id"`
+	AuthorID       uuid.UUID `gorm:"not null" json:"author_id"`
+	Body           string    `gorm:"not null" json:"body"`
+}
+
+// FeedbackActivity records one change to a feedback's triage state. From
+// and To hold the previous and new values in their string form, such as a
+// status, an assignee ID or a comma separated list of tags.
+type FeedbackActivity struct {
+	sharedModels.BaseModel
+	FeedbackID     uuid.UUID      `gorm:"not null" json:"feedback_id"`
+	OrganizationID uuid.UUID      `gorm:"not null" json:"organization_id"`
+	ActorID        *uuid.UUID     `json:"actor_id,omitempty"`
+	Action         ActivityAction `gorm:"not null" json:"action"`
+	FromValue      string         `json:"from,omitempty"`
+	ToValue        string         `json:"to,omitempty"`
+	NoteID         *uuid.UUID     `json:"note_id,omitempty"`
+}
+
+type UpdateStatusRequest struct {
+	Status FeedbackStatus `json:"status" validate:"required,oneof=new in_progress resolved dismissed"`
+}
+
+// AssignFeedbackRequest assigns the feedback to a team member, or clears the
+// assignment when AssigneeID is null.
+type AssignFeedbackRequest struct {
+	AssigneeID *uuid.UUID `json:"assignee_id"`
+}
+
+type UpdateTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+type CreateNoteRequest struct {
+	Body string `json:"body" validate:"required"`
+}
+
+// NormalizeTags lowercases and trims tags, drops empty ones and duplicates,
+// and returns them sorted so equal tag sets compare equal.
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len([]rune(tag)) > MaxTagLength {
+			return nil, fmt.Errorf("tag %q is longer than %d characters", tag, MaxTagLength)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxTags {
+		return nil, fmt.Errorf("a feedback can have at most %d tags", MaxTags)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
@@ -0,0 +1,263 @@
+package feedbackmodel
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AnswerErrorRequired        = "required"
+	AnswerErrorUnknownQuestion = "unknown_question"
+	AnswerErrorDuplicate       = "duplicate_answer"
+	AnswerErrorInvalidType     = "invalid_type"
+	AnswerErrorInvalidOption   = "invalid_option"
+	AnswerErrorOutOfRange      = "out_of_range"
+)
+
+const (
+	defaultRatingMin = 1
+	defaultRatingMax = 5
+	defaultScaleMin  = 1
+	defaultScaleMax  = 10
+	npsMin           = 0
+	npsMax           = 10
+)
+
+type AnswerError struct {
+	QuestionID uuid.UUID `json:"question_id"`
+	Code       string    `json:"code"`
+	Message    string    `json:"message"`
+}
+
+// Bounds returns the inclusive numeric range accepted by rating, scale and
+// matrix questions. NPS always uses the standard 0-10 range.
+func (q *Question) Bounds() (int, int) {
+	if q.Type == QuestionTypeNPS {
+		return npsMin, npsMax
+	}
+	min, max := defaultRatingMin, defaultRatingMax
+	if q.Type == QuestionTypeScale {
+		min, max = defaultScaleMin, defaultScaleMax
+	}
+	if q.MinValue != nil {
+		min = *q.MinValue
+	}
+	if q.MaxValue != nil {
+		max = *q.MaxValue
+	}
+	return min, max
+}
+
+// IsAnswered reports whether the answer carries a value. Unanswered numeric
+// questions arrive as 0 from the public client, so 0 below the lower bound counts as empty.
+func (q *Question) IsAnswered(answer any) bool {
+	switch v := answer.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.TrimSpace(v) != ""
+	case []any:
+		return len(v) > 0
+	case []string:
+		return len(v) > 0
+	case map[string]any:
+		return len(v) > 0
+	case float64:
+		if q.Type == QuestionTypeRating || q.Type == QuestionTypeScale {
+			min, _ := q.Bounds()
+			return !(v == 0 && min > 0)
+		}
+	}
+	return true
+}
+
+// NormalizeAnswer checks an answer against the question definition and returns
+// it in the canonical shape the analytics aggregators expect.
+func (q *Question) NormalizeAnswer(answer any) (any, *AnswerError) {
+	switch q.Type {
+	case QuestionTypeRating, QuestionTypeScale, QuestionTypeNPS:
+		return q.normalizeScore(answer)
+
+	case QuestionTypeMatrix:
+		return q.normalizeMatrix(answer)
+
+	case QuestionTypeRanking:
+		return q.normalizeRanking(answer)
+
+	case QuestionTypeSingleChoice:
+		value, ok := answer.(string)
+		if !ok {
+			return nil, q.answerError(AnswerErrorInvalidType, "Answer must be a single option")
+		}
+		key, ok := q.resolveOption(value)
+		if !ok {
+			return nil, q.answerError(AnswerErrorInvalidOption, fmt.Sprintf("%q is not a valid option", value))
+		}
+		return key, nil
+
+	case QuestionTypeMultiChoice:
+		items, ok := answer.([]any)
+		if !ok {
+			return nil, q.answerError(AnswerErrorInvalidType, "Answer must be a list of options")
+		}
+		choices := make([]string, 0, len(items))
+		seen := make(map[string]bool)
+		for _, item := range items {
+			value, ok := item.(string)
+			if !ok {
+				return nil, q.answerError(AnswerErrorInvalidType, "Answer must be a list of options")
+			}
+			key, ok := q.resolveOption(value)
+			if !ok {
+				return nil, q.answerError(AnswerErrorInvalidOption, fmt.Sprintf("%q is not a valid option", value))
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			choices = append(choices, key)
+		}
+		return choices, nil
+
+	case QuestionTypeYesNo:
+		switch v := answer.(type) {
+		case bool:
+			return v, nil
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "yes", "true":
+				return true, nil
+			case "no", "false":
+				return false, nil
+			}
+		}
+		return nil, q.answerError(AnswerErrorInvalidType, "Answer must be yes or no")
+
+	case QuestionTypePhoto:
+		value, ok := answer.(string)
+		if !ok {
+			return nil, q.answerError(AnswerErrorInvalidType, "Answer must be an uploaded photo")
+		}
+		attachmentID, err := uuid.Parse(value)
+		if err != nil {
+			return nil, q.answerError(AnswerErrorInvalidType, "Answer must be an uploaded photo")
+		}
+		return attachmentID.String(), nil
+
+	case QuestionTypeText:
+		value, ok := answer.(string)
+		if !ok {
+			return nil, q.answerError(AnswerErrorInvalidType, "Answer must be text")
+		}
+		return strings.TrimSpace(value), nil
+	}
+
+	return nil, q.answerError(AnswerErrorInvalidType, fmt.Sprintf("Unsupported question type %q", q.Type))
+}
+
+func (q *Question) normalizeScore(answer any) (float64, *AnswerError) {
+	value, ok := answer.(float64)
+	if !ok || value != math.Trunc(value) {
+		return 0, q.answerError(AnswerErrorInvalidType, "Answer must be a whole number")
+	}
+	min, max := q.Bounds()
+	if int(value) < min || int(value) > max {
+		return 0, q.answerError(AnswerErrorOutOfRange, fmt.Sprintf("Answer must be between %d and %d", min, max))
+	}
+	return value, nil
+}
+
+func (q *Question) answerError(code, message string) *AnswerError {
+	return &AnswerError{QuestionID: q.ID, Code: code, Message: message}
+}
+
+// ValidateResponses checks submitted responses against the product's questions.
+// Valid answers are normalized in place and their question text and type are
+// taken from the definition rather than the client. Free-form text responses
+// that do not belong to a question (e.g. "Additional Comments") are kept as-is.
+// Answers to questions hidden by display rules are dropped, and only visible
+// questions are checked for required answers.
+func ValidateResponses(questions []Question, responses Responses) (Responses, []AnswerError) {
+	questionMap := make(map[uuid.UUID]*Question, len(questions))
+	for i := range questions {
+		questionMap[questions[i].ID] = &questions[i]
+	}
+
+	var answerErrors []AnswerError
+	var extra Responses
+	answers := make(map[uuid.UUID]any)
+	questionErrors := make(map[uuid.UUID]*AnswerError)
+	var answeredOrder []uuid.UUID
+
+	for _, response := range responses {
+		question, exists := questionMap[response.QuestionID]
+		if !exists {
+			if response.QuestionType == QuestionTypeText {
+				if text, ok := response.Answer.(string); ok {
+					if strings.TrimSpace(text) != "" {
+						extra = append(extra, response)
+					}
+					continue
+				}
+			}
+			answerErrors = append(answerErrors, AnswerError{
+				QuestionID: response.QuestionID,
+				Code:       AnswerErrorUnknownQuestion,
+				Message:    "Question does not belong to this product",
+			})
+			continue
+		}
+
+		if _, answered := answers[question.ID]; answered || questionErrors[question.ID] != nil {
+			answerErrors = append(answerErrors, *question.answerError(AnswerErrorDuplicate, "Question was answered more than once"))
+			continue
+		}
+
+		if !question.IsAnswered(response.Answer) {
+			continue
+		}
+
+		answer, answerErr := question.NormalizeAnswer(response.Answer)
+		if answerErr != nil {
+			questionErrors[question.ID] = answerErr
+			continue
+		}
+		answers[question.ID] = answer
+		answeredOrder = append(answeredOrder, question.ID)
+	}
+
+	visible := VisibleQuestions(questions, answers)
+
+	validated := make(Responses, 0, len(answeredOrder)+len(extra))
+	for _, questionID := range answeredOrder {
+		if !visible[questionID] {
+			continue
+		}
+		question := questionMap[questionID]
+		validated = append(validated, Response{
+			QuestionID:   question.ID,
+			QuestionText: question.Text,
+			QuestionType: question.Type,
+			Answer:       answers[questionID],
+		})
+	}
+	validated = append(validated, extra...)
+
+	for _, question := range questions {
+		if !visible[question.ID] {
+			continue
+		}
+		if answerErr := questionErrors[question.ID]; answerErr != nil {
+			answerErrors = append(answerErrors, *answerErr)
+			continue
+		}
+		if _, answered := answers[question.ID]; question.IsRequired && !answered {
+			answerErrors = append(answerErrors, *question.answerError(AnswerErrorRequired, "This question requires an answer"))
+		}
+	}
+
+	return validated, answerErrors
+}
@@ -0,0 +1,219 @@
+package feedbackmodel
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/google/uuid"
+	sharedModels "kyooar/internal/shared/models"
+)
+
+// QuestionnaireVersion is an immutable snapshot of a product's questions.
+// A new version is recorded whenever the question set changes, and every
+// feedback is pinned to the version that was live when it was answered.
+type QuestionnaireVersion struct {
+	sharedModels.BaseModel
+	OrganizationID uuid.UUID         `gorm:"not null" json:"organization_id"`
+	ProductID      uuid.UUID         `gorm:"not null" json:"product_id"`
+	Version        int               `gorm:"not null" json:"version"`
+	Hash           string            `gorm:"not null" json:"hash"`
+	QuestionCount  int               `gorm:"not null" json:"question_count"`
+	Questions      QuestionSnapshots `gorm:"type:jsonb" json:"questions,omitempty"`
+}
+
+// QuestionSnapshot holds the parts of a question that shape how it is
+// answered and reported. Timestamps are left out so that saving an unchanged
+// question does not produce a new version.
+type QuestionSnapshot struct {
+	ID           uuid.UUID            `json:"id"`
+	Text         string               `json:"text"`
+	Type         QuestionType         `json:"type"`
+	IsRequired   bool                 `json:"is_required"`
+	DisplayOrder int                  `json:"display_order"`
+	Options      []string             `json:"options"`
+	MinValue     *int                 `json:"min_value"`
+	MaxValue     *int                 `json:"max_value"`
+	MinLabel     string               `json:"min_label"`
+	MaxLabel     string               `json:"max_label"`
+	DisplayRules DisplayRules         `json:"display_rules"`
+	Translations QuestionTranslations `json:"translations,omitempty"`
+}
+
+type QuestionSnapshots []QuestionSnapshot
+
+func (s QuestionSnapshots) Value() (driver.Value, error) {
+	if s == nil {
+		return json.Marshal(QuestionSnapshots{})
+	}
+	return json.Marshal(s)
+}
+
+func (s *QuestionSnapshots) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return json.Unmarshal([]byte("[]"), s)
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+// NewQuestionSnapshots captures questions in display order. Questions sharing
+// a display order are ordered by ID, so the same questions always produce the
+// same snapshot and hash however they were loaded.
+func NewQuestionSnapshots(questions []Question) QuestionSnapshots {
+	snapshots := make(QuestionSnapshots, 0, len(questions))
+	for _, question := range questions {
+		options := []string(question.Options)
+		if options == nil {
+			options = []string{}
+		}
+		rules := question.DisplayRules
+		if rules == nil {
+			rules = DisplayRules{}
+		}
+		snapshots = append(snapshots, QuestionSnapshot{
+			ID:           question.ID,
+			Text:         question.Text,
+			Type:         question.Type,
+			IsRequired:   question.IsRequired,
+			DisplayOrder: question.DisplayOrder,
+			Options:      options,
+			MinValue:     question.MinValue,
+			MaxValue:     question.MaxValue,
+			MinLabel:     question.MinLabel,
+			MaxLabel:     question.MaxLabel,
+			DisplayRules: rules,
+			Translations: question.Translations,
+		})
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if snapshots[i].DisplayOrder != snapshots[j].DisplayOrder {
+			return snapshots[i].DisplayOrder < snapshots[j].DisplayOrder
+		}
+		return bytes.Compare(snapshots[i].ID[:], snapshots[j].ID[:]) < 0
+	})
+	return snapshots
+}
+
+// Hash fingerprints the snapshot so unchanged question sets map to the
+// current version instead of creating a new one.
+func (s QuestionSnapshots) Hash() string {
+	data, _ := json.Marshal(s)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type QuestionChange struct {
+	QuestionID uuid.UUID        `json:"question_id"`
+	Fields     []string         `json:"fields"`
+	Before     QuestionSnapshot `json:"before"`
+	After      QuestionSnapshot `json:"after"`
+}
+
+type QuestionnaireVersionDiff struct {
+	ProductID   uuid.UUID          `json:"product_id"`
+	FromVersion int                `json:"from_version"`
+	ToVersion   int                `json:"to_version"`
+	Added       []QuestionSnapshot `json:"added"`
+	Removed     []QuestionSnapshot `json:"removed"`
+	Changed     []QuestionChange   `json:"changed"`
+}
+
+// DiffVersions compares two versions of the same product's questions.
+// Questions are matched by ID, so rewording a question shows up as a change
+// rather than a removal and an addition.
+func DiffVersions(from, to *QuestionnaireVersion) *QuestionnaireVersionDiff {
+	diff := &QuestionnaireVersionDiff{
+		ProductID:   to.ProductID,
+		FromVersion: from.Version,
+		ToVersion:   to.Version,
+		Added:       []QuestionSnapshot{},
+		Removed:     []QuestionSnapshot{},
+		Changed:     []QuestionChange{},
+	}
+
+	before := make(map[uuid.UUID]QuestionSnapshot, len(from.Questions))
+	for _, question := range from.Questions {
+		before[question.ID] = question
+	}
+
+	after := make(map[uuid.UUID]bool, len(to.Questions))
+	for _, question := range to.Questions {
+		after[question.ID] = true
+		previous, exists := before[question.ID]
+		if !exists {
+			diff.Added = append(diff.Added, question)
+			continue
+		}
+		if fields := changedFields(previous, question); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, QuestionChange{
+				QuestionID: question.ID,
+				Fields:     fields,
+				Before:     previous,
+				After:      question,
+			})
+		}
+	}
+
+	for _, question := range from.Questions {
+		if !after[question.ID] {
+			diff.Removed = append(diff.Removed, question)
+		}
+	}
+
+	return diff
+}
+
+func changedFields(before, after QuestionSnapshot) []string {
+	var fields []string
+	if before.Text != after.Text {
+		fields = append(fields, "text")
+	}
+	if before.Type != after.Type {
+		fields = append(fields, "type")
+	}
+	if before.IsRequired != after.IsRequired {
+		fields = append(fields, "is_required")
+	}
+	if before.DisplayOrder != after.DisplayOrder {
+		fields = append(fields, "display_order")
+	}
+	if !reflect.DeepEqual(before.Options, after.Options) {
+		fields = append(fields, "options")
+	}
+	if !equalIntPtr(before.MinValue, after.MinValue) {
+		fields = append(fields, "min_value")
+	}
+	if !equalIntPtr(before.MaxValue, after.MaxValue) {
+		fields = append(fields, "max_value")
+	}
+	if before.MinLabel != after.MinLabel {
+		fields = append(fields, "min_label")
+	}
+	if before.MaxLabel != after.MaxLabel {
+		fields = append(fields, "max_label")
+	}
+	beforeRules, _ := json.Marshal(before.DisplayRules)
+	afterRules, _ := json.Marshal(after.DisplayRules)
+	if string(beforeRules) != string(afterRules) {
+		fields = append(fields, "display_rules")
+	}
+	if !reflect.DeepEqual(before.Translations, after.Translations) && len(before.Translations)+len(after.Translations) > 0 {
+		fields = append(fields, "translations")
+	}
+	return fields
+}
+
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,195 @@
+package feedbackmodel
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	sharedModels "kyooar/internal/shared/models"
+)
+
+const (
+	MaxViewNameLength = 100
+	MaxViewColumns    = 100
+	// MaxDigestItems bounds how many feedbacks a digest email lists.
+	MaxDigestItems = 10
+)
+
+// FeedbackPeriod is a date range relative to when a view is opened, so a
+// view like "this week" keeps meaning this week.
+type FeedbackPeriod string
+
+const (
+	FeedbackPeriodToday      FeedbackPeriod = "today"
+	FeedbackPeriodYesterday  FeedbackPeriod = "yesterday"
+	FeedbackPeriodThisWeek   FeedbackPeriod = "this_week"
+	FeedbackPeriodLast7Days  FeedbackPeriod = "last_7_days"
+	FeedbackPeriodThisMonth  FeedbackPeriod = "this_month"
+	FeedbackPeriodLast30Days FeedbackPeriod = "last_30_days"
+)
+
+func (p FeedbackPeriod) IsValid() bool {
+	switch p {
+	case FeedbackPeriodToday, FeedbackPeriodYesterday, FeedbackPeriodThisWeek,
+		FeedbackPeriodLast7Days, FeedbackPeriodThisMonth, FeedbackPeriodLast30Days:
+		return true
+	}
+	return false
+}
+
+// Range returns the first and last day of the period as used by
+// FeedbackFilter, where DateTo is inclusive and nil means up to now. Days
+// start at midnight in the given location and weeks on Monday.
+func (p FeedbackPeriod) Range(now time.Time, location *time.Location) (*time.Time, *time.Time) {
+	now = now.In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+
+	var from time.Time
+	switch p {
+	case FeedbackPeriodToday:
+		from = today
+	case FeedbackPeriodYesterday:
+		from = today.AddDate(0, 0, -1)
+		return &from, &from
+	case FeedbackPeriodThisWeek:
+		from = today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	case FeedbackPeriodLast7Days:
+		from = today.AddDate(0, 0, -6)
+	case FeedbackPeriodThisMonth:
+		from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, location)
+	case FeedbackPeriodLast30Days:
+		from = today.AddDate(0, 0, -29)
+	default:
+		return nil, nil
+	}
+	return &from, nil
+}
+
+type DigestFrequency string
+
+const (
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+func (f DigestFrequency) IsValid() bool {
+	return f == DigestDaily || f == DigestWeekly
+}
+
+// Interval is how much time a digest covers when none was sent before.
+func (f DigestFrequency) Interval() time.Duration {
+	if f == DigestWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// FeedbackView is a named, saved feedback query: its filters, sort order and
+// the columns shown in the list and in exports. Views are private to the
+// member who created them unless shared with the team.
+type FeedbackView struct {
+	sharedModels.BaseModel
+	OrganizationID uuid.UUID      `gorm:"not null" json:"organization_id"`
+	CreatedBy      uuid.UUID      `gorm:"not null" json:"created_by"`
+	Name           string         `gorm:"not null" json:"name"`
+	Shared         bool           `json:"shared"`
+	Filters        FeedbackFilter `gorm:"type:jsonb" json:"filters"`
+	// Period, when set, replaces the date range of the filters.
+	Period  FeedbackPeriod `json:"period,omitempty"`
+	Sort    FeedbackSort   `json:"sort,omitempty"`
+	Columns pq.StringArray `gorm:"type:text[]" json:"columns" swaggertype:"array,string"`
+
+	// Subscription is the requesting member's digest subscription, if any.
+	Subscription *FeedbackViewSubscription `gorm:"-" json:"subscription,omitempty"`
+}
+
+// ResolvedFilters returns the view's filters with its period turned into
+// dates as of now.
+func (v *FeedbackView) ResolvedFilters(now time.Time, location *time.Location) FeedbackFilter {
+	filters := v.Filters
+	if v.Period != "" {
+		filters.DateFrom, filters.DateTo = v.Period.Range(now, location)
+	}
+	return filters
+}
+
+// FeedbackViewSubscription emails a member a digest of new feedback matching
+// a view.
+type FeedbackViewSubscription struct {
+	sharedModels.BaseModel
+	ViewID     uuid.UUID       `gorm:"not null" json:"view_id"`
+	MemberID   uuid.UUID       `gorm:"not null" json:"member_id"`
+	Frequency  DigestFrequency `gorm:"not null" json:"frequency"`
+	LastSentAt *time.Time      `json:"last_sent_at,omitempty"`
+
+	View *FeedbackView `gorm:"foreignKey:ViewID" json:"-"`
+}
+
+// IsDue reports whether the next digest should go out at now. Weekly digests
+// go out on Mondays.
+func (s *FeedbackViewSubscription) IsDue(now time.Time) bool {
+	if s.Frequency == DigestWeekly && now.Weekday() != time.Monday {
+		return false
+	}
+	// Leave an hour of slack so a digest sent a little late yesterday does
+	// not skip today.
+	return s.LastSentAt == nil || now.Sub(*s.LastSentAt) >= s.Frequency.Interval()-time.Hour
+}
+
+type SaveFeedbackViewRequest struct {
+	Name    string         `json:"name" validate:"required"`
+	Shared  bool           `json:"shared"`
+	Filters FeedbackFilter `json:"filters"`
+	Period  FeedbackPeriod `json:"period"`
+	Sort    FeedbackSort   `json:"sort"`
+	Columns []string       `json:"columns"`
+}
+
+// Validate checks the request and trims the name and columns.
+func (r *SaveFeedbackViewRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return fmt.Errorf("Name is required")
+	}
+	if len([]rune(r.Name)) > MaxViewNameLength {
+		return fmt.Errorf("Name must be at most %d characters", MaxViewNameLength)
+	}
+	if r.Period != "" && !r.Period.IsValid() {
+		return fmt.Errorf("Period must be one of today, yesterday, this_week, last_7_days, this_month or last_30_days")
+	}
+	if r.Sort != "" && !r.Sort.IsValid() {
+		return fmt.Errorf("Sort must be one of newest, oldest, highest_rating, lowest_rating or product")
+	}
+	if len(r.Columns) > MaxViewColumns {
+		return fmt.Errorf("A view can show at most %d columns", MaxViewColumns)
+	}
+	for i, column := range r.Columns {
+		r.Columns[i] = strings.TrimSpace(column)
+		if !IsExportColumnKey(r.Columns[i]) {
+			return fmt.Errorf("Unknown column %q", column)
+		}
+	}
+	tags, err := NormalizeTags(r.Filters.Tags)
+	if err != nil {
+		return err
+	}
+	r.Filters.Tags = tags
+	if r.Filters.Status != nil && !r.Filters.Status.IsValid() {
+		return fmt.Errorf("Status must be one of new, in_progress, resolved or dismissed")
+	}
+	return nil
+}
+
+type SubscribeViewRequest struct {
+	Frequency DigestFrequency `json:"frequency" validate:"required,oneof=daily weekly"`
+}
+
+// FeedbackDigest is the new feedback matching a view since the last digest.
+type FeedbackDigest struct {
+	View  *FeedbackView
+	Since time.Time
+	Total int
+	Items []Feedback
+}
@@ -1,25 +1,42 @@
 package feedback
 
 import (
+	"fmt"
+
 	"github.com/labstack/echo/v4"
 	"github.com/samber/do"
 	"gorm.io/gorm"
 
 	aiservices "kyooar/internal/ai/services"
+	authinterface "kyooar/internal/auth/interface"
 	feedbackcontroller "kyooar/internal/feedback/controller"
 	feedbackinterface "kyooar/internal/feedback/interface"
 	feedbackmiddleware "kyooar/internal/feedback/middleware"
 	gormrepo "kyooar/internal/feedback/repository/gorm"
 	feedbackservice "kyooar/internal/feedback/service"
+	kioskinterface "kyooar/internal/kiosk/interface"
 	productRepos "kyooar/internal/product/repositories"
 	productServices "kyooar/internal/product/services"
 	organizationinterface "kyooar/internal/organization/interface"
 	qrcodeinterface "kyooar/internal/qrcode/interface"
+	"kyooar/internal/shared/config"
+	sharedServices "kyooar/internal/shared/services"
+	subscriptioninterface "kyooar/internal/subscription/interface"
 )
 
 func ProvideFeedbackRepository(i *do.Injector) (feedbackinterface.FeedbackRepository, error) {
 	db := do.MustInvoke[*gorm.DB](i)
-	return gormrepo.NewFeedbackRepository(db), nil
+	cfg := do.MustInvoke[*config.Config](i)
+
+	var known bool
+	if err := db.Raw("SELECT to_regconfig(?) IS NOT NULL", cfg.Search.Language).Scan(&known).Error; err != nil {
+		return nil, err
+	}
+	if !known {
+		return nil, fmt.Errorf("unknown SEARCH_LANGUAGE %q: not a Postgres text search configuration", cfg.Search.Language)
+	}
+
+	return gormrepo.NewFeedbackRepository(db, cfg.Search.Language), nil
 }
 
 func ProvideQuestionRepository(i *do.Injector) (feedbackinterface.QuestionRepository, error) {
@@ -32,15 +49,192 @@ func ProvideQuestionnaireRepository(i *do.Injector) (feedbackinterface.Questionn
 	return gormrepo.NewQuestionnaireRepository(db), nil
 }
 
+func ProvideAttachmentRepository(i *do.Injector) (feedbackinterface.AttachmentRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return gormrepo.NewAttachmentRepository(db), nil
+}
+
+func ProvideVersionRepository(i *do.Injector) (feedbackinterface.VersionRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return gormrepo.NewVersionRepository(db), nil
+}
+
+func ProvideMessageRepository(i *do.Injector) (feedbackinterface.MessageRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return gormrepo.NewMessageRepository(db), nil
+}
+
+func ProvideTriageRepository(i *do.Injector) (feedbackinterface.TriageRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return gormrepo.NewTriageRepository(db), nil
+}
+
+func ProvideExportRepository(i *do.Injector) (feedbackinterface.ExportRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return gormrepo.NewExportRepository(db), nil
+}
+
+func ProvideImportRepository(i *do.Injector) (feedbackinterface.ImportRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	cfg := do.MustInvoke[*config.Config](i)
+	return gormrepo.NewImportRepository(db, cfg.Search.Language), nil
+}
+
+func ProvideViewRepository(i *do.Injector) (feedbackinterface.ViewRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return gormrepo.NewViewRepository(db), nil
+}
+
+func ProvideVersionService(i *do.Injector) (feedbackinterface.VersionService, error) {
+	versionRepo := do.MustInvoke[feedbackinterface.VersionRepository](i)
+	questionRepo := do.MustInvoke[feedbackinterface.QuestionRepository](i)
+	productRepo := do.MustInvoke[productRepos.ProductRepository](i)
+	organizationRepo := do.MustInvoke[organizationinterface.OrganizationRepository](i)
+
+	return feedbackservice.NewVersionService(
+		versionRepo,
+		questionRepo,
+		productRepo,
+		organizationRepo,
+	), nil
+}
+
+func ProvideAttachmentService(i *do.Injector) (feedbackinterface.AttachmentService, error) {
+	attachmentRepo := do.MustInvoke[feedbackinterface.AttachmentRepository](i)
+	qrCodeRepo := do.MustInvoke[qrcodeinterface.QRCodeRepository](i)
+	qrCodeService := do.MustInvoke[qrcodeinterface.QRCodeService](i)
+	deviceService := do.MustInvoke[kioskinterface.DeviceService](i)
+	storage := do.MustInvoke[sharedServices.Storage](i)
+	cfg := do.MustInvoke[*config.Config](i)
+
+	return feedbackservice.NewAttachmentService(
+		attachmentRepo,
+		qrCodeRepo,
+		qrCodeService,
+		deviceService,
+		storage,
+		cfg.Storage,
+	), nil
+}
+
+func ProvideAbuseGuard(i *do.Injector) (feedbackinterface.AbuseGuard, error) {
+	feedbackRepo := do.MustInvoke[feedbackinterface.FeedbackRepository](i)
+	proofOfWork := do.MustInvoke[sharedServices.ProofOfWork](i)
+	cfg := do.MustInvoke[*config.Config](i)
+
+	return feedbackservice.NewAbuseGuard(
+		feedbackRepo,
+		proofOfWork,
+		cfg.Abuse,
+	), nil
+}
+
 func ProvideFeedbackService(i *do.Injector) (feedbackinterface.FeedbackService, error) {
 	feedbackRepo := do.MustInvoke[feedbackinterface.FeedbackRepository](i)
 	organizationRepo := do.MustInvoke[organizationinterface.OrganizationRepository](i)
+	productRepo := do.MustInvoke[productRepos.ProductRepository](i)
 	qrCodeRepo := do.MustInvoke[qrcodeinterface.QRCodeRepository](i)
+	qrCodeService := do.MustInvoke[qrcodeinterface.QRCodeService](i)
+	attachmentService := do.MustInvoke[feedbackinterface.AttachmentService](i)
+	versionService := do.MustInvoke[feedbackinterface.VersionService](i)
+	abuseGuard := do.MustInvoke[feedbackinterface.AbuseGuard](i)
+	deviceService := do.MustInvoke[kioskinterface.DeviceService](i)
 
 	return feedbackservice.NewFeedbackService(
 		feedbackRepo,
 		organizationRepo,
+		productRepo,
 		qrCodeRepo,
+		qrCodeService,
+		attachmentService,
+		versionService,
+		abuseGuard,
+		deviceService,
+	), nil
+}
+
+func ProvideMessageService(i *do.Injector) (feedbackinterface.MessageService, error) {
+	messageRepo := do.MustInvoke[feedbackinterface.MessageRepository](i)
+	feedbackRepo := do.MustInvoke[feedbackinterface.FeedbackRepository](i)
+	organizationRepo := do.MustInvoke[organizationinterface.OrganizationRepository](i)
+	emailService := do.MustInvoke[sharedServices.EmailService](i)
+
+	return feedbackservice.NewMessageService(
+		messageRepo,
+		feedbackRepo,
+		organizationRepo,
+		emailService,
+	), nil
+}
+
+func ProvideTriageService(i *do.Injector) (feedbackinterface.TriageService, error) {
+	triageRepo := do.MustInvoke[feedbackinterface.TriageRepository](i)
+	feedbackRepo := do.MustInvoke[feedbackinterface.FeedbackRepository](i)
+	organizationRepo := do.MustInvoke[organizationinterface.OrganizationRepository](i)
+	teamMemberRepo := do.MustInvoke[authinterface.TeamMemberRepository](i)
+
+	return feedbackservice.NewTriageService(
+		triageRepo,
+		feedbackRepo,
+		organizationRepo,
+		teamMemberRepo,
+	), nil
+}
+
+func ProvideExportService(i *do.Injector) (feedbackinterface.ExportService, error) {
+	exportRepo := do.MustInvoke[feedbackinterface.ExportRepository](i)
+	feedbackRepo := do.MustInvoke[feedbackinterface.FeedbackRepository](i)
+	organizationRepo := do.MustInvoke[organizationinterface.OrganizationRepository](i)
+	subscriptionRepo := do.MustInvoke[subscriptioninterface.SubscriptionRepository](i)
+	storage := do.MustInvoke[sharedServices.Storage](i)
+	cfg := do.MustInvoke[*config.Config](i)
+
+	return feedbackservice.NewExportService(
+		exportRepo,
+		feedbackRepo,
+		organizationRepo,
+		subscriptionRepo,
+		storage,
+		cfg.Export,
+		cfg.Storage.URLExpiration,
+	), nil
+}
+
+func ProvideImportService(i *do.Injector) (feedbackinterface.ImportService, error) {
+	importRepo := do.MustInvoke[feedbackinterface.ImportRepository](i)
+	questionRepo := do.MustInvoke[feedbackinterface.QuestionRepository](i)
+	productRepo := do.MustInvoke[productRepos.ProductRepository](i)
+	organizationRepo := do.MustInvoke[organizationinterface.OrganizationRepository](i)
+	qrCodeRepo := do.MustInvoke[qrcodeinterface.QRCodeRepository](i)
+	subscriptionRepo := do.MustInvoke[subscriptioninterface.SubscriptionRepository](i)
+	usageService := do.MustInvoke[subscriptioninterface.UsageService](i)
+
+	return feedbackservice.NewImportService(
+		importRepo,
+		questionRepo,
+		productRepo,
+		organizationRepo,
+		qrCodeRepo,
+		subscriptionRepo,
+		usageService,
+	), nil
+}
+
+func ProvideViewService(i *do.Injector) (feedbackinterface.ViewService, error) {
+	viewRepo := do.MustInvoke[feedbackinterface.ViewRepository](i)
+	feedbackRepo := do.MustInvoke[feedbackinterface.FeedbackRepository](i)
+	organizationRepo := do.MustInvoke[organizationinterface.OrganizationRepository](i)
+	accountRepo := do.MustInvoke[authinterface.AccountRepository](i)
+	teamMemberRepo := do.MustInvoke[authinterface.TeamMemberRepository](i)
+	emailService := do.MustInvoke[sharedServices.EmailService](i)
+
+	return feedbackservice.NewViewService(
+		viewRepo,
+		feedbackRepo,
+		organizationRepo,
+		accountRepo,
+		teamMemberRepo,
+		emailService,
 	), nil
 }
 
@@ -48,20 +242,28 @@ func ProvideQuestionService(i *do.Injector) (feedbackinterface.QuestionService,
 	questionRepo := do.MustInvoke[feedbackinterface.QuestionRepository](i)
 	productRepo := do.MustInvoke[productRepos.ProductRepository](i)
 	organizationRepo := do.MustInvoke[organizationinterface.OrganizationRepository](i)
+	versionService := do.MustInvoke[feedbackinterface.VersionService](i)
 
 	return feedbackservice.NewQuestionService(
 		questionRepo,
 		productRepo,
 		organizationRepo,
+		versionService,
 	), nil
 }
 
 func ProvideQuestionnaireService(i *do.Injector) (feedbackinterface.QuestionnaireService, error) {
 	questionnaireRepo := do.MustInvoke[feedbackinterface.QuestionnaireRepository](i)
+	questionRepo := do.MustInvoke[feedbackinterface.QuestionRepository](i)
+	productRepo := do.MustInvoke[productRepos.ProductRepository](i)
+	organizationRepo := do.MustInvoke[organizationinterface.OrganizationRepository](i)
 	questionGenerator := do.MustInvoke[*aiservices.QuestionGenerator](i)
 
 	return feedbackservice.NewQuestionnaireService(
 		questionnaireRepo,
+		questionRepo,
+		productRepo,
+		organizationRepo,
 		questionGenerator,
 	), nil
 }
@@ -73,7 +275,8 @@ func ProvideFeedbackMiddleware(i *do.Injector) (*feedbackmiddleware.FeedbackMidd
 
 func ProvideFeedbackController(i *do.Injector) (*feedbackcontroller.FeedbackController, error) {
 	feedbackService := do.MustInvoke[feedbackinterface.FeedbackService](i)
-	return feedbackcontroller.NewFeedbackController(feedbackService), nil
+	viewService := do.MustInvoke[feedbackinterface.ViewService](i)
+	return feedbackcontroller.NewFeedbackController(feedbackService, viewService), nil
 }
 
 func ProvideQuestionController(i *do.Injector) (*feedbackcontroller.QuestionController, error) {
@@ -81,6 +284,37 @@ func ProvideQuestionController(i *do.Injector) (*feedbackcontroller.QuestionCont
 	return feedbackcontroller.NewQuestionController(questionService), nil
 }
 
+func ProvideVersionController(i *do.Injector) (*feedbackcontroller.VersionController, error) {
+	versionService := do.MustInvoke[feedbackinterface.VersionService](i)
+	return feedbackcontroller.NewVersionController(versionService), nil
+}
+
+func ProvideMessageController(i *do.Injector) (*feedbackcontroller.MessageController, error) {
+	messageService := do.MustInvoke[feedbackinterface.MessageService](i)
+	return feedbackcontroller.NewMessageController(messageService), nil
+}
+
+func ProvideTriageController(i *do.Injector) (*feedbackcontroller.TriageController, error) {
+	triageService := do.MustInvoke[feedbackinterface.TriageService](i)
+	return feedbackcontroller.NewTriageController(triageService), nil
+}
+
+func ProvideExportController(i *do.Injector) (*feedbackcontroller.ExportController, error) {
+	exportService := do.MustInvoke[feedbackinterface.ExportService](i)
+	viewService := do.MustInvoke[feedbackinterface.ViewService](i)
+	return feedbackcontroller.NewExportController(exportService, viewService), nil
+}
+
+func ProvideViewController(i *do.Injector) (*feedbackcontroller.ViewController, error) {
+	viewService := do.MustInvoke[feedbackinterface.ViewService](i)
+	return feedbackcontroller.NewViewController(viewService), nil
+}
+
+func ProvideImportController(i *do.Injector) (*feedbackcontroller.ImportController, error) {
+	importService := do.MustInvoke[feedbackinterface.ImportService](i)
+	return feedbackcontroller.NewImportController(importService), nil
+}
+
 func ProvideQuestionnaireController(i *do.Injector) (*feedbackcontroller.QuestionnaireController, error) {
 	questionnaireService := do.MustInvoke[feedbackinterface.QuestionnaireService](i)
 	productService := do.MustInvoke[productServices.ProductService](i)
@@ -89,10 +323,13 @@ func ProvideQuestionnaireController(i *do.Injector) (*feedbackcontroller.Questio
 
 func ProvidePublicController(i *do.Injector) (*feedbackcontroller.PublicController, error) {
 	feedbackService := do.MustInvoke[feedbackinterface.FeedbackService](i)
+	questionnaireService := do.MustInvoke[feedbackinterface.QuestionnaireService](i)
+	attachmentService := do.MustInvoke[feedbackinterface.AttachmentService](i)
 	productRepo := do.MustInvoke[productRepos.ProductRepository](i)
 	questionnaireRepo := do.MustInvoke[feedbackinterface.QuestionnaireRepository](i)
 	questionRepo := do.MustInvoke[feedbackinterface.QuestionRepository](i)
-	return feedbackcontroller.NewPublicController(feedbackService, productRepo, questionnaireRepo, questionRepo), nil
+	messageService := do.MustInvoke[feedbackinterface.MessageService](i)
+	return feedbackcontroller.NewPublicController(feedbackService, questionnaireService, attachmentService, productRepo, questionnaireRepo, questionRepo, messageService), nil
 }
 
 type FeedbackModule struct {
@@ -107,23 +344,53 @@ func (m *FeedbackModule) RegisterRoutes(v1 *echo.Group) {
 	publicController := do.MustInvoke[*feedbackcontroller.PublicController](m.injector)
 
 	// Public feedback routes only (organization-scoped routes are now in organization module)
-	v1.GET("/questionnaire/:organizationId/:productId", publicController.GetQuestionnaire)
+	v1.GET("/public/questionnaire/:organizationId/:productId", publicController.GetQuestionnaire)
 	v1.GET("/public/organization/:organizationId/products/:productId/questions", publicController.GetProductQuestions)
 	v1.GET("/public/organization/:organizationId/questions/products-with-questions", publicController.GetProductsWithQuestions)
 	v1.POST("/public/feedback", publicController.SubmitFeedback)
+	v1.POST("/public/feedback/batch", publicController.SubmitFeedbackBatch)
+	v1.POST("/public/feedback/sessions", publicController.StartSession)
+	v1.GET("/public/feedback/sessions/:token", publicController.GetSession)
+	v1.PUT("/public/feedback/sessions/:token/answers/:questionId", publicController.SaveAnswer)
+	v1.POST("/public/feedback/sessions/:token/complete", publicController.CompleteSession)
+	v1.POST("/public/feedback/attachments", publicController.UploadAttachment)
+	v1.GET("/public/feedback/replies/:token", publicController.GetReplyThread)
+	v1.POST("/public/feedback/replies/:token", publicController.PostReply)
+	v1.GET("/public/files/*", publicController.ServeFile)
 }
 
 func RegisterNewModule(container *do.Injector) error {
 	do.Provide(container, ProvideFeedbackRepository)
 	do.Provide(container, ProvideQuestionRepository)
 	do.Provide(container, ProvideQuestionnaireRepository)
+	do.Provide(container, ProvideAttachmentRepository)
+	do.Provide(container, ProvideVersionRepository)
+	do.Provide(container, ProvideMessageRepository)
+	do.Provide(container, ProvideTriageRepository)
+	do.Provide(container, ProvideExportRepository)
+	do.Provide(container, ProvideImportRepository)
+	do.Provide(container, ProvideViewRepository)
+	do.Provide(container, ProvideVersionService)
+	do.Provide(container, ProvideAttachmentService)
+	do.Provide(container, ProvideAbuseGuard)
 	do.Provide(container, ProvideFeedbackService)
+	do.Provide(container, ProvideMessageService)
+	do.Provide(container, ProvideTriageService)
+	do.Provide(container, ProvideExportService)
+	do.Provide(container, ProvideImportService)
+	do.Provide(container, ProvideViewService)
 	do.Provide(container, ProvideQuestionService)
 	do.Provide(container, ProvideQuestionnaireService)
 	do.Provide(container, ProvideFeedbackMiddleware)
 	do.Provide(container, ProvideFeedbackController)
 	do.Provide(container, ProvideQuestionController)
 	do.Provide(container, ProvideQuestionnaireController)
+	do.Provide(container, ProvideVersionController)
+	do.Provide(container, ProvideMessageController)
+	do.Provide(container, ProvideTriageController)
+	do.Provide(container, ProvideExportController)
+	do.Provide(container, ProvideImportController)
+	do.Provide(container, ProvideViewController)
 	do.Provide(container, ProvidePublicController)
 
 	return nil
@@ -0,0 +1,75 @@
+package gorm
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+	sharedRepos "kyooar/internal/shared/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type attachmentRepository struct {
+	*sharedRepos.BaseRepository[feedbackmodel.FeedbackAttachment]
+}
+
+func NewAttachmentRepository(db *gorm.DB) *attachmentRepository {
+	return &attachmentRepository{
+		BaseRepository: sharedRepos.NewBaseRepository[feedbackmodel.FeedbackAttachment](db),
+	}
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *feedbackmodel.FeedbackAttachment) error {
+	return r.BaseRepository.Create(ctx, attachment)
+}
+
+func (r *attachmentRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]feedbackmodel.FeedbackAttachment, error) {
+	var attachments []feedbackmodel.FeedbackAttachment
+	if len(ids) == 0 {
+		return attachments, nil
+	}
+	err := r.DB.WithContext(ctx).Where("id IN ?", ids).Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *attachmentRepository) CountByQRCodeSince(ctx context.Context, qrCodeID uuid.UUID, since time.Time) (int64, error) {
+	var count int64
+	err := r.DB.WithContext(ctx).Unscoped().
+		Model(&feedbackmodel.FeedbackAttachment{}).
+		Where("qr_code_id = ? AND created_at >= ?", qrCodeID, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *attachmentRepository) CountByDeviceSince(ctx context.Context, deviceHash string, since time.Time) (int64, error) {
+	var count int64
+	err := r.DB.WithContext(ctx).Unscoped().
+		Model(&feedbackmodel.FeedbackAttachment{}).
+		Where("device_hash = ? AND created_at >= ?", deviceHash, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *attachmentRepository) FindUnclaimedBefore(ctx context.Context, before time.Time, limit int) ([]feedbackmodel.FeedbackAttachment, error) {
+	var attachments []feedbackmodel.FeedbackAttachment
+	err := r.DB.WithContext(ctx).Unscoped().
+		Where("feedback_id IS NULL AND created_at < ?", before).
+		Order("created_at").
+		Limit(limit).
+		Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *attachmentRepository) DeleteUnclaimed(ctx context.Context, ids []uuid.UUID) ([]feedbackmodel.FeedbackAttachment, error) {
+	var deleted []feedbackmodel.FeedbackAttachment
+	if len(ids) == 0 {
+		return deleted, nil
+	}
+	err := r.DB.WithContext(ctx).Unscoped().
+		Clauses(clause.Returning{}).
+		Where("id IN ? AND feedback_id IS NULL", ids).
+		Delete(&deleted).Error
+	return deleted, err
+}
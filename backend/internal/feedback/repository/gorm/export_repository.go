@@ -0,0 +1,87 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+	sharedRepos "kyooar/internal/shared/repositories"
+	"gorm.io/gorm"
+)
+
+type exportRepository struct {
+	DB *gorm.DB
+}
+
+func NewExportRepository(db *gorm.DB) *exportRepository {
+	return &exportRepository{DB: db}
+}
+
+func (r *exportRepository) Create(ctx context.Context, export *feedbackmodel.FeedbackExport) error {
+	return r.DB.WithContext(ctx).Create(export).Error
+}
+
+func (r *exportRepository) Update(ctx context.Context, export *feedbackmodel.FeedbackExport) error {
+	return r.DB.WithContext(ctx).Save(export).Error
+}
+
+func (r *exportRepository) FindByID(ctx context.Context, id uuid.UUID) (*feedbackmodel.FeedbackExport, error) {
+	var export feedbackmodel.FeedbackExport
+	if err := r.DB.WithContext(ctx).Where("id = ?", id).First(&export).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, sharedRepos.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *exportRepository) FindByOrganizationID(ctx context.Context, organizationID uuid.UUID, limit int) ([]feedbackmodel.FeedbackExport, error) {
+	var exports []feedbackmodel.FeedbackExport
+	err := r.DB.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&exports).Error
+	return exports, err
+}
+
+func (r *exportRepository) FindExpired(ctx context.Context, before time.Time) ([]feedbackmodel.FeedbackExport, error) {
+	var exports []feedbackmodel.FeedbackExport
+	err := r.DB.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Find(&exports).Error
+	return exports, err
+}
+
+func (r *exportRepository) FailUnfinished(ctx context.Context, before time.Time, reason string) (int64, error) {
+	result := r.DB.WithContext(ctx).Model(&feedbackmodel.FeedbackExport{}).
+		Where("status IN ? AND created_at < ?", []feedbackmodel.ExportStatus{feedbackmodel.ExportStatusPending, feedbackmodel.ExportStatusRunning}, before).
+		Updates(map[string]interface{}{
+			"status": feedbackmodel.ExportStatusFailed,
+			"error":  reason,
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *exportRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.DB.WithContext(ctx).Unscoped().Delete(&feedbackmodel.FeedbackExport{}, "id = ?", id).Error
+}
+
+func (r *exportRepository) FindQuestions(ctx context.Context, organizationID uuid.UUID, productID *uuid.UUID) ([]*feedbackmodel.Question, error) {
+	query := r.DB.WithContext(ctx).Unscoped().
+		Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Joins("JOIN products ON products.id = questions.product_id").
+		Where("products.organization_id = ?", organizationID)
+	if productID != nil {
+		query = query.Where("questions.product_id = ?", *productID)
+	}
+
+	var questions []*feedbackmodel.Question
+	err := query.
+		Order("products.name, questions.product_id, questions.deleted_at NULLS FIRST, questions.display_order, questions.created_at, questions.id").
+		Find(&questions).Error
+	return questions, err
+}
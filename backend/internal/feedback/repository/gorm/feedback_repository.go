@@ -3,29 +3,171 @@ package gorm
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	feedbackmodel "kyooar/internal/feedback/model"
 	sharedModels "kyooar/internal/shared/models"
 	sharedRepos "kyooar/internal/shared/repositories"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// searchHeadlineOptions keep snippets short enough for a results list.
+const searchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=8, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// searchSnippetTextSQL is the searched text with HTML special characters
+// escaped, so the only markup in a snippet is the <mark> tags ts_headline
+// adds and clients can render it as HTML. The parser keeps the entities
+// intact in the headline.
+const searchSnippetTextSQL = `replace(replace(replace(replace(replace(` +
+	`feedback_search_text(customer_name, customer_email, customer_phone, responses), ` +
+	`'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+
 type feedbackRepository struct {
 	*sharedRepos.BaseRepository[feedbackmodel.Feedback]
+	searchLanguage string
 }
 
-func NewFeedbackRepository(db *gorm.DB) *feedbackRepository {
+func NewFeedbackRepository(db *gorm.DB, searchLanguage string) *feedbackRepository {
 	return &feedbackRepository{
 		BaseRepository: sharedRepos.NewBaseRepository[feedbackmodel.Feedback](db),
+		searchLanguage: searchLanguage,
+	}
+}
+
+// withSearchLanguage indexes new rows with the configured language. Rows keep
+// the language they were indexed with, so changing it only affects new feedback.
+func (r *feedbackRepository) withSearchLanguage(feedback *feedbackmodel.Feedback) {
+	if feedback.SearchLanguage == "" {
+		feedback.SearchLanguage = r.searchLanguage
 	}
 }
 
+// Create inserts the feedback and claims the photos its answers reference.
 func (r *feedbackRepository) Create(ctx context.Context, feedback *feedbackmodel.Feedback) error {
-	return r.BaseRepository.Create(ctx, feedback)
+	r.withSearchLanguage(feedback)
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(feedback).Error; err != nil {
+			return err
+		}
+		return claimAttachments(tx, feedback)
+	})
+}
+
+// CreateSession inserts an incomplete feedback. is_complete has a database
+// default of true, so it is reset explicitly after the insert.
+func (r *feedbackRepository) CreateSession(ctx context.Context, feedback *feedbackmodel.Feedback) error {
+	r.withSearchLanguage(feedback)
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(feedback).Error; err != nil {
+			return err
+		}
+		feedback.IsComplete = false
+		return tx.Model(feedback).Update("is_complete", false).Error
+	})
+}
+
+// CreateWithIdempotencyKey inserts the feedback unless another submission
+// already claimed its idempotency key, in which case created is false.
+// Photos are claimed as in Create.
+func (r *feedbackRepository) CreateWithIdempotencyKey(ctx context.Context, feedback *feedbackmodel.Feedback) (bool, error) {
+	r.withSearchLanguage(feedback)
+	created := false
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(feedback)
+		if result.Error != nil {
+			return result.Error
+		}
+		if created = result.RowsAffected > 0; !created {
+			return nil
+		}
+		return claimAttachments(tx, feedback)
+	})
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+// CompleteSession saves a finished session and claims its photos as in
+// Create.
+func (r *feedbackRepository) CompleteSession(ctx context.Context, feedback *feedbackmodel.Feedback) error {
+	r.withSearchLanguage(feedback)
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(feedback).Error; err != nil {
+			return err
+		}
+		return claimAttachments(tx, feedback)
+	})
+}
+
+// claimAttachments links the photos the feedback's answers reference to it,
+// failing with feedbackmodel.ErrAttachmentClaimed when another feedback
+// claimed one since the answers were validated, so that the transaction
+// saving the feedback is rolled back.
+func claimAttachments(tx *gorm.DB, feedback *feedbackmodel.Feedback) error {
+	seen := make(map[uuid.UUID]bool)
+	var ids []uuid.UUID
+	for _, id := range feedback.Responses.PhotoAttachmentIDs() {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	result := tx.Model(&feedbackmodel.FeedbackAttachment{}).
+		Where("id IN ? AND (feedback_id IS NULL OR feedback_id = ?)", ids, feedback.ID).
+		Update("feedback_id", feedback.ID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected != int64(len(ids)) {
+		return feedbackmodel.ErrAttachmentClaimed
+	}
+	return nil
+}
+
+func (r *feedbackRepository) FindByIdempotencyKey(ctx context.Context, qrCodeID uuid.UUID, key string) (*feedbackmodel.Feedback, error) {
+	var feedback feedbackmodel.Feedback
+	if err := r.DB.WithContext(ctx).Where("qr_code_id = ? AND idempotency_key = ?", qrCodeID, key).First(&feedback).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, sharedRepos.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &feedback, nil
+}
+
+// FindStoredIdempotencyKeys returns the keys among keys that feedback
+// through the QR code was already stored with.
+func (r *feedbackRepository) FindStoredIdempotencyKeys(ctx context.Context, qrCodeID uuid.UUID, keys []string) ([]string, error) {
+	var stored []string
+	if len(keys) == 0 {
+		return stored, nil
+	}
+	err := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).
+		Where("qr_code_id = ? AND idempotency_key IN ?", qrCodeID, keys).
+		Pluck("idempotency_key", &stored).Error
+	return stored, err
+}
+
+func (r *feedbackRepository) FindBySessionTokenHash(ctx context.Context, tokenHash string) (*feedbackmodel.Feedback, error) {
+	var feedback feedbackmodel.Feedback
+	if err := r.DB.WithContext(ctx).Where("session_token_hash = ?", tokenHash).First(&feedback).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, sharedRepos.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &feedback, nil
 }
 
 func (r *feedbackRepository) FindByID(ctx context.Context, id uuid.UUID) (*feedbackmodel.Feedback, error) {
@@ -33,6 +175,7 @@ func (r *feedbackRepository) FindByID(ctx context.Context, id uuid.UUID) (*feedb
 }
 
 func (r *feedbackRepository) Update(ctx context.Context, feedback *feedbackmodel.Feedback) error {
+	r.withSearchLanguage(feedback)
 	return r.BaseRepository.Update(ctx, feedback)
 }
 
@@ -40,120 +183,295 @@ func (r *feedbackRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.BaseRepository.Delete(ctx, id)
 }
 
-func (r *feedbackRepository) FindByOrganizationID(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, page, limit int) (*sharedModels.PageResponse[feedbackmodel.Feedback], error) {
-	var feedbacks []feedbackmodel.Feedback
-	var total int64
+func (r *feedbackRepository) FindByOrganizationID(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, req sharedModels.PageRequest) (*sharedModels.PageResponse[feedbackmodel.Feedback], error) {
+	return r.FindByOrganizationIDWithFilters(ctx, accountID, organizationID, req, feedbackmodel.FeedbackFilter{})
+}
 
-	if page < 1 {
-		page = 1
+// FindByOrganizationIDWithFilters lists matching feedback in the order named
+// by req.Sort, a FeedbackSort. Pages after the first are best fetched with
+// the returned cursor, which seeks straight to the next row instead of
+// skipping the rows before it like req.Page does.
+func (r *feedbackRepository) FindByOrganizationIDWithFilters(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, req sharedModels.PageRequest, filters feedbackmodel.FeedbackFilter) (*sharedModels.PageResponse[feedbackmodel.Feedback], error) {
+	baseQuery := r.applyFilters(r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).Where("organization_id = ?", organizationID), filters)
+
+	query := baseQuery.Session(&gorm.Session{})
+	search := strings.TrimSpace(filters.Search)
+	if search != "" {
+		query = query.
+			Select("feedbacks.*, "+searchRankSQL+" AS search_rank, "+
+				"ts_headline(search_language, "+searchSnippetTextSQL+", websearch_to_tsquery(?::regconfig, ?), ?) AS search_snippet",
+				r.searchLanguage, search, r.searchLanguage, search, searchHeadlineOptions)
 	}
-	if limit < 1 {
-		limit = 20
+
+	keyset := r.feedbackKeyset(feedbackmodel.FeedbackSort(req.Sort), search)
+	return r.findPage(ctx, baseQuery, query, req, keyset)
+}
+
+// feedbackKeyset is the sort keys of a feedback list order and the name its
+// cursors are issued under.
+type feedbackKeyset struct {
+	name    string
+	columns []sharedRepos.KeysetColumn
+}
+
+// searchRankSQL scores how well feedback matches a search. ts_rank_cd returns
+// a float4, which is widened so the rank read into SearchRank and sent back in
+// a cursor compares equal to the expression it came from.
+const searchRankSQL = "ts_rank_cd(search_vector, websearch_to_tsquery(?::regconfig, ?))::float8"
+
+// productNameSQL sorts feedback by product name without a join, which would
+// make the columns used by applyFilters ambiguous. Deleted products sort as
+// an empty name, the same as they are preloaded.
+const productNameSQL = "COALESCE((SELECT products.name FROM products WHERE products.id = feedbacks.product_id AND products.deleted_at IS NULL), '')"
+
+func (r *feedbackRepository) feedbackKeyset(sort feedbackmodel.FeedbackSort, search string) feedbackKeyset {
+	newest := []sharedRepos.KeysetColumn{{Expr: "created_at", Desc: true}, {Expr: "id", Desc: true}}
+
+	switch sort {
+	case feedbackmodel.FeedbackSortOldest:
+		return feedbackKeyset{name: string(sort), columns: []sharedRepos.KeysetColumn{{Expr: "created_at"}, {Expr: "id"}}}
+	case feedbackmodel.FeedbackSortHighestRating:
+		return feedbackKeyset{name: string(sort), columns: append([]sharedRepos.KeysetColumn{{Expr: "overall_rating", Desc: true}}, newest...)}
+	case feedbackmodel.FeedbackSortLowestRating:
+		return feedbackKeyset{name: string(sort), columns: append([]sharedRepos.KeysetColumn{{Expr: "overall_rating"}}, newest...)}
+	case feedbackmodel.FeedbackSortProduct:
+		return feedbackKeyset{name: string(sort), columns: append([]sharedRepos.KeysetColumn{{Expr: productNameSQL}}, newest...)}
+	case "":
+		// Searches without an explicit sort list the best matches first.
+		if search != "" {
+			rank := sharedRepos.KeysetColumn{
+				Expr: searchRankSQL,
+				Args: []interface{}{r.searchLanguage, search},
+				Desc: true,
+			}
+			return feedbackKeyset{name: "relevance", columns: append([]sharedRepos.KeysetColumn{rank}, newest...)}
+		}
 	}
+	return feedbackKeyset{name: string(feedbackmodel.FeedbackSortNewest), columns: newest}
+}
 
-	r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).Where("organization_id = ?", organizationID).Count(&total)
+// values returns the sort key values of a feedback row.
+func (k feedbackKeyset) values(feedback *feedbackmodel.Feedback) []interface{} {
+	switch k.name {
+	case string(feedbackmodel.FeedbackSortHighestRating), string(feedbackmodel.FeedbackSortLowestRating):
+		return []interface{}{feedback.OverallRating, feedback.CreatedAt, feedback.ID}
+	case string(feedbackmodel.FeedbackSortProduct):
+		return []interface{}{feedback.Product.Name, feedback.CreatedAt, feedback.ID}
+	case "relevance":
+		return []interface{}{feedback.SearchRank, feedback.CreatedAt, feedback.ID}
+	}
+	return []interface{}{feedback.CreatedAt, feedback.ID}
+}
 
-	query := r.DB.WithContext(ctx).Preload("Product").Preload("QRCode").
-		Where("organization_id = ?", organizationID).
-		Limit(limit).
-		Offset((page - 1) * limit).
-		Order("created_at DESC")
+// decode reads the sort key values from a cursor issued for this order.
+func (k feedbackKeyset) decode(cursor string) ([]interface{}, error) {
+	var createdAt time.Time
+	var id uuid.UUID
 
-	if err := query.Find(&feedbacks).Error; err != nil {
+	switch k.name {
+	case string(feedbackmodel.FeedbackSortHighestRating), string(feedbackmodel.FeedbackSortLowestRating):
+		var rating int
+		if err := sharedModels.DecodeCursor(cursor, k.name, &rating, &createdAt, &id); err != nil {
+			return nil, err
+		}
+		return []interface{}{rating, createdAt, id}, nil
+	case string(feedbackmodel.FeedbackSortProduct):
+		var productName string
+		if err := sharedModels.DecodeCursor(cursor, k.name, &productName, &createdAt, &id); err != nil {
+			return nil, err
+		}
+		return []interface{}{productName, createdAt, id}, nil
+	case "relevance":
+		var rank float64
+		if err := sharedModels.DecodeCursor(cursor, k.name, &rank, &createdAt, &id); err != nil {
+			return nil, err
+		}
+		return []interface{}{rank, createdAt, id}, nil
+	}
+	if err := sharedModels.DecodeCursor(cursor, k.name, &createdAt, &id); err != nil {
 		return nil, err
 	}
+	return []interface{}{createdAt, id}, nil
+}
 
-	if err := r.populateQuestionDataBatch(ctx, feedbacks); err != nil {
-		fmt.Printf("Error populating question data in batch: %v\n", err)
+// findPage fetches one page of query in keyset order, counting the rows of
+// countQuery when the total is requested. One extra row is fetched to tell
+// whether another page follows.
+func (r *feedbackRepository) findPage(ctx context.Context, countQuery, query *gorm.DB, req sharedModels.PageRequest, keyset feedbackKeyset) (*sharedModels.PageResponse[feedbackmodel.Feedback], error) {
+	req.Normalize()
+	response := &sharedModels.PageResponse[feedbackmodel.Feedback]{
+		Data:  []feedbackmodel.Feedback{},
+		Page:  req.Page,
+		Limit: req.Limit,
+	}
+
+	if req.IncludeTotal {
+		var total int64
+		if err := countQuery.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		response.SetTotal(total)
 	}
 
-	totalPages := int(total) / limit
-	if int(total)%limit > 0 {
-		totalPages++
+	if req.Cursor != "" {
+		values, err := keyset.decode(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = sharedRepos.AfterKeyset(query, keyset.columns, values)
+	} else if req.Page > 1 {
+		query = query.Offset(req.Offset())
 	}
 
-	return &sharedModels.PageResponse[feedbackmodel.Feedback]{
-		Data:       feedbacks,
-		Page:       page,
-		Limit:      limit,
-		Total:      int(total),
-		TotalPages: totalPages,
-	}, nil
-}
-
-func (r *feedbackRepository) FindByOrganizationIDWithFilters(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, page, limit int, filters feedbackmodel.FeedbackFilter) (*sharedModels.PageResponse[feedbackmodel.Feedback], error) {
 	var feedbacks []feedbackmodel.Feedback
-	var total int64
+	query = sharedRepos.OrderByKeyset(query.Preload("Product").Preload("QRCode").Preload("Attachments"), keyset.columns)
+	if err := query.Limit(req.Limit + 1).Find(&feedbacks).Error; err != nil {
+		return nil, err
+	}
 
-	if page < 1 {
-		page = 1
+	if len(feedbacks) > req.Limit {
+		feedbacks = feedbacks[:req.Limit]
+		cursor, err := sharedModels.EncodeCursor(keyset.name, keyset.values(&feedbacks[len(feedbacks)-1])...)
+		if err != nil {
+			return nil, err
+		}
+		response.NextCursor = cursor
+		response.HasMore = true
 	}
-	if limit < 1 {
-		limit = 20
+
+	if err := r.populateQuestionDataBatch(ctx, feedbacks); err != nil {
+		fmt.Printf("Error populating question data in batch: %v\n", err)
 	}
 
-	baseQuery := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).Where("organization_id = ?", organizationID)
+	if feedbacks != nil {
+		response.Data = feedbacks
+	}
+	return response, nil
+}
 
-	if filters.Search != "" {
-		searchTerm := "%" + strings.ToLower(filters.Search) + "%"
-		baseQuery = baseQuery.Where("(LOWER(customer_name) LIKE ? OR LOWER(customer_email) LIKE ? OR EXISTS (SELECT 1 FROM json_array_elements(responses) AS response WHERE LOWER(response->>'answer') LIKE ?))",
-			searchTerm, searchTerm, searchTerm)
+// applyFilters narrows a feedback query to the rows matching the filter.
+func (r *feedbackRepository) applyFilters(query *gorm.DB, filters feedbackmodel.FeedbackFilter) *gorm.DB {
+	if search := strings.TrimSpace(filters.Search); search != "" {
+		query = query.Where("search_vector @@ websearch_to_tsquery(?::regconfig, ?)", r.searchLanguage, search)
 	}
 
 	if filters.RatingMin != nil {
-		baseQuery = baseQuery.Where("overall_rating >= ?", *filters.RatingMin)
+		query = query.Where("overall_rating >= ?", *filters.RatingMin)
 	}
 
 	if filters.RatingMax != nil {
-		baseQuery = baseQuery.Where("overall_rating <= ?", *filters.RatingMax)
+		query = query.Where("overall_rating <= ?", *filters.RatingMax)
 	}
 
 	if filters.DateFrom != nil {
-		baseQuery = baseQuery.Where("created_at >= ?", *filters.DateFrom)
+		query = query.Where("created_at >= ?", *filters.DateFrom)
 	}
 
 	if filters.DateTo != nil {
 		endDate := filters.DateTo.Add(24 * time.Hour).Add(-time.Second)
-		baseQuery = baseQuery.Where("created_at <= ?", endDate)
+		query = query.Where("created_at <= ?", endDate)
 	}
 
 	if filters.ProductID != nil {
-		baseQuery = baseQuery.Where("product_id = ?", *filters.ProductID)
+		query = query.Where("product_id = ?", *filters.ProductID)
 	}
 
 	if filters.IsComplete != nil {
-		baseQuery = baseQuery.Where("is_complete = ?", *filters.IsComplete)
+		query = query.Where("is_complete = ?", *filters.IsComplete)
 	}
 
-	baseQuery.Count(&total)
+	if filters.QuestionnaireVersionID != nil {
+		query = query.Where("questionnaire_version_id = ?", *filters.QuestionnaireVersionID)
+	}
 
-	query := baseQuery.
-		Preload("Product").
-		Preload("QRCode").
-		Limit(limit).
-		Offset((page - 1) * limit).
-		Order("created_at DESC")
+	if filters.IsQuarantined != nil {
+		query = query.Where("is_quarantined = ?", *filters.IsQuarantined)
+	}
 
-	if err := query.Find(&feedbacks).Error; err != nil {
-		return nil, err
+	if filters.KioskDeviceID != nil {
+		query = query.Where("kiosk_device_id = ?", *filters.KioskDeviceID)
 	}
 
-	if err := r.populateQuestionDataBatch(ctx, feedbacks); err != nil {
-		fmt.Printf("Error populating question data in batch: %v\n", err)
+	if filters.Status != nil {
+		query = query.Where("status = ?", *filters.Status)
+	}
+
+	if filters.AssigneeID != nil {
+		query = query.Where("assignee_id = ?", *filters.AssigneeID)
+	} else if filters.Unassigned {
+		query = query.Where("assignee_id IS NULL")
+	}
+
+	if len(filters.Tags) > 0 {
+		query = query.Where("tags @> ?", pq.StringArray(filters.Tags))
+	}
+
+	if filters.ImportID != nil {
+		query = query.Where("import_id = ?", *filters.ImportID)
+	} else if filters.IsImported != nil {
+		if *filters.IsImported {
+			query = query.Where("import_id IS NOT NULL")
+		} else {
+			query = query.Where("import_id IS NULL")
+		}
 	}
 
-	totalPages := int(total) / limit
-	if int(total)%limit > 0 {
-		totalPages++
+	if filters.AnalyticsOnly {
+		query = query.Where("NOT excluded_from_analytics")
 	}
 
-	return &sharedModels.PageResponse[feedbackmodel.Feedback]{
-		Data:       feedbacks,
-		Page:       page,
-		Limit:      limit,
-		Total:      int(total),
-		TotalPages: totalPages,
-	}, nil
+	return query
+}
+
+// CountByOrganizationIDWithFilters counts the feedback an export would contain.
+func (r *feedbackRepository) CountByOrganizationIDWithFilters(ctx context.Context, organizationID uuid.UUID, filters feedbackmodel.FeedbackFilter) (int64, error) {
+	var total int64
+	query := r.applyFilters(r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).Where("organization_id = ?", organizationID), filters)
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// StreamByOrganizationIDWithFilters calls fn for every matching feedback,
+// newest first, loading batchSize rows at a time so memory stays flat however
+// many rows match. Pages are keyed on (created_at, id) rather than offsets so
+// that feedback arriving during the export does not shift later pages.
+func (r *feedbackRepository) StreamByOrganizationIDWithFilters(ctx context.Context, organizationID uuid.UUID, filters feedbackmodel.FeedbackFilter, batchSize int, fn func(*feedbackmodel.Feedback) error) error {
+	if batchSize < 1 {
+		batchSize = 500
+	}
+
+	var lastCreatedAt time.Time
+	var lastID uuid.UUID
+	for {
+		query := r.applyFilters(r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).Where("organization_id = ?", organizationID), filters)
+		if lastID != uuid.Nil {
+			query = query.Where("(created_at, id) < (?, ?)", lastCreatedAt, lastID)
+		}
+
+		var batch []feedbackmodel.Feedback
+		if err := query.
+			Preload("Product").
+			Preload("QRCode").
+			Order("created_at DESC, id DESC").
+			Limit(batchSize).
+			Find(&batch).Error; err != nil {
+			return err
+		}
+
+		for i := range batch {
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(batch) < batchSize {
+			return nil
+		}
+		lastCreatedAt = batch[len(batch)-1].CreatedAt
+		lastID = batch[len(batch)-1].ID
+	}
 }
 
 func (r *feedbackRepository) GetStatsByOrganization(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID) (*feedbackmodel.FeedbackStats, error) {
@@ -161,7 +479,7 @@ func (r *feedbackRepository) GetStatsByOrganization(ctx context.Context, account
 
 	var totalFeedbacks int64
 	if err := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).
-		Where("organization_id = ?", organizationID).
+		Where("organization_id = ? AND is_complete = ? AND is_quarantined = ? AND NOT excluded_from_analytics", organizationID, true, false).
 		Count(&totalFeedbacks).Error; err != nil {
 		return nil, err
 	}
@@ -170,7 +488,7 @@ func (r *feedbackRepository) GetStatsByOrganization(ctx context.Context, account
 	var avgRating sql.NullFloat64
 	if err := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).
 		Select("AVG(overall_rating)").
-		Where("organization_id = ? AND overall_rating > 0", organizationID).
+		Where("organization_id = ? AND overall_rating > 0 AND is_complete = ? AND is_quarantined = ? AND NOT excluded_from_analytics", organizationID, true, false).
 		Scan(&avgRating).Error; err != nil {
 		return nil, err
 	}
@@ -181,7 +499,7 @@ func (r *feedbackRepository) GetStatsByOrganization(ctx context.Context, account
 	today := time.Now().Truncate(24 * time.Hour)
 	var todayFeedbacks int64
 	if err := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).
-		Where("organization_id = ? AND created_at >= ?", organizationID, today).
+		Where("organization_id = ? AND created_at >= ? AND is_complete = ? AND is_quarantined = ? AND NOT excluded_from_analytics", organizationID, today, true, false).
 		Count(&todayFeedbacks).Error; err != nil {
 		return nil, err
 	}
@@ -190,7 +508,7 @@ func (r *feedbackRepository) GetStatsByOrganization(ctx context.Context, account
 	startOfWeek := time.Now().AddDate(0, 0, -int(time.Now().Weekday())).Truncate(24 * time.Hour)
 	var thisWeekFeedbacks int64
 	if err := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).
-		Where("organization_id = ? AND created_at >= ?", organizationID, startOfWeek).
+		Where("organization_id = ? AND created_at >= ? AND is_complete = ? AND is_quarantined = ? AND NOT excluded_from_analytics", organizationID, startOfWeek, true, false).
 		Count(&thisWeekFeedbacks).Error; err != nil {
 		return nil, err
 	}
@@ -204,7 +522,7 @@ func (r *feedbackRepository) FindByOrganizationIDForAnalytics(ctx context.Contex
 	
 	query := r.DB.WithContext(ctx).
 		Preload("Product").
-		Where("organization_id = ?", organizationID).
+		Where("organization_id = ? AND is_complete = ? AND is_quarantined = ? AND NOT excluded_from_analytics", organizationID, true, false).
 		Order("created_at DESC")
 
 	if limit > 0 {
@@ -222,7 +540,7 @@ func (r *feedbackRepository) FindByQuestionInPeriod(ctx context.Context, questio
 	var feedbacks []feedbackmodel.Feedback
 
 	if err := r.DB.WithContext(ctx).
-		Where("created_at BETWEEN ? AND ?", startDate, endDate).
+		Where("created_at BETWEEN ? AND ? AND is_complete = ? AND is_quarantined = ? AND NOT excluded_from_analytics", startDate, endDate, true, false).
 		Where("EXISTS (SELECT 1 FROM json_array_elements(responses) AS response WHERE (response->>'question_id')::uuid = ?)", questionID).
 		Find(&feedbacks).Error; err != nil {
 		return nil, err
@@ -235,7 +553,7 @@ func (r *feedbackRepository) populateQuestionDataBatch(ctx context.Context, feed
 	questionIDs := make(map[uuid.UUID]bool)
 	for _, feedback := range feedbacks {
 		for _, response := range feedback.Responses {
-			if response.QuestionID != uuid.Nil {
+			if response.QuestionID != uuid.Nil && response.QuestionText == "" {
 				questionIDs[response.QuestionID] = true
 			}
 		}
@@ -260,8 +578,13 @@ func (r *feedbackRepository) populateQuestionDataBatch(ctx context.Context, feed
 		questionMap[q.ID] = q
 	}
 
+	// Responses keep the wording they were answered with; only fill in
+	// responses stored before question text was captured at submit time.
 	for i := range feedbacks {
 		for j := range feedbacks[i].Responses {
+			if feedbacks[i].Responses[j].QuestionText != "" {
+				continue
+			}
 			if q, exists := questionMap[feedbacks[i].Responses[j].QuestionID]; exists {
 				feedbacks[i].Responses[j].QuestionText = q.Text
 				feedbacks[i].Responses[j].QuestionType = q.Type
@@ -274,7 +597,7 @@ func (r *feedbackRepository) populateQuestionDataBatch(ctx context.Context, feed
 
 func (r *feedbackRepository) CountByOrganizationID(ctx context.Context, organizationID uuid.UUID, since time.Time) (int64, error) {
 	var count int64
-	query := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).Where("organization_id = ?", organizationID)
+	query := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).Where("organization_id = ? AND is_complete = ? AND is_quarantined = ? AND NOT excluded_from_analytics", organizationID, true, false)
 	if !since.IsZero() {
 		query = query.Where("created_at >= ?", since)
 	}
@@ -284,16 +607,52 @@ func (r *feedbackRepository) CountByOrganizationID(ctx context.Context, organiza
 
 func (r *feedbackRepository) CountByProductID(ctx context.Context, productID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).Where("product_id = ?", productID).Count(&count).Error
+	err := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).Where("product_id = ? AND is_complete = ? AND is_quarantined = ? AND NOT excluded_from_analytics", productID, true, false).Count(&count).Error
 	return count, err
 }
 
 func (r *feedbackRepository) CountByQRCodeID(ctx context.Context, qrCodeID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).Where("qr_code_id = ?", qrCodeID).Count(&count).Error
+	err := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).Where("qr_code_id = ? AND is_complete = ? AND is_quarantined = ? AND NOT excluded_from_analytics", qrCodeID, true, false).Count(&count).Error
 	return count, err
 }
 
+// CountSubmissionsByQRCodeSince counts every submission attempt, including
+// started sessions and quarantined feedback, so rate limits cannot be
+// sidestepped by abandoning sessions.
+func (r *feedbackRepository) CountSubmissionsByQRCodeSince(ctx context.Context, qrCodeID uuid.UUID, since time.Time) (int64, error) {
+	var count int64
+	err := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).
+		Where("qr_code_id = ? AND created_at >= ?", qrCodeID, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *feedbackRepository) CountSubmissionsByDeviceSince(ctx context.Context, deviceHash string, since time.Time) (int64, error) {
+	var count int64
+	err := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).
+		Where("device_hash = ? AND created_at >= ?", deviceHash, since).
+		Count(&count).Error
+	return count, err
+}
+
+// HasDuplicateBody reports whether a completed feedback with the same body
+// was left on the QR code since the given time. An empty deviceHash matches
+// any device.
+func (r *feedbackRepository) HasDuplicateBody(ctx context.Context, qrCodeID uuid.UUID, bodyHash, deviceHash string, since time.Time, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	query := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).
+		Where("qr_code_id = ? AND body_hash = ? AND is_complete = ? AND created_at >= ?", qrCodeID, bodyHash, true, since)
+	if deviceHash != "" {
+		query = query.Where("device_hash = ?", deviceHash)
+	}
+	if excludeID != uuid.Nil {
+		query = query.Where("id <> ?", excludeID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
 func (r *feedbackRepository) CountByQRCodeIDs(ctx context.Context, qrCodeIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
 	if len(qrCodeIDs) == 0 {
 		return make(map[uuid.UUID]int64), nil
@@ -308,7 +667,7 @@ func (r *feedbackRepository) CountByQRCodeIDs(ctx context.Context, qrCodeIDs []u
 	err := r.DB.WithContext(ctx).
 		Model(&feedbackmodel.Feedback{}).
 		Select("qr_code_id, COUNT(*) as count").
-		Where("qr_code_id IN ?", qrCodeIDs).
+		Where("qr_code_id IN ? AND is_complete = ? AND is_quarantined = ? AND NOT excluded_from_analytics", qrCodeIDs, true, false).
 		Group("qr_code_id").
 		Scan(&results).Error
 
@@ -334,7 +693,7 @@ func (r *feedbackRepository) GetAverageRating(ctx context.Context, organizationI
 	var avgRating sql.NullFloat64
 	query := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).
 		Select("AVG(overall_rating)").
-		Where("organization_id = ? AND overall_rating > 0", organizationID)
+		Where("organization_id = ? AND overall_rating > 0 AND is_complete = ? AND is_quarantined = ? AND NOT excluded_from_analytics", organizationID, true, false)
 
 	if productID != nil {
 		query = query.Where("product_id = ?", *productID)
@@ -352,51 +711,15 @@ func (r *feedbackRepository) GetAverageRating(ctx context.Context, organizationI
 }
 
 func (r *feedbackRepository) FindByProductID(ctx context.Context, productID uuid.UUID, req sharedModels.PageRequest) (*sharedModels.PageResponse[feedbackmodel.Feedback], error) {
-	var feedbacks []feedbackmodel.Feedback
-	var total int64
-
-	if req.Page < 1 {
-		req.Page = 1
-	}
-	if req.Limit < 1 {
-		req.Limit = 20
-	}
-
-	r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).Where("product_id = ?", productID).Count(&total)
-
-	query := r.DB.WithContext(ctx).Preload("Product").Preload("QRCode").
-		Where("product_id = ?", productID).
-		Limit(req.Limit).
-		Offset((req.Page - 1) * req.Limit).
-		Order("created_at DESC")
-
-	if err := query.Find(&feedbacks).Error; err != nil {
-		return nil, err
-	}
-
-	if err := r.populateQuestionDataBatch(ctx, feedbacks); err != nil {
-		fmt.Printf("Error populating question data in batch: %v\n", err)
-	}
-
-	totalPages := int(total) / req.Limit
-	if int(total)%req.Limit > 0 {
-		totalPages++
-	}
-
-	return &sharedModels.PageResponse[feedbackmodel.Feedback]{
-		Data:       feedbacks,
-		Page:       req.Page,
-		Limit:      req.Limit,
-		Total:      int(total),
-		TotalPages: totalPages,
-	}, nil
+	query := r.DB.WithContext(ctx).Model(&feedbackmodel.Feedback{}).Where("product_id = ?", productID)
+	return r.findPage(ctx, query, query.Session(&gorm.Session{}), req, r.feedbackKeyset(feedbackmodel.FeedbackSortNewest, ""))
 }
 
 func (r *feedbackRepository) FindByProductIDForAnalytics(ctx context.Context, productID uuid.UUID, limit int) ([]feedbackmodel.Feedback, error) {
 	var feedbacks []feedbackmodel.Feedback
 
 	query := r.DB.WithContext(ctx).
-		Where("product_id = ?", productID).
+		Where("product_id = ? AND is_complete = ? AND is_quarantined = ? AND NOT excluded_from_analytics", productID, true, false).
 		Order("created_at DESC")
 
 	if limit > 0 {
@@ -414,7 +737,7 @@ func (r *feedbackRepository) GetQuestionsByProductID(ctx context.Context, produc
 	var questions []feedbackmodel.Question
 	err := r.DB.WithContext(ctx).
 		Where("product_id = ?", productID).
-		Order("display_order ASC").
+		Order("display_order ASC, id ASC").
 		Find(&questions).Error
 	return questions, err
 }
\ No newline at end of file
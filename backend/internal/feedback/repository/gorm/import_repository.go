@@ -0,0 +1,77 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+	sharedRepos "kyooar/internal/shared/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// importBatchSize keeps each insert well under the Postgres parameter limit.
+const importBatchSize = 200
+
+type importRepository struct {
+	DB             *gorm.DB
+	searchLanguage string
+}
+
+func NewImportRepository(db *gorm.DB, searchLanguage string) *importRepository {
+	return &importRepository{DB: db, searchLanguage: searchLanguage}
+}
+
+func (r *importRepository) Create(ctx context.Context, feedbackImport *feedbackmodel.FeedbackImport, feedbacks []*feedbackmodel.Feedback) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(feedbackImport).Error; err != nil {
+			return err
+		}
+		if len(feedbacks) == 0 {
+			return nil
+		}
+		for _, feedback := range feedbacks {
+			feedback.ImportID = &feedbackImport.ID
+			if feedback.SearchLanguage == "" {
+				feedback.SearchLanguage = r.searchLanguage
+			}
+		}
+		return tx.Omit(clause.Associations).CreateInBatches(feedbacks, importBatchSize).Error
+	})
+}
+
+func (r *importRepository) FindByID(ctx context.Context, id uuid.UUID) (*feedbackmodel.FeedbackImport, error) {
+	var feedbackImport feedbackmodel.FeedbackImport
+	if err := r.DB.WithContext(ctx).Where("id = ?", id).First(&feedbackImport).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, sharedRepos.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &feedbackImport, nil
+}
+
+func (r *importRepository) FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]feedbackmodel.FeedbackImport, error) {
+	var imports []feedbackmodel.FeedbackImport
+	err := r.DB.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("created_at DESC").
+		Find(&imports).Error
+	return imports, err
+}
+
+func (r *importRepository) SetIncludeInAnalytics(ctx context.Context, id uuid.UUID, include bool) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&feedbackmodel.FeedbackImport{}).Where("id = ?", id).Update("include_in_analytics", include).Error; err != nil {
+			return err
+		}
+		return tx.Model(&feedbackmodel.Feedback{}).Where("import_id = ?", id).Update("excluded_from_analytics", !include).Error
+	})
+}
+
+// Delete removes the import for good; its feedback goes with it through the
+// foreign key.
+func (r *importRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.DB.WithContext(ctx).Unscoped().Delete(&feedbackmodel.FeedbackImport{}, "id = ?", id).Error
+}
@@ -0,0 +1,54 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+	sharedRepos "kyooar/internal/shared/repositories"
+	"gorm.io/gorm"
+)
+
+type messageRepository struct {
+	*sharedRepos.BaseRepository[feedbackmodel.FeedbackMessage]
+}
+
+func NewMessageRepository(db *gorm.DB) *messageRepository {
+	return &messageRepository{
+		BaseRepository: sharedRepos.NewBaseRepository[feedbackmodel.FeedbackMessage](db),
+	}
+}
+
+func (r *messageRepository) Create(ctx context.Context, message *feedbackmodel.FeedbackMessage) error {
+	return r.BaseRepository.Create(ctx, message)
+}
+
+func (r *messageRepository) Update(ctx context.Context, message *feedbackmodel.FeedbackMessage) error {
+	return r.BaseRepository.Update(ctx, message)
+}
+
+func (r *messageRepository) FindByID(ctx context.Context, id uuid.UUID) (*feedbackmodel.FeedbackMessage, error) {
+	return r.BaseRepository.FindByID(ctx, id)
+}
+
+// FindByFeedbackID returns the conversation oldest first.
+func (r *messageRepository) FindByFeedbackID(ctx context.Context, feedbackID uuid.UUID) ([]feedbackmodel.FeedbackMessage, error) {
+	var messages []feedbackmodel.FeedbackMessage
+	err := r.DB.WithContext(ctx).
+		Where("feedback_id = ?", feedbackID).
+		Order("created_at ASC").
+		Find(&messages).Error
+	return messages, err
+}
+
+func (r *messageRepository) FindByReplyTokenHash(ctx context.Context, tokenHash string) (*feedbackmodel.FeedbackMessage, error) {
+	var message feedbackmodel.FeedbackMessage
+	if err := r.DB.WithContext(ctx).Where("reply_token_hash = ?", tokenHash).First(&message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, sharedRepos.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &message, nil
+}
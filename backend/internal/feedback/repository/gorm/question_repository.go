@@ -44,7 +44,7 @@ func (r *questionRepository) FindByProductID(ctx context.Context, productID uuid
 	var questions []*feedbackmodel.Question
 	err := r.db.WithContext(ctx).
 		Where("product_id = ?", productID).
-		Order("display_order ASC").
+		Order("display_order ASC, id ASC").
 		Find(&questions).Error
 	return questions, err
 }
@@ -57,7 +57,7 @@ func (r *questionRepository) FindByProductIDs(ctx context.Context, productIDs []
 	var questions []*feedbackmodel.Question
 	err := r.db.WithContext(ctx).
 		Where("product_id IN ?", productIDs).
-		Order("product_id ASC, display_order ASC").
+		Order("product_id ASC, display_order ASC, id ASC").
 		Find(&questions).Error
 	return questions, err
 }
@@ -72,7 +72,7 @@ func (r *questionRepository) FindByProductIDsOptimized(ctx context.Context, prod
 		Model(&feedbackmodel.Question{}).
 		Select("id, product_id, text, type").
 		Where("product_id IN ?", productIDs).
-		Order("product_id ASC, display_order ASC").
+		Order("product_id ASC, display_order ASC, id ASC").
 		Find(&results).Error
 	return results, err
 }
@@ -114,7 +114,7 @@ func (r *questionRepository) GetQuestionsByProductID(ctx context.Context, produc
 	var questions []*feedbackmodel.Question
 	err := r.db.WithContext(ctx).
 		Where("product_id = ?", productID).
-		Order("display_order ASC").
+		Order("display_order ASC, id ASC").
 		Find(&questions).Error
 	return questions, err
 }
@@ -123,7 +123,7 @@ func (r *questionRepository) GetQuestionsByProductIDForAnalytics(ctx context.Con
 	var questions []*feedbackmodel.Question
 	err := r.db.WithContext(ctx).
 		Where("product_id = ?", productID).
-		Order("display_order ASC").
+		Order("display_order ASC, id ASC").
 		Find(&questions).Error
 	return questions, err
 }
@@ -56,7 +56,7 @@ func (r *questionnaireRepository) DeactivateDefaultQuestionnaires(ctx context.Co
 func (r *questionnaireRepository) FindByIDWithQuestions(ctx context.Context, id uuid.UUID) (*feedbackmodel.Questionnaire, error) {
 	var questionnaire feedbackmodel.Questionnaire
 	err := r.DB.WithContext(ctx).Preload("Questions", func(db *gorm.DB) *gorm.DB {
-		return db.Order("display_order ASC")
+		return db.Order("display_order ASC, id ASC")
 	}).First(&questionnaire, "id = ?", id).Error
 	if err != nil {
 		return nil, err
@@ -75,6 +75,30 @@ func (r *questionnaireRepository) FindByProductID(ctx context.Context, productID
 	return &questionnaire, nil
 }
 
+func (r *questionnaireRepository) FindActiveByProductID(ctx context.Context, productID uuid.UUID) (*feedbackmodel.Questionnaire, error) {
+	var questionnaire feedbackmodel.Questionnaire
+	err := r.DB.WithContext(ctx).
+		Where("product_id = ? AND is_active = ?", productID, true).
+		Order("updated_at DESC").
+		First(&questionnaire).Error
+	if err != nil {
+		return nil, err
+	}
+	return &questionnaire, nil
+}
+
+func (r *questionnaireRepository) FindDefaultByOrganizationID(ctx context.Context, organizationID uuid.UUID) (*feedbackmodel.Questionnaire, error) {
+	var questionnaire feedbackmodel.Questionnaire
+	err := r.DB.WithContext(ctx).
+		Where("organization_id = ? AND is_default = ? AND is_active = ?", organizationID, true, true).
+		Order("updated_at DESC").
+		First(&questionnaire).Error
+	if err != nil {
+		return nil, err
+	}
+	return &questionnaire, nil
+}
+
 func (r *questionnaireRepository) CreateQuestion(ctx context.Context, question *feedbackmodel.Question) error {
 	return r.DB.WithContext(ctx).Create(question).Error
 }
@@ -0,0 +1,56 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+	"gorm.io/gorm"
+)
+
+type triageRepository struct {
+	DB *gorm.DB
+}
+
+func NewTriageRepository(db *gorm.DB) *triageRepository {
+	return &triageRepository{DB: db}
+}
+
+func (r *triageRepository) UpdateFeedback(ctx context.Context, feedbackID uuid.UUID, updates map[string]interface{}, activity *feedbackmodel.FeedbackActivity) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&feedbackmodel.Feedback{}).Where("id = ?", feedbackID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Create(activity).Error
+	})
+}
+
+func (r *triageRepository) CreateNote(ctx context.Context, note *feedbackmodel.FeedbackNote, activity *feedbackmodel.FeedbackActivity) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(note).Error; err != nil {
+			return err
+		}
+		activity.NoteID = &note.ID
+		return tx.Create(activity).Error
+	})
+}
+
+func (r *triageRepository) FindNotesByFeedbackID(ctx context.Context, feedbackID uuid.UUID) ([]feedbackmodel.FeedbackNote, error) {
+	var notes []feedbackmodel.FeedbackNote
+	err := r.DB.WithContext(ctx).
+		Where("feedback_id = ?", feedbackID).
+		Order("created_at ASC").
+		Find(&notes).Error
+	return notes, err
+}
+
+// FindActivityByFeedbackID returns the most recent activity first.
+func (r *triageRepository) FindActivityByFeedbackID(ctx context.Context, feedbackID uuid.UUID, limit int) ([]feedbackmodel.FeedbackActivity, error) {
+	var activity []feedbackmodel.FeedbackActivity
+	err := r.DB.WithContext(ctx).
+		Where("feedback_id = ?", feedbackID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&activity).Error
+	return activity, err
+}
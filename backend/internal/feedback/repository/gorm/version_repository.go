@@ -0,0 +1,106 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+	sharedRepos "kyooar/internal/shared/repositories"
+	"gorm.io/gorm"
+)
+
+type versionRepository struct {
+	*sharedRepos.BaseRepository[feedbackmodel.QuestionnaireVersion]
+}
+
+func NewVersionRepository(db *gorm.DB) *versionRepository {
+	return &versionRepository{
+		BaseRepository: sharedRepos.NewBaseRepository[feedbackmodel.QuestionnaireVersion](db),
+	}
+}
+
+// CreateIfChanged stores the snapshot as the product's next version unless it
+// matches the latest one, in which case the latest version is returned. An
+// advisory lock keeps concurrent editors and submissions from racing for the
+// same version number. The usual case of an unchanged snapshot is answered
+// without taking the lock.
+func (r *versionRepository) CreateIfChanged(ctx context.Context, version *feedbackmodel.QuestionnaireVersion) (*feedbackmodel.QuestionnaireVersion, error) {
+	var current feedbackmodel.QuestionnaireVersion
+	err := r.DB.WithContext(ctx).
+		Where("product_id = ?", version.ProductID).
+		Order("version DESC").
+		First(&current).Error
+	if err == nil && current.Hash == version.Hash {
+		return &current, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	result := version
+	err = r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", version.ProductID.String()).Error; err != nil {
+			return err
+		}
+
+		var latest feedbackmodel.QuestionnaireVersion
+		err := tx.Where("product_id = ?", version.ProductID).
+			Order("version DESC").
+			First(&latest).Error
+		switch {
+		case err == nil:
+			if latest.Hash == version.Hash {
+				result = &latest
+				return nil
+			}
+			version.Version = latest.Version + 1
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			version.Version = 1
+		default:
+			return err
+		}
+
+		return tx.Create(version).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *versionRepository) FindByID(ctx context.Context, id uuid.UUID) (*feedbackmodel.QuestionnaireVersion, error) {
+	version, err := r.BaseRepository.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, sharedRepos.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return version, nil
+}
+
+// FindByProductID lists versions newest first without their snapshots.
+func (r *versionRepository) FindByProductID(ctx context.Context, productID uuid.UUID) ([]feedbackmodel.QuestionnaireVersion, error) {
+	var versions []feedbackmodel.QuestionnaireVersion
+	err := r.DB.WithContext(ctx).
+		Select("id, created_at, updated_at, organization_id, product_id, version, hash, question_count").
+		Where("product_id = ?", productID).
+		Order("version DESC").
+		Find(&versions).Error
+	return versions, err
+}
+
+func (r *versionRepository) FindByProductAndNumber(ctx context.Context, productID uuid.UUID, version int) (*feedbackmodel.QuestionnaireVersion, error) {
+	var result feedbackmodel.QuestionnaireVersion
+	err := r.DB.WithContext(ctx).
+		Where("product_id = ? AND version = ?", productID, version).
+		First(&result).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, sharedRepos.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,107 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+	sharedRepos "kyooar/internal/shared/repositories"
+	"gorm.io/gorm"
+)
+
+type viewRepository struct {
+	DB *gorm.DB
+}
+
+func NewViewRepository(db *gorm.DB) *viewRepository {
+	return &viewRepository{DB: db}
+}
+
+func (r *viewRepository) Create(ctx context.Context, view *feedbackmodel.FeedbackView) error {
+	return r.DB.WithContext(ctx).Create(view).Error
+}
+
+func (r *viewRepository) Update(ctx context.Context, view *feedbackmodel.FeedbackView) error {
+	return r.DB.WithContext(ctx).Save(view).Error
+}
+
+func (r *viewRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("view_id = ?", id).Delete(&feedbackmodel.FeedbackViewSubscription{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&feedbackmodel.FeedbackView{}, "id = ?", id).Error
+	})
+}
+
+func (r *viewRepository) FindByID(ctx context.Context, id uuid.UUID) (*feedbackmodel.FeedbackView, error) {
+	var view feedbackmodel.FeedbackView
+	if err := r.DB.WithContext(ctx).Where("id = ?", id).First(&view).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, sharedRepos.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &view, nil
+}
+
+func (r *viewRepository) FindVisible(ctx context.Context, organizationID, memberID uuid.UUID) ([]feedbackmodel.FeedbackView, error) {
+	var views []feedbackmodel.FeedbackView
+	err := r.DB.WithContext(ctx).
+		Where("organization_id = ? AND (shared OR created_by = ?)", organizationID, memberID).
+		Order("name ASC").
+		Find(&views).Error
+	return views, err
+}
+
+func (r *viewRepository) FindSubscription(ctx context.Context, viewID, memberID uuid.UUID) (*feedbackmodel.FeedbackViewSubscription, error) {
+	var subscription feedbackmodel.FeedbackViewSubscription
+	if err := r.DB.WithContext(ctx).Where("view_id = ? AND member_id = ?", viewID, memberID).First(&subscription).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, sharedRepos.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *viewRepository) FindSubscriptionsByMember(ctx context.Context, organizationID, memberID uuid.UUID) ([]feedbackmodel.FeedbackViewSubscription, error) {
+	var subscriptions []feedbackmodel.FeedbackViewSubscription
+	err := r.DB.WithContext(ctx).
+		Joins("JOIN feedback_views ON feedback_views.id = feedback_view_subscriptions.view_id AND feedback_views.deleted_at IS NULL").
+		Where("feedback_views.organization_id = ? AND feedback_view_subscriptions.member_id = ?", organizationID, memberID).
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *viewRepository) FindSubscriptions(ctx context.Context) ([]feedbackmodel.FeedbackViewSubscription, error) {
+	var subscriptions []feedbackmodel.FeedbackViewSubscription
+	err := r.DB.WithContext(ctx).
+		Preload("View").
+		Order("created_at ASC").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *viewRepository) SaveSubscription(ctx context.Context, subscription *feedbackmodel.FeedbackViewSubscription) error {
+	return r.DB.WithContext(ctx).Save(subscription).Error
+}
+
+func (r *viewRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	return r.DB.WithContext(ctx).Delete(&feedbackmodel.FeedbackViewSubscription{}, "id = ?", id).Error
+}
+
+func (r *viewRepository) DeleteOtherSubscriptions(ctx context.Context, viewID, creatorID uuid.UUID) error {
+	return r.DB.WithContext(ctx).
+		Where("view_id = ? AND member_id <> ?", viewID, creatorID).
+		Delete(&feedbackmodel.FeedbackViewSubscription{}).Error
+}
+
+func (r *viewRepository) MarkDigestSent(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.DB.WithContext(ctx).
+		Model(&feedbackmodel.FeedbackViewSubscription{}).
+		Where("id = ?", id).
+		Update("last_sent_at", at).Error
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	feedbackinterface "kyooar/internal/feedback/interface"
+	feedbackmodel "kyooar/internal/feedback/model"
+	"kyooar/internal/shared/config"
+	"kyooar/internal/shared/errors"
+	sharedServices "kyooar/internal/shared/services"
+)
+
+type abuseGuard struct {
+	feedbackRepo feedbackinterface.FeedbackRepository
+	proofOfWork  sharedServices.ProofOfWork
+	config       config.AbuseConfig
+}
+
+func NewAbuseGuard(
+	feedbackRepo feedbackinterface.FeedbackRepository,
+	proofOfWork sharedServices.ProofOfWork,
+	config config.AbuseConfig,
+) feedbackinterface.AbuseGuard {
+	return &abuseGuard{
+		feedbackRepo: feedbackRepo,
+		proofOfWork:  proofOfWork,
+		config:       config,
+	}
+}
+
+// CheckLimits rejects a submission when the QR code or the device has gone
+// over its hourly limit. A limit of zero disables that check.
+func (g *abuseGuard) CheckLimits(ctx context.Context, qrCodeID uuid.UUID, deviceHash string) error {
+	return g.CheckBatchLimits(ctx, map[uuid.UUID]int{qrCodeID: 1}, deviceHash)
+}
+
+func (g *abuseGuard) CheckBatchLimits(ctx context.Context, pending map[uuid.UUID]int, deviceHash string) error {
+	since := time.Now().Add(-time.Hour)
+
+	total := 0
+	for qrCodeID, n := range pending {
+		total += n
+		if g.config.QRSubmissionsPerHour <= 0 || n == 0 {
+			continue
+		}
+		count, err := g.feedbackRepo.CountSubmissionsByQRCodeSince(ctx, qrCodeID, since)
+		if err != nil {
+			return err
+		}
+		if count+int64(n) > int64(g.config.QRSubmissionsPerHour) {
+			return errors.New("RATE_LIMIT", "This QR code is receiving too much feedback, please try again later", http.StatusTooManyRequests)
+		}
+	}
+
+	if g.config.DeviceSubmissionsPerHour > 0 && deviceHash != "" && total > 0 {
+		count, err := g.feedbackRepo.CountSubmissionsByDeviceSince(ctx, deviceHash, since)
+		if err != nil {
+			return err
+		}
+		if count+int64(total) > int64(g.config.DeviceSubmissionsPerHour) {
+			return errors.New("RATE_LIMIT", "Too many submissions from this device, please try again later", http.StatusTooManyRequests)
+		}
+	}
+
+	return nil
+}
+
+// Screen quarantines feedback that trips the honeypot, fails the
+// proof-of-work challenge or repeats a recent body. The feedback is still
+// saved so that false positives can be approved later.
+func (g *abuseGuard) Screen(ctx context.Context, feedback *feedbackmodel.Feedback) error {
+	if feedback.DeviceHash == "" {
+		feedback.DeviceHash = feedbackmodel.DeviceHash(feedback.DeviceInfo)
+	}
+	feedback.BodyHash = feedback.ComputeBodyHash()
+
+	if feedback.Honeypot != "" {
+		feedback.Quarantine(feedbackmodel.QuarantineReasonHoneypot)
+	}
+
+	// Paired kiosks are authenticated by their device token
+	trusted := feedback.KioskDeviceID != nil
+
+	if g.proofOfWork.Enabled() && !trusted {
+		// Offline clients solve the challenge when the feedback is captured,
+		// and it may expire before the queue is sent. The capture time is
+		// the client's word, so it only counts within the offline grace.
+		now := time.Now()
+		solvedAt := now
+		if feedback.Queued && feedback.CapturedAt != nil {
+			solvedAt = *feedback.CapturedAt
+			if earliest := now.Add(-g.config.OfflineChallengeGrace); solvedAt.Before(earliest) {
+				solvedAt = earliest
+			}
+		}
+		if err := g.proofOfWork.VerifyAt(feedback.QRCodeID.String(), feedback.Challenge, feedback.ChallengeSolution, solvedAt); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"qr_code_id": feedback.QRCodeID,
+				"reason":     err.Error(),
+			}).Info("Feedback failed proof-of-work challenge")
+			feedback.Quarantine(feedbackmodel.QuarantineReasonChallenge)
+		}
+	}
+
+	// Rating-only bodies are only compared against the same device, as
+	// different customers often leave identical ratings. A kiosk is shared by
+	// many customers, so there only bodies with free text are compared.
+	hasFreeText := feedback.HasFreeText()
+	if g.config.DuplicateWindow > 0 && (hasFreeText || !trusted) {
+		deviceHash := feedback.DeviceHash
+		if hasFreeText {
+			deviceHash = ""
+		}
+		duplicate, err := g.feedbackRepo.HasDuplicateBody(ctx, feedback.QRCodeID, feedback.BodyHash, deviceHash, time.Now().Add(-g.config.DuplicateWindow), feedback.ID)
+		if err != nil {
+			return err
+		}
+		if duplicate {
+			feedback.Quarantine(feedbackmodel.QuarantineReasonDuplicate)
+		}
+	}
+
+	return nil
+}
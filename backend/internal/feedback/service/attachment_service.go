@@ -0,0 +1,345 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	feedbackinterface "kyooar/internal/feedback/interface"
+	feedbackmodel "kyooar/internal/feedback/model"
+	kioskinterface "kyooar/internal/kiosk/interface"
+	qrcodeinterface "kyooar/internal/qrcode/interface"
+	qrcodemodel "kyooar/internal/qrcode/model"
+	"kyooar/internal/shared/config"
+	"kyooar/internal/shared/errors"
+	"kyooar/internal/shared/logger"
+	sharedServices "kyooar/internal/shared/services"
+	"kyooar/internal/shared/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// unclaimedDeleteBatch bounds how many unclaimed uploads one cleanup pass
+// loads and deletes at a time.
+const unclaimedDeleteBatch = 500
+
+type attachmentService struct {
+	attachmentRepo feedbackinterface.AttachmentRepository
+	qrCodeRepo     qrcodeinterface.QRCodeRepository
+	qrCodeService  qrcodeinterface.QRCodeService
+	deviceService  kioskinterface.DeviceService
+	storage        sharedServices.Storage
+	config         config.StorageConfig
+}
+
+func NewAttachmentService(
+	attachmentRepo feedbackinterface.AttachmentRepository,
+	qrCodeRepo qrcodeinterface.QRCodeRepository,
+	qrCodeService qrcodeinterface.QRCodeService,
+	deviceService kioskinterface.DeviceService,
+	storage sharedServices.Storage,
+	cfg config.StorageConfig,
+) feedbackinterface.AttachmentService {
+	return &attachmentService{
+		attachmentRepo: attachmentRepo,
+		qrCodeRepo:     qrCodeRepo,
+		qrCodeService:  qrCodeService,
+		deviceService:  deviceService,
+		storage:        storage,
+		config:         cfg,
+	}
+}
+
+// checkQRCode applies the same rules as submitting feedback: paired kiosks of
+// the code's organization are trusted without the token of a rotating code,
+// everyone else needs it.
+func (s *attachmentService) checkQRCode(ctx context.Context, qrCode *qrcodemodel.QRCode, req *feedbackmodel.UploadAttachmentRequest) error {
+	if req.DeviceToken == "" {
+		return s.qrCodeService.CheckAccess(qrCode, req.QRToken)
+	}
+
+	device, err := s.deviceService.Authenticate(ctx, req.DeviceToken)
+	if err != nil {
+		return err
+	}
+	if device.OrganizationID != qrCode.OrganizationID {
+		return errors.Forbidden("upload photos for this QR code from this device")
+	}
+	if reason := qrCode.Rejection(time.Now()); reason != "" {
+		return qrcodemodel.RejectedError(reason)
+	}
+	return nil
+}
+
+// checkLimits rejects an upload when the QR code or the device has gone over
+// its hourly limit. A limit of zero disables that check.
+func (s *attachmentService) checkLimits(ctx context.Context, qrCodeID uuid.UUID, deviceHash string) error {
+	since := time.Now().Add(-time.Hour)
+
+	if s.config.QRUploadsPerHour > 0 {
+		count, err := s.attachmentRepo.CountByQRCodeSince(ctx, qrCodeID, since)
+		if err != nil {
+			return err
+		}
+		if count >= int64(s.config.QRUploadsPerHour) {
+			return errors.New("RATE_LIMIT", "This QR code is receiving too many photos, please try again later", http.StatusTooManyRequests)
+		}
+	}
+
+	if s.config.DeviceUploadsPerHour > 0 && deviceHash != "" {
+		count, err := s.attachmentRepo.CountByDeviceSince(ctx, deviceHash, since)
+		if err != nil {
+			return err
+		}
+		if count >= int64(s.config.DeviceUploadsPerHour) {
+			return errors.New("RATE_LIMIT", "Too many photos from this device, please try again later", http.StatusTooManyRequests)
+		}
+	}
+
+	return nil
+}
+
+func (s *attachmentService) CleanupUnclaimed(ctx context.Context) error {
+	if s.config.UnclaimedUploadTTL <= 0 {
+		return nil
+	}
+	cutoff := time.Now().Add(-s.config.UnclaimedUploadTTL)
+
+	var total int
+	for {
+		attachments, err := s.attachmentRepo.FindUnclaimedBefore(ctx, cutoff, unclaimedDeleteBatch)
+		if err != nil {
+			return err
+		}
+		if len(attachments) == 0 {
+			break
+		}
+
+		ids := make([]uuid.UUID, len(attachments))
+		for i, attachment := range attachments {
+			ids[i] = attachment.ID
+		}
+		// Rows go first so a photo claimed in the meantime keeps its files
+		deleted, err := s.attachmentRepo.DeleteUnclaimed(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, attachment := range deleted {
+			for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+				if err := s.storage.Delete(ctx, key); err != nil {
+					logger.Error("Failed to delete unclaimed photo", err, logrus.Fields{
+						"attachment_id": attachment.ID,
+						"key":           key,
+					})
+				}
+			}
+		}
+		total += len(deleted)
+
+		if len(attachments) < unclaimedDeleteBatch {
+			break
+		}
+	}
+
+	if total > 0 {
+		logger.Info("Deleted unclaimed feedback photos", logrus.Fields{
+			"deleted": total,
+			"before":  cutoff,
+		})
+	}
+	return nil
+}
+
+func (s *attachmentService) MaxUploadSize() int64 {
+	return s.config.MaxUploadSize
+}
+
+func (s *attachmentService) Upload(ctx context.Context, req *feedbackmodel.UploadAttachmentRequest, file io.Reader) (*feedbackmodel.FeedbackAttachment, error) {
+	qrCode, err := s.qrCodeRepo.FindByID(ctx, req.QRCodeID)
+	if err != nil {
+		return nil, errors.NotFound("QR code")
+	}
+	if err := s.checkQRCode(ctx, qrCode, req); err != nil {
+		return nil, err
+	}
+
+	// Kiosks are shared by many customers and only held to the QR code limit
+	deviceHash := ""
+	if req.DeviceToken == "" {
+		deviceHash = feedbackmodel.DeviceHash(req.DeviceInfo)
+	}
+	if err := s.checkLimits(ctx, qrCode.ID, deviceHash); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, s.config.MaxUploadSize+1))
+	if err != nil {
+		return nil, errors.BadRequest("Failed to read uploaded file")
+	}
+	if int64(len(data)) > s.config.MaxUploadSize {
+		return nil, errors.New("FILE_TOO_LARGE", fmt.Sprintf("Photos must be smaller than %d MB", s.config.MaxUploadSize/(1024*1024)), http.StatusRequestEntityTooLarge)
+	}
+
+	contentType := http.DetectContentType(data)
+	if !feedbackmodel.AllowedAttachmentTypes[contentType] {
+		return nil, errors.New("UNSUPPORTED_MEDIA_TYPE", "Only JPEG, PNG and GIF photos are supported", http.StatusUnsupportedMediaType)
+	}
+
+	imgConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.BadRequest("Uploaded file is not a valid image")
+	}
+	if imgConfig.Width*imgConfig.Height > feedbackmodel.MaxAttachmentPixels {
+		return nil, errors.BadRequest("Photo dimensions are too large")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.BadRequest("Uploaded file is not a valid image")
+	}
+
+	// Re-encoding drops EXIF metadata such as GPS coordinates
+	full, storedType, ext, err := encodeImage(img, contentType)
+	if err != nil {
+		return nil, err
+	}
+	thumbnail, _, _, err := encodeImage(utils.Thumbnail(img, feedbackmodel.ThumbnailMaxSize), contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &feedbackmodel.FeedbackAttachment{
+		OrganizationID: qrCode.OrganizationID,
+		QRCodeID:       qrCode.ID,
+		QuestionID:     req.QuestionID,
+		ContentType:    storedType,
+		Size:           int64(len(full)),
+		Width:          img.Bounds().Dx(),
+		Height:         img.Bounds().Dy(),
+		DeviceHash:     deviceHash,
+	}
+	attachment.ID = uuid.New()
+	attachment.StorageKey = path.Join("feedback", qrCode.OrganizationID.String(), attachment.ID.String()+ext)
+	attachment.ThumbnailKey = path.Join("feedback", qrCode.OrganizationID.String(), attachment.ID.String()+"_thumb"+ext)
+
+	if err := s.storage.Put(ctx, attachment.StorageKey, bytes.NewReader(full), storedType); err != nil {
+		return nil, err
+	}
+	if err := s.storage.Put(ctx, attachment.ThumbnailKey, bytes.NewReader(thumbnail), storedType); err != nil {
+		s.storage.Delete(ctx, attachment.StorageKey)
+		return nil, err
+	}
+
+	if err := s.attachmentRepo.Create(ctx, attachment); err != nil {
+		s.storage.Delete(ctx, attachment.StorageKey)
+		s.storage.Delete(ctx, attachment.ThumbnailKey)
+		return nil, err
+	}
+
+	signed := []feedbackmodel.FeedbackAttachment{*attachment}
+	s.SignURLs(ctx, signed)
+	return &signed[0], nil
+}
+
+// ValidateForFeedback checks that every photo answer points to an unused
+// upload made through the same QR code.
+func (s *attachmentService) ValidateForFeedback(ctx context.Context, feedback *feedbackmodel.Feedback) error {
+	ids := feedback.Responses.PhotoAttachmentIDs()
+	if len(ids) == 0 {
+		return nil
+	}
+
+	attachments, err := s.attachmentRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[uuid.UUID]feedbackmodel.FeedbackAttachment, len(attachments))
+	for _, attachment := range attachments {
+		found[attachment.ID] = attachment
+	}
+
+	var answerErrors []feedbackmodel.AnswerError
+	for _, response := range feedback.Responses {
+		if response.QuestionType != feedbackmodel.QuestionTypePhoto {
+			continue
+		}
+		id, _ := uuid.Parse(fmt.Sprint(response.Answer))
+		attachment, ok := found[id]
+		if !ok || attachment.QRCodeID != feedback.QRCodeID || (attachment.FeedbackID != nil && *attachment.FeedbackID != feedback.ID) {
+			answerErrors = append(answerErrors, feedbackmodel.AnswerError{
+				QuestionID: response.QuestionID,
+				Code:       feedbackmodel.AnswerErrorInvalidOption,
+				Message:    "Photo was not found",
+			})
+		}
+	}
+
+	if len(answerErrors) > 0 {
+		return errors.NewWithDetails("VALIDATION_ERROR", "Some answers are missing or invalid", http.StatusBadRequest, answerErrors)
+	}
+	return nil
+}
+
+func (s *attachmentService) SignURLs(ctx context.Context, attachments []feedbackmodel.FeedbackAttachment) {
+	for i := range attachments {
+		url, err := s.storage.SignedURL(ctx, attachments[i].StorageKey, s.config.URLExpiration)
+		if err != nil {
+			logger.Error("Failed to sign attachment URL", err, logrus.Fields{
+				"attachment_id": attachments[i].ID,
+			})
+			continue
+		}
+		attachments[i].URL = url
+
+		thumbnailURL, err := s.storage.SignedURL(ctx, attachments[i].ThumbnailKey, s.config.URLExpiration)
+		if err == nil {
+			attachments[i].ThumbnailURL = thumbnailURL
+		}
+	}
+}
+
+func (s *attachmentService) OpenSigned(ctx context.Context, key string, expires int64, signature string) (io.ReadCloser, string, error) {
+	if !s.storage.VerifySignature(key, expires, signature) {
+		return nil, "", errors.Forbidden("access this file")
+	}
+
+	file, err := s.storage.Open(ctx, key)
+	if err != nil {
+		return nil, "", errors.NotFound("File")
+	}
+
+	contentType := "application/octet-stream"
+	switch path.Ext(key) {
+	case ".jpg":
+		contentType = "image/jpeg"
+	case ".png":
+		contentType = "image/png"
+	case ".csv", ".xlsx", ".ndjson":
+		contentType = feedbackmodel.ExportFormat(strings.TrimPrefix(path.Ext(key), ".")).ContentType()
+	}
+	return file, contentType, nil
+}
+
+func encodeImage(img image.Image, sourceType string) ([]byte, string, string, error) {
+	var buf bytes.Buffer
+	if sourceType == "image/jpeg" {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", "", err
+		}
+		return buf.Bytes(), "image/jpeg", ".jpg", nil
+	}
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", "", err
+	}
+	return buf.Bytes(), "image/png", ".png", nil
+}
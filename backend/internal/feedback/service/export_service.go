@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	feedbackinterface "kyooar/internal/feedback/interface"
+	feedbackmodel "kyooar/internal/feedback/model"
+	organizationinterface "kyooar/internal/organization/interface"
+	"kyooar/internal/shared/config"
+	"kyooar/internal/shared/errors"
+	"kyooar/internal/shared/logger"
+	sharedServices "kyooar/internal/shared/services"
+	subscriptionconstants "kyooar/internal/subscription/constants"
+	subscriptioninterface "kyooar/internal/subscription/interface"
+)
+
+// maxListedExports bounds how many past exports are listed for an organization.
+const maxListedExports = 50
+
+// interruptedExportError is shown on exports a server restart cut short.
+const interruptedExportError = "The export was interrupted by a server restart. Please start it again."
+
+type exportJob struct {
+	export *feedbackmodel.FeedbackExport
+	plan   *feedbackinterface.ExportPlan
+}
+
+type exportService struct {
+	exportRepo       feedbackinterface.ExportRepository
+	feedbackRepo     feedbackinterface.FeedbackRepository
+	organizationRepo organizationinterface.OrganizationRepository
+	subscriptionRepo subscriptioninterface.SubscriptionRepository
+	storage          sharedServices.Storage
+	config           config.ExportConfig
+	urlTTL           time.Duration
+
+	// Background exports are queued for a fixed number of workers. ctx is
+	// cancelled when shutdown runs out of time, aborting running exports.
+	jobs    chan exportJob
+	workers sync.WaitGroup
+	mu      sync.Mutex
+	stopped bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+func NewExportService(
+	exportRepo feedbackinterface.ExportRepository,
+	feedbackRepo feedbackinterface.FeedbackRepository,
+	organizationRepo organizationinterface.OrganizationRepository,
+	subscriptionRepo subscriptioninterface.SubscriptionRepository,
+	storage sharedServices.Storage,
+	exportConfig config.ExportConfig,
+	urlTTL time.Duration,
+) feedbackinterface.ExportService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &exportService{
+		exportRepo:       exportRepo,
+		feedbackRepo:     feedbackRepo,
+		organizationRepo: organizationRepo,
+		subscriptionRepo: subscriptionRepo,
+		storage:          storage,
+		config:           exportConfig,
+		urlTTL:           urlTTL,
+		jobs:             make(chan exportJob, exportConfig.QueueSize),
+		ctx:              ctx,
+		cancel:           cancel,
+	}
+}
+
+func (s *exportService) Start(ctx context.Context) error {
+	// Exports created before this point belong to an earlier run of the
+	// server, so nothing is working on them anymore.
+	failed, err := s.exportRepo.FailUnfinished(ctx, time.Now(), interruptedExportError)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		logger.Info("Failed feedback exports interrupted by a restart", logrus.Fields{
+			"exports": failed,
+		})
+	}
+
+	workers := s.config.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		s.workers.Add(1)
+		go s.work()
+	}
+	return nil
+}
+
+func (s *exportService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.jobs)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		// Running exports fail fast once cancelled, and queued ones are
+		// failed by the next Start.
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (s *exportService) work() {
+	defer s.workers.Done()
+	for job := range s.jobs {
+		s.run(job.export, job.plan)
+	}
+}
+
+// enqueue hands the job to a worker without waiting, reporting whether the
+// queue had room.
+func (s *exportService) enqueue(job exportJob) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return false
+	}
+	select {
+	case s.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *exportService) Prepare(ctx context.Context, accountID, organizationID uuid.UUID, format feedbackmodel.ExportFormat, filters feedbackmodel.FeedbackFilter) (*feedbackinterface.ExportPlan, error) {
+	if !format.IsValid() {
+		return nil, errors.BadRequest("Format must be one of csv, xlsx or ndjson")
+	}
+
+	organization, err := s.organizationRepo.FindByID(ctx, organizationID)
+	if err != nil {
+		return nil, errors.NotFound("Organization")
+	}
+	if organization.AccountID != accountID {
+		return nil, errors.Forbidden("export feedback for this organization")
+	}
+
+	// The plan belongs to the organization's account, so team members export
+	// under the owner's subscription.
+	subscription, err := s.subscriptionRepo.FindByAccountID(ctx, accountID)
+	if err != nil || subscription == nil {
+		return nil, errors.ErrNoSubscriptionFound
+	}
+	if !subscription.Plan.GetFlag(subscriptionconstants.FlagFeedbackExplorer) {
+		return nil, errors.Forbidden("export feedback on your current plan")
+	}
+
+	count, err := s.feedbackRepo.CountByOrganizationIDWithFilters(ctx, organizationID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &feedbackinterface.ExportPlan{
+		OrganizationID: organizationID,
+		Format:         format,
+		Filters:        filters,
+		FileName:       format.FileName(organization.Name, time.Now()),
+		RowCount:       count,
+		Background:     count > int64(s.config.MaxStreamRows),
+	}, nil
+}
+
+// Write streams the export to w and returns how many feedback rows it wrote.
+func (s *exportService) Write(ctx context.Context, plan *feedbackinterface.ExportPlan, w io.Writer) (int, error) {
+	questions, err := s.exportRepo.FindQuestions(ctx, plan.OrganizationID, plan.Filters.ProductID)
+	if err != nil {
+		return 0, err
+	}
+
+	productNames := map[uuid.UUID]string{}
+	for _, question := range questions {
+		if question.Product != nil {
+			productNames[question.ProductID] = question.Product.Name
+		}
+	}
+	layout := feedbackmodel.NewExportLayout(questions, productNames)
+	layout.Select(plan.Columns)
+
+	writer := newExportWriter(plan.Format, w)
+	if err := writer.WriteHeader(layout.Columns); err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	err = s.feedbackRepo.StreamByOrganizationIDWithFilters(ctx, plan.OrganizationID, plan.Filters, s.config.BatchSize, func(feedback *feedbackmodel.Feedback) error {
+		rows++
+		return writer.WriteRow(layout.Row(feedback))
+	})
+	if err != nil {
+		return rows, err
+	}
+
+	return rows, writer.Close()
+}
+
+// StartBackground records the export and queues it to be written to storage
+// in the background. The caller polls the export until it has a download link.
+func (s *exportService) StartBackground(ctx context.Context, plan *feedbackinterface.ExportPlan, requestedBy uuid.UUID) (*feedbackmodel.FeedbackExport, error) {
+	expiresAt := time.Now().Add(s.config.FileTTL)
+	export := &feedbackmodel.FeedbackExport{
+		OrganizationID: plan.OrganizationID,
+		RequestedBy:    requestedBy,
+		Format:         plan.Format,
+		Filters:        plan.Filters,
+		Status:         feedbackmodel.ExportStatusPending,
+		FileName:       plan.FileName,
+		ExpiresAt:      &expiresAt,
+	}
+	if err := s.exportRepo.Create(ctx, export); err != nil {
+		return nil, err
+	}
+
+	// The file is stored under its download name so the link saves with it.
+	export.FileKey = fmt.Sprintf("exports/%s/%s/%s", plan.OrganizationID, export.ID, plan.FileName)
+
+	job := *export
+	if !s.enqueue(exportJob{export: &job, plan: plan}) {
+		if err := s.exportRepo.Delete(ctx, export.ID); err != nil {
+			logger.Error("Failed to delete refused feedback export", err, logrus.Fields{
+				"export_id": export.ID,
+			})
+		}
+		return nil, errors.New("EXPORT_QUEUE_FULL", "Too many exports are running. Please try again in a few minutes.", http.StatusServiceUnavailable)
+	}
+
+	return export, nil
+}
+
+func (s *exportService) run(export *feedbackmodel.FeedbackExport, plan *feedbackinterface.ExportPlan) {
+	ctx := s.ctx
+
+	export.Status = feedbackmodel.ExportStatusRunning
+	if err := s.exportRepo.Update(ctx, export); err != nil {
+		logger.Error("Failed to start feedback export", err, logrus.Fields{
+			"export_id": export.ID,
+		})
+		return
+	}
+
+	type result struct {
+		rows int
+		err  error
+	}
+	written := make(chan result, 1)
+
+	reader, writer := io.Pipe()
+	go func() {
+		rows, err := s.Write(ctx, plan, writer)
+		writer.CloseWithError(err)
+		written <- result{rows: rows, err: err}
+	}()
+
+	putErr := s.storage.Put(ctx, export.FileKey, reader, plan.Format.ContentType())
+	// Unblocks the writer if storage stopped reading early.
+	reader.CloseWithError(io.ErrClosedPipe)
+	res := <-written
+
+	err := res.err
+	if err == nil {
+		err = putErr
+	}
+
+	now := time.Now()
+	export.RowCount = res.rows
+	if err != nil {
+		logger.Error("Feedback export failed", err, logrus.Fields{
+			"export_id":       export.ID,
+			"organization_id": export.OrganizationID,
+		})
+		export.Status = feedbackmodel.ExportStatusFailed
+		export.Error = err.Error()
+		if deleteErr := s.storage.Delete(context.WithoutCancel(ctx), export.FileKey); deleteErr != nil {
+			logger.Error("Failed to delete partial export", deleteErr, logrus.Fields{
+				"export_id": export.ID,
+			})
+		}
+		export.FileKey = ""
+	} else {
+		export.Status = feedbackmodel.ExportStatusCompleted
+		export.CompletedAt = &now
+	}
+
+	// The outcome is recorded even when shutdown cancelled the export.
+	if err := s.exportRepo.Update(context.WithoutCancel(ctx), export); err != nil {
+		logger.Error("Failed to update feedback export", err, logrus.Fields{
+			"export_id": export.ID,
+		})
+	}
+}
+
+func (s *exportService) GetExport(ctx context.Context, accountID, organizationID, exportID uuid.UUID) (*feedbackmodel.FeedbackExport, error) {
+	if err := s.authorize(ctx, accountID, organizationID); err != nil {
+		return nil, err
+	}
+
+	export, err := s.exportRepo.FindByID(ctx, exportID)
+	if err != nil || export.OrganizationID != organizationID {
+		return nil, errors.NotFound("Export")
+	}
+
+	s.signDownload(ctx, export)
+	return export, nil
+}
+
+func (s *exportService) ListExports(ctx context.Context, accountID, organizationID uuid.UUID) ([]feedbackmodel.FeedbackExport, error) {
+	if err := s.authorize(ctx, accountID, organizationID); err != nil {
+		return nil, err
+	}
+
+	exports, err := s.exportRepo.FindByOrganizationID(ctx, organizationID, maxListedExports)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range exports {
+		s.signDownload(ctx, &exports[i])
+	}
+	return exports, nil
+}
+
+// CleanupExpired deletes expired export files along with their records,
+// including exports that failed or never finished.
+func (s *exportService) CleanupExpired(ctx context.Context) error {
+	exports, err := s.exportRepo.FindExpired(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		if export.FileKey != "" {
+			if err := s.storage.Delete(ctx, export.FileKey); err != nil {
+				return err
+			}
+		}
+		if err := s.exportRepo.Delete(ctx, export.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *exportService) authorize(ctx context.Context, accountID, organizationID uuid.UUID) error {
+	organization, err := s.organizationRepo.FindByID(ctx, organizationID)
+	if err != nil {
+		return errors.NotFound("Organization")
+	}
+	if organization.AccountID != accountID {
+		return errors.Forbidden("export feedback for this organization")
+	}
+	return nil
+}
+
+func (s *exportService) signDownload(ctx context.Context, export *feedbackmodel.FeedbackExport) {
+	if export.Status != feedbackmodel.ExportStatusCompleted || export.FileKey == "" {
+		return
+	}
+	url, err := s.storage.SignedURL(ctx, export.FileKey, s.urlTTL)
+	if err != nil {
+		logger.Error("Failed to sign export URL", err, logrus.Fields{
+			"export_id": export.ID,
+		})
+		return
+	}
+	export.DownloadURL = url
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	feedbackmodel "kyooar/internal/feedback/model"
+)
+
+const (
+	// xlsxMaxRows is the most rows a worksheet can hold, header included.
+	xlsxMaxRows = 1048576
+	// xlsxMaxCellLength is the most characters a spreadsheet cell can hold.
+	xlsxMaxCellLength = 32767
+)
+
+// exportWriter writes rows as they are produced so an export never holds
+// more than one row in memory.
+type exportWriter interface {
+	WriteHeader(columns []feedbackmodel.ExportColumn) error
+	WriteRow(row []any) error
+	Close() error
+}
+
+func newExportWriter(format feedbackmodel.ExportFormat, w io.Writer) exportWriter {
+	switch format {
+	case feedbackmodel.ExportFormatXLSX:
+		return &xlsxWriter{zip: zip.NewWriter(w)}
+	case feedbackmodel.ExportFormatNDJSON:
+		return &ndjsonWriter{w: bufio.NewWriter(w)}
+	default:
+		return &csvWriter{w: w}
+	}
+}
+
+type csvWriter struct {
+	w   io.Writer
+	csv *csv.Writer
+}
+
+func (cw *csvWriter) WriteHeader(columns []feedbackmodel.ExportColumn) error {
+	// The byte order mark lets spreadsheet apps detect UTF-8.
+	if _, err := io.WriteString(cw.w, "\ufeff"); err != nil {
+		return err
+	}
+	cw.csv = csv.NewWriter(cw.w)
+
+	titles := make([]string, len(columns))
+	for i, column := range columns {
+		titles[i] = escapeFormula(column.Title)
+	}
+	return cw.csv.Write(titles)
+}
+
+func (cw *csvWriter) WriteRow(row []any) error {
+	record := make([]string, len(row))
+	for i, value := range row {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			record[i] = escapeFormula(v)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return cw.csv.Write(record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.csv.Flush()
+	return cw.csv.Error()
+}
+
+// escapeFormula keeps spreadsheet apps from evaluating customer text that
+// looks like a formula.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+type ndjsonWriter struct {
+	w    *bufio.Writer
+	keys [][]byte
+}
+
+func (nw *ndjsonWriter) WriteHeader(columns []feedbackmodel.ExportColumn) error {
+	nw.keys = make([][]byte, len(columns))
+	for i, column := range columns {
+		key, err := json.Marshal(column.Key)
+		if err != nil {
+			return err
+		}
+		nw.keys[i] = key
+	}
+	return nil
+}
+
+// WriteRow writes one JSON object per line with fields in column order.
+func (nw *ndjsonWriter) WriteRow(row []any) error {
+	nw.w.WriteByte('{')
+	for i, value := range row {
+		if i > 0 {
+			nw.w.WriteByte(',')
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		nw.w.Write(nw.keys[i])
+		nw.w.WriteByte(':')
+		nw.w.Write(data)
+	}
+	nw.w.WriteByte('}')
+	return nw.w.WriteByte('\n')
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nw.w.Flush()
+}
+
+// xlsxWriter writes a minimal single-sheet workbook. The package parts are
+// written up front and the sheet last, so rows can go straight into the zip
+// stream without building the sheet in memory.
+type xlsxWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Feedback" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+func (xw *xlsxWriter) WriteHeader(columns []feedbackmodel.ExportColumn) error {
+	for _, part := range xlsxParts {
+		f, err := xw.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+
+	f, err := xw.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	xw.sheet = bufio.NewWriter(f)
+	xw.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	titles := make([]any, len(columns))
+	for i, column := range columns {
+		titles[i] = column.Title
+	}
+	return xw.WriteRow(titles)
+}
+
+func (xw *xlsxWriter) WriteRow(row []any) error {
+	if xw.rows >= xlsxMaxRows {
+		return fmt.Errorf("export has more rows than a spreadsheet can hold; use CSV or NDJSON instead")
+	}
+	xw.rows++
+
+	number := strconv.Itoa(xw.rows)
+	xw.sheet.WriteString(`<row r="` + number + `">`)
+	for i, value := range row {
+		ref := xlsxColumnName(i) + number
+		switch v := value.(type) {
+		case nil:
+		case bool:
+			flag := "0"
+			if v {
+				flag = "1"
+			}
+			xw.sheet.WriteString(`<c r="` + ref + `" t="b"><v>` + flag + `</v></c>`)
+		case int:
+			xw.sheet.WriteString(`<c r="` + ref + `"><v>` + strconv.Itoa(v) + `</v></c>`)
+		case float64:
+			xw.sheet.WriteString(`<c r="` + ref + `"><v>` + strconv.FormatFloat(v, 'f', -1, 64) + `</v></c>`)
+		default:
+			text := []rune(fmt.Sprint(v))
+			if len(text) > xlsxMaxCellLength {
+				text = text[:xlsxMaxCellLength]
+			}
+			xw.sheet.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+			if err := xml.EscapeText(xw.sheet, []byte(string(text))); err != nil {
+				return err
+			}
+			xw.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := xw.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (xw *xlsxWriter) Close() error {
+	if xw.sheet != nil {
+		xw.sheet.WriteString(`</sheetData></worksheet>`)
+		if err := xw.sheet.Flush(); err != nil {
+			return err
+		}
+	}
+	return xw.zip.Close()
+}
+
+// xlsxColumnName converts a zero-based column index to its letters: A, B, …, Z, AA.
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
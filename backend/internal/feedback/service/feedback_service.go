@@ -2,48 +2,404 @@ package service
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 	feedbackinterface "kyooar/internal/feedback/interface"
 	feedbackmodel "kyooar/internal/feedback/model"
+	kioskmodel "kyooar/internal/kiosk/model"
+	kioskinterface "kyooar/internal/kiosk/interface"
 	organizationinterface "kyooar/internal/organization/interface"
+	menuRepos "kyooar/internal/product/repositories"
 	qrcodeinterface "kyooar/internal/qrcode/interface"
+	qrcodemodel "kyooar/internal/qrcode/model"
+	"kyooar/internal/shared/errors"
+	"kyooar/internal/shared/logger"
 	sharedModels "kyooar/internal/shared/models"
+	sharedRepos "kyooar/internal/shared/repositories"
 )
 
 type feedbackService struct {
-	feedbackRepo     feedbackinterface.FeedbackRepository
-	organizationRepo organizationinterface.OrganizationRepository
-	qrCodeRepo       qrcodeinterface.QRCodeRepository
+	feedbackRepo      feedbackinterface.FeedbackRepository
+	organizationRepo  organizationinterface.OrganizationRepository
+	productRepo       menuRepos.ProductRepository
+	qrCodeRepo        qrcodeinterface.QRCodeRepository
+	qrCodeService     qrcodeinterface.QRCodeService
+	attachmentService feedbackinterface.AttachmentService
+	versionService    feedbackinterface.VersionService
+	abuseGuard        feedbackinterface.AbuseGuard
+	deviceService     kioskinterface.DeviceService
 }
 
 func NewFeedbackService(
 	feedbackRepo feedbackinterface.FeedbackRepository,
 	organizationRepo organizationinterface.OrganizationRepository,
+	productRepo menuRepos.ProductRepository,
 	qrCodeRepo qrcodeinterface.QRCodeRepository,
+	qrCodeService qrcodeinterface.QRCodeService,
+	attachmentService feedbackinterface.AttachmentService,
+	versionService feedbackinterface.VersionService,
+	abuseGuard feedbackinterface.AbuseGuard,
+	deviceService kioskinterface.DeviceService,
 ) feedbackinterface.FeedbackService {
 	return &feedbackService{
-		feedbackRepo:     feedbackRepo,
-		organizationRepo: organizationRepo,
-		qrCodeRepo:       qrCodeRepo,
+		feedbackRepo:      feedbackRepo,
+		organizationRepo:  organizationRepo,
+		productRepo:       productRepo,
+		qrCodeRepo:        qrCodeRepo,
+		qrCodeService:     qrCodeService,
+		attachmentService: attachmentService,
+		versionService:    versionService,
+		abuseGuard:        abuseGuard,
+		deviceService:     deviceService,
 	}
 }
 
-func (s *feedbackService) Submit(ctx context.Context, feedback *feedbackmodel.Feedback) error {
+func (s *feedbackService) Submit(ctx context.Context, feedback *feedbackmodel.Feedback) (*feedbackmodel.SubmissionResult, error) {
+	device, err := s.authenticateDevice(ctx, feedback.DeviceToken)
+	if err != nil {
+		return nil, err
+	}
+	if device != nil && feedback.QRCodeID == uuid.Nil && device.QRCodeID != nil {
+		feedback.QRCodeID = *device.QRCodeID
+	}
+
 	qrCode, err := s.qrCodeRepo.FindByID(ctx, feedback.QRCodeID)
 	if err != nil {
-		return err
+		return nil, errors.NotFound("QR code")
 	}
 
 	feedback.OrganizationID = qrCode.OrganizationID
+	feedback.KioskDeviceID = nil
+	if device != nil {
+		if device.OrganizationID != qrCode.OrganizationID {
+			return nil, errors.Forbidden("submit feedback for this QR code from this device")
+		}
+		feedback.KioskDeviceID = &device.ID
+	}
 	// ProductID comes from the request payload, QRCodeID identifies the location
 
-	return s.feedbackRepo.Create(ctx, feedback)
+	if feedback.ProductID == uuid.Nil {
+		return nil, errors.BadRequest("Product ID is required")
+	}
+	if err := s.checkProduct(ctx, feedback.ProductID, qrCode.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	feedback.IdempotencyKey = strings.TrimSpace(feedback.IdempotencyKey)
+	if len(feedback.IdempotencyKey) > feedbackmodel.MaxIdempotencyKeyLength {
+		return nil, errors.BadRequest(fmt.Sprintf("Idempotency key must be at most %d characters", feedbackmodel.MaxIdempotencyKeyLength))
+	}
+	// A retry of feedback that was already accepted gets the stored feedback
+	// back even if the code has since expired or rotated.
+	if feedback.IdempotencyKey != "" {
+		if existing, err := s.findByIdempotencyKey(ctx, feedback); existing != nil || err != nil {
+			return existing, err
+		}
+	}
+
+	if err := s.checkQRCode(qrCode, feedback.QRToken, device); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := validateCapturedAt(feedback, now); err != nil {
+		return nil, err
+	}
+
+	// These fields are owned by the server and never taken from the client
+	feedback.ID = uuid.Nil
+	feedback.IsQuarantined = false
+	feedback.QuarantineReasons = nil
+	feedback.ReviewedAt = nil
+	feedback.ReviewedBy = nil
+
+	feedback.DeviceHash = feedbackmodel.DeviceHash(feedback.DeviceInfo)
+	if !feedback.Queued {
+		if err := s.abuseGuard.CheckLimits(ctx, qrCode.ID, limitedDeviceHash(feedback)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.validateResponses(ctx, feedback); err != nil {
+		return nil, err
+	}
+
+	if err := s.abuseGuard.Screen(ctx, feedback); err != nil {
+		return nil, err
+	}
+
+	feedback.Language = feedbackmodel.NormalizeLanguageTag(feedback.Language)
+	feedback.IsComplete = true
+	feedback.LastActivityAt = &now
+	feedback.CompletedAt = &now
+
+	if feedback.IdempotencyKey == "" {
+		if err := s.feedbackRepo.Create(ctx, feedback); err != nil {
+			return nil, claimError(err)
+		}
+	} else {
+		created, err := s.feedbackRepo.CreateWithIdempotencyKey(ctx, feedback)
+		if err != nil {
+			return nil, claimError(err)
+		}
+		if !created {
+			// A concurrent retry with the same key won the insert
+			return s.findByIdempotencyKey(ctx, feedback)
+		}
+	}
+
+	return &feedbackmodel.SubmissionResult{
+		IdempotencyKey: feedback.IdempotencyKey,
+		Status:         feedbackmodel.SubmissionStatusCreated,
+		FeedbackID:     &feedback.ID,
+	}, nil
+}
+
+// SubmitBatch submits feedback queued by an offline client one item at a
+// time, so a failing item does not prevent the others from being stored.
+// Items carry idempotency keys, which makes resending a whole batch safe.
+// The whole batch must fit in what is left of the hourly limits.
+func (s *feedbackService) SubmitBatch(ctx context.Context, items []feedbackmodel.BatchFeedbackItem, deviceInfo feedbackmodel.DeviceInfo, deviceToken string) (*feedbackmodel.BatchFeedbackResponse, error) {
+	if len(items) == 0 {
+		return nil, errors.BadRequest("At least one feedback is required")
+	}
+	if len(items) > feedbackmodel.MaxBatchSize {
+		return nil, errors.BadRequest(fmt.Sprintf("A batch can contain at most %d feedbacks", feedbackmodel.MaxBatchSize))
+	}
+	if err := s.checkBatchLimits(ctx, items, deviceInfo, deviceToken); err != nil {
+		return nil, err
+	}
+
+	response := &feedbackmodel.BatchFeedbackResponse{
+		Results: make([]feedbackmodel.SubmissionResult, len(items)),
+	}
+	for i := range items {
+		item := &items[i]
+		feedback := item.Feedback()
+		feedback.DeviceInfo = deviceInfo
+		feedback.DeviceToken = deviceToken
+		feedback.IdempotencyKey = item.IdempotencyKey
+		feedback.Challenge = item.Challenge
+		feedback.ChallengeSolution = item.ChallengeSolution
+		feedback.QRToken = item.QRToken
+		feedback.Queued = true
+
+		result, err := s.submitBatchItem(ctx, feedback)
+		if err != nil {
+			result = failedSubmission(err)
+			result.IdempotencyKey = item.IdempotencyKey
+		}
+		result.Index = i
+		response.Results[i] = *result
+
+		switch result.Status {
+		case feedbackmodel.SubmissionStatusCreated:
+			response.Created++
+		case feedbackmodel.SubmissionStatusDuplicate:
+			response.Duplicate++
+		default:
+			response.Failed++
+		}
+	}
+
+	return response, nil
+}
+
+// checkBatchLimits counts every item of the batch against the hourly
+// limits, except resent items that were already stored. Paired kiosks are
+// only held to the QR code limit.
+func (s *feedbackService) checkBatchLimits(ctx context.Context, items []feedbackmodel.BatchFeedbackItem, deviceInfo feedbackmodel.DeviceInfo, deviceToken string) error {
+	device, err := s.authenticateDevice(ctx, deviceToken)
+	if err != nil {
+		return err
+	}
+	deviceHash := ""
+	if device == nil {
+		deviceHash = feedbackmodel.DeviceHash(deviceInfo)
+	}
+
+	// Items without a QR code or idempotency key are refused on their own.
+	keys := make(map[uuid.UUID][]string)
+	for _, item := range items {
+		qrCodeID := item.QRCodeID
+		if qrCodeID == uuid.Nil && device != nil && device.QRCodeID != nil {
+			qrCodeID = *device.QRCodeID
+		}
+		key := strings.TrimSpace(item.IdempotencyKey)
+		if qrCodeID == uuid.Nil || key == "" {
+			continue
+		}
+		keys[qrCodeID] = append(keys[qrCodeID], key)
+	}
+
+	pending := make(map[uuid.UUID]int, len(keys))
+	for qrCodeID, qrKeys := range keys {
+		stored, err := s.feedbackRepo.FindStoredIdempotencyKeys(ctx, qrCodeID, qrKeys)
+		if err != nil {
+			return err
+		}
+		isStored := make(map[string]bool, len(stored))
+		for _, key := range stored {
+			isStored[key] = true
+		}
+		for _, key := range qrKeys {
+			if !isStored[key] {
+				pending[qrCodeID]++
+			}
+		}
+	}
+
+	return s.abuseGuard.CheckBatchLimits(ctx, pending, deviceHash)
+}
+
+func (s *feedbackService) submitBatchItem(ctx context.Context, feedback *feedbackmodel.Feedback) (*feedbackmodel.SubmissionResult, error) {
+	if strings.TrimSpace(feedback.IdempotencyKey) == "" {
+		return nil, errors.BadRequest("Idempotency key is required for batched feedback")
+	}
+	if feedback.CapturedAt == nil {
+		return nil, errors.BadRequest("Capture time is required for batched feedback")
+	}
+	return s.Submit(ctx, feedback)
+}
+
+func (s *feedbackService) findByIdempotencyKey(ctx context.Context, feedback *feedbackmodel.Feedback) (*feedbackmodel.SubmissionResult, error) {
+	existing, err := s.feedbackRepo.FindByIdempotencyKey(ctx, feedback.QRCodeID, feedback.IdempotencyKey)
+	if err != nil {
+		if err == sharedRepos.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if existing.ProductID != feedback.ProductID {
+		return nil, errors.New("IDEMPOTENCY_KEY_REUSED", "This idempotency key was already used for a different feedback", http.StatusUnprocessableEntity)
+	}
+
+	return &feedbackmodel.SubmissionResult{
+		IdempotencyKey: existing.IdempotencyKey,
+		Status:         feedbackmodel.SubmissionStatusDuplicate,
+		FeedbackID:     &existing.ID,
+	}, nil
+}
+
+// authenticateDevice resolves the token of a paired kiosk. Anonymous
+// submissions have no token and yield no device.
+func (s *feedbackService) authenticateDevice(ctx context.Context, token string) (*kioskmodel.KioskDevice, error) {
+	if token == "" {
+		return nil, nil
+	}
+	return s.deviceService.Authenticate(ctx, token)
+}
+
+// checkQRCode refuses feedback through a code that cannot be used. Paired
+// kiosks are trusted without the token of a rotating code.
+func (s *feedbackService) checkQRCode(qrCode *qrcodemodel.QRCode, token string, device *kioskmodel.KioskDevice) error {
+	if device != nil {
+		if reason := qrCode.Rejection(time.Now()); reason != "" {
+			return qrcodemodel.RejectedError(reason)
+		}
+		return nil
+	}
+	return s.qrCodeService.CheckAccess(qrCode, token)
+}
+
+// checkProduct refuses feedback on a product that does not belong to the QR
+// code's organization.
+func (s *feedbackService) checkProduct(ctx context.Context, productID, organizationID uuid.UUID) error {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil || product.OrganizationID != organizationID {
+		return errors.NotFound("Product")
+	}
+	return nil
+}
+
+// limitedDeviceHash is the device the hourly limit applies to. Paired kiosks
+// are shared by many customers and are only held to the QR code limit.
+func limitedDeviceHash(feedback *feedbackmodel.Feedback) string {
+	if feedback.KioskDeviceID != nil {
+		return ""
+	}
+	return feedback.DeviceHash
+}
+
+// validateCapturedAt defaults the capture time to now and rejects times an
+// offline client could not plausibly have recorded.
+func validateCapturedAt(feedback *feedbackmodel.Feedback, now time.Time) error {
+	if feedback.CapturedAt == nil || feedback.CapturedAt.IsZero() {
+		feedback.CapturedAt = &now
+		return nil
+	}
+	if feedback.CapturedAt.After(now.Add(feedbackmodel.CaptureClockSkew)) {
+		return errors.BadRequest("Capture time cannot be in the future")
+	}
+	if feedback.CapturedAt.Before(now.Add(-feedbackmodel.MaxCaptureAge)) {
+		return errors.BadRequest("Capture time is too old")
+	}
+	return nil
+}
+
+// claimError reports a photo that another feedback claimed between
+// validation and saving as a conflict rather than a server error.
+func claimError(err error) error {
+	if stderrors.Is(err, feedbackmodel.ErrAttachmentClaimed) {
+		return errors.New("PHOTO_ALREADY_SUBMITTED", "A photo in this feedback was already submitted with another feedback", http.StatusConflict)
+	}
+	return err
 }
 
-func (s *feedbackService) GetByOrganizationID(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, page, limit int) (*sharedModels.PageResponse[feedbackmodel.Feedback], error) {
+func failedSubmission(err error) *feedbackmodel.SubmissionResult {
+	appErr, ok := errors.IsAppError(err)
+	if !ok {
+		logger.Error("Failed to submit batched feedback", err, logrus.Fields{})
+		appErr = errors.Internal("Failed to process feedback submission")
+	}
+	return &feedbackmodel.SubmissionResult{
+		Status:    feedbackmodel.SubmissionStatusFailed,
+		ErrorCode: appErr.Code,
+		Error:     appErr.Message,
+		Details:   appErr.Details,
+	}
+}
+
+func (s *feedbackService) validateResponses(ctx context.Context, feedback *feedbackmodel.Feedback) error {
+	questions, err := s.feedbackRepo.GetQuestionsByProductID(ctx, feedback.ProductID)
+	if err != nil {
+		return err
+	}
+
+	responses, answerErrors := feedbackmodel.ValidateResponses(questions, feedback.Responses)
+	if len(answerErrors) > 0 {
+		return errors.NewWithDetails("VALIDATION_ERROR", "Some answers are missing or invalid", http.StatusBadRequest, answerErrors)
+	}
+
+	feedback.Responses = responses
+	if err := s.attachmentService.ValidateForFeedback(ctx, feedback); err != nil {
+		return err
+	}
+
+	// Pin the feedback to the questions it was validated against so later
+	// edits do not change what historical answers mean.
+	version, err := s.versionService.CurrentVersion(ctx, feedback.OrganizationID, feedback.ProductID, questions)
+	if err != nil {
+		return err
+	}
+	feedback.QuestionnaireVersionID = &version.ID
+	return nil
+}
+
+func (s *feedbackService) signAttachments(ctx context.Context, page *sharedModels.PageResponse[feedbackmodel.Feedback]) {
+	for i := range page.Data {
+		s.attachmentService.SignURLs(ctx, page.Data[i].Attachments)
+	}
+}
+
+func (s *feedbackService) GetByOrganizationID(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, req sharedModels.PageRequest) (*sharedModels.PageResponse[feedbackmodel.Feedback], error) {
 	organization, err := s.organizationRepo.FindByID(ctx, organizationID)
 	if err != nil {
 		return nil, err
@@ -53,10 +409,16 @@ func (s *feedbackService) GetByOrganizationID(ctx context.Context, accountID uui
 		return nil, fmt.Errorf("organization not found")
 	}
 
-	return s.feedbackRepo.FindByOrganizationID(ctx, accountID, organizationID, page, limit)
+	feedbacks, err := s.feedbackRepo.FindByOrganizationID(ctx, accountID, organizationID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.signAttachments(ctx, feedbacks)
+	return feedbacks, nil
 }
 
-func (s *feedbackService) GetByOrganizationIDWithFilters(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, page, limit int, filters feedbackmodel.FeedbackFilter) (*sharedModels.PageResponse[feedbackmodel.Feedback], error) {
+func (s *feedbackService) GetByOrganizationIDWithFilters(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, req sharedModels.PageRequest, filters feedbackmodel.FeedbackFilter) (*sharedModels.PageResponse[feedbackmodel.Feedback], error) {
 	organization, err := s.organizationRepo.FindByID(ctx, organizationID)
 	if err != nil {
 		return nil, err
@@ -66,7 +428,13 @@ func (s *feedbackService) GetByOrganizationIDWithFilters(ctx context.Context, ac
 		return nil, fmt.Errorf("organization not found")
 	}
 
-	return s.feedbackRepo.FindByOrganizationIDWithFilters(ctx, accountID, organizationID, page, limit, filters)
+	feedbacks, err := s.feedbackRepo.FindByOrganizationIDWithFilters(ctx, accountID, organizationID, req, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	s.signAttachments(ctx, feedbacks)
+	return feedbacks, nil
 }
 
 func (s *feedbackService) GetStats(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID) (*feedbackmodel.FeedbackStats, error) {
@@ -88,4 +456,42 @@ func (s *feedbackService) GetByOrganizationIDForAnalytics(ctx context.Context, o
 
 func (s *feedbackService) GetByQuestionInPeriod(ctx context.Context, questionID uuid.UUID, startDate, endDate time.Time) ([]feedbackmodel.Feedback, error) {
 	return s.feedbackRepo.FindByQuestionInPeriod(ctx, questionID, startDate, endDate)
-}
\ No newline at end of file
+}
+
+// Review approves or rejects quarantined feedback. Approved feedback is
+// counted in analytics again; rejected feedback stays quarantined but is
+// marked as reviewed.
+func (s *feedbackService) Review(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, feedbackID uuid.UUID, reviewerID uuid.UUID, req *feedbackmodel.ReviewFeedbackRequest) (*feedbackmodel.Feedback, error) {
+	organization, err := s.organizationRepo.FindByID(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if organization.AccountID != accountID {
+		return nil, errors.Forbidden("review feedback for this organization")
+	}
+
+	feedback, err := s.feedbackRepo.FindByID(ctx, feedbackID)
+	if err != nil {
+		return nil, errors.NotFound("Feedback")
+	}
+
+	if feedback.OrganizationID != organizationID {
+		return nil, errors.NotFound("Feedback")
+	}
+
+	if !feedback.IsQuarantined && feedback.ReviewedAt == nil {
+		return nil, errors.New("NOT_QUARANTINED", "This feedback is not quarantined", http.StatusConflict)
+	}
+
+	now := time.Now()
+	feedback.IsQuarantined = req.Action == feedbackmodel.ReviewActionReject
+	feedback.ReviewedAt = &now
+	feedback.ReviewedBy = &reviewerID
+
+	if err := s.feedbackRepo.Update(ctx, feedback); err != nil {
+		return nil, err
+	}
+
+	return feedback, nil
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+	qrcodemodel "kyooar/internal/qrcode/model"
+	"kyooar/internal/shared/errors"
+	sharedRepos "kyooar/internal/shared/repositories"
+)
+
+func (s *feedbackService) StartSession(ctx context.Context, req *feedbackmodel.StartSessionRequest, deviceInfo feedbackmodel.DeviceInfo) (*feedbackmodel.FeedbackSession, error) {
+	device, err := s.authenticateDevice(ctx, req.DeviceToken)
+	if err != nil {
+		return nil, err
+	}
+	if device != nil && req.QRCodeID == uuid.Nil && device.QRCodeID != nil {
+		req.QRCodeID = *device.QRCodeID
+	}
+
+	if req.QRCodeID == uuid.Nil {
+		return nil, errors.BadRequest("QR code ID is required")
+	}
+	if req.ProductID == uuid.Nil {
+		return nil, errors.BadRequest("Product ID is required")
+	}
+
+	qrCode, err := s.qrCodeRepo.FindByID(ctx, req.QRCodeID)
+	if err != nil {
+		return nil, errors.NotFound("QR code")
+	}
+	if err := s.checkProduct(ctx, req.ProductID, qrCode.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	feedback := &feedbackmodel.Feedback{
+		OrganizationID: qrCode.OrganizationID,
+		ProductID:      req.ProductID,
+		QRCodeID:       qrCode.ID,
+		Responses:      feedbackmodel.Responses{},
+		DeviceInfo:     deviceInfo,
+		DeviceHash:     feedbackmodel.DeviceHash(deviceInfo),
+		Language:       feedbackmodel.NormalizeLanguageTag(req.Language),
+		LastActivityAt: &now,
+	}
+	if device != nil {
+		if device.OrganizationID != qrCode.OrganizationID {
+			return nil, errors.Forbidden("submit feedback for this QR code from this device")
+		}
+		feedback.KioskDeviceID = &device.ID
+	}
+	if err := s.checkQRCode(qrCode, req.QRToken, device); err != nil {
+		return nil, err
+	}
+
+	if err := s.abuseGuard.CheckLimits(ctx, qrCode.ID, limitedDeviceHash(feedback)); err != nil {
+		return nil, err
+	}
+
+	token, tokenHash, err := feedbackmodel.GenerateSessionToken()
+	if err != nil {
+		return nil, err
+	}
+	feedback.SessionTokenHash = &tokenHash
+
+	if err := s.feedbackRepo.CreateSession(ctx, feedback); err != nil {
+		return nil, err
+	}
+
+	return newFeedbackSession(token, feedback), nil
+}
+
+func (s *feedbackService) GetSession(ctx context.Context, token string) (*feedbackmodel.FeedbackSession, error) {
+	feedback, err := s.findSession(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return newFeedbackSession("", feedback), nil
+}
+
+func (s *feedbackService) SaveAnswer(ctx context.Context, token string, questionID uuid.UUID, answer any) (*feedbackmodel.FeedbackSession, error) {
+	feedback, err := s.findOpenSession(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := s.feedbackRepo.GetQuestionsByProductID(ctx, feedback.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	var question *feedbackmodel.Question
+	for i := range questions {
+		if questions[i].ID == questionID {
+			question = &questions[i]
+			break
+		}
+	}
+	if question == nil {
+		return nil, errors.NotFound("Question")
+	}
+
+	if question.IsAnswered(answer) {
+		normalized, answerErr := question.NormalizeAnswer(answer)
+		if answerErr != nil {
+			return nil, errors.NewWithDetails("VALIDATION_ERROR", answerErr.Message, http.StatusBadRequest, []feedbackmodel.AnswerError{*answerErr})
+		}
+		feedback.SetAnswer(question, normalized)
+	} else {
+		feedback.SetAnswer(question, nil)
+	}
+
+	now := time.Now()
+	feedback.LastActivityAt = &now
+
+	if err := s.feedbackRepo.Update(ctx, feedback); err != nil {
+		return nil, err
+	}
+
+	return newFeedbackSession("", feedback), nil
+}
+
+func (s *feedbackService) CompleteSession(ctx context.Context, token string, req *feedbackmodel.CompleteSessionRequest) (*feedbackmodel.Feedback, error) {
+	feedback, err := s.findOpenSession(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	feedback.CustomerName = req.CustomerName
+	feedback.CustomerEmail = req.CustomerEmail
+	feedback.CustomerPhone = req.CustomerPhone
+	feedback.OverallRating = req.OverallRating
+	feedback.Honeypot = req.Website
+	feedback.Challenge = req.Challenge
+	feedback.ChallengeSolution = req.ChallengeSolution
+
+	// The rotating token was checked when the session started, but a code
+	// deactivated or expired since then no longer takes feedback.
+	qrCode, err := s.qrCodeRepo.FindByID(ctx, feedback.QRCodeID)
+	if err != nil {
+		return nil, errors.NotFound("QR code")
+	}
+	if reason := qrCode.Rejection(time.Now()); reason != "" {
+		return nil, qrcodemodel.RejectedError(reason)
+	}
+
+	if err := s.validateResponses(ctx, feedback); err != nil {
+		return nil, err
+	}
+
+	if err := s.abuseGuard.Screen(ctx, feedback); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	feedback.IsComplete = true
+	feedback.LastActivityAt = &now
+	feedback.CompletedAt = &now
+	feedback.CapturedAt = &now
+
+	if err := s.feedbackRepo.CompleteSession(ctx, feedback); err != nil {
+		return nil, claimError(err)
+	}
+
+	return feedback, nil
+}
+
+func (s *feedbackService) findSession(ctx context.Context, token string) (*feedbackmodel.Feedback, error) {
+	if token == "" {
+		return nil, errors.NotFound("Session")
+	}
+
+	feedback, err := s.feedbackRepo.FindBySessionTokenHash(ctx, feedbackmodel.HashSessionToken(token))
+	if err != nil {
+		if err == sharedRepos.ErrRecordNotFound {
+			return nil, errors.NotFound("Session")
+		}
+		return nil, err
+	}
+
+	return feedback, nil
+}
+
+func (s *feedbackService) findOpenSession(ctx context.Context, token string) (*feedbackmodel.Feedback, error) {
+	feedback, err := s.findSession(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if feedback.IsComplete {
+		return nil, errors.New("SESSION_COMPLETED", "This feedback has already been submitted", http.StatusConflict)
+	}
+	if feedback.IsSessionExpired(time.Now()) {
+		return nil, errors.New("SESSION_EXPIRED", "This feedback session has expired", http.StatusGone)
+	}
+
+	return feedback, nil
+}
+
+func newFeedbackSession(token string, feedback *feedbackmodel.Feedback) *feedbackmodel.FeedbackSession {
+	session := &feedbackmodel.FeedbackSession{
+		Token:       token,
+		FeedbackID:  feedback.ID,
+		Responses:   feedback.Responses,
+		Language:    feedback.Language,
+		IsComplete:  feedback.IsComplete,
+		CompletedAt: feedback.CompletedAt,
+	}
+	if feedback.LastActivityAt != nil {
+		session.ExpiresAt = feedback.LastActivityAt.Add(feedbackmodel.SessionResumeWindow)
+	}
+	return session
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	feedbackinterface "kyooar/internal/feedback/interface"
+	feedbackmodel "kyooar/internal/feedback/model"
+	organizationinterface "kyooar/internal/organization/interface"
+	organizationmodel "kyooar/internal/organization/model"
+	productRepos "kyooar/internal/product/repositories"
+	qrcodeinterface "kyooar/internal/qrcode/interface"
+	"kyooar/internal/shared/errors"
+	"kyooar/internal/shared/logger"
+	subscriptioninterface "kyooar/internal/subscription/interface"
+	subscriptionmodel "kyooar/internal/subscription/model"
+)
+
+// maxReportedImportErrors bounds the errors listed in a report so a file
+// with a systematic problem does not produce one entry per row.
+const maxReportedImportErrors = 500
+
+type importService struct {
+	importRepo       feedbackinterface.ImportRepository
+	questionRepo     feedbackinterface.QuestionRepository
+	productRepo      productRepos.ProductRepository
+	organizationRepo organizationinterface.OrganizationRepository
+	qrCodeRepo       qrcodeinterface.QRCodeRepository
+	subscriptionRepo subscriptioninterface.SubscriptionRepository
+	usageService     subscriptioninterface.UsageService
+}
+
+func NewImportService(
+	importRepo feedbackinterface.ImportRepository,
+	questionRepo feedbackinterface.QuestionRepository,
+	productRepo productRepos.ProductRepository,
+	organizationRepo organizationinterface.OrganizationRepository,
+	qrCodeRepo qrcodeinterface.QRCodeRepository,
+	subscriptionRepo subscriptioninterface.SubscriptionRepository,
+	usageService subscriptioninterface.UsageService,
+) feedbackinterface.ImportService {
+	return &importService{
+		importRepo:       importRepo,
+		questionRepo:     questionRepo,
+		productRepo:      productRepo,
+		organizationRepo: organizationRepo,
+		qrCodeRepo:       qrCodeRepo,
+		subscriptionRepo: subscriptionRepo,
+		usageService:     usageService,
+	}
+}
+
+func (s *importService) Import(ctx context.Context, accountID, organizationID, requestedBy uuid.UUID, file io.Reader, req feedbackmodel.ImportFeedbackRequest) (*feedbackmodel.ImportReport, error) {
+	organization, err := s.authorize(ctx, accountID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.productRepo.FindByOrganizationID(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	productIDs := make([]uuid.UUID, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+	questions, err := s.questionRepo.FindByProductIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = false
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.BadRequest("The file must be a CSV file with a header row")
+	}
+
+	report := &feedbackmodel.ImportReport{
+		DryRun: req.DryRun,
+		Errors: []feedbackmodel.ImportRowError{},
+	}
+	addErrors := func(rowErrors ...feedbackmodel.ImportRowError) {
+		for _, rowError := range rowErrors {
+			if len(report.Errors) >= maxReportedImportErrors {
+				return
+			}
+			report.Errors = append(report.Errors, rowError)
+		}
+	}
+
+	layout, mappingErrors := feedbackmodel.NewImportLayout(req.Mapping, header, products, questions)
+	addErrors(mappingErrors...)
+
+	if req.Mapping.QRCodeID != uuid.Nil {
+		qrCode, err := s.qrCodeRepo.FindByID(ctx, req.Mapping.QRCodeID)
+		if err != nil || qrCode.OrganizationID != organizationID {
+			addErrors(feedbackmodel.ImportRowError{Message: "QR code not found in this organization"})
+		}
+	}
+
+	var feedbacks []*feedbackmodel.Feedback
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if stderrors.As(err, &parseErr) {
+				addErrors(feedbackmodel.ImportRowError{Row: parseErr.Line, Message: parseErr.Err.Error()})
+			} else {
+				addErrors(feedbackmodel.ImportRowError{Row: row, Message: err.Error()})
+			}
+			break
+		}
+
+		report.TotalRows++
+		if report.TotalRows > feedbackmodel.MaxImportRows {
+			return nil, errors.BadRequest(fmt.Sprintf("A file can import at most %d rows", feedbackmodel.MaxImportRows))
+		}
+
+		feedback, rowErrors := layout.Parse(row, record)
+		if len(rowErrors) > 0 {
+			addErrors(rowErrors...)
+			continue
+		}
+		report.ValidRows++
+
+		feedback.OrganizationID = organizationID
+		feedback.ExcludedFromAnalytics = !req.IncludeInAnalytics
+		feedbacks = append(feedbacks, feedback)
+	}
+
+	var subscription *subscriptionmodel.Subscription
+	if req.CountTowardLimit {
+		subscription, err = s.subscriptionRepo.FindByAccountID(ctx, accountID)
+		if err != nil || subscription == nil {
+			return nil, errors.ErrNoSubscriptionFound
+		}
+		remaining, err := s.remainingFeedbacks(ctx, subscription)
+		if err != nil {
+			return nil, err
+		}
+		if remaining >= 0 && report.ValidRows > remaining {
+			addErrors(feedbackmodel.ImportRowError{
+				Message: fmt.Sprintf("Importing %d feedbacks would exceed your plan, which allows %d more this month", report.ValidRows, remaining),
+			})
+		}
+	}
+
+	noteRetention(report, feedbacks, organization.Settings.Retention, time.Now())
+
+	if report.TotalRows == 0 && len(report.Errors) == 0 {
+		addErrors(feedbackmodel.ImportRowError{Message: "The file has no rows to import"})
+	}
+
+	if req.DryRun {
+		return report, nil
+	}
+	if len(report.Errors) > 0 {
+		return nil, errors.NewWithDetails("IMPORT_INVALID", "The file has errors, nothing was imported", http.StatusUnprocessableEntity, report)
+	}
+
+	feedbackImport := &feedbackmodel.FeedbackImport{
+		OrganizationID:     organizationID,
+		RequestedBy:        requestedBy,
+		FileName:           req.FileName,
+		Mapping:            req.Mapping,
+		RowCount:           len(feedbacks),
+		CountTowardLimit:   req.CountTowardLimit,
+		IncludeInAnalytics: req.IncludeInAnalytics,
+	}
+	if err := s.importRepo.Create(ctx, feedbackImport, feedbacks); err != nil {
+		return nil, err
+	}
+
+	if subscription != nil {
+		if err := s.usageService.TrackUsage(ctx, subscription.ID, subscriptionmodel.ResourceTypeFeedback, len(feedbacks)); err != nil {
+			logger.Error("Failed to track imported feedback usage", err, logrus.Fields{
+				"import_id":       feedbackImport.ID,
+				"subscription_id": subscription.ID,
+			})
+		}
+	}
+
+	report.Import = feedbackImport
+	return report, nil
+}
+
+// noteRetention counts the rows the retention policy would already cover by
+// their date. Retention measures imported feedback from the import, so the
+// report warns that these rows are kept in full for another full period.
+func noteRetention(report *feedbackmodel.ImportReport, feedbacks []*feedbackmodel.Feedback, policy organizationmodel.RetentionPolicy, now time.Time) {
+	days := 0
+	for _, period := range []int{policy.AnonymizeAfterDays, policy.DeleteTextAfterDays} {
+		if period > 0 && (days == 0 || period < days) {
+			days = period
+		}
+	}
+	if days == 0 {
+		return
+	}
+
+	cutoff := now.AddDate(0, 0, -days)
+	for _, feedback := range feedbacks {
+		if feedback.CreatedAt.Before(cutoff) {
+			report.OlderThanRetention++
+		}
+	}
+	if report.OlderThanRetention > 0 {
+		report.RetentionNotice = fmt.Sprintf("%d rows are older than your %d-day retention policy. Imported feedback is kept until %d days after the import, then the policy applies.", report.OlderThanRetention, days, days)
+	}
+}
+
+// remainingFeedbacks returns how many more feedbacks the plan allows this
+// month, or -1 when it is unlimited.
+func (s *importService) remainingFeedbacks(ctx context.Context, subscription *subscriptionmodel.Subscription) (int, error) {
+	limit := subscription.Plan.MaxFeedbacksPerMonth
+	if limit == -1 {
+		return -1, nil
+	}
+	usage, err := s.usageService.GetCurrentUsage(ctx, subscription.ID)
+	if err != nil {
+		return 0, err
+	}
+	if usage.FeedbacksCount >= limit {
+		return 0, nil
+	}
+	return limit - usage.FeedbacksCount, nil
+}
+
+func (s *importService) ListImports(ctx context.Context, accountID, organizationID uuid.UUID) ([]feedbackmodel.FeedbackImport, error) {
+	if _, err := s.authorize(ctx, accountID, organizationID); err != nil {
+		return nil, err
+	}
+	return s.importRepo.FindByOrganizationID(ctx, organizationID)
+}
+
+func (s *importService) SetIncludeInAnalytics(ctx context.Context, accountID, organizationID, importID uuid.UUID, include bool) (*feedbackmodel.FeedbackImport, error) {
+	feedbackImport, err := s.findImport(ctx, accountID, organizationID, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.importRepo.SetIncludeInAnalytics(ctx, importID, include); err != nil {
+		return nil, err
+	}
+	feedbackImport.IncludeInAnalytics = include
+	return feedbackImport, nil
+}
+
+// DeleteImport removes the import and its feedback. Usage already counted
+// against the plan is not given back.
+func (s *importService) DeleteImport(ctx context.Context, accountID, organizationID, importID uuid.UUID) error {
+	if _, err := s.findImport(ctx, accountID, organizationID, importID); err != nil {
+		return err
+	}
+	return s.importRepo.Delete(ctx, importID)
+}
+
+func (s *importService) findImport(ctx context.Context, accountID, organizationID, importID uuid.UUID) (*feedbackmodel.FeedbackImport, error) {
+	if _, err := s.authorize(ctx, accountID, organizationID); err != nil {
+		return nil, err
+	}
+	feedbackImport, err := s.importRepo.FindByID(ctx, importID)
+	if err != nil || feedbackImport.OrganizationID != organizationID {
+		return nil, errors.NotFound("Import")
+	}
+	return feedbackImport, nil
+}
+
+func (s *importService) authorize(ctx context.Context, accountID, organizationID uuid.UUID) (*organizationmodel.Organization, error) {
+	organization, err := s.organizationRepo.FindByID(ctx, organizationID)
+	if err != nil {
+		return nil, errors.NotFound("Organization")
+	}
+	if organization.AccountID != accountID {
+		return nil, errors.Forbidden("import feedback for this organization")
+	}
+	return organization, nil
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	feedbackinterface "kyooar/internal/feedback/interface"
+	feedbackmodel "kyooar/internal/feedback/model"
+	organizationinterface "kyooar/internal/organization/interface"
+	organizationmodel "kyooar/internal/organization/model"
+	"kyooar/internal/shared/errors"
+	"kyooar/internal/shared/logger"
+	sharedRepos "kyooar/internal/shared/repositories"
+	sharedServices "kyooar/internal/shared/services"
+)
+
+type messageService struct {
+	messageRepo      feedbackinterface.MessageRepository
+	feedbackRepo     feedbackinterface.FeedbackRepository
+	organizationRepo organizationinterface.OrganizationRepository
+	emailService     sharedServices.EmailService
+}
+
+func NewMessageService(
+	messageRepo feedbackinterface.MessageRepository,
+	feedbackRepo feedbackinterface.FeedbackRepository,
+	organizationRepo organizationinterface.OrganizationRepository,
+	emailService sharedServices.EmailService,
+) feedbackinterface.MessageService {
+	return &messageService{
+		messageRepo:      messageRepo,
+		feedbackRepo:     feedbackRepo,
+		organizationRepo: organizationRepo,
+		emailService:     emailService,
+	}
+}
+
+func (s *messageService) ListMessages(ctx context.Context, accountID, organizationID, feedbackID uuid.UUID) ([]feedbackmodel.FeedbackMessage, error) {
+	if _, _, err := s.authorizeFeedback(ctx, accountID, organizationID, feedbackID); err != nil {
+		return nil, err
+	}
+	return s.messageRepo.FindByFeedbackID(ctx, feedbackID)
+}
+
+// Reply stores a staff message and emails it to the customer. The message is
+// kept with a failed status when the email cannot be sent, so it can be
+// resent later.
+func (s *messageService) Reply(ctx context.Context, accountID, organizationID, feedbackID, memberID uuid.UUID, body string) (*feedbackmodel.FeedbackMessage, error) {
+	organization, feedback, err := s.authorizeFeedback(ctx, accountID, organizationID, feedbackID)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err = normalizeMessageBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(feedback.CustomerEmail) == "" {
+		return nil, errors.BadRequest("This feedback has no customer email to reply to")
+	}
+
+	message := &feedbackmodel.FeedbackMessage{
+		FeedbackID:     feedback.ID,
+		OrganizationID: organization.ID,
+		Direction:      feedbackmodel.MessageDirectionOutbound,
+		Body:           body,
+		DeliveryStatus: feedbackmodel.DeliveryStatusPending,
+	}
+	if memberID != uuid.Nil {
+		message.AuthorMemberID = &memberID
+	}
+
+	token, err := assignReplyToken(message)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.messageRepo.Create(ctx, message); err != nil {
+		return nil, err
+	}
+
+	return s.deliver(ctx, organization, feedback, message, token)
+}
+
+// Resend retries a staff message that could not be delivered. A new reply
+// link is issued since the previous one was never received.
+func (s *messageService) Resend(ctx context.Context, accountID, organizationID, feedbackID, messageID uuid.UUID) (*feedbackmodel.FeedbackMessage, error) {
+	organization, feedback, err := s.authorizeFeedback(ctx, accountID, organizationID, feedbackID)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil || message.FeedbackID != feedback.ID {
+		return nil, errors.NotFound("Message")
+	}
+
+	if message.Direction != feedbackmodel.MessageDirectionOutbound || message.DeliveryStatus != feedbackmodel.DeliveryStatusFailed {
+		return nil, errors.New("MESSAGE_NOT_FAILED", "Only replies that failed to send can be resent", http.StatusConflict)
+	}
+
+	token, err := assignReplyToken(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.deliver(ctx, organization, feedback, message, token)
+}
+
+func (s *messageService) GetPublicThread(ctx context.Context, token string) (*feedbackmodel.PublicThread, error) {
+	message, err := s.findByReplyToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.publicThread(ctx, message)
+}
+
+// ReplyAsCustomer adds the customer's answer to the conversation.
+func (s *messageService) ReplyAsCustomer(ctx context.Context, token string, body string) (*feedbackmodel.PublicThread, error) {
+	message, err := s.findByReplyToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.ReplyTokenExpiresAt != nil && time.Now().After(*message.ReplyTokenExpiresAt) {
+		return nil, errors.New("REPLY_LINK_EXPIRED", "This reply link has expired", http.StatusGone)
+	}
+
+	body, err = normalizeMessageBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	answer := &feedbackmodel.FeedbackMessage{
+		FeedbackID:     message.FeedbackID,
+		OrganizationID: message.OrganizationID,
+		Direction:      feedbackmodel.MessageDirectionInbound,
+		Body:           body,
+		DeliveryStatus: feedbackmodel.DeliveryStatusReceived,
+		SentAt:         &now,
+	}
+
+	if err := s.messageRepo.Create(ctx, answer); err != nil {
+		return nil, err
+	}
+
+	return s.publicThread(ctx, message)
+}
+
+func (s *messageService) deliver(ctx context.Context, organization *organizationmodel.Organization, feedback *feedbackmodel.Feedback, message *feedbackmodel.FeedbackMessage, token string) (*feedbackmodel.FeedbackMessage, error) {
+	if err := s.emailService.SendFeedbackReply(ctx, feedback.CustomerEmail, organization.Name, message.Body, token); err != nil {
+		logger.Error("Failed to send feedback reply", err, logrus.Fields{
+			"feedback_id": feedback.ID,
+			"message_id":  message.ID,
+		})
+		message.DeliveryStatus = feedbackmodel.DeliveryStatusFailed
+		message.DeliveryError = err.Error()
+	} else {
+		now := time.Now()
+		message.DeliveryStatus = feedbackmodel.DeliveryStatusSent
+		message.DeliveryError = ""
+		message.SentAt = &now
+	}
+
+	if err := s.messageRepo.Update(ctx, message); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+func (s *messageService) publicThread(ctx context.Context, message *feedbackmodel.FeedbackMessage) (*feedbackmodel.PublicThread, error) {
+	organization, err := s.organizationRepo.FindByID(ctx, message.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := s.messageRepo.FindByFeedbackID(ctx, message.FeedbackID)
+	if err != nil {
+		return nil, err
+	}
+
+	thread := &feedbackmodel.PublicThread{
+		OrganizationName: organization.Name,
+		CanReply:         message.ReplyTokenExpiresAt == nil || time.Now().Before(*message.ReplyTokenExpiresAt),
+		Messages:         make([]feedbackmodel.PublicMessage, 0, len(messages)),
+	}
+	for _, m := range messages {
+		// Replies that never reached the customer are not part of their view
+		if m.Direction == feedbackmodel.MessageDirectionOutbound && m.DeliveryStatus != feedbackmodel.DeliveryStatusSent {
+			continue
+		}
+		thread.Messages = append(thread.Messages, feedbackmodel.PublicMessage{
+			Direction: m.Direction,
+			Body:      m.Body,
+			CreatedAt: m.CreatedAt,
+		})
+	}
+
+	return thread, nil
+}
+
+func (s *messageService) findByReplyToken(ctx context.Context, token string) (*feedbackmodel.FeedbackMessage, error) {
+	if token == "" {
+		return nil, errors.NotFound("Conversation")
+	}
+
+	message, err := s.messageRepo.FindByReplyTokenHash(ctx, feedbackmodel.HashSessionToken(token))
+	if err != nil {
+		if err == sharedRepos.ErrRecordNotFound {
+			return nil, errors.NotFound("Conversation")
+		}
+		return nil, err
+	}
+
+	return message, nil
+}
+
+func (s *messageService) authorizeFeedback(ctx context.Context, accountID, organizationID, feedbackID uuid.UUID) (*organizationmodel.Organization, *feedbackmodel.Feedback, error) {
+	organization, err := s.organizationRepo.FindByID(ctx, organizationID)
+	if err != nil {
+		return nil, nil, errors.NotFound("Organization")
+	}
+
+	if organization.AccountID != accountID {
+		return nil, nil, errors.Forbidden("reply to feedback for this organization")
+	}
+
+	feedback, err := s.feedbackRepo.FindByID(ctx, feedbackID)
+	if err != nil || feedback.OrganizationID != organizationID {
+		return nil, nil, errors.NotFound("Feedback")
+	}
+
+	return organization, feedback, nil
+}
+
+func assignReplyToken(message *feedbackmodel.FeedbackMessage) (string, error) {
+	token, tokenHash, err := feedbackmodel.GenerateReplyToken()
+	if err != nil {
+		return "", err
+	}
+	expiresAt := time.Now().Add(feedbackmodel.ReplyLinkTTL)
+	message.ReplyTokenHash = &tokenHash
+	message.ReplyTokenExpiresAt = &expiresAt
+	return token, nil
+}
+
+func normalizeMessageBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", errors.BadRequest("Message cannot be empty")
+	}
+	if len([]rune(body)) > feedbackmodel.MaxMessageLength {
+		return "", errors.BadRequest(fmt.Sprintf("Message must be at most %d characters", feedbackmodel.MaxMessageLength))
+	}
+	return body, nil
+}
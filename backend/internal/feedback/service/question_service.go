@@ -10,23 +10,28 @@ import (
 	feedbackmodel "kyooar/internal/feedback/model"
 	menuRepos "kyooar/internal/product/repositories"
 	organizationinterface "kyooar/internal/organization/interface"
+	"kyooar/internal/shared/logger"
+	"github.com/sirupsen/logrus"
 )
 
 type questionService struct {
 	questionRepo     feedbackinterface.QuestionRepository
 	productRepo      menuRepos.ProductRepository
 	organizationRepo organizationinterface.OrganizationRepository
+	versionService   feedbackinterface.VersionService
 }
 
 func NewQuestionService(
 	questionRepo feedbackinterface.QuestionRepository,
 	productRepo menuRepos.ProductRepository,
 	organizationRepo organizationinterface.OrganizationRepository,
+	versionService feedbackinterface.VersionService,
 ) feedbackinterface.QuestionService {
 	return &questionService{
 		questionRepo:     questionRepo,
 		productRepo:      productRepo,
 		organizationRepo: organizationRepo,
+		versionService:   versionService,
 	}
 }
 
@@ -55,13 +60,29 @@ func (s *questionService) CreateQuestion(ctx context.Context, accountID, product
 		MaxValue:     request.MaxValue,
 		MinLabel:     request.MinLabel,
 		MaxLabel:     request.MaxLabel,
+		DisplayRules: request.DisplayRules,
+		Translations: request.Translations.Normalized(),
 		DisplayOrder: 0,
 	}
 
+	if err := question.ValidateDefinition(); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := question.ValidateTranslations(); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := s.validateDisplayRules(ctx, question); err != nil {
+		return nil, err
+	}
+
 	if err := s.questionRepo.Create(ctx, question); err != nil {
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to create question")
 	}
 
+	s.recordVersion(ctx, productID)
+
 	return question, nil
 }
 
@@ -160,11 +181,31 @@ func (s *questionService) UpdateQuestion(ctx context.Context, accountID, questio
 	question.MaxValue = request.MaxValue
 	question.MinLabel = request.MinLabel
 	question.MaxLabel = request.MaxLabel
+	if request.DisplayRules != nil {
+		question.DisplayRules = request.DisplayRules
+	}
+	if request.Translations != nil {
+		question.Translations = request.Translations.Normalized()
+	}
+
+	if err := question.ValidateDefinition(); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := question.ValidateTranslations(); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := s.validateDisplayRules(ctx, question); err != nil {
+		return nil, err
+	}
 
 	if err := s.questionRepo.Update(ctx, question); err != nil {
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to update question")
 	}
 
+	s.recordVersion(ctx, question.ProductID)
+
 	return question, nil
 }
 
@@ -188,7 +229,12 @@ func (s *questionService) DeleteQuestion(ctx context.Context, accountID, questio
 		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
 	}
 
-	return s.questionRepo.Delete(ctx, questionID)
+	if err := s.questionRepo.Delete(ctx, questionID); err != nil {
+		return err
+	}
+
+	s.recordVersion(ctx, question.ProductID)
+	return nil
 }
 
 func (s *questionService) ReorderQuestions(ctx context.Context, accountID, productID uuid.UUID, questionIDs []uuid.UUID) error {
@@ -206,7 +252,12 @@ func (s *questionService) ReorderQuestions(ctx context.Context, accountID, produ
 		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
 	}
 
-	return s.questionRepo.ReorderQuestions(ctx, productID, questionIDs)
+	if err := s.questionRepo.ReorderQuestions(ctx, productID, questionIDs); err != nil {
+		return err
+	}
+
+	s.recordVersion(ctx, productID)
+	return nil
 }
 
 func (s *questionService) GetProductsWithQuestions(ctx context.Context, accountID, organizationID uuid.UUID) ([]uuid.UUID, error) {
@@ -224,4 +275,47 @@ func (s *questionService) GetProductsWithQuestions(ctx context.Context, accountI
 
 func (s *questionService) GetQuestionsByProductIDForAnalytics(ctx context.Context, productID uuid.UUID) ([]*feedbackmodel.Question, error) {
 	return s.questionRepo.GetQuestionsByProductIDForAnalytics(ctx, productID)
-}
\ No newline at end of file
+}
+
+// recordVersion snapshots the product's questions after an edit. A failure is
+// not fatal: the next submission records the version before pinning to it.
+func (s *questionService) recordVersion(ctx context.Context, productID uuid.UUID) {
+	if _, err := s.versionService.RecordVersion(ctx, productID); err != nil {
+		logger.Error("Failed to record questionnaire version", err, logrus.Fields{
+			"product_id": productID,
+		})
+	}
+}
+
+func (s *questionService) validateDisplayRules(ctx context.Context, question *feedbackmodel.Question) error {
+	if len(question.DisplayRules) == 0 {
+		return nil
+	}
+
+	siblings, err := s.questionRepo.FindByProductID(ctx, question.ProductID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load questions")
+	}
+
+	productQuestions := make(map[uuid.UUID]bool, len(siblings))
+	for _, sibling := range siblings {
+		productQuestions[sibling.ID] = true
+	}
+
+	for _, rule := range question.DisplayRules {
+		if err := rule.Validate(); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid display rule: "+err.Error())
+		}
+		if rule.QuestionID == nil || *rule.QuestionID == uuid.Nil || *rule.QuestionID == question.ID {
+			if rule.Action == feedbackmodel.DisplayRuleShowIf {
+				return echo.NewHTTPError(http.StatusBadRequest, "Invalid display rule: show_if must refer to another question")
+			}
+			continue
+		}
+		if !productQuestions[*rule.QuestionID] {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid display rule: referenced question does not belong to this product")
+		}
+	}
+
+	return nil
+}
@@ -8,20 +8,32 @@ import (
 	aiServices "kyooar/internal/ai/services"
 	feedbackinterface "kyooar/internal/feedback/interface"
 	feedbackmodel "kyooar/internal/feedback/model"
+	organizationinterface "kyooar/internal/organization/interface"
 	productModels "kyooar/internal/product/models"
+	menuRepos "kyooar/internal/product/repositories"
+	"kyooar/internal/shared/errors"
 )
 
 type questionnaireService struct {
 	repo              feedbackinterface.QuestionnaireRepository
+	questionRepo      feedbackinterface.QuestionRepository
+	productRepo       menuRepos.ProductRepository
+	organizationRepo  organizationinterface.OrganizationRepository
 	questionGenerator *aiServices.QuestionGenerator
 }
 
 func NewQuestionnaireService(
 	repo feedbackinterface.QuestionnaireRepository,
+	questionRepo feedbackinterface.QuestionRepository,
+	productRepo menuRepos.ProductRepository,
+	organizationRepo organizationinterface.OrganizationRepository,
 	generator *aiServices.QuestionGenerator,
 ) feedbackinterface.QuestionnaireService {
 	return &questionnaireService{
 		repo:              repo,
+		questionRepo:      questionRepo,
+		productRepo:       productRepo,
+		organizationRepo:  organizationRepo,
 		questionGenerator: generator,
 	}
 }
@@ -165,4 +177,65 @@ func (s *questionnaireService) GenerateAndSaveQuestionnaireForProduct(ctx contex
 	}
 
 	return questionnaire, nil
-}
\ No newline at end of file
+}
+
+func (s *questionnaireService) GetPublicQuestionnaire(ctx context.Context, organizationID, productID uuid.UUID, languages []string) (*feedbackmodel.PublicQuestionnaire, error) {
+	organization, err := s.organizationRepo.FindByID(ctx, organizationID)
+	if err != nil || !organization.IsActive {
+		return nil, errors.NotFound("Organization")
+	}
+
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil || product.OrganizationID != organizationID || !product.IsActive {
+		return nil, errors.NotFound("Product")
+	}
+
+	questionnaire, err := s.repo.FindActiveByProductID(ctx, productID)
+	if err != nil {
+		questionnaire, err = s.repo.FindDefaultByOrganizationID(ctx, organizationID)
+		if err != nil {
+			questionnaire = nil
+		}
+	}
+
+	questions, err := s.questionRepo.GetQuestionsByProductID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultLanguage := organization.Settings.Language
+	available := feedbackmodel.AvailableLanguages(questions, defaultLanguage)
+	language := feedbackmodel.SelectLanguage(languages, available, defaultLanguage)
+
+	return &feedbackmodel.PublicQuestionnaire{
+		Questionnaire: questionnaire,
+		Organization:  organization.PublicBranding(),
+		Product:       product,
+		Questions:     localizeQuestions(questions, language),
+		Language:      language,
+		Languages:     available,
+	}, nil
+}
+
+// LocalizeQuestions words the questions in the first of the requested
+// languages they are available in, falling back to the organization's
+// default language, and returns the language that was used.
+func (s *questionnaireService) LocalizeQuestions(ctx context.Context, organizationID uuid.UUID, questions []*feedbackmodel.Question, languages []string) ([]*feedbackmodel.Question, string, error) {
+	organization, err := s.organizationRepo.FindByID(ctx, organizationID)
+	if err != nil {
+		return nil, "", errors.NotFound("Organization")
+	}
+
+	defaultLanguage := organization.Settings.Language
+	available := feedbackmodel.AvailableLanguages(questions, defaultLanguage)
+	language := feedbackmodel.SelectLanguage(languages, available, defaultLanguage)
+	return localizeQuestions(questions, language), language, nil
+}
+
+func localizeQuestions(questions []*feedbackmodel.Question, language string) []*feedbackmodel.Question {
+	localized := make([]*feedbackmodel.Question, 0, len(questions))
+	for _, question := range questions {
+		localized = append(localized, question.Localize(language))
+	}
+	return localized
+}
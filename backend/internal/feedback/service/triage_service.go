@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	authinterface "kyooar/internal/auth/interface"
+	feedbackinterface "kyooar/internal/feedback/interface"
+	feedbackmodel "kyooar/internal/feedback/model"
+	organizationinterface "kyooar/internal/organization/interface"
+	"kyooar/internal/shared/errors"
+)
+
+type triageService struct {
+	triageRepo       feedbackinterface.TriageRepository
+	feedbackRepo     feedbackinterface.FeedbackRepository
+	organizationRepo organizationinterface.OrganizationRepository
+	teamMemberRepo   authinterface.TeamMemberRepository
+}
+
+func NewTriageService(
+	triageRepo feedbackinterface.TriageRepository,
+	feedbackRepo feedbackinterface.FeedbackRepository,
+	organizationRepo organizationinterface.OrganizationRepository,
+	teamMemberRepo authinterface.TeamMemberRepository,
+) feedbackinterface.TriageService {
+	return &triageService{
+		triageRepo:       triageRepo,
+		feedbackRepo:     feedbackRepo,
+		organizationRepo: organizationRepo,
+		teamMemberRepo:   teamMemberRepo,
+	}
+}
+
+func (s *triageService) UpdateStatus(ctx context.Context, accountID, organizationID, feedbackID, actorID uuid.UUID, status feedbackmodel.FeedbackStatus) (*feedbackmodel.Feedback, error) {
+	if !status.IsValid() {
+		return nil, errors.BadRequest("Status must be one of new, in_progress, resolved or dismissed")
+	}
+
+	feedback, err := s.authorizeFeedback(ctx, accountID, organizationID, feedbackID)
+	if err != nil {
+		return nil, err
+	}
+
+	if feedback.Status == status {
+		return feedback, nil
+	}
+
+	activity := newActivity(feedback, actorID, feedbackmodel.ActivityStatusChanged, string(feedback.Status), string(status))
+	if err := s.triageRepo.UpdateFeedback(ctx, feedback.ID, map[string]interface{}{"status": status}, activity); err != nil {
+		return nil, err
+	}
+
+	feedback.Status = status
+	return feedback, nil
+}
+
+// Assign hands the feedback to a team member of the organization's account,
+// or clears the assignment when assigneeID is nil.
+func (s *triageService) Assign(ctx context.Context, accountID, organizationID, feedbackID, actorID uuid.UUID, assigneeID *uuid.UUID) (*feedbackmodel.Feedback, error) {
+	feedback, err := s.authorizeFeedback(ctx, accountID, organizationID, feedbackID)
+	if err != nil {
+		return nil, err
+	}
+
+	if assigneeID != nil {
+		member, err := s.teamMemberRepo.FindByID(ctx, *assigneeID)
+		if err != nil || member.AccountID != accountID {
+			return nil, errors.BadRequest("Assignee must be a member of this team")
+		}
+	}
+
+	if equalUUIDPtr(feedback.AssigneeID, assigneeID) {
+		return feedback, nil
+	}
+
+	action := feedbackmodel.ActivityAssigned
+	if assigneeID == nil {
+		action = feedbackmodel.ActivityUnassigned
+	}
+	var assignee interface{}
+	if assigneeID != nil {
+		assignee = *assigneeID
+	}
+	activity := newActivity(feedback, actorID, action, uuidPtrString(feedback.AssigneeID), uuidPtrString(assigneeID))
+	if err := s.triageRepo.UpdateFeedback(ctx, feedback.ID, map[string]interface{}{"assignee_id": assignee}, activity); err != nil {
+		return nil, err
+	}
+
+	feedback.AssigneeID = assigneeID
+	return feedback, nil
+}
+
+// UpdateTags replaces the feedback's tags with the given set.
+func (s *triageService) UpdateTags(ctx context.Context, accountID, organizationID, feedbackID, actorID uuid.UUID, tags []string) (*feedbackmodel.Feedback, error) {
+	normalized, err := feedbackmodel.NormalizeTags(tags)
+	if err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	feedback, err := s.authorizeFeedback(ctx, accountID, organizationID, feedbackID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := strings.Join(feedback.Tags, ",")
+	current := strings.Join(normalized, ",")
+	if previous == current {
+		return feedback, nil
+	}
+
+	activity := newActivity(feedback, actorID, feedbackmodel.ActivityTagsChanged, previous, current)
+	if err := s.triageRepo.UpdateFeedback(ctx, feedback.ID, map[string]interface{}{"tags": pq.StringArray(normalized)}, activity); err != nil {
+		return nil, err
+	}
+
+	feedback.Tags = normalized
+	return feedback, nil
+}
+
+func (s *triageService) AddNote(ctx context.Context, accountID, organizationID, feedbackID, authorID uuid.UUID, body string) (*feedbackmodel.FeedbackNote, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, errors.BadRequest("Note cannot be empty")
+	}
+	if len([]rune(body)) > feedbackmodel.MaxNoteLength {
+		return nil, errors.BadRequest("Note is too long")
+	}
+
+	feedback, err := s.authorizeFeedback(ctx, accountID, organizationID, feedbackID)
+	if err != nil {
+		return nil, err
+	}
+
+	note := &feedbackmodel.FeedbackNote{
+		FeedbackID:     feedback.ID,
+		OrganizationID: feedback.OrganizationID,
+		AuthorID:       authorID,
+		Body:           body,
+	}
+	activity := newActivity(feedback, authorID, feedbackmodel.ActivityNoteAdded, "", "")
+	if err := s.triageRepo.CreateNote(ctx, note, activity); err != nil {
+		return nil, err
+	}
+
+	return note, nil
+}
+
+func (s *triageService) ListNotes(ctx context.Context, accountID, organizationID, feedbackID uuid.UUID) ([]feedbackmodel.FeedbackNote, error) {
+	if _, err := s.authorizeFeedback(ctx, accountID, organizationID, feedbackID); err != nil {
+		return nil, err
+	}
+	return s.triageRepo.FindNotesByFeedbackID(ctx, feedbackID)
+}
+
+func (s *triageService) ListActivity(ctx context.Context, accountID, organizationID, feedbackID uuid.UUID) ([]feedbackmodel.FeedbackActivity, error) {
+	if _, err := s.authorizeFeedback(ctx, accountID, organizationID, feedbackID); err != nil {
+		return nil, err
+	}
+	return s.triageRepo.FindActivityByFeedbackID(ctx, feedbackID, feedbackmodel.DefaultActivityLimit)
+}
+
+func (s *triageService) authorizeFeedback(ctx context.Context, accountID, organizationID, feedbackID uuid.UUID) (*feedbackmodel.Feedback, error) {
+	organization, err := s.organizationRepo.FindByID(ctx, organizationID)
+	if err != nil {
+		return nil, errors.NotFound("Organization")
+	}
+
+	if organization.AccountID != accountID {
+		return nil, errors.Forbidden("manage feedback for this organization")
+	}
+
+	feedback, err := s.feedbackRepo.FindByID(ctx, feedbackID)
+	if err != nil || feedback.OrganizationID != organizationID {
+		return nil, errors.NotFound("Feedback")
+	}
+
+	return feedback, nil
+}
+
+func newActivity(feedback *feedbackmodel.Feedback, actorID uuid.UUID, action feedbackmodel.ActivityAction, from, to string) *feedbackmodel.FeedbackActivity {
+	activity := &feedbackmodel.FeedbackActivity{
+		FeedbackID:     feedback.ID,
+		OrganizationID: feedback.OrganizationID,
+		Action:         action,
+		FromValue:      from,
+		ToValue:        to,
+	}
+	if actorID != uuid.Nil {
+		activity.ActorID = &actorID
+	}
+	return activity
+}
+
+func equalUUIDPtr(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func uuidPtrString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	feedbackinterface "kyooar/internal/feedback/interface"
+	feedbackmodel "kyooar/internal/feedback/model"
+	organizationinterface "kyooar/internal/organization/interface"
+	productModels "kyooar/internal/product/models"
+	menuRepos "kyooar/internal/product/repositories"
+	"kyooar/internal/shared/errors"
+)
+
+type versionService struct {
+	versionRepo      feedbackinterface.VersionRepository
+	questionRepo     feedbackinterface.QuestionRepository
+	productRepo      menuRepos.ProductRepository
+	organizationRepo organizationinterface.OrganizationRepository
+}
+
+func NewVersionService(
+	versionRepo feedbackinterface.VersionRepository,
+	questionRepo feedbackinterface.QuestionRepository,
+	productRepo menuRepos.ProductRepository,
+	organizationRepo organizationinterface.OrganizationRepository,
+) feedbackinterface.VersionService {
+	return &versionService{
+		versionRepo:      versionRepo,
+		questionRepo:     questionRepo,
+		productRepo:      productRepo,
+		organizationRepo: organizationRepo,
+	}
+}
+
+// RecordVersion snapshots the product's questions as they are now stored.
+func (s *versionService) RecordVersion(ctx context.Context, productID uuid.UUID) (*feedbackmodel.QuestionnaireVersion, error) {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, errors.NotFound("Product")
+	}
+
+	stored, err := s.questionRepo.FindByProductID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	questions := make([]feedbackmodel.Question, 0, len(stored))
+	for _, question := range stored {
+		questions = append(questions, *question)
+	}
+
+	return s.CurrentVersion(ctx, product.OrganizationID, productID, questions)
+}
+
+// CurrentVersion returns the version matching the given questions, recording
+// a new one when they differ from the latest snapshot. Questions edited
+// outside the question service are picked up here on the next submission.
+func (s *versionService) CurrentVersion(ctx context.Context, organizationID, productID uuid.UUID, questions []feedbackmodel.Question) (*feedbackmodel.QuestionnaireVersion, error) {
+	snapshots := feedbackmodel.NewQuestionSnapshots(questions)
+	return s.versionRepo.CreateIfChanged(ctx, &feedbackmodel.QuestionnaireVersion{
+		OrganizationID: organizationID,
+		ProductID:      productID,
+		Hash:           snapshots.Hash(),
+		QuestionCount:  len(snapshots),
+		Questions:      snapshots,
+	})
+}
+
+func (s *versionService) ListVersions(ctx context.Context, accountID, productID uuid.UUID) ([]feedbackmodel.QuestionnaireVersion, error) {
+	if _, err := s.authorizeProduct(ctx, accountID, productID); err != nil {
+		return nil, err
+	}
+	return s.versionRepo.FindByProductID(ctx, productID)
+}
+
+func (s *versionService) GetVersion(ctx context.Context, accountID, productID uuid.UUID, version int) (*feedbackmodel.QuestionnaireVersion, error) {
+	if _, err := s.authorizeProduct(ctx, accountID, productID); err != nil {
+		return nil, err
+	}
+
+	result, err := s.versionRepo.FindByProductAndNumber(ctx, productID, version)
+	if err != nil {
+		return nil, errors.NotFound("Questionnaire version")
+	}
+	return result, nil
+}
+
+func (s *versionService) DiffVersions(ctx context.Context, accountID, productID uuid.UUID, from, to int) (*feedbackmodel.QuestionnaireVersionDiff, error) {
+	fromVersion, err := s.GetVersion(ctx, accountID, productID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toVersion, err := s.versionRepo.FindByProductAndNumber(ctx, productID, to)
+	if err != nil {
+		return nil, errors.NotFound("Questionnaire version")
+	}
+
+	return feedbackmodel.DiffVersions(fromVersion, toVersion), nil
+}
+
+func (s *versionService) authorizeProduct(ctx context.Context, accountID, productID uuid.UUID) (*productModels.Product, error) {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, errors.NotFound("Product")
+	}
+
+	organization, err := s.organizationRepo.FindByID(ctx, product.OrganizationID)
+	if err != nil {
+		return nil, errors.NotFound("Organization")
+	}
+
+	if organization.AccountID != accountID {
+		return nil, errors.Forbidden("access this product")
+	}
+
+	return product, nil
+}
@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	authinterface "kyooar/internal/auth/interface"
+	feedbackinterface "kyooar/internal/feedback/interface"
+	feedbackmodel "kyooar/internal/feedback/model"
+	organizationinterface "kyooar/internal/organization/interface"
+	organizationmodel "kyooar/internal/organization/model"
+	"kyooar/internal/shared/errors"
+	"kyooar/internal/shared/logger"
+	sharedModels "kyooar/internal/shared/models"
+	sharedServices "kyooar/internal/shared/services"
+)
+
+// maxDigestExcerptLength bounds the answer text quoted in a digest email.
+const maxDigestExcerptLength = 200
+
+type viewService struct {
+	viewRepo         feedbackinterface.ViewRepository
+	feedbackRepo     feedbackinterface.FeedbackRepository
+	organizationRepo organizationinterface.OrganizationRepository
+	accountRepo      authinterface.AccountRepository
+	teamMemberRepo   authinterface.TeamMemberRepository
+	emailService     sharedServices.EmailService
+}
+
+func NewViewService(
+	viewRepo feedbackinterface.ViewRepository,
+	feedbackRepo feedbackinterface.FeedbackRepository,
+	organizationRepo organizationinterface.OrganizationRepository,
+	accountRepo authinterface.AccountRepository,
+	teamMemberRepo authinterface.TeamMemberRepository,
+	emailService sharedServices.EmailService,
+) feedbackinterface.ViewService {
+	return &viewService{
+		viewRepo:         viewRepo,
+		feedbackRepo:     feedbackRepo,
+		organizationRepo: organizationRepo,
+		accountRepo:      accountRepo,
+		teamMemberRepo:   teamMemberRepo,
+		emailService:     emailService,
+	}
+}
+
+func (s *viewService) CreateView(ctx context.Context, accountID, organizationID, memberID uuid.UUID, req *feedbackmodel.SaveFeedbackViewRequest) (*feedbackmodel.FeedbackView, error) {
+	if err := req.Validate(); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+	if _, err := s.authorize(ctx, accountID, organizationID); err != nil {
+		return nil, err
+	}
+
+	view := &feedbackmodel.FeedbackView{
+		OrganizationID: organizationID,
+		CreatedBy:      memberID,
+	}
+	applyViewRequest(view, req)
+	if err := s.viewRepo.Create(ctx, view); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+func (s *viewService) ListViews(ctx context.Context, accountID, organizationID, memberID uuid.UUID) ([]feedbackmodel.FeedbackView, error) {
+	if _, err := s.authorize(ctx, accountID, organizationID); err != nil {
+		return nil, err
+	}
+
+	views, err := s.viewRepo.FindVisible(ctx, organizationID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := s.viewRepo.FindSubscriptionsByMember(ctx, organizationID, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	byView := make(map[uuid.UUID]*feedbackmodel.FeedbackViewSubscription, len(subscriptions))
+	for i := range subscriptions {
+		byView[subscriptions[i].ViewID] = &subscriptions[i]
+	}
+	for i := range views {
+		views[i].Subscription = byView[views[i].ID]
+	}
+	return views, nil
+}
+
+func (s *viewService) GetView(ctx context.Context, accountID, organizationID, memberID, viewID uuid.UUID) (*feedbackmodel.FeedbackView, error) {
+	if _, err := s.authorize(ctx, accountID, organizationID); err != nil {
+		return nil, err
+	}
+	view, err := s.findVisible(ctx, organizationID, memberID, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription, err := s.viewRepo.FindSubscription(ctx, viewID, memberID); err == nil {
+		view.Subscription = subscription
+	}
+	return view, nil
+}
+
+// UpdateView replaces the view's settings. Only the member who created a view
+// can change it; when it stops being shared, other members' digests stop too.
+func (s *viewService) UpdateView(ctx context.Context, accountID, organizationID, memberID, viewID uuid.UUID, req *feedbackmodel.SaveFeedbackViewRequest) (*feedbackmodel.FeedbackView, error) {
+	if err := req.Validate(); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+	view, err := s.findOwned(ctx, accountID, organizationID, memberID, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	wasShared := view.Shared
+	applyViewRequest(view, req)
+	if err := s.viewRepo.Update(ctx, view); err != nil {
+		return nil, err
+	}
+
+	if wasShared && !view.Shared {
+		if err := s.viewRepo.DeleteOtherSubscriptions(ctx, view.ID, view.CreatedBy); err != nil {
+			return nil, err
+		}
+	}
+	return view, nil
+}
+
+func (s *viewService) DeleteView(ctx context.Context, accountID, organizationID, memberID, viewID uuid.UUID) error {
+	if _, err := s.findOwned(ctx, accountID, organizationID, memberID, viewID); err != nil {
+		return err
+	}
+	return s.viewRepo.Delete(ctx, viewID)
+}
+
+func (s *viewService) ResolveView(ctx context.Context, accountID, organizationID, memberID, viewID uuid.UUID) (*feedbackmodel.FeedbackView, feedbackmodel.FeedbackFilter, error) {
+	organization, err := s.authorize(ctx, accountID, organizationID)
+	if err != nil {
+		return nil, feedbackmodel.FeedbackFilter{}, err
+	}
+	view, err := s.findVisible(ctx, organizationID, memberID, viewID)
+	if err != nil {
+		return nil, feedbackmodel.FeedbackFilter{}, err
+	}
+	return view, view.ResolvedFilters(time.Now(), organizationLocation(organization)), nil
+}
+
+func (s *viewService) Subscribe(ctx context.Context, accountID, organizationID, memberID, viewID uuid.UUID, frequency feedbackmodel.DigestFrequency) (*feedbackmodel.FeedbackViewSubscription, error) {
+	if !frequency.IsValid() {
+		return nil, errors.BadRequest("Frequency must be daily or weekly")
+	}
+	if _, err := s.authorize(ctx, accountID, organizationID); err != nil {
+		return nil, err
+	}
+	if _, err := s.findVisible(ctx, organizationID, memberID, viewID); err != nil {
+		return nil, err
+	}
+
+	subscription, err := s.viewRepo.FindSubscription(ctx, viewID, memberID)
+	if err != nil {
+		// The first digest covers the time since subscribing.
+		now := time.Now()
+		subscription = &feedbackmodel.FeedbackViewSubscription{
+			ViewID:     viewID,
+			MemberID:   memberID,
+			LastSentAt: &now,
+		}
+	}
+	subscription.Frequency = frequency
+
+	if err := s.viewRepo.SaveSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *viewService) Unsubscribe(ctx context.Context, accountID, organizationID, memberID, viewID uuid.UUID) error {
+	if _, err := s.authorize(ctx, accountID, organizationID); err != nil {
+		return err
+	}
+	subscription, err := s.viewRepo.FindSubscription(ctx, viewID, memberID)
+	if err != nil {
+		return errors.NotFound("Subscription")
+	}
+	return s.viewRepo.DeleteSubscription(ctx, subscription.ID)
+}
+
+// SendDigests sends the digests that are due. A digest is only sent when new
+// feedback matches the view; either way the window moves on. Subscriptions
+// of members who left the team are removed.
+func (s *viewService) SendDigests(ctx context.Context) error {
+	subscriptions, err := s.viewRepo.FindSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if subscription.View == nil || !subscription.IsDue(now) {
+			continue
+		}
+		if err := s.sendDigest(ctx, subscription, now); err != nil {
+			logger.Error("Failed to send feedback digest", err, logrus.Fields{
+				"subscription_id": subscription.ID,
+				"view_id":         subscription.ViewID,
+			})
+		}
+	}
+	return nil
+}
+
+func (s *viewService) sendDigest(ctx context.Context, subscription *feedbackmodel.FeedbackViewSubscription, now time.Time) error {
+	view := subscription.View
+
+	organization, err := s.organizationRepo.FindByID(ctx, view.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if !s.isMember(ctx, organization.AccountID, subscription.MemberID) {
+		return s.viewRepo.DeleteSubscription(ctx, subscription.ID)
+	}
+	account, err := s.accountRepo.FindByID(ctx, subscription.MemberID)
+	if err != nil {
+		return err
+	}
+
+	since := now.Add(-subscription.Frequency.Interval())
+	if subscription.LastSentAt != nil {
+		since = *subscription.LastSentAt
+	}
+
+	// The digest window replaces the view's own period. Quarantined feedback
+	// is left out even if the view asks for it, since it may be spam or abuse
+	// that nobody has reviewed yet, and so are sessions still being filled in.
+	filters := view.Filters
+	filters.DateFrom = &since
+	filters.DateTo = nil
+	notQuarantined := false
+	filters.IsQuarantined = &notQuarantined
+	complete := true
+	filters.IsComplete = &complete
+
+	page, err := s.feedbackRepo.FindByOrganizationIDWithFilters(ctx, organization.AccountID, organization.ID, sharedModels.PageRequest{
+		Limit:        feedbackmodel.MaxDigestItems,
+		Sort:         string(view.Sort),
+		IncludeTotal: true,
+	}, filters)
+	if err != nil {
+		return err
+	}
+
+	if page.Total > 0 {
+		location := organizationLocation(organization)
+		digest := sharedServices.FeedbackDigestEmail{
+			OrganizationName: organization.Name,
+			ViewName:         view.Name,
+			ViewID:           view.ID.String(),
+			Since:            since.In(location),
+			Total:            page.Total,
+		}
+		for _, feedback := range page.Data {
+			digest.Items = append(digest.Items, sharedServices.FeedbackDigestItem{
+				ProductName: feedback.Product.Name,
+				Rating:      feedback.OverallRating,
+				Customer:    feedback.CustomerName,
+				Excerpt:     digestExcerpt(&feedback),
+				CreatedAt:   feedback.CreatedAt.In(location),
+			})
+		}
+		if err := s.emailService.SendFeedbackDigest(ctx, account.Email, digest); err != nil {
+			return err
+		}
+	}
+
+	return s.viewRepo.MarkDigestSent(ctx, subscription.ID, now)
+}
+
+func (s *viewService) isMember(ctx context.Context, accountID, memberID uuid.UUID) bool {
+	if accountID == memberID {
+		return true
+	}
+	member, err := s.teamMemberRepo.FindByAccountAndMember(ctx, accountID, memberID)
+	return err == nil && member != nil
+}
+
+func (s *viewService) authorize(ctx context.Context, accountID, organizationID uuid.UUID) (*organizationmodel.Organization, error) {
+	organization, err := s.organizationRepo.FindByID(ctx, organizationID)
+	if err != nil {
+		return nil, errors.NotFound("Organization")
+	}
+	if organization.AccountID != accountID {
+		return nil, errors.Forbidden("access feedback views for this organization")
+	}
+	return organization, nil
+}
+
+// findVisible returns the view when it is shared or belongs to the member.
+// Other members' private views are reported as missing.
+func (s *viewService) findVisible(ctx context.Context, organizationID, memberID, viewID uuid.UUID) (*feedbackmodel.FeedbackView, error) {
+	view, err := s.viewRepo.FindByID(ctx, viewID)
+	if err != nil || view.OrganizationID != organizationID || (!view.Shared && view.CreatedBy != memberID) {
+		return nil, errors.NotFound("View")
+	}
+	return view, nil
+}
+
+func (s *viewService) findOwned(ctx context.Context, accountID, organizationID, memberID, viewID uuid.UUID) (*feedbackmodel.FeedbackView, error) {
+	if _, err := s.authorize(ctx, accountID, organizationID); err != nil {
+		return nil, err
+	}
+	view, err := s.findVisible(ctx, organizationID, memberID, viewID)
+	if err != nil {
+		return nil, err
+	}
+	if view.CreatedBy != memberID {
+		return nil, errors.Forbidden("change a view created by someone else")
+	}
+	return view, nil
+}
+
+func applyViewRequest(view *feedbackmodel.FeedbackView, req *feedbackmodel.SaveFeedbackViewRequest) {
+	view.Name = req.Name
+	view.Shared = req.Shared
+	view.Filters = req.Filters
+	view.Period = req.Period
+	view.Sort = req.Sort
+	view.Columns = pq.StringArray(req.Columns)
+	if view.Columns == nil {
+		view.Columns = pq.StringArray{}
+	}
+}
+
+// organizationLocation is the organization's configured timezone, used to
+// decide where days and weeks start.
+func organizationLocation(organization *organizationmodel.Organization) *time.Location {
+	if organization.Settings.Timezone != "" {
+		if location, err := time.LoadLocation(organization.Settings.Timezone); err == nil {
+			return location
+		}
+	}
+	return time.UTC
+}
+
+// digestExcerpt quotes the first written answer of the feedback.
+func digestExcerpt(feedback *feedbackmodel.Feedback) string {
+	for _, response := range feedback.Responses {
+		if response.QuestionType != feedbackmodel.QuestionTypeText {
+			continue
+		}
+		if text, ok := response.Answer.(string); ok && text != "" {
+			runes := []rune(text)
+			if len(runes) > maxDigestExcerptLength {
+				return string(runes[:maxDigestExcerptLength]) + "…"
+			}
+			return text
+		}
+	}
+	return ""
+}
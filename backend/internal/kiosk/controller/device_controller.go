@@ -0,0 +1,182 @@
+package kioskcontroller
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	kioskinterface "kyooar/internal/kiosk/interface"
+	kioskmodel "kyooar/internal/kiosk/model"
+	"kyooar/internal/shared/errors"
+	"kyooar/internal/shared/logger"
+	"kyooar/internal/shared/middleware"
+	"kyooar/internal/shared/response"
+	"kyooar/internal/shared/validator"
+	"github.com/sirupsen/logrus"
+)
+
+type DeviceController struct {
+	deviceService kioskinterface.DeviceService
+	validator     *validator.Validator
+}
+
+func NewDeviceController(deviceService kioskinterface.DeviceService) *DeviceController {
+	return &DeviceController{
+		deviceService: deviceService,
+		validator:     validator.New(),
+	}
+}
+
+// @Summary Register kiosk device
+// @Description Register a kiosk tablet under an organization. The returned one-time pairing code is shown only once and must be entered on the tablet before it expires.
+// @Tags kiosk-devices
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param device body kioskmodel.CreateDeviceRequest true "Device information"
+// @Success 200 {object} response.Response{data=kioskmodel.PairingCode}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/kiosk-devices [post]
+func (h *DeviceController) Register(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return response.Error(c, errors.ErrBadRequest)
+	}
+
+	var req kioskmodel.CreateDeviceRequest
+	if err := c.Bind(&req); err != nil {
+		return response.Error(c, errors.ErrBadRequest)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		return response.Error(c, errors.NewWithDetails("VALIDATION_ERROR", "Validation failed", http.StatusBadRequest, h.validator.FormatErrors(err)))
+	}
+
+	resourceAccountID := middleware.GetResourceAccountID(c)
+
+	pairing, err := h.deviceService.Register(ctx, resourceAccountID, organizationID, &req)
+	if err != nil {
+		logger.Error("Failed to register kiosk device", err, logrus.Fields{
+			"account_id":      resourceAccountID,
+			"organization_id": organizationID,
+		})
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, pairing)
+}
+
+// @Summary Get kiosk devices by organization
+// @Description List the kiosk devices of an organization with their health: status, last seen and submissions since midnight in the organization's timezone
+// @Tags kiosk-devices
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Success 200 {object} response.Response{data=[]kioskmodel.DeviceHealth}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/kiosk-devices [get]
+func (h *DeviceController) GetByOrganization(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return response.Error(c, errors.ErrBadRequest)
+	}
+
+	resourceAccountID := middleware.GetResourceAccountID(c)
+
+	devices, err := h.deviceService.GetByOrganizationID(ctx, resourceAccountID, organizationID)
+	if err != nil {
+		logger.Error("Failed to get kiosk devices", err, logrus.Fields{
+			"account_id":      resourceAccountID,
+			"organization_id": organizationID,
+		})
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, devices)
+}
+
+// @Summary Regenerate pairing code
+// @Description Issue a new one-time pairing code for a kiosk device, for example to replace its tablet
+// @Tags kiosk-devices
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Device ID"
+// @Success 200 {object} response.Response{data=kioskmodel.PairingCode}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/kiosk-devices/{id}/pairing-code [post]
+func (h *DeviceController) RegeneratePairingCode(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return response.Error(c, errors.ErrBadRequest)
+	}
+
+	resourceAccountID := middleware.GetResourceAccountID(c)
+
+	pairing, err := h.deviceService.RegeneratePairingCode(ctx, resourceAccountID, deviceID)
+	if err != nil {
+		logger.Error("Failed to regenerate kiosk pairing code", err, logrus.Fields{
+			"account_id": resourceAccountID,
+			"device_id":  deviceID,
+		})
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, pairing)
+}
+
+// @Summary Revoke kiosk device
+// @Description Revoke a kiosk device, for example a lost tablet. Its device token stops working immediately.
+// @Tags kiosk-devices
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Device ID"
+// @Success 200 {object} response.Response{data=kioskmodel.KioskDevice}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/kiosk-devices/{id}/revoke [post]
+func (h *DeviceController) Revoke(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return response.Error(c, errors.ErrBadRequest)
+	}
+
+	resourceAccountID := middleware.GetResourceAccountID(c)
+
+	device, err := h.deviceService.Revoke(ctx, resourceAccountID, deviceID)
+	if err != nil {
+		logger.Error("Failed to revoke kiosk device", err, logrus.Fields{
+			"account_id": resourceAccountID,
+			"device_id":  deviceID,
+		})
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, device)
+}
@@ -0,0 +1,82 @@
+package kioskcontroller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	kioskinterface "kyooar/internal/kiosk/interface"
+	kioskmodel "kyooar/internal/kiosk/model"
+	"kyooar/internal/shared/errors"
+	"kyooar/internal/shared/logger"
+	"kyooar/internal/shared/response"
+	"kyooar/internal/shared/validator"
+	"github.com/sirupsen/logrus"
+)
+
+type PublicController struct {
+	deviceService kioskinterface.DeviceService
+	validator     *validator.Validator
+}
+
+func NewPublicController(deviceService kioskinterface.DeviceService) *PublicController {
+	return &PublicController{
+		deviceService: deviceService,
+		validator:     validator.New(),
+	}
+}
+
+// @Summary Pair kiosk device
+// @Description Exchange a one-time pairing code for a long-lived device token. The token is returned only once and is sent in the X-Device-Token header on feedback submissions.
+// @Tags public
+// @Accept json
+// @Produce json
+// @Param request body kioskmodel.PairDeviceRequest true "Pairing code"
+// @Success 200 {object} response.Response{data=kioskmodel.PairedDevice}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/public/kiosk/pair [post]
+func (h *PublicController) Pair(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req kioskmodel.PairDeviceRequest
+	if err := c.Bind(&req); err != nil {
+		return response.Error(c, errors.ErrBadRequest)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		return response.Error(c, errors.NewWithDetails("VALIDATION_ERROR", "Validation failed", http.StatusBadRequest, h.validator.FormatErrors(err)))
+	}
+
+	paired, err := h.deviceService.Pair(ctx, req.Code)
+	if err != nil {
+		if _, ok := errors.IsAppError(err); !ok {
+			logger.Error("Failed to pair kiosk device", err, logrus.Fields{})
+		}
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, paired)
+}
+
+// @Summary Kiosk heartbeat
+// @Description Record that a paired kiosk is online and return its configuration
+// @Tags public
+// @Produce json
+// @Param X-Device-Token header string true "Device token"
+// @Success 200 {object} response.Response{data=kioskmodel.KioskDevice}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/public/kiosk/heartbeat [post]
+func (h *PublicController) Heartbeat(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	device, err := h.deviceService.Authenticate(ctx, c.Request().Header.Get(kioskmodel.DeviceTokenHeader))
+	if err != nil {
+		if _, ok := errors.IsAppError(err); !ok {
+			logger.Error("Failed to authenticate kiosk device", err, logrus.Fields{})
+		}
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, device)
+}
@@ -0,0 +1,29 @@
+package kioskinterface
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	kioskmodel "kyooar/internal/kiosk/model"
+)
+
+type DeviceRepository interface {
+	Create(ctx context.Context, device *kioskmodel.KioskDevice) error
+	FindByID(ctx context.Context, id uuid.UUID, preloads ...string) (*kioskmodel.KioskDevice, error)
+	FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]kioskmodel.KioskDevice, error)
+	FindByPairingCodeHash(ctx context.Context, codeHash string) (*kioskmodel.KioskDevice, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*kioskmodel.KioskDevice, error)
+	Update(ctx context.Context, device *kioskmodel.KioskDevice) error
+	TouchLastSeen(ctx context.Context, id uuid.UUID, seenAt time.Time) error
+	CountSubmissionsSince(ctx context.Context, deviceIDs []uuid.UUID, since time.Time) (map[uuid.UUID]int64, error)
+}
+
+type DeviceService interface {
+	Register(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, req *kioskmodel.CreateDeviceRequest) (*kioskmodel.PairingCode, error)
+	RegeneratePairingCode(ctx context.Context, accountID uuid.UUID, deviceID uuid.UUID) (*kioskmodel.PairingCode, error)
+	Pair(ctx context.Context, code string) (*kioskmodel.PairedDevice, error)
+	Authenticate(ctx context.Context, token string) (*kioskmodel.KioskDevice, error)
+	GetByOrganizationID(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID) ([]kioskmodel.DeviceHealth, error)
+	Revoke(ctx context.Context, accountID uuid.UUID, deviceID uuid.UUID) (*kioskmodel.KioskDevice, error)
+}
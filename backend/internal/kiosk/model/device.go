@@ -0,0 +1,141 @@
+package kioskmodel
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	sharedModels "kyooar/internal/shared/models"
+)
+
+type DeviceStatus string
+
+const (
+	DeviceStatusPending DeviceStatus = "pending"
+	DeviceStatusActive  DeviceStatus = "active"
+	DeviceStatusRevoked DeviceStatus = "revoked"
+)
+
+// DeviceTokenHeader carries the device token on requests from a paired kiosk.
+const DeviceTokenHeader = "X-Device-Token"
+
+const (
+	// PairingCodeTTL is how long a one-time pairing code can be used.
+	PairingCodeTTL = 15 * time.Minute
+	// OnlineWindow is how recently a device must have been seen to count as online.
+	OnlineWindow = 5 * time.Minute
+	// LastSeenInterval throttles last-seen writes from busy devices.
+	LastSeenInterval = time.Minute
+
+	deviceTokenPrefix   = "kd_"
+	pairingCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	pairingCodeLength   = 8
+)
+
+// KioskDevice is a tablet fixed at a venue. It is registered by staff,
+// paired once with a short code typed on the tablet and from then on
+// authenticates with a long-lived device token. Only hashes of the code and
+// the token are stored.
+type KioskDevice struct {
+	sharedModels.BaseModel
+	OrganizationID   uuid.UUID  `gorm:"not null" json:"organization_id"`
+	QRCodeID         *uuid.UUID `json:"qr_code_id"`
+	Name             string     `gorm:"not null" json:"name"`
+	PairingCodeHash  *string    `json:"-"`
+	PairingExpiresAt *time.Time `json:"pairing_expires_at,omitempty"`
+	TokenHash        *string    `json:"-"`
+	PairedAt         *time.Time `json:"paired_at,omitempty"`
+	LastSeenAt       *time.Time `json:"last_seen_at,omitempty"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (d *KioskDevice) Status() DeviceStatus {
+	switch {
+	case d.RevokedAt != nil:
+		return DeviceStatusRevoked
+	case d.TokenHash != nil:
+		return DeviceStatusActive
+	default:
+		return DeviceStatusPending
+	}
+}
+
+func (d *KioskDevice) IsOnline(now time.Time) bool {
+	return d.LastSeenAt != nil && now.Sub(*d.LastSeenAt) <= OnlineWindow
+}
+
+type CreateDeviceRequest struct {
+	Name     string     `json:"name" validate:"required,min=1,max=100"`
+	QRCodeID *uuid.UUID `json:"qr_code_id"`
+}
+
+type PairDeviceRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// PairingCode is returned to staff once, when a device is registered or a
+// new code is requested.
+type PairingCode struct {
+	Device    *KioskDevice `json:"device"`
+	Code      string       `json:"pairing_code"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+// PairedDevice is returned to the tablet once, when pairing succeeds.
+type PairedDevice struct {
+	DeviceID       uuid.UUID  `json:"device_id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	QRCodeID       *uuid.UUID `json:"qr_code_id"`
+	Name           string     `json:"name"`
+	Token          string     `json:"token"`
+}
+
+type DeviceHealth struct {
+	*KioskDevice
+	Status           DeviceStatus `json:"status"`
+	Online           bool         `json:"online"`
+	SubmissionsToday int64        `json:"submissions_today"`
+}
+
+// GeneratePairingCode returns a short code without easily confused
+// characters, formatted as XXXX-XXXX, and the hash that is stored in its place.
+func GeneratePairingCode() (string, string, error) {
+	bytes := make([]byte, pairingCodeLength)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", "", err
+	}
+	code := make([]byte, pairingCodeLength)
+	for i, b := range bytes {
+		code[i] = pairingCodeAlphabet[int(b)%len(pairingCodeAlphabet)]
+	}
+	formatted := string(code[:pairingCodeLength/2]) + "-" + string(code[pairingCodeLength/2:])
+	return formatted, HashPairingCode(formatted), nil
+}
+
+// HashPairingCode ignores case, spaces and dashes so codes can be typed loosely.
+func HashPairingCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return hashSecret(normalized)
+}
+
+// GenerateDeviceToken returns a random device token and the hash that is stored in its place.
+func GenerateDeviceToken() (string, string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", "", err
+	}
+	token := deviceTokenPrefix + hex.EncodeToString(bytes)
+	return token, HashDeviceToken(token), nil
+}
+
+func HashDeviceToken(token string) string {
+	return hashSecret(strings.TrimSpace(token))
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,77 @@
+package kiosk
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/samber/do"
+	"gorm.io/gorm"
+
+	kioskcontroller "kyooar/internal/kiosk/controller"
+	kioskinterface "kyooar/internal/kiosk/interface"
+	gormkiosk "kyooar/internal/kiosk/repository/gorm"
+	kioskservice "kyooar/internal/kiosk/service"
+	organizationinterface "kyooar/internal/organization/interface"
+	qrcodeinterface "kyooar/internal/qrcode/interface"
+	sharedMiddleware "kyooar/internal/shared/middleware"
+)
+
+func ProvideDeviceRepository(i *do.Injector) (kioskinterface.DeviceRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return gormkiosk.NewDeviceRepository(db), nil
+}
+
+func ProvideDeviceService(i *do.Injector) (kioskinterface.DeviceService, error) {
+	deviceRepo := do.MustInvoke[kioskinterface.DeviceRepository](i)
+	organizationRepo := do.MustInvoke[organizationinterface.OrganizationRepository](i)
+	qrCodeRepo := do.MustInvoke[qrcodeinterface.QRCodeRepository](i)
+
+	return kioskservice.NewDeviceService(
+		deviceRepo,
+		organizationRepo,
+		qrCodeRepo,
+	), nil
+}
+
+func ProvideDeviceController(i *do.Injector) (*kioskcontroller.DeviceController, error) {
+	deviceService := do.MustInvoke[kioskinterface.DeviceService](i)
+	return kioskcontroller.NewDeviceController(deviceService), nil
+}
+
+func ProvidePublicController(i *do.Injector) (*kioskcontroller.PublicController, error) {
+	deviceService := do.MustInvoke[kioskinterface.DeviceService](i)
+	return kioskcontroller.NewPublicController(deviceService), nil
+}
+
+type KioskModule struct {
+	injector *do.Injector
+}
+
+func NewKioskModule(i *do.Injector) *KioskModule {
+	return &KioskModule{injector: i}
+}
+
+func (m *KioskModule) RegisterRoutes(v1 *echo.Group) {
+	deviceController := do.MustInvoke[*kioskcontroller.DeviceController](m.injector)
+	publicController := do.MustInvoke[*kioskcontroller.PublicController](m.injector)
+
+	middlewareProvider := do.MustInvoke[*sharedMiddleware.MiddlewareProvider](m.injector)
+
+	// Public routes used by the tablets themselves
+	v1.POST("/public/kiosk/pair", publicController.Pair)
+	v1.POST("/public/kiosk/heartbeat", publicController.Heartbeat)
+
+	// Device management routes; registering and listing are organization-scoped
+	devices := v1.Group("/kiosk-devices")
+	devices.Use(middlewareProvider.AuthMiddleware())
+	devices.Use(middlewareProvider.TeamAwareMiddleware())
+	devices.POST("/:id/pairing-code", deviceController.RegeneratePairingCode)
+	devices.POST("/:id/revoke", deviceController.Revoke)
+}
+
+func RegisterNewModule(container *do.Injector) error {
+	do.Provide(container, ProvideDeviceRepository)
+	do.Provide(container, ProvideDeviceService)
+	do.Provide(container, ProvideDeviceController)
+	do.Provide(container, ProvidePublicController)
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package gormkiosk
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	kioskinterface "kyooar/internal/kiosk/interface"
+	kioskmodel "kyooar/internal/kiosk/model"
+	sharedRepos "kyooar/internal/shared/repositories"
+	"gorm.io/gorm"
+)
+
+type deviceRepository struct {
+	*sharedRepos.BaseRepository[kioskmodel.KioskDevice]
+}
+
+func NewDeviceRepository(db *gorm.DB) kioskinterface.DeviceRepository {
+	return &deviceRepository{
+		BaseRepository: sharedRepos.NewBaseRepository[kioskmodel.KioskDevice](db),
+	}
+}
+
+func (r *deviceRepository) FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]kioskmodel.KioskDevice, error) {
+	var devices []kioskmodel.KioskDevice
+	err := r.DB.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("created_at DESC").
+		Find(&devices).Error
+	return devices, err
+}
+
+func (r *deviceRepository) FindByPairingCodeHash(ctx context.Context, codeHash string) (*kioskmodel.KioskDevice, error) {
+	return r.findOne(ctx, "pairing_code_hash = ?", codeHash)
+}
+
+func (r *deviceRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*kioskmodel.KioskDevice, error) {
+	return r.findOne(ctx, "token_hash = ?", tokenHash)
+}
+
+func (r *deviceRepository) findOne(ctx context.Context, query string, args ...interface{}) (*kioskmodel.KioskDevice, error) {
+	var device kioskmodel.KioskDevice
+	if err := r.DB.WithContext(ctx).Where(query, args...).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, sharedRepos.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *deviceRepository) TouchLastSeen(ctx context.Context, id uuid.UUID, seenAt time.Time) error {
+	return r.DB.WithContext(ctx).Model(&kioskmodel.KioskDevice{}).
+		Where("id = ?", id).
+		UpdateColumn("last_seen_at", seenAt).Error
+}
+
+// CountSubmissionsSince counts completed feedback left on each device.
+func (r *deviceRepository) CountSubmissionsSince(ctx context.Context, deviceIDs []uuid.UUID, since time.Time) (map[uuid.UUID]int64, error) {
+	counts := make(map[uuid.UUID]int64, len(deviceIDs))
+	if len(deviceIDs) == 0 {
+		return counts, nil
+	}
+
+	type result struct {
+		KioskDeviceID uuid.UUID `gorm:"column:kiosk_device_id"`
+		Count         int64     `gorm:"column:count"`
+	}
+
+	var results []result
+	err := r.DB.WithContext(ctx).
+		Table("feedbacks").
+		Select("kiosk_device_id, COUNT(*) as count").
+		Where("kiosk_device_id IN ? AND is_complete = ? AND created_at >= ? AND deleted_at IS NULL", deviceIDs, true, since).
+		Group("kiosk_device_id").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, res := range results {
+		counts[res.KioskDeviceID] = res.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,282 @@
+package kioskservice
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	kioskinterface "kyooar/internal/kiosk/interface"
+	kioskmodel "kyooar/internal/kiosk/model"
+	organizationinterface "kyooar/internal/organization/interface"
+	organizationmodel "kyooar/internal/organization/model"
+	qrcodeinterface "kyooar/internal/qrcode/interface"
+	"kyooar/internal/shared/errors"
+	"kyooar/internal/shared/logger"
+	sharedRepos "kyooar/internal/shared/repositories"
+)
+
+var errInvalidDeviceToken = errors.New("INVALID_DEVICE_TOKEN", "The device token is invalid or has been revoked", http.StatusUnauthorized)
+
+type deviceService struct {
+	deviceRepo       kioskinterface.DeviceRepository
+	organizationRepo organizationinterface.OrganizationRepository
+	qrCodeRepo       qrcodeinterface.QRCodeRepository
+}
+
+func NewDeviceService(
+	deviceRepo kioskinterface.DeviceRepository,
+	organizationRepo organizationinterface.OrganizationRepository,
+	qrCodeRepo qrcodeinterface.QRCodeRepository,
+) kioskinterface.DeviceService {
+	return &deviceService{
+		deviceRepo:       deviceRepo,
+		organizationRepo: organizationRepo,
+		qrCodeRepo:       qrCodeRepo,
+	}
+}
+
+func (s *deviceService) Register(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, req *kioskmodel.CreateDeviceRequest) (*kioskmodel.PairingCode, error) {
+	if _, err := s.authorizeOrganization(ctx, accountID, organizationID); err != nil {
+		return nil, err
+	}
+
+	if req.QRCodeID != nil {
+		qrCode, err := s.qrCodeRepo.FindByID(ctx, *req.QRCodeID)
+		if err != nil || qrCode.OrganizationID != organizationID {
+			return nil, errors.NotFound("QR code")
+		}
+	}
+
+	device := &kioskmodel.KioskDevice{
+		OrganizationID: organizationID,
+		QRCodeID:       req.QRCodeID,
+		Name:           req.Name,
+	}
+
+	code, err := s.assignPairingCode(device)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.deviceRepo.Create(ctx, device); err != nil {
+		return nil, err
+	}
+
+	return code, nil
+}
+
+// RegeneratePairingCode issues a new code, for example to move a device to a
+// new tablet. The current token keeps working until the new pairing succeeds.
+func (s *deviceService) RegeneratePairingCode(ctx context.Context, accountID uuid.UUID, deviceID uuid.UUID) (*kioskmodel.PairingCode, error) {
+	device, err := s.findOwnedDevice(ctx, accountID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if device.Status() == kioskmodel.DeviceStatusRevoked {
+		return nil, errors.New("DEVICE_REVOKED", "This device has been revoked, register a new one instead", http.StatusConflict)
+	}
+
+	code, err := s.assignPairingCode(device)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.deviceRepo.Update(ctx, device); err != nil {
+		return nil, err
+	}
+
+	return code, nil
+}
+
+// Pair exchanges a one-time pairing code for a device token. The code is
+// consumed and any token issued by an earlier pairing stops working.
+func (s *deviceService) Pair(ctx context.Context, code string) (*kioskmodel.PairedDevice, error) {
+	invalidCode := errors.New("INVALID_PAIRING_CODE", "The pairing code is invalid or has expired", http.StatusBadRequest)
+
+	device, err := s.deviceRepo.FindByPairingCodeHash(ctx, kioskmodel.HashPairingCode(code))
+	if err != nil {
+		if err == sharedRepos.ErrRecordNotFound {
+			return nil, invalidCode
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if device.Status() == kioskmodel.DeviceStatusRevoked || device.PairingExpiresAt == nil || now.After(*device.PairingExpiresAt) {
+		return nil, invalidCode
+	}
+
+	token, tokenHash, err := kioskmodel.GenerateDeviceToken()
+	if err != nil {
+		return nil, err
+	}
+
+	device.TokenHash = &tokenHash
+	device.PairingCodeHash = nil
+	device.PairingExpiresAt = nil
+	device.PairedAt = &now
+	device.LastSeenAt = &now
+
+	if err := s.deviceRepo.Update(ctx, device); err != nil {
+		return nil, err
+	}
+
+	return &kioskmodel.PairedDevice{
+		DeviceID:       device.ID,
+		OrganizationID: device.OrganizationID,
+		QRCodeID:       device.QRCodeID,
+		Name:           device.Name,
+		Token:          token,
+	}, nil
+}
+
+// Authenticate resolves a device token and records that the device was seen.
+func (s *deviceService) Authenticate(ctx context.Context, token string) (*kioskmodel.KioskDevice, error) {
+	if token == "" {
+		return nil, errInvalidDeviceToken
+	}
+
+	device, err := s.deviceRepo.FindByTokenHash(ctx, kioskmodel.HashDeviceToken(token))
+	if err != nil {
+		if err == sharedRepos.ErrRecordNotFound {
+			return nil, errInvalidDeviceToken
+		}
+		return nil, err
+	}
+
+	if device.Status() != kioskmodel.DeviceStatusActive {
+		return nil, errInvalidDeviceToken
+	}
+
+	now := time.Now()
+	if device.LastSeenAt == nil || now.Sub(*device.LastSeenAt) >= kioskmodel.LastSeenInterval {
+		if err := s.deviceRepo.TouchLastSeen(ctx, device.ID, now); err != nil {
+			logger.Error("Failed to record kiosk device activity", err, logrus.Fields{
+				"device_id": device.ID,
+			})
+		} else {
+			device.LastSeenAt = &now
+		}
+	}
+
+	return device, nil
+}
+
+// GetByOrganizationID lists devices with their health. Submissions are
+// counted from midnight in the organization's timezone.
+func (s *deviceService) GetByOrganizationID(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID) ([]kioskmodel.DeviceHealth, error) {
+	organization, err := s.authorizeOrganization(ctx, accountID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := s.deviceRepo.FindByOrganizationID(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(devices))
+	for i := range devices {
+		ids[i] = devices[i].ID
+	}
+
+	now := time.Now()
+	counts, err := s.deviceRepo.CountSubmissionsSince(ctx, ids, startOfDay(now, organization))
+	if err != nil {
+		return nil, err
+	}
+
+	health := make([]kioskmodel.DeviceHealth, len(devices))
+	for i := range devices {
+		device := &devices[i]
+		health[i] = kioskmodel.DeviceHealth{
+			KioskDevice:      device,
+			Status:           device.Status(),
+			Online:           device.Status() == kioskmodel.DeviceStatusActive && device.IsOnline(now),
+			SubmissionsToday: counts[device.ID],
+		}
+	}
+
+	return health, nil
+}
+
+// Revoke permanently disables a device, for example a lost tablet.
+func (s *deviceService) Revoke(ctx context.Context, accountID uuid.UUID, deviceID uuid.UUID) (*kioskmodel.KioskDevice, error) {
+	device, err := s.findOwnedDevice(ctx, accountID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if device.RevokedAt != nil {
+		return device, nil
+	}
+
+	now := time.Now()
+	device.RevokedAt = &now
+	device.TokenHash = nil
+	device.PairingCodeHash = nil
+	device.PairingExpiresAt = nil
+
+	if err := s.deviceRepo.Update(ctx, device); err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+func (s *deviceService) assignPairingCode(device *kioskmodel.KioskDevice) (*kioskmodel.PairingCode, error) {
+	code, codeHash, err := kioskmodel.GeneratePairingCode()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(kioskmodel.PairingCodeTTL)
+	device.PairingCodeHash = &codeHash
+	device.PairingExpiresAt = &expiresAt
+
+	return &kioskmodel.PairingCode{
+		Device:    device,
+		Code:      code,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *deviceService) authorizeOrganization(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID) (*organizationmodel.Organization, error) {
+	organization, err := s.organizationRepo.FindByID(ctx, organizationID)
+	if err != nil {
+		return nil, errors.NotFound("Organization")
+	}
+
+	if organization.AccountID != accountID {
+		return nil, errors.Forbidden("manage devices for this organization")
+	}
+
+	return organization, nil
+}
+
+func (s *deviceService) findOwnedDevice(ctx context.Context, accountID uuid.UUID, deviceID uuid.UUID) (*kioskmodel.KioskDevice, error) {
+	device, err := s.deviceRepo.FindByID(ctx, deviceID)
+	if err != nil {
+		return nil, errors.NotFound("Device")
+	}
+
+	if _, err := s.authorizeOrganization(ctx, accountID, device.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+func startOfDay(now time.Time, organization *organizationmodel.Organization) time.Time {
+	location := time.UTC
+	if organization.Settings.Timezone != "" {
+		if loaded, err := time.LoadLocation(organization.Settings.Timezone); err == nil {
+			location = loaded
+		}
+	}
+	local := now.In(location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	feedbackcontroller "kyooar/internal/feedback/controller"
+	kioskcontroller "kyooar/internal/kiosk/controller"
 	productHandlers "kyooar/internal/product/handlers"
 	organizationinterface "kyooar/internal/organization/interface"
 	organizationmodel "kyooar/internal/organization/model"
@@ -22,9 +23,17 @@ type OrganizationController struct {
 	organizationService     organizationinterface.OrganizationService
 	productHandler         *productHandlers.ProductHandler
 	qrCodeHandler          *qrcodecontroller.QRCodeController
+	printController        *qrcodecontroller.PrintController
 	feedbackController     *feedbackcontroller.FeedbackController
 	questionnaireController *feedbackcontroller.QuestionnaireController
 	questionController     *feedbackcontroller.QuestionController
+	versionController      *feedbackcontroller.VersionController
+	messageController      *feedbackcontroller.MessageController
+	triageController       *feedbackcontroller.TriageController
+	exportController       *feedbackcontroller.ExportController
+	importController       *feedbackcontroller.ImportController
+	viewController         *feedbackcontroller.ViewController
+	deviceController       *kioskcontroller.DeviceController
 	validator              *validator.Validator
 }
 
@@ -32,17 +41,33 @@ func NewOrganizationController(
 	organizationService organizationinterface.OrganizationService,
 	productHandler *productHandlers.ProductHandler,
 	qrCodeHandler *qrcodecontroller.QRCodeController,
+	printController *qrcodecontroller.PrintController,
 	feedbackController *feedbackcontroller.FeedbackController,
 	questionnaireController *feedbackcontroller.QuestionnaireController,
 	questionController *feedbackcontroller.QuestionController,
+	versionController *feedbackcontroller.VersionController,
+	messageController *feedbackcontroller.MessageController,
+	triageController *feedbackcontroller.TriageController,
+	exportController *feedbackcontroller.ExportController,
+	importController *feedbackcontroller.ImportController,
+	viewController *feedbackcontroller.ViewController,
+	deviceController *kioskcontroller.DeviceController,
 ) *OrganizationController {
 	return &OrganizationController{
 		organizationService:     organizationService,
 		productHandler:         productHandler,
 		qrCodeHandler:          qrCodeHandler,
+		printController:        printController,
 		feedbackController:     feedbackController,
 		questionnaireController: questionnaireController,
 		questionController:     questionController,
+		versionController:      versionController,
+		messageController:      messageController,
+		triageController:       triageController,
+		exportController:       exportController,
+		importController:       importController,
+		viewController:         viewController,
+		deviceController:       deviceController,
 		validator:              validator.New(),
 	}
 }
@@ -72,10 +97,44 @@ func (c *OrganizationController) RegisterRoutes(v1 *echo.Group, middlewareProvid
 	// Organization-scoped QR code routes
 	organizations.POST("/:organizationId/qr-codes", c.qrCodeHandler.Generate)
 	organizations.GET("/:organizationId/qr-codes", c.qrCodeHandler.GetByOrganization)
+	organizations.POST("/:organizationId/qr-codes/bulk", c.qrCodeHandler.BulkGenerate)
+	organizations.POST("/:organizationId/qr-codes/print", c.printController.Print)
+	organizations.POST("/:organizationId/qr-print-templates", c.printController.CreateTemplate)
+	organizations.GET("/:organizationId/qr-print-templates", c.printController.ListTemplates)
+	organizations.PUT("/:organizationId/qr-print-templates/:templateId", c.printController.UpdateTemplate)
+	organizations.DELETE("/:organizationId/qr-print-templates/:templateId", c.printController.DeleteTemplate)
+	
+	// Organization-scoped kiosk device routes
+	organizations.POST("/:organizationId/kiosk-devices", c.deviceController.Register)
+	organizations.GET("/:organizationId/kiosk-devices", c.deviceController.GetByOrganization)
 	
 	// Organization-scoped feedback routes
 	organizations.GET("/:organizationId/feedback", c.feedbackController.GetByOrganization)
+	organizations.GET("/:organizationId/feedback/export", c.exportController.Export)
+	organizations.GET("/:organizationId/feedback/exports", c.exportController.ListExports)
+	organizations.GET("/:organizationId/feedback/exports/:exportId", c.exportController.GetExport)
+	organizations.POST("/:organizationId/feedback/imports", c.importController.Import)
+	organizations.GET("/:organizationId/feedback/imports", c.importController.ListImports)
+	organizations.PUT("/:organizationId/feedback/imports/:importId", c.importController.UpdateImport)
+	organizations.DELETE("/:organizationId/feedback/imports/:importId", c.importController.DeleteImport)
+	organizations.POST("/:organizationId/feedback/views", c.viewController.CreateView)
+	organizations.GET("/:organizationId/feedback/views", c.viewController.ListViews)
+	organizations.GET("/:organizationId/feedback/views/:viewId", c.viewController.GetView)
+	organizations.PUT("/:organizationId/feedback/views/:viewId", c.viewController.UpdateView)
+	organizations.DELETE("/:organizationId/feedback/views/:viewId", c.viewController.DeleteView)
+	organizations.PUT("/:organizationId/feedback/views/:viewId/subscription", c.viewController.Subscribe)
+	organizations.DELETE("/:organizationId/feedback/views/:viewId/subscription", c.viewController.Unsubscribe)
 	organizations.GET("/:organizationId/analytics", c.feedbackController.GetStats)
+	organizations.POST("/:organizationId/feedback/:feedbackId/review", c.feedbackController.ReviewFeedback)
+	organizations.GET("/:organizationId/feedback/:feedbackId/messages", c.messageController.ListMessages)
+	organizations.POST("/:organizationId/feedback/:feedbackId/messages", c.messageController.SendMessage)
+	organizations.POST("/:organizationId/feedback/:feedbackId/messages/:messageId/resend", c.messageController.ResendMessage)
+	organizations.PUT("/:organizationId/feedback/:feedbackId/status", c.triageController.UpdateStatus)
+	organizations.PUT("/:organizationId/feedback/:feedbackId/assignee", c.triageController.Assign)
+	organizations.PUT("/:organizationId/feedback/:feedbackId/tags", c.triageController.UpdateTags)
+	organizations.GET("/:organizationId/feedback/:feedbackId/notes", c.triageController.ListNotes)
+	organizations.POST("/:organizationId/feedback/:feedbackId/notes", c.triageController.AddNote)
+	organizations.GET("/:organizationId/feedback/:feedbackId/activity", c.triageController.ListActivity)
 	
 	// Organization-scoped questionnaire routes
 	organizations.POST("/:organizationId/questionnaires", c.questionnaireController.CreateQuestionnaire)
@@ -97,6 +156,9 @@ func (c *OrganizationController) RegisterRoutes(v1 *echo.Group, middlewareProvid
 	organizations.DELETE("/:organizationId/products/:productId/questions/:questionId", c.questionController.DeleteQuestion)
 	organizations.POST("/:organizationId/products/:productId/questions/reorder", c.questionController.ReorderQuestions)
 	organizations.GET("/:organizationId/questions/products-with-questions", c.questionController.GetProductsWithQuestions)
+	organizations.GET("/:organizationId/products/:productId/questionnaire-versions", c.versionController.ListVersions)
+	organizations.GET("/:organizationId/products/:productId/questionnaire-versions/diff", c.versionController.DiffVersions)
+	organizations.GET("/:organizationId/products/:productId/questionnaire-versions/:version", c.versionController.GetVersion)
 	
 	// AI-powered routes
 	organizations.POST("/:organizationId/products/:productId/ai/generate-questions", c.questionnaireController.GenerateQuestions)
@@ -194,7 +256,7 @@ func (h *OrganizationController) GetByID(c echo.Context) error {
 }
 
 // @Summary Update organization
-// @Description Update a organization's information
+// @Description Update a organization's information. A retention object with anonymize_after_days and delete_text_after_days sets how long customer data is kept; 0 keeps it forever.
 // @Tags organizations
 // @Accept json
 // @Produce json
@@ -3,6 +3,7 @@ package organizationmodel
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -28,6 +29,34 @@ type Settings struct {
 	Timezone             string `json:"timezone"`
 	FeedbackNotification bool   `json:"feedback_notification"`
 	LowRatingThreshold   int    `json:"low_rating_threshold"`
+	Retention            RetentionPolicy `json:"retention"`
+}
+
+// MaxRetentionDays bounds retention periods to ten years.
+const MaxRetentionDays = 3650
+
+// RetentionPolicy controls how long customer data is kept. A period of zero
+// keeps the data forever.
+type RetentionPolicy struct {
+	// AnonymizeAfterDays strips contact and device details from feedback.
+	AnonymizeAfterDays int `json:"anonymize_after_days"`
+	// DeleteTextAfterDays clears free text and photo answers and removes
+	// reply conversations and internal notes, keeping ratings and choices.
+	DeleteTextAfterDays int `json:"delete_text_after_days"`
+}
+
+func (p RetentionPolicy) Enabled() bool {
+	return p.AnonymizeAfterDays > 0 || p.DeleteTextAfterDays > 0
+}
+
+func (p RetentionPolicy) Validate() error {
+	if p.AnonymizeAfterDays < 0 || p.AnonymizeAfterDays > MaxRetentionDays {
+		return fmt.Errorf("anonymize_after_days must be between 0 and %d", MaxRetentionDays)
+	}
+	if p.DeleteTextAfterDays < 0 || p.DeleteTextAfterDays > MaxRetentionDays {
+		return fmt.Errorf("delete_text_after_days must be between 0 and %d", MaxRetentionDays)
+	}
+	return nil
 }
 
 func (s Settings) Value() (driver.Value, error) {
@@ -1,5 +1,7 @@
 package organizationmodel
 
+import "github.com/google/uuid"
+
 type OrganizationResponse struct {
 	Organization *Organization `json:"organization"`
 }
@@ -9,6 +11,24 @@ type OrganizationListResponse struct {
 	Total         int64          `json:"total"`
 }
 
+type PublicBranding struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Logo        string    `json:"logo"`
+	Website     string    `json:"website"`
+}
+
+func (o *Organization) PublicBranding() PublicBranding {
+	return PublicBranding{
+		ID:          o.ID,
+		Name:        o.Name,
+		Description: o.Description,
+		Logo:        o.Logo,
+		Website:     o.Website,
+	}
+}
+
 type OrganizationStatsResponse struct {
 	TotalOrganizations int64 `json:"total_organizations"`
 	ActiveOrganizations int64 `json:"active_organizations"`
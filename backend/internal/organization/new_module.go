@@ -6,6 +6,7 @@ import (
 	"gorm.io/gorm"
 
 	feedbackcontroller "kyooar/internal/feedback/controller"
+	kioskcontroller "kyooar/internal/kiosk/controller"
 	productHandlers "kyooar/internal/product/handlers"
 	organizationcontroller "kyooar/internal/organization/controller"
 	organizationinterface "kyooar/internal/organization/interface"
@@ -36,17 +37,33 @@ func ProvideOrganizationController(i *do.Injector) (*organizationcontroller.Orga
 	organizationService := do.MustInvoke[organizationinterface.OrganizationService](i)
 	productHandler := do.MustInvoke[*productHandlers.ProductHandler](i)
 	qrCodeHandler := do.MustInvoke[*qrcodecontroller.QRCodeController](i)
+	printController := do.MustInvoke[*qrcodecontroller.PrintController](i)
 	feedbackController := do.MustInvoke[*feedbackcontroller.FeedbackController](i)
 	questionnaireController := do.MustInvoke[*feedbackcontroller.QuestionnaireController](i)
 	questionController := do.MustInvoke[*feedbackcontroller.QuestionController](i)
+	versionController := do.MustInvoke[*feedbackcontroller.VersionController](i)
+	messageController := do.MustInvoke[*feedbackcontroller.MessageController](i)
+	triageController := do.MustInvoke[*feedbackcontroller.TriageController](i)
+	exportController := do.MustInvoke[*feedbackcontroller.ExportController](i)
+	importController := do.MustInvoke[*feedbackcontroller.ImportController](i)
+	viewController := do.MustInvoke[*feedbackcontroller.ViewController](i)
+	deviceController := do.MustInvoke[*kioskcontroller.DeviceController](i)
 	
 	return organizationcontroller.NewOrganizationController(
 		organizationService,
 		productHandler,
 		qrCodeHandler,
+		printController,
 		feedbackController,
 		questionnaireController,
 		questionController,
+		versionController,
+		messageController,
+		triageController,
+		exportController,
+		importController,
+		viewController,
+		deviceController,
 	), nil
 }
 
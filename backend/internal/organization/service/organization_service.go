@@ -2,6 +2,8 @@ package organizationservice
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/google/uuid"
 	organizationinterface "kyooar/internal/organization/interface"
@@ -97,6 +99,12 @@ func (s *organizationService) Update(ctx context.Context, accountID uuid.UUID, o
 			if v, ok := value.(bool); ok {
 				organization.IsActive = v
 			}
+		case "retention":
+			policy, err := parseRetentionPolicy(value)
+			if err != nil {
+				return errors.BadRequest(err.Error())
+			}
+			organization.Settings.Retention = *policy
 		}
 	}
 
@@ -162,3 +170,20 @@ func (s *organizationService) CountByAccountID(ctx context.Context, accountID uu
 func (s *organizationService) GetByIDForAnalytics(ctx context.Context, organizationID uuid.UUID) (*organizationmodel.Organization, error) {
 	return s.organizationRepo.FindByID(ctx, organizationID)
 }
+
+func parseRetentionPolicy(value interface{}) (*organizationmodel.RetentionPolicy, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retention policy")
+	}
+
+	var policy organizationmodel.RetentionPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("invalid retention policy")
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
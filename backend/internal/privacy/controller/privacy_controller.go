@@ -0,0 +1,186 @@
+package privacycontroller
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	authModels "kyooar/internal/auth/models"
+	privacyinterface "kyooar/internal/privacy/interface"
+	privacymodel "kyooar/internal/privacy/model"
+	"kyooar/internal/shared/errors"
+	"kyooar/internal/shared/logger"
+	"kyooar/internal/shared/middleware"
+	"kyooar/internal/shared/response"
+	"github.com/sirupsen/logrus"
+)
+
+type PrivacyController struct {
+	privacyService   privacyinterface.PrivacyService
+	retentionService privacyinterface.RetentionService
+}
+
+func NewPrivacyController(privacyService privacyinterface.PrivacyService, retentionService privacyinterface.RetentionService) *PrivacyController {
+	return &PrivacyController{
+		privacyService:   privacyService,
+		retentionService: retentionService,
+	}
+}
+
+// @Summary Export a customer's data
+// @Description Export every feedback left with a customer email or phone number across the account's organizations, as JSON. Only account owners and admins can export.
+// @Tags privacy
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body privacymodel.SubjectRequest true "Customer identifier"
+// @Success 200 {object} response.Response{data=privacymodel.SubjectExport}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/privacy/export [post]
+func (h *PrivacyController) Export(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	req, err := h.subjectRequest(c)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	resourceAccountID := middleware.GetResourceAccountID(c)
+
+	export, err := h.privacyService.Export(ctx, resourceAccountID, req)
+	if err != nil {
+		logger.Error("Failed to export customer data", err, logrus.Fields{
+			"account_id": resourceAccountID,
+		})
+		return response.Error(c, err)
+	}
+
+	c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"customer-data-%s.json\"", export.RequestID))
+	return response.Success(c, export)
+}
+
+// @Summary Erase a customer's data
+// @Description Anonymize every feedback left with a customer email or phone number across the account's organizations. Ratings and choice answers are kept for analytics; contact details, free text, photos, reply conversations and internal notes are removed. Only account owners and admins can erase.
+// @Tags privacy
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body privacymodel.SubjectRequest true "Customer identifier"
+// @Success 200 {object} response.Response{data=privacymodel.ErasureResult}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/privacy/erase [post]
+func (h *PrivacyController) Erase(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	req, err := h.subjectRequest(c)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	resourceAccountID := middleware.GetResourceAccountID(c)
+
+	result, err := h.privacyService.Erase(ctx, resourceAccountID, req)
+	if err != nil {
+		logger.Error("Failed to erase customer data", err, logrus.Fields{
+			"account_id": resourceAccountID,
+		})
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// @Summary List data subject requests
+// @Description List the audit log of customer data exports and erasures for the account, newest first
+// @Tags privacy
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=[]privacymodel.DataSubjectRequest}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/privacy/requests [get]
+func (h *PrivacyController) ListRequests(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	if !canManagePrivacy(c) {
+		return response.Error(c, errors.Forbidden("view privacy requests"))
+	}
+
+	resourceAccountID := middleware.GetResourceAccountID(c)
+
+	requests, err := h.privacyService.ListRequests(ctx, resourceAccountID)
+	if err != nil {
+		logger.Error("Failed to list data subject requests", err, logrus.Fields{
+			"account_id": resourceAccountID,
+		})
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, requests)
+}
+
+// @Summary List retention runs
+// @Description List what the nightly retention job did for each of the account's organizations, newest first
+// @Tags privacy
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=[]privacymodel.RetentionRun}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/privacy/retention-runs [get]
+func (h *PrivacyController) ListRetentionRuns(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	if !canManagePrivacy(c) {
+		return response.Error(c, errors.Forbidden("view retention runs"))
+	}
+
+	resourceAccountID := middleware.GetResourceAccountID(c)
+
+	runs, err := h.retentionService.ListRuns(ctx, resourceAccountID)
+	if err != nil {
+		logger.Error("Failed to list retention runs", err, logrus.Fields{
+			"account_id": resourceAccountID,
+		})
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, runs)
+}
+
+func (h *PrivacyController) subjectRequest(c echo.Context) (*privacymodel.SubjectRequest, error) {
+	if !canManagePrivacy(c) {
+		return nil, errors.Forbidden("handle customer data requests")
+	}
+
+	var req privacymodel.SubjectRequest
+	if err := c.Bind(&req); err != nil {
+		return nil, errors.ErrBadRequest
+	}
+
+	memberID, err := middleware.GetMemberID(c)
+	if err != nil {
+		return nil, errors.ErrUnauthorized
+	}
+	req.RequestedBy = &memberID
+	req.Source = privacymodel.SourceAPI
+
+	return &req, nil
+}
+
+// canManagePrivacy allows account owners, and team members with the owner
+// or admin role.
+func canManagePrivacy(c echo.Context) bool {
+	if !middleware.IsTeamMember(c) {
+		return true
+	}
+	role, ok := middleware.GetTeamRole(c)
+	return ok && (role == authModels.RoleOwner || role == authModels.RoleAdmin)
+}
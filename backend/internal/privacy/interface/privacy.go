@@ -0,0 +1,30 @@
+package privacyinterface
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+	privacymodel "kyooar/internal/privacy/model"
+)
+
+type PrivacyRepository interface {
+	CreateRequest(ctx context.Context, request *privacymodel.DataSubjectRequest) error
+	UpdateRequest(ctx context.Context, request *privacymodel.DataSubjectRequest) error
+	FindRequestsByAccountID(ctx context.Context, accountID uuid.UUID) ([]privacymodel.DataSubjectRequest, error)
+	// FindFeedbacks returns the feedback left with the identifier in any of
+	// the account's organizations, soft-deleted or not.
+	FindFeedbacks(ctx context.Context, accountID uuid.UUID, identifierType privacymodel.IdentifierType, value string) ([]feedbackmodel.Feedback, error)
+	FindMessages(ctx context.Context, feedbackIDs []uuid.UUID) ([]feedbackmodel.FeedbackMessage, error)
+	FindAttachments(ctx context.Context, feedbackIDs []uuid.UUID) ([]feedbackmodel.FeedbackAttachment, error)
+	FindQuestionTypes(ctx context.Context, questionIDs []uuid.UUID) (map[uuid.UUID]feedbackmodel.QuestionType, error)
+	// EraseFeedbacks saves the anonymized feedbacks and deletes their
+	// conversations, photos, notes and activity in one transaction.
+	EraseFeedbacks(ctx context.Context, feedbacks []feedbackmodel.Feedback) (*privacymodel.ErasureResult, error)
+}
+
+type PrivacyService interface {
+	Export(ctx context.Context, accountID uuid.UUID, req *privacymodel.SubjectRequest) (*privacymodel.SubjectExport, error)
+	Erase(ctx context.Context, accountID uuid.UUID, req *privacymodel.SubjectRequest) (*privacymodel.ErasureResult, error)
+	ListRequests(ctx context.Context, accountID uuid.UUID) ([]privacymodel.DataSubjectRequest, error)
+}
@@ -0,0 +1,34 @@
+package privacyinterface
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+	organizationmodel "kyooar/internal/organization/model"
+	privacymodel "kyooar/internal/privacy/model"
+)
+
+type RetentionRepository interface {
+	FindOrganizationsWithPolicy(ctx context.Context) ([]organizationmodel.Organization, error)
+	// FindFeedbacksToAnonymize returns up to limit feedbacks created before
+	// the cutoff that still carry personal data.
+	FindFeedbacksToAnonymize(ctx context.Context, organizationID uuid.UUID, before time.Time, limit int) ([]feedbackmodel.Feedback, error)
+	// FindFeedbacksWithText returns up to limit feedbacks created before the
+	// cutoff whose free text has not been removed yet.
+	FindFeedbacksWithText(ctx context.Context, organizationID uuid.UUID, before time.Time, limit int) ([]feedbackmodel.Feedback, error)
+	SaveAnonymized(ctx context.Context, feedbacks []feedbackmodel.Feedback) error
+	// SaveTextRemoved saves the cleared answers and deletes the feedbacks'
+	// conversations, photos and internal notes in one transaction.
+	SaveTextRemoved(ctx context.Context, feedbacks []feedbackmodel.Feedback) (deletedMessages int64, deletedAttachments int64, deletedNotes int64, err error)
+	CreateRun(ctx context.Context, run *privacymodel.RetentionRun) error
+	UpdateRun(ctx context.Context, run *privacymodel.RetentionRun) error
+	FindRunsByAccountID(ctx context.Context, accountID uuid.UUID, limit int) ([]privacymodel.RetentionRun, error)
+}
+
+type RetentionService interface {
+	// Enforce applies every organization's retention policy.
+	Enforce(ctx context.Context) error
+	ListRuns(ctx context.Context, accountID uuid.UUID) ([]privacymodel.RetentionRun, error)
+}
@@ -0,0 +1,167 @@
+package privacymodel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+	sharedModels "kyooar/internal/shared/models"
+)
+
+type RequestType string
+
+const (
+	RequestTypeExport RequestType = "export"
+	RequestTypeErase  RequestType = "erase"
+)
+
+type IdentifierType string
+
+const (
+	IdentifierEmail IdentifierType = "email"
+	IdentifierPhone IdentifierType = "phone"
+)
+
+type RequestSource string
+
+const (
+	SourceAPI RequestSource = "api"
+	SourceCLI RequestSource = "cli"
+)
+
+type RequestStatus string
+
+const (
+	RequestStatusPending   RequestStatus = "pending"
+	RequestStatusCompleted RequestStatus = "completed"
+	RequestStatusFailed    RequestStatus = "failed"
+)
+
+// DataSubjectRequest is the audit record of an export or erasure. The
+// identifier itself is not kept, only a hash to recognise repeated requests
+// and a masked form for people reading the log.
+type DataSubjectRequest struct {
+	sharedModels.BaseModel
+	AccountID        uuid.UUID      `gorm:"not null" json:"account_id"`
+	Type             RequestType    `gorm:"not null" json:"type"`
+	IdentifierType   IdentifierType `gorm:"not null" json:"identifier_type"`
+	IdentifierHash   string         `gorm:"not null" json:"-"`
+	MaskedIdentifier string         `gorm:"not null" json:"identifier"`
+	Source           RequestSource  `gorm:"not null" json:"source"`
+	RequestedBy      *uuid.UUID     `json:"requested_by,omitempty"`
+	Reason           string         `json:"reason,omitempty"`
+	Status           RequestStatus  `gorm:"not null" json:"status"`
+	MatchedFeedbacks int            `json:"matched_feedbacks"`
+	Error            string         `json:"error,omitempty"`
+	CompletedAt      *time.Time     `json:"completed_at,omitempty"`
+
+	// Counts of what an erasure deleted along with the customer's details.
+	DeletedMessages    int64 `json:"deleted_messages"`
+	DeletedAttachments int64 `json:"deleted_attachments"`
+	DeletedNotes       int64 `json:"deleted_notes"`
+	DeletedActivities  int64 `json:"deleted_activities"`
+}
+
+// SubjectRequest identifies the customer by exactly one of email or phone.
+type SubjectRequest struct {
+	Email  string `json:"email"`
+	Phone  string `json:"phone"`
+	Reason string `json:"reason"`
+
+	// Set by the caller rather than the request body.
+	RequestedBy *uuid.UUID    `json:"-"`
+	Source      RequestSource `json:"-"`
+}
+
+// Identifier returns the normalized identifier and its type, or false when
+// neither or both of email and phone are given.
+func (r *SubjectRequest) Identifier() (IdentifierType, string, bool) {
+	email := NormalizeEmail(r.Email)
+	phone := NormalizePhone(r.Phone)
+	switch {
+	case email != "" && phone == "":
+		return IdentifierEmail, email, true
+	case phone != "" && email == "":
+		return IdentifierPhone, phone, true
+	}
+	return "", "", false
+}
+
+type SubjectExport struct {
+	RequestID   uuid.UUID         `json:"request_id"`
+	Identifier  string            `json:"identifier"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Feedbacks   []SubjectFeedback `json:"feedbacks"`
+}
+
+// SubjectFeedback is everything stored about the customer in one feedback.
+type SubjectFeedback struct {
+	ID               uuid.UUID                     `json:"id"`
+	OrganizationID   uuid.UUID                     `json:"organization_id"`
+	OrganizationName string                        `json:"organization_name"`
+	ProductName      string                        `json:"product_name"`
+	CreatedAt        time.Time                     `json:"created_at"`
+	CustomerName     string                        `json:"customer_name"`
+	CustomerEmail    string                        `json:"customer_email"`
+	CustomerPhone    string                        `json:"customer_phone"`
+	OverallRating    int                           `json:"overall_rating"`
+	Responses        feedbackmodel.Responses       `json:"responses"`
+	DeviceInfo       feedbackmodel.DeviceInfo      `json:"device_info"`
+	Language         string                        `json:"language,omitempty"`
+	Attachments      int                           `json:"attachments"`
+	Messages         []feedbackmodel.PublicMessage `json:"messages"`
+}
+
+type ErasureResult struct {
+	RequestID          uuid.UUID `json:"request_id"`
+	ErasedFeedbacks    int       `json:"erased_feedbacks"`
+	DeletedMessages    int64     `json:"deleted_messages"`
+	DeletedAttachments int64     `json:"deleted_attachments"`
+	DeletedNotes       int64     `json:"deleted_notes"`
+	DeletedActivities  int64     `json:"deleted_activities"`
+}
+
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizePhone keeps the digits and a leading plus sign, so numbers typed
+// with spaces, dashes or brackets match the same customer.
+func NormalizePhone(phone string) string {
+	phone = strings.TrimSpace(phone)
+	var b strings.Builder
+	for i, r := range phone {
+		if unicode.IsDigit(r) || (r == '+' && i == 0) {
+			b.WriteRune(r)
+		}
+	}
+	if b.String() == "+" {
+		return ""
+	}
+	return b.String()
+}
+
+func HashIdentifier(identifierType IdentifierType, value string) string {
+	sum := sha256.Sum256([]byte(string(identifierType) + ":" + value))
+	return hex.EncodeToString(sum[:])
+}
+
+// MaskIdentifier keeps just enough of the identifier to tell requests apart,
+// such as "j***@example.com" or "***4321".
+func MaskIdentifier(identifierType IdentifierType, value string) string {
+	if identifierType == IdentifierEmail {
+		local, domain, found := strings.Cut(value, "@")
+		if !found || local == "" {
+			return "***"
+		}
+		return string([]rune(local)[:1]) + "***@" + domain
+	}
+	if len(value) <= 4 {
+		return "***"
+	}
+	return "***" + value[len(value)-4:]
+}
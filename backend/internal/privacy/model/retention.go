@@ -0,0 +1,25 @@
+package privacymodel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	sharedModels "kyooar/internal/shared/models"
+)
+
+// RetentionRun records what one nightly run of the retention job did for an
+// organization, so clients can show that their policy is being enforced.
+type RetentionRun struct {
+	sharedModels.BaseModel
+	OrganizationID       uuid.UUID  `gorm:"not null" json:"organization_id"`
+	AnonymizeAfterDays   int        `json:"anonymize_after_days"`
+	DeleteTextAfterDays  int        `json:"delete_text_after_days"`
+	StartedAt            time.Time  `gorm:"not null" json:"started_at"`
+	FinishedAt           *time.Time `json:"finished_at,omitempty"`
+	AnonymizedFeedbacks  int        `json:"anonymized_feedbacks"`
+	TextRemovedFeedbacks int        `json:"text_removed_feedbacks"`
+	DeletedMessages      int64      `json:"deleted_messages"`
+	DeletedAttachments   int64      `json:"deleted_attachments"`
+	DeletedNotes         int64      `json:"deleted_notes"`
+	Error                string     `json:"error,omitempty"`
+}
@@ -0,0 +1,88 @@
+package privacy
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/samber/do"
+	"gorm.io/gorm"
+
+	privacycontroller "kyooar/internal/privacy/controller"
+	privacyinterface "kyooar/internal/privacy/interface"
+	gormprivacy "kyooar/internal/privacy/repository/gorm"
+	privacyservice "kyooar/internal/privacy/service"
+	"kyooar/internal/shared/config"
+	sharedMiddleware "kyooar/internal/shared/middleware"
+	sharedServices "kyooar/internal/shared/services"
+)
+
+func ProvidePrivacyRepository(i *do.Injector) (privacyinterface.PrivacyRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return gormprivacy.NewPrivacyRepository(db), nil
+}
+
+func ProvidePrivacyService(i *do.Injector) (privacyinterface.PrivacyService, error) {
+	privacyRepo := do.MustInvoke[privacyinterface.PrivacyRepository](i)
+	storage := do.MustInvoke[sharedServices.Storage](i)
+
+	return privacyservice.NewPrivacyService(
+		privacyRepo,
+		storage,
+	), nil
+}
+
+func ProvideRetentionRepository(i *do.Injector) (privacyinterface.RetentionRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return gormprivacy.NewRetentionRepository(db), nil
+}
+
+func ProvideRetentionService(i *do.Injector) (privacyinterface.RetentionService, error) {
+	retentionRepo := do.MustInvoke[privacyinterface.RetentionRepository](i)
+	privacyRepo := do.MustInvoke[privacyinterface.PrivacyRepository](i)
+	storage := do.MustInvoke[sharedServices.Storage](i)
+	cfg := do.MustInvoke[*config.Config](i)
+
+	return privacyservice.NewRetentionService(
+		retentionRepo,
+		privacyRepo,
+		storage,
+		cfg.Retention.BatchSize,
+	), nil
+}
+
+func ProvidePrivacyController(i *do.Injector) (*privacycontroller.PrivacyController, error) {
+	privacyService := do.MustInvoke[privacyinterface.PrivacyService](i)
+	retentionService := do.MustInvoke[privacyinterface.RetentionService](i)
+	return privacycontroller.NewPrivacyController(privacyService, retentionService), nil
+}
+
+type PrivacyModule struct {
+	injector *do.Injector
+}
+
+func NewPrivacyModule(i *do.Injector) *PrivacyModule {
+	return &PrivacyModule{injector: i}
+}
+
+func (m *PrivacyModule) RegisterRoutes(v1 *echo.Group) {
+	privacyController := do.MustInvoke[*privacycontroller.PrivacyController](m.injector)
+
+	middlewareProvider := do.MustInvoke[*sharedMiddleware.MiddlewareProvider](m.injector)
+
+	// Account-level customer data requests, spanning every organization
+	privacy := v1.Group("/privacy")
+	privacy.Use(middlewareProvider.AuthMiddleware())
+	privacy.Use(middlewareProvider.TeamAwareMiddleware())
+	privacy.GET("/requests", privacyController.ListRequests)
+	privacy.POST("/export", privacyController.Export)
+	privacy.POST("/erase", privacyController.Erase)
+	privacy.GET("/retention-runs", privacyController.ListRetentionRuns)
+}
+
+func RegisterNewModule(container *do.Injector) error {
+	do.Provide(container, ProvidePrivacyRepository)
+	do.Provide(container, ProvidePrivacyService)
+	do.Provide(container, ProvideRetentionRepository)
+	do.Provide(container, ProvideRetentionService)
+	do.Provide(container, ProvidePrivacyController)
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package gormprivacy
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+	privacymodel "kyooar/internal/privacy/model"
+	"gorm.io/gorm"
+)
+
+type privacyRepository struct {
+	DB *gorm.DB
+}
+
+func NewPrivacyRepository(db *gorm.DB) *privacyRepository {
+	return &privacyRepository{DB: db}
+}
+
+func (r *privacyRepository) CreateRequest(ctx context.Context, request *privacymodel.DataSubjectRequest) error {
+	return r.DB.WithContext(ctx).Create(request).Error
+}
+
+func (r *privacyRepository) UpdateRequest(ctx context.Context, request *privacymodel.DataSubjectRequest) error {
+	return r.DB.WithContext(ctx).Save(request).Error
+}
+
+func (r *privacyRepository) FindRequestsByAccountID(ctx context.Context, accountID uuid.UUID) ([]privacymodel.DataSubjectRequest, error) {
+	var requests []privacymodel.DataSubjectRequest
+	err := r.DB.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Order("created_at DESC").
+		Find(&requests).Error
+	return requests, err
+}
+
+// FindFeedbacks includes soft-deleted feedback, which still holds the
+// customer's details until it is purged.
+func (r *privacyRepository) FindFeedbacks(ctx context.Context, accountID uuid.UUID, identifierType privacymodel.IdentifierType, value string) ([]feedbackmodel.Feedback, error) {
+	query := r.DB.WithContext(ctx).
+		Unscoped().
+		Model(&feedbackmodel.Feedback{}).
+		Joins("JOIN organizations ON organizations.id = feedbacks.organization_id").
+		Where("organizations.account_id = ?", accountID)
+
+	switch identifierType {
+	case privacymodel.IdentifierEmail:
+		query = query.Where("LOWER(TRIM(feedbacks.customer_email)) = ?", value)
+	case privacymodel.IdentifierPhone:
+		query = query.Where("regexp_replace(feedbacks.customer_phone, '[^0-9+]', '', 'g') = ?", value)
+	default:
+		return nil, nil
+	}
+
+	var feedbacks []feedbackmodel.Feedback
+	err := query.
+		Preload("Organization").
+		Preload("Product").
+		Order("feedbacks.created_at ASC").
+		Find(&feedbacks).Error
+	return feedbacks, err
+}
+
+func (r *privacyRepository) FindMessages(ctx context.Context, feedbackIDs []uuid.UUID) ([]feedbackmodel.FeedbackMessage, error) {
+	var messages []feedbackmodel.FeedbackMessage
+	if len(feedbackIDs) == 0 {
+		return messages, nil
+	}
+	err := r.DB.WithContext(ctx).
+		Unscoped().
+		Where("feedback_id IN ?", feedbackIDs).
+		Order("created_at ASC").
+		Find(&messages).Error
+	return messages, err
+}
+
+func (r *privacyRepository) FindAttachments(ctx context.Context, feedbackIDs []uuid.UUID) ([]feedbackmodel.FeedbackAttachment, error) {
+	var attachments []feedbackmodel.FeedbackAttachment
+	if len(feedbackIDs) == 0 {
+		return attachments, nil
+	}
+	err := r.DB.WithContext(ctx).
+		Unscoped().
+		Where("feedback_id IN ?", feedbackIDs).
+		Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *privacyRepository) FindQuestionTypes(ctx context.Context, questionIDs []uuid.UUID) (map[uuid.UUID]feedbackmodel.QuestionType, error) {
+	types := make(map[uuid.UUID]feedbackmodel.QuestionType, len(questionIDs))
+	if len(questionIDs) == 0 {
+		return types, nil
+	}
+
+	var questions []feedbackmodel.Question
+	if err := r.DB.WithContext(ctx).
+		Unscoped().
+		Select("id", "type").
+		Where("id IN ?", questionIDs).
+		Find(&questions).Error; err != nil {
+		return nil, err
+	}
+
+	for _, question := range questions {
+		types[question.ID] = question.Type
+	}
+	return types, nil
+}
+
+func (r *privacyRepository) EraseFeedbacks(ctx context.Context, feedbacks []feedbackmodel.Feedback) (*privacymodel.ErasureResult, error) {
+	result := &privacymodel.ErasureResult{}
+	if len(feedbacks) == 0 {
+		return result, nil
+	}
+
+	feedbackIDs := make([]uuid.UUID, 0, len(feedbacks))
+	for _, feedback := range feedbacks {
+		feedbackIDs = append(feedbackIDs, feedback.ID)
+	}
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, feedback := range feedbacks {
+			if err := tx.Unscoped().Model(&feedbackmodel.Feedback{}).Where("id = ?", feedback.ID).Updates(map[string]interface{}{
+				"customer_name":   feedback.CustomerName,
+				"customer_email":  feedback.CustomerEmail,
+				"customer_phone":  feedback.CustomerPhone,
+				"device_info":     feedback.DeviceInfo,
+				"device_hash":     feedback.DeviceHash,
+				"body_hash":       feedback.BodyHash,
+				"responses":       feedback.Responses,
+				"anonymized_at":   feedback.AnonymizedAt,
+				"text_removed_at": feedback.TextRemovedAt,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		messages := tx.Unscoped().Where("feedback_id IN ?", feedbackIDs).Delete(&feedbackmodel.FeedbackMessage{})
+		if messages.Error != nil {
+			return messages.Error
+		}
+		result.DeletedMessages = messages.RowsAffected
+
+		attachments := tx.Unscoped().Where("feedback_id IN ?", feedbackIDs).Delete(&feedbackmodel.FeedbackAttachment{})
+		if attachments.Error != nil {
+			return attachments.Error
+		}
+		result.DeletedAttachments = attachments.RowsAffected
+
+		// Notes are written about the customer and activity can point at them
+		// or repeat their wording, so neither outlives the erasure.
+		activities := tx.Unscoped().Where("feedback_id IN ?", feedbackIDs).Delete(&feedbackmodel.FeedbackActivity{})
+		if activities.Error != nil {
+			return activities.Error
+		}
+		result.DeletedActivities = activities.RowsAffected
+
+		notes := tx.Unscoped().Where("feedback_id IN ?", feedbackIDs).Delete(&feedbackmodel.FeedbackNote{})
+		if notes.Error != nil {
+			return notes.Error
+		}
+		result.DeletedNotes = notes.RowsAffected
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.ErasedFeedbacks = len(feedbacks)
+	return result, nil
+}
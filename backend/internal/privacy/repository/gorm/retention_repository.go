@@ -0,0 +1,147 @@
+package gormprivacy
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	feedbackmodel "kyooar/internal/feedback/model"
+	organizationmodel "kyooar/internal/organization/model"
+	privacymodel "kyooar/internal/privacy/model"
+	"gorm.io/gorm"
+)
+
+// retainedBeforeSQL matches feedback older than the cutoff. Imported feedback
+// keeps its original date, so its age is counted from the import instead;
+// otherwise an import of old feedback would be stripped the night it lands.
+const retainedBeforeSQL = "((import_id IS NULL AND created_at < ?) OR import_id IN (SELECT id FROM feedback_imports WHERE created_at < ?))"
+
+type retentionRepository struct {
+	DB *gorm.DB
+}
+
+func NewRetentionRepository(db *gorm.DB) *retentionRepository {
+	return &retentionRepository{DB: db}
+}
+
+func (r *retentionRepository) FindOrganizationsWithPolicy(ctx context.Context) ([]organizationmodel.Organization, error) {
+	var organizations []organizationmodel.Organization
+	err := r.DB.WithContext(ctx).
+		Where("COALESCE((settings->'retention'->>'anonymize_after_days')::int, 0) > 0 OR COALESCE((settings->'retention'->>'delete_text_after_days')::int, 0) > 0").
+		Find(&organizations).Error
+	return organizations, err
+}
+
+// FindFeedbacksToAnonymize and FindFeedbacksWithText include soft-deleted
+// feedback, which keeps its data until it is purged.
+func (r *retentionRepository) FindFeedbacksToAnonymize(ctx context.Context, organizationID uuid.UUID, before time.Time, limit int) ([]feedbackmodel.Feedback, error) {
+	var feedbacks []feedbackmodel.Feedback
+	err := r.DB.WithContext(ctx).
+		Unscoped().
+		Where("organization_id = ? AND anonymized_at IS NULL", organizationID).
+		Where(retainedBeforeSQL, before, before).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&feedbacks).Error
+	return feedbacks, err
+}
+
+func (r *retentionRepository) FindFeedbacksWithText(ctx context.Context, organizationID uuid.UUID, before time.Time, limit int) ([]feedbackmodel.Feedback, error) {
+	var feedbacks []feedbackmodel.Feedback
+	err := r.DB.WithContext(ctx).
+		Unscoped().
+		Where("organization_id = ? AND text_removed_at IS NULL", organizationID).
+		Where(retainedBeforeSQL, before, before).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&feedbacks).Error
+	return feedbacks, err
+}
+
+func (r *retentionRepository) SaveAnonymized(ctx context.Context, feedbacks []feedbackmodel.Feedback) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, feedback := range feedbacks {
+			if err := tx.Unscoped().Model(&feedbackmodel.Feedback{}).Where("id = ?", feedback.ID).Updates(map[string]interface{}{
+				"customer_name":  feedback.CustomerName,
+				"customer_email": feedback.CustomerEmail,
+				"customer_phone": feedback.CustomerPhone,
+				"device_info":    feedback.DeviceInfo,
+				"device_hash":    feedback.DeviceHash,
+				"body_hash":      feedback.BodyHash,
+				"anonymized_at":  feedback.AnonymizedAt,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *retentionRepository) SaveTextRemoved(ctx context.Context, feedbacks []feedbackmodel.Feedback) (int64, int64, int64, error) {
+	var deletedMessages, deletedAttachments, deletedNotes int64
+	if len(feedbacks) == 0 {
+		return 0, 0, 0, nil
+	}
+
+	feedbackIDs := make([]uuid.UUID, 0, len(feedbacks))
+	for _, feedback := range feedbacks {
+		feedbackIDs = append(feedbackIDs, feedback.ID)
+	}
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, feedback := range feedbacks {
+			if err := tx.Unscoped().Model(&feedbackmodel.Feedback{}).Where("id = ?", feedback.ID).Updates(map[string]interface{}{
+				"responses":       feedback.Responses,
+				"text_removed_at": feedback.TextRemovedAt,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		messages := tx.Unscoped().Where("feedback_id IN ?", feedbackIDs).Delete(&feedbackmodel.FeedbackMessage{})
+		if messages.Error != nil {
+			return messages.Error
+		}
+		deletedMessages = messages.RowsAffected
+
+		attachments := tx.Unscoped().Where("feedback_id IN ?", feedbackIDs).Delete(&feedbackmodel.FeedbackAttachment{})
+		if attachments.Error != nil {
+			return attachments.Error
+		}
+		deletedAttachments = attachments.RowsAffected
+
+		// Internal notes are free text too. Their activity entries stay, with
+		// the note reference cleared by the foreign key.
+		notes := tx.Unscoped().Where("feedback_id IN ?", feedbackIDs).Delete(&feedbackmodel.FeedbackNote{})
+		if notes.Error != nil {
+			return notes.Error
+		}
+		deletedNotes = notes.RowsAffected
+
+		return nil
+	})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	return deletedMessages, deletedAttachments, deletedNotes, nil
+}
+
+func (r *retentionRepository) CreateRun(ctx context.Context, run *privacymodel.RetentionRun) error {
+	return r.DB.WithContext(ctx).Create(run).Error
+}
+
+func (r *retentionRepository) UpdateRun(ctx context.Context, run *privacymodel.RetentionRun) error {
+	return r.DB.WithContext(ctx).Save(run).Error
+}
+
+func (r *retentionRepository) FindRunsByAccountID(ctx context.Context, accountID uuid.UUID, limit int) ([]privacymodel.RetentionRun, error) {
+	var runs []privacymodel.RetentionRun
+	err := r.DB.WithContext(ctx).
+		Joins("JOIN organizations ON organizations.id = retention_runs.organization_id").
+		Where("organizations.account_id = ?", accountID).
+		Order("retention_runs.started_at DESC").
+		Limit(limit).
+		Find(&runs).Error
+	return runs, err
+}
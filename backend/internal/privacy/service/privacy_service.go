@@ -0,0 +1,229 @@
+package privacyservice
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	feedbackmodel "kyooar/internal/feedback/model"
+	privacyinterface "kyooar/internal/privacy/interface"
+	privacymodel "kyooar/internal/privacy/model"
+	"kyooar/internal/shared/errors"
+	"kyooar/internal/shared/logger"
+	sharedServices "kyooar/internal/shared/services"
+)
+
+type privacyService struct {
+	privacyRepo privacyinterface.PrivacyRepository
+	storage     sharedServices.Storage
+}
+
+func NewPrivacyService(
+	privacyRepo privacyinterface.PrivacyRepository,
+	storage sharedServices.Storage,
+) privacyinterface.PrivacyService {
+	return &privacyService{
+		privacyRepo: privacyRepo,
+		storage:     storage,
+	}
+}
+
+// Export gathers everything stored about the customer across the account's
+// organizations.
+func (s *privacyService) Export(ctx context.Context, accountID uuid.UUID, req *privacymodel.SubjectRequest) (*privacymodel.SubjectExport, error) {
+	audit, value, err := s.openRequest(ctx, accountID, privacymodel.RequestTypeExport, req)
+	if err != nil {
+		return nil, err
+	}
+
+	export, err := s.export(ctx, accountID, audit, value)
+	s.closeRequest(ctx, audit, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// Erase anonymizes every feedback left with the identifier. Ratings and
+// choice answers are kept so organization analytics do not change; contact
+// details, free text, photos, reply conversations and internal notes are
+// removed, including from feedback that was soft-deleted.
+func (s *privacyService) Erase(ctx context.Context, accountID uuid.UUID, req *privacymodel.SubjectRequest) (*privacymodel.ErasureResult, error) {
+	audit, value, err := s.openRequest(ctx, accountID, privacymodel.RequestTypeErase, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.erase(ctx, accountID, audit, value)
+	s.closeRequest(ctx, audit, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *privacyService) ListRequests(ctx context.Context, accountID uuid.UUID) ([]privacymodel.DataSubjectRequest, error) {
+	return s.privacyRepo.FindRequestsByAccountID(ctx, accountID)
+}
+
+func (s *privacyService) export(ctx context.Context, accountID uuid.UUID, audit *privacymodel.DataSubjectRequest, value string) (*privacymodel.SubjectExport, error) {
+	feedbacks, err := s.privacyRepo.FindFeedbacks(ctx, accountID, audit.IdentifierType, value)
+	if err != nil {
+		return nil, err
+	}
+	audit.MatchedFeedbacks = len(feedbacks)
+
+	feedbackIDs := feedbackIDs(feedbacks)
+
+	messages, err := s.privacyRepo.FindMessages(ctx, feedbackIDs)
+	if err != nil {
+		return nil, err
+	}
+	messagesByFeedback := make(map[uuid.UUID][]feedbackmodel.PublicMessage)
+	for _, message := range messages {
+		messagesByFeedback[message.FeedbackID] = append(messagesByFeedback[message.FeedbackID], feedbackmodel.PublicMessage{
+			Direction: message.Direction,
+			Body:      message.Body,
+			CreatedAt: message.CreatedAt,
+		})
+	}
+
+	attachments, err := s.privacyRepo.FindAttachments(ctx, feedbackIDs)
+	if err != nil {
+		return nil, err
+	}
+	attachmentCounts := make(map[uuid.UUID]int)
+	for _, attachment := range attachments {
+		attachmentCounts[*attachment.FeedbackID]++
+	}
+
+	export := &privacymodel.SubjectExport{
+		RequestID:   audit.ID,
+		Identifier:  value,
+		GeneratedAt: time.Now(),
+		Feedbacks:   make([]privacymodel.SubjectFeedback, 0, len(feedbacks)),
+	}
+	for _, feedback := range feedbacks {
+		messages := messagesByFeedback[feedback.ID]
+		if messages == nil {
+			messages = []feedbackmodel.PublicMessage{}
+		}
+		export.Feedbacks = append(export.Feedbacks, privacymodel.SubjectFeedback{
+			ID:               feedback.ID,
+			OrganizationID:   feedback.OrganizationID,
+			OrganizationName: feedback.Organization.Name,
+			ProductName:      feedback.Product.Name,
+			CreatedAt:        feedback.CreatedAt,
+			CustomerName:     feedback.CustomerName,
+			CustomerEmail:    feedback.CustomerEmail,
+			CustomerPhone:    feedback.CustomerPhone,
+			OverallRating:    feedback.OverallRating,
+			Responses:        feedback.Responses,
+			DeviceInfo:       feedback.DeviceInfo,
+			Language:         feedback.Language,
+			Attachments:      attachmentCounts[feedback.ID],
+			Messages:         messages,
+		})
+	}
+
+	return export, nil
+}
+
+func (s *privacyService) erase(ctx context.Context, accountID uuid.UUID, audit *privacymodel.DataSubjectRequest, value string) (*privacymodel.ErasureResult, error) {
+	feedbacks, err := s.privacyRepo.FindFeedbacks(ctx, accountID, audit.IdentifierType, value)
+	if err != nil {
+		return nil, err
+	}
+	audit.MatchedFeedbacks = len(feedbacks)
+
+	attachments, err := s.privacyRepo.FindAttachments(ctx, feedbackIDs(feedbacks))
+	if err != nil {
+		return nil, err
+	}
+
+	questionTypes, err := s.privacyRepo.FindQuestionTypes(ctx, questionIDs(feedbacks))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range feedbacks {
+		feedbacks[i].Anonymize(questionTypes, now)
+	}
+
+	result, err := s.privacyRepo.EraseFeedbacks(ctx, feedbacks)
+	if err != nil {
+		return nil, err
+	}
+	result.RequestID = audit.ID
+	audit.DeletedMessages = result.DeletedMessages
+	audit.DeletedAttachments = result.DeletedAttachments
+	audit.DeletedNotes = result.DeletedNotes
+	audit.DeletedActivities = result.DeletedActivities
+
+	// Files are removed after the records so a failed transaction never
+	// leaves feedback pointing at missing photos.
+	deleteAttachmentFiles(ctx, s.storage, attachments, logrus.Fields{
+		"request_id": audit.ID,
+	})
+
+	return result, nil
+}
+
+// openRequest validates the identifier and writes the audit record before
+// any data is read.
+func (s *privacyService) openRequest(ctx context.Context, accountID uuid.UUID, requestType privacymodel.RequestType, req *privacymodel.SubjectRequest) (*privacymodel.DataSubjectRequest, string, error) {
+	identifierType, value, ok := req.Identifier()
+	if !ok {
+		return nil, "", errors.BadRequest("Provide either an email or a phone number")
+	}
+
+	source := req.Source
+	if source == "" {
+		source = privacymodel.SourceAPI
+	}
+
+	audit := &privacymodel.DataSubjectRequest{
+		AccountID:        accountID,
+		Type:             requestType,
+		IdentifierType:   identifierType,
+		IdentifierHash:   privacymodel.HashIdentifier(identifierType, value),
+		MaskedIdentifier: privacymodel.MaskIdentifier(identifierType, value),
+		Source:           source,
+		RequestedBy:      req.RequestedBy,
+		Reason:           req.Reason,
+		Status:           privacymodel.RequestStatusPending,
+	}
+	if err := s.privacyRepo.CreateRequest(ctx, audit); err != nil {
+		return nil, "", err
+	}
+
+	return audit, value, nil
+}
+
+func (s *privacyService) closeRequest(ctx context.Context, audit *privacymodel.DataSubjectRequest, result error) {
+	now := time.Now()
+	audit.CompletedAt = &now
+	audit.Status = privacymodel.RequestStatusCompleted
+	if result != nil {
+		audit.Status = privacymodel.RequestStatusFailed
+		audit.Error = result.Error()
+	}
+
+	if err := s.privacyRepo.UpdateRequest(ctx, audit); err != nil {
+		logger.Error("Failed to update data subject request", err, logrus.Fields{
+			"request_id": audit.ID,
+		})
+	}
+}
+
+func feedbackIDs(feedbacks []feedbackmodel.Feedback) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(feedbacks))
+	for _, feedback := range feedbacks {
+		ids = append(ids, feedback.ID)
+	}
+	return ids
+}
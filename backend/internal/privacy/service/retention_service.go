@@ -0,0 +1,224 @@
+package privacyservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	feedbackmodel "kyooar/internal/feedback/model"
+	organizationmodel "kyooar/internal/organization/model"
+	privacyinterface "kyooar/internal/privacy/interface"
+	privacymodel "kyooar/internal/privacy/model"
+	"kyooar/internal/shared/logger"
+	sharedServices "kyooar/internal/shared/services"
+)
+
+// maxRetentionRuns bounds how many runs are listed for an account.
+const maxRetentionRuns = 200
+
+type retentionService struct {
+	retentionRepo privacyinterface.RetentionRepository
+	privacyRepo   privacyinterface.PrivacyRepository
+	storage       sharedServices.Storage
+	batchSize     int
+}
+
+func NewRetentionService(
+	retentionRepo privacyinterface.RetentionRepository,
+	privacyRepo privacyinterface.PrivacyRepository,
+	storage sharedServices.Storage,
+	batchSize int,
+) privacyinterface.RetentionService {
+	if batchSize < 1 {
+		batchSize = 500
+	}
+	return &retentionService{
+		retentionRepo: retentionRepo,
+		privacyRepo:   privacyRepo,
+		storage:       storage,
+		batchSize:     batchSize,
+	}
+}
+
+// Enforce runs each organization's policy and records a run per
+// organization. A failing organization does not stop the others; the number
+// of failures is returned once all have been processed.
+func (s *retentionService) Enforce(ctx context.Context) error {
+	organizations, err := s.retentionRepo.FindOrganizationsWithPolicy(ctx)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for i := range organizations {
+		if err := s.enforceOrganization(ctx, &organizations[i]); err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("retention failed for %d of %d organizations", failed, len(organizations))
+	}
+	return nil
+}
+
+func (s *retentionService) ListRuns(ctx context.Context, accountID uuid.UUID) ([]privacymodel.RetentionRun, error) {
+	return s.retentionRepo.FindRunsByAccountID(ctx, accountID, maxRetentionRuns)
+}
+
+func (s *retentionService) enforceOrganization(ctx context.Context, organization *organizationmodel.Organization) error {
+	policy := organization.Settings.Retention
+	if !policy.Enabled() {
+		return nil
+	}
+
+	run := &privacymodel.RetentionRun{
+		OrganizationID:      organization.ID,
+		AnonymizeAfterDays:  policy.AnonymizeAfterDays,
+		DeleteTextAfterDays: policy.DeleteTextAfterDays,
+		StartedAt:           time.Now(),
+	}
+	if err := s.retentionRepo.CreateRun(ctx, run); err != nil {
+		logger.Error("Failed to record retention run", err, logrus.Fields{
+			"organization_id": organization.ID,
+		})
+		return err
+	}
+
+	err := s.anonymize(ctx, organization.ID, policy, run)
+	if err == nil {
+		err = s.removeText(ctx, organization.ID, policy, run)
+	}
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	if err != nil {
+		run.Error = err.Error()
+		logger.Error("Retention run failed", err, logrus.Fields{
+			"organization_id": organization.ID,
+			"run_id":          run.ID,
+		})
+	}
+
+	if updateErr := s.retentionRepo.UpdateRun(ctx, run); updateErr != nil {
+		logger.Error("Failed to update retention run", updateErr, logrus.Fields{
+			"organization_id": organization.ID,
+			"run_id":          run.ID,
+		})
+	}
+
+	return err
+}
+
+func (s *retentionService) anonymize(ctx context.Context, organizationID uuid.UUID, policy organizationmodel.RetentionPolicy, run *privacymodel.RetentionRun) error {
+	if policy.AnonymizeAfterDays <= 0 {
+		return nil
+	}
+	cutoff := run.StartedAt.AddDate(0, 0, -policy.AnonymizeAfterDays)
+
+	for {
+		feedbacks, err := s.retentionRepo.FindFeedbacksToAnonymize(ctx, organizationID, cutoff, s.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(feedbacks) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		for i := range feedbacks {
+			feedbacks[i].StripPersonalData(now)
+		}
+		if err := s.retentionRepo.SaveAnonymized(ctx, feedbacks); err != nil {
+			return err
+		}
+		run.AnonymizedFeedbacks += len(feedbacks)
+
+		if len(feedbacks) < s.batchSize {
+			return nil
+		}
+	}
+}
+
+func (s *retentionService) removeText(ctx context.Context, organizationID uuid.UUID, policy organizationmodel.RetentionPolicy, run *privacymodel.RetentionRun) error {
+	if policy.DeleteTextAfterDays <= 0 {
+		return nil
+	}
+	cutoff := run.StartedAt.AddDate(0, 0, -policy.DeleteTextAfterDays)
+
+	for {
+		feedbacks, err := s.retentionRepo.FindFeedbacksWithText(ctx, organizationID, cutoff, s.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(feedbacks) == 0 {
+			return nil
+		}
+
+		ids := feedbackIDs(feedbacks)
+		attachments, err := s.privacyRepo.FindAttachments(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		questionTypes, err := s.privacyRepo.FindQuestionTypes(ctx, questionIDs(feedbacks))
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for i := range feedbacks {
+			feedbacks[i].RemoveFreeText(questionTypes, now)
+		}
+
+		deletedMessages, deletedAttachments, deletedNotes, err := s.retentionRepo.SaveTextRemoved(ctx, feedbacks)
+		if err != nil {
+			return err
+		}
+		run.TextRemovedFeedbacks += len(feedbacks)
+		run.DeletedMessages += deletedMessages
+		run.DeletedAttachments += deletedAttachments
+		run.DeletedNotes += deletedNotes
+
+		deleteAttachmentFiles(ctx, s.storage, attachments, logrus.Fields{
+			"organization_id": organizationID,
+			"run_id":          run.ID,
+		})
+
+		if len(feedbacks) < s.batchSize {
+			return nil
+		}
+	}
+}
+
+func questionIDs(feedbacks []feedbackmodel.Feedback) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, feedback := range feedbacks {
+		for _, response := range feedback.Responses {
+			ids = append(ids, response.QuestionID)
+		}
+	}
+	return ids
+}
+
+// deleteAttachmentFiles removes photo files once their records are gone.
+// Failures are logged rather than returned, since the records can no longer
+// point at the files.
+func deleteAttachmentFiles(ctx context.Context, storage sharedServices.Storage, attachments []feedbackmodel.FeedbackAttachment, fields logrus.Fields) {
+	for _, attachment := range attachments {
+		for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+			if key == "" {
+				continue
+			}
+			if err := storage.Delete(ctx, key); err != nil {
+				logFields := logrus.Fields{"attachment_id": attachment.ID}
+				for k, v := range fields {
+					logFields[k] = v
+				}
+				logger.Error("Failed to delete attachment file", err, logFields)
+			}
+		}
+	}
+}
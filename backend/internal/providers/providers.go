@@ -22,6 +22,8 @@ func RegisterAll(i *do.Injector, cfg *config.Config, db *gorm.DB) {
 	do.ProvideValue(i, db)
 	
 	do.Provide(i, sharedServices.NewEmailService)
+	do.Provide(i, sharedServices.NewStorage)
+	do.Provide(i, sharedServices.NewProofOfWork)
 	do.Provide(i, middleware.NewMiddlewareProvider)
 	
 	organization.RegisterNewModule(i)
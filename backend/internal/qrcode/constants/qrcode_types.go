@@ -8,4 +8,12 @@ const (
 	QRCodeTypeTakeaway QRCodeType = "takeaway"
 	QRCodeTypeDelivery QRCodeType = "delivery"
 	QRCodeTypeGeneral  QRCodeType = "general"
-)
\ No newline at end of file
+)
+
+func (t QRCodeType) IsValid() bool {
+	switch t {
+	case QRCodeTypeTable, QRCodeTypeLocation, QRCodeTypeTakeaway, QRCodeTypeDelivery, QRCodeTypeGeneral:
+		return true
+	}
+	return false
+}
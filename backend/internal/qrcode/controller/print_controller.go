@@ -0,0 +1,220 @@
+package qrcodecontroller
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	qrcodeinterface "kyooar/internal/qrcode/interface"
+	qrcodemodel "kyooar/internal/qrcode/model"
+	"kyooar/internal/shared/errors"
+	"kyooar/internal/shared/logger"
+	"kyooar/internal/shared/middleware"
+	"kyooar/internal/shared/response"
+	"kyooar/internal/shared/validator"
+	"github.com/sirupsen/logrus"
+)
+
+type PrintController struct {
+	printService qrcodeinterface.PrintService
+	validator    *validator.Validator
+}
+
+func NewPrintController(printService qrcodeinterface.PrintService) *PrintController {
+	return &PrintController{
+		printService: printService,
+		validator:    validator.New(),
+	}
+}
+
+// @Summary Create print template
+// @Description Save a layout for printing QR codes: an A4 grid with its columns and rows, a folding table tent, or a sticker roll with the sticker size in millimetres, plus the call to action printed with each code
+// @Tags qr-codes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param template body qrcodemodel.SavePrintTemplateRequest true "Print template"
+// @Success 200 {object} response.Response{data=qrcodemodel.PrintTemplate}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/qr-print-templates [post]
+func (h *PrintController) CreateTemplate(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return response.Error(c, errors.BadRequest("Invalid organization ID"))
+	}
+
+	var req qrcodemodel.SavePrintTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return response.Error(c, errors.ErrBadRequest)
+	}
+
+	resourceAccountID := middleware.GetResourceAccountID(c)
+
+	template, err := h.printService.CreateTemplate(ctx, resourceAccountID, organizationID, &req)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, template)
+}
+
+// @Summary List print templates
+// @Description List the organization's saved QR code print templates
+// @Tags qr-codes
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Success 200 {object} response.Response{data=[]qrcodemodel.PrintTemplate}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/qr-print-templates [get]
+func (h *PrintController) ListTemplates(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return response.Error(c, errors.BadRequest("Invalid organization ID"))
+	}
+
+	resourceAccountID := middleware.GetResourceAccountID(c)
+
+	templates, err := h.printService.ListTemplates(ctx, resourceAccountID, organizationID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, templates)
+}
+
+// @Summary Update print template
+// @Description Replace the settings of a saved print template
+// @Tags qr-codes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param templateId path string true "Print template ID"
+// @Param template body qrcodemodel.SavePrintTemplateRequest true "Print template"
+// @Success 200 {object} response.Response{data=qrcodemodel.PrintTemplate}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/qr-print-templates/{templateId} [put]
+func (h *PrintController) UpdateTemplate(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	organizationID, templateID, err := templateParams(c)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	var req qrcodemodel.SavePrintTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return response.Error(c, errors.ErrBadRequest)
+	}
+
+	resourceAccountID := middleware.GetResourceAccountID(c)
+
+	template, err := h.printService.UpdateTemplate(ctx, resourceAccountID, organizationID, templateID, &req)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, template)
+}
+
+// @Summary Delete print template
+// @Description Delete a saved print template
+// @Tags qr-codes
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param templateId path string true "Print template ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/qr-print-templates/{templateId} [delete]
+func (h *PrintController) DeleteTemplate(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	organizationID, templateID, err := templateParams(c)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	resourceAccountID := middleware.GetResourceAccountID(c)
+
+	if err := h.printService.DeleteTemplate(ctx, resourceAccountID, organizationID, templateID); err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, map[string]string{"message": "Print template deleted successfully"})
+}
+
+// @Summary Print QR codes
+// @Description Download a PDF of the given QR codes, in order, each with its label and location, the organization name and the call to action. Pass a saved template, or a layout (a4_grid by default) to print with the default settings.
+// @Tags qr-codes
+// @Accept json
+// @Produce application/pdf
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param request body qrcodemodel.PrintQRCodesRequest true "QR codes to print"
+// @Success 200 {file} binary
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/qr-codes/print [post]
+func (h *PrintController) Print(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return response.Error(c, errors.BadRequest("Invalid organization ID"))
+	}
+
+	var req qrcodemodel.PrintQRCodesRequest
+	if err := c.Bind(&req); err != nil {
+		return response.Error(c, errors.ErrBadRequest)
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		return response.Error(c, errors.NewWithDetails("VALIDATION_ERROR", "Validation failed", http.StatusBadRequest, h.validator.FormatErrors(err)))
+	}
+
+	resourceAccountID := middleware.GetResourceAccountID(c)
+
+	pdf, err := h.printService.Print(ctx, resourceAccountID, organizationID, &req)
+	if err != nil {
+		logger.Error("Failed to print QR codes", err, logrus.Fields{
+			"account_id":      resourceAccountID,
+			"organization_id": organizationID,
+			"count":           len(req.QRCodeIDs),
+		})
+		return response.Error(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="qr-codes.pdf"`)
+	return c.Blob(http.StatusOK, "application/pdf", pdf)
+}
+
+func templateParams(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.BadRequest("Invalid organization ID")
+	}
+	templateID, err := uuid.Parse(c.Param("templateId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.BadRequest("Invalid print template ID")
+	}
+	return organizationID, templateID, nil
+}
@@ -3,31 +3,45 @@ package qrcodecontroller
 import (
 	"github.com/labstack/echo/v4"
 	qrcodeinterface "kyooar/internal/qrcode/interface"
+	qrcodemodel "kyooar/internal/qrcode/model"
 	"kyooar/internal/shared/errors"
 	"kyooar/internal/shared/logger"
 	"kyooar/internal/shared/response"
+	sharedServices "kyooar/internal/shared/services"
+	"kyooar/internal/shared/utils"
 	"github.com/sirupsen/logrus"
 )
 
 type PublicController struct {
 	qrCodeService qrcodeinterface.QRCodeService
+	proofOfWork   sharedServices.ProofOfWork
 }
 
-func NewPublicController(qrCodeService qrcodeinterface.QRCodeService) *PublicController {
+func NewPublicController(qrCodeService qrcodeinterface.QRCodeService, proofOfWork sharedServices.ProofOfWork) *PublicController {
 	return &PublicController{
 		qrCodeService: qrCodeService,
+		proofOfWork:   proofOfWork,
 	}
 }
 
+// ValidatedQRCode is a scanned QR code plus, when proof-of-work is enabled,
+// the challenge the client must solve before submitting feedback.
+type ValidatedQRCode struct {
+	*qrcodemodel.QRCode
+	Challenge *sharedServices.Challenge `json:"challenge,omitempty"`
+}
+
 // @Summary Validate QR code
-// @Description Validate a QR code and return associated data
+// @Description Validate a QR code and return associated data, including a proof-of-work challenge when enabled. A code that exists but cannot be used is refused with QR_CODE_REJECTED and the reason (inactive, expired or rotated) in the details.
 // @Tags public
 // @Accept json
 // @Produce json
 // @Param code path string true "QR Code"
-// @Success 200 {object} response.Response{data=qrcodemodel.QRCode}
+// @Param t query string false "Token from a rotating code's URL"
+// @Success 200 {object} response.Response{data=ValidatedQRCode}
 // @Failure 400 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 410 {object} response.Response
 // @Router /api/v1/public/qr/{code} [get]
 func (h *PublicController) ValidateQRCode(c echo.Context) error {
 	ctx := c.Request().Context()
@@ -36,17 +50,40 @@ func (h *PublicController) ValidateQRCode(c echo.Context) error {
 		return response.Error(c, errors.BadRequest("QR code parameter is required"))
 	}
 
-	qrCode, err := h.qrCodeService.GetByCode(ctx, code)
+	qrCode, err := h.qrCodeService.GetByCode(ctx, code, c.QueryParam("t"))
 	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return response.Error(c, appErr)
+		}
 		return response.Error(c, errors.NotFound("QR code"))
 	}
 
-	if err := h.qrCodeService.RecordScan(ctx, code); err != nil {
+	deviceInfo := utils.ExtractDeviceInfo(c.Request())
+	visit := qrcodemodel.ScanVisit{
+		IP:        deviceInfo.IP,
+		UserAgent: deviceInfo.UserAgent,
+		Platform:  deviceInfo.Platform,
+		Browser:   deviceInfo.Browser,
+		Referrer:  c.Request().Referer(),
+	}
+	if err := h.qrCodeService.RecordScan(ctx, qrCode, visit); err != nil {
 		logger.Error("Failed to record QR scan", err, logrus.Fields{
 			"qr_code_id": qrCode.ID,
 			"code":       code,
 		})
 	}
 
-	return response.Success(c, qrCode)
+	validated := ValidatedQRCode{QRCode: qrCode}
+	if h.proofOfWork.Enabled() {
+		challenge, err := h.proofOfWork.Issue(qrCode.ID.String())
+		if err != nil {
+			logger.Error("Failed to issue feedback challenge", err, logrus.Fields{
+				"qr_code_id": qrCode.ID,
+			})
+			return response.Error(c, errors.Internal("Failed to validate QR code"))
+		}
+		validated.Challenge = challenge
+	}
+
+	return response.Success(c, validated)
 }
\ No newline at end of file
@@ -1,7 +1,11 @@
 package qrcodecontroller
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -74,6 +78,95 @@ func (h *QRCodeController) Generate(c echo.Context) error {
 	return response.Success(c, qrCode)
 }
 
+// @Summary Generate QR codes in bulk
+// @Description Generate QR codes of one type from a label pattern such as "Table {1..50}", sent as JSON, or from a CSV file with label and optional location columns, sent as multipart form data with the type. Either every code is created or none is, and the batch must fit within the plan's QR code limit.
+// @Tags qr-codes
+// @Accept json
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param organizationId path string true "Organization ID"
+// @Param request body qrcodemodel.BulkGenerateRequest false "Type, pattern and location"
+// @Param type formData string false "QR code type, with a CSV file"
+// @Param file formData file false "CSV file of labels and locations"
+// @Success 200 {object} response.Response{data=[]qrcodemodel.QRCode}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 402 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{organizationId}/qr-codes/bulk [post]
+func (h *QRCodeController) BulkGenerate(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	organizationID, err := uuid.Parse(c.Param("organizationId"))
+	if err != nil {
+		return response.Error(c, errors.BadRequest("Invalid organization ID"))
+	}
+
+	var qrType qrcodemodel.QRCodeType
+	var entries []qrcodemodel.BulkQRCodeEntry
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		// Leave room for the multipart envelope around the file itself
+		c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, qrcodemodel.MaxBulkFileSize+64*1024)
+
+		qrType = qrcodemodel.QRCodeType(c.FormValue("type"))
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return response.Error(c, errors.BadRequest("A CSV file is required"))
+		}
+		if fileHeader.Size > qrcodemodel.MaxBulkFileSize {
+			return response.Error(c, errors.New("FILE_TOO_LARGE", "File is too large", http.StatusRequestEntityTooLarge))
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return response.Error(c, errors.BadRequest("Failed to read uploaded file"))
+		}
+		defer file.Close()
+
+		if entries, err = qrcodemodel.ParseBulkCSV(file); err != nil {
+			return response.Error(c, errors.BadRequest(err.Error()))
+		}
+	} else {
+		var req qrcodemodel.BulkGenerateRequest
+		if err := c.Bind(&req); err != nil {
+			return response.Error(c, errors.ErrBadRequest)
+		}
+		qrType = req.Type
+
+		labels, err := qrcodemodel.ExpandPattern(req.Pattern)
+		if err != nil {
+			return response.Error(c, errors.BadRequest(err.Error()))
+		}
+		if req.Location != nil && len([]rune(*req.Location)) > qrcodemodel.MaxLocationLength {
+			return response.Error(c, errors.BadRequest(fmt.Sprintf("Location must be at most %d characters", qrcodemodel.MaxLocationLength)))
+		}
+		entries = make([]qrcodemodel.BulkQRCodeEntry, len(labels))
+		for i, label := range labels {
+			entries[i] = qrcodemodel.BulkQRCodeEntry{Label: label, Location: req.Location}
+		}
+	}
+
+	if !qrType.IsValid() {
+		return response.Error(c, errors.BadRequest("Type must be one of table, location, takeaway, delivery or general"))
+	}
+
+	resourceAccountID := middleware.GetResourceAccountID(c)
+
+	qrCodes, err := h.qrCodeService.BulkGenerate(ctx, resourceAccountID, organizationID, qrType, entries)
+	if err != nil {
+		logger.Error("Failed to generate QR codes in bulk", err, logrus.Fields{
+			"account_id":      resourceAccountID,
+			"organization_id": organizationID,
+			"type":            qrType,
+			"count":           len(entries),
+		})
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, qrCodes)
+}
+
 // @Summary Get QR codes by organization
 // @Description Get all QR codes for a specific organization
 // @Tags qr-codes
@@ -143,14 +236,89 @@ func (h *QRCodeController) Delete(c echo.Context) error {
 	})
 }
 
+// @Summary Get QR code image
+// @Description Render the QR code's public URL as a PNG or SVG image. Responses carry an ETag and return 304 when If-None-Match is current.
+// @Tags qr-codes
+// @Produce image/png
+// @Produce image/svg+xml
+// @Security ApiKeyAuth
+// @Param id path string true "QR Code ID"
+// @Param format query string false "png (default) or svg"
+// @Param size query int false "Width and height in pixels (default: 512, 64-4096)"
+// @Param ec query string false "Error correction level L, M (default), Q or H"
+// @Param margin query int false "Quiet zone in modules (default: 4, max: 16)"
+// @Param logo query boolean false "Embed the organization logo in the center; needs the custom branding feature and raises the level to H"
+// @Success 200 {file} binary
+// @Success 304 "Not modified"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/qr-codes/{id}/image [get]
+func (h *QRCodeController) GetImage(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	qrCodeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return response.Error(c, errors.ErrBadRequest)
+	}
+
+	opts := qrcodemodel.ImageOptions{
+		Format: qrcodemodel.ImageFormat(c.QueryParam("format")),
+		Level:  c.QueryParam("ec"),
+		Logo:   c.QueryParam("logo") == "true",
+	}
+	if size := c.QueryParam("size"); size != "" {
+		if opts.Size, err = strconv.Atoi(size); err != nil {
+			return response.Error(c, errors.BadRequest("Size must be a number"))
+		}
+	}
+	if marginParam := c.QueryParam("margin"); marginParam != "" {
+		margin, err := strconv.Atoi(marginParam)
+		if err != nil {
+			return response.Error(c, errors.BadRequest("Margin must be a number"))
+		}
+		opts.Margin = &margin
+	}
+	if err := opts.Normalize(); err != nil {
+		return response.Error(c, errors.BadRequest(err.Error()))
+	}
+
+	resourceAccountID := middleware.GetResourceAccountID(c)
+
+	image, err := h.qrCodeService.GetImage(ctx, resourceAccountID, qrCodeID, opts, c.Request().Header.Get("If-None-Match"))
+	if err != nil {
+		logger.Error("Failed to get QR code image", err, logrus.Fields{
+			"account_id": resourceAccountID,
+			"qr_code_id": qrCodeID,
+		})
+		return response.Error(c, err)
+	}
+
+	c.Response().Header().Set("ETag", image.ETag)
+	c.Response().Header().Set("Cache-Control", "private, no-cache")
+	if image.Data == nil {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(http.StatusOK, image.ContentType, image.Data)
+}
+
 type UpdateQRCodeRequest struct {
 	IsActive *bool   `json:"is_active"`
 	Label    *string `json:"label" validate:"omitempty,min=1,max=100"`
 	Location *string `json:"location" validate:"omitempty,max=200"`
+	// StartsAt and ExpiresAt limit when the code works, such as for an event.
+	StartsAt  *time.Time `json:"starts_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	// ClearSchedule removes the start and expiry times.
+	ClearSchedule bool `json:"clear_schedule"`
+	// RotationSeconds makes the code's URL carry a signed token replaced this
+	// often, for codes shown on screens. Zero makes the code static again.
+	RotationSeconds *int `json:"rotation_seconds" validate:"omitempty,min=0"`
 }
 
 // @Summary Update QR code
-// @Description Update QR code details like active status, label, or location
+// @Description Update QR code details like active status, label, location, the times it starts and stops working, or how often a rotating code's token changes
 // @Tags qr-codes
 // @Accept json
 // @Produce json
@@ -183,9 +351,13 @@ func (h *QRCodeController) Update(c echo.Context) error {
 	}
 
 	serviceReq := &qrcodeinterface.UpdateQRCodeRequest{
-		IsActive: req.IsActive,
-		Label:    req.Label,
-		Location: req.Location,
+		IsActive:        req.IsActive,
+		Label:           req.Label,
+		Location:        req.Location,
+		StartsAt:        req.StartsAt,
+		ExpiresAt:       req.ExpiresAt,
+		ClearSchedule:   req.ClearSchedule,
+		RotationSeconds: req.RotationSeconds,
 	}
 
 	updatedQRCode, err := h.qrCodeService.Update(ctx, resourceAccountID, qrCodeID, serviceReq)
@@ -0,0 +1,26 @@
+package qrcodeinterface
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	qrcodemodel "kyooar/internal/qrcode/model"
+)
+
+type PrintTemplateRepository interface {
+	Create(ctx context.Context, template *qrcodemodel.PrintTemplate) error
+	Update(ctx context.Context, template *qrcodemodel.PrintTemplate) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, id uuid.UUID) (*qrcodemodel.PrintTemplate, error)
+	FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]qrcodemodel.PrintTemplate, error)
+}
+
+type PrintService interface {
+	CreateTemplate(ctx context.Context, accountID, organizationID uuid.UUID, req *qrcodemodel.SavePrintTemplateRequest) (*qrcodemodel.PrintTemplate, error)
+	ListTemplates(ctx context.Context, accountID, organizationID uuid.UUID) ([]qrcodemodel.PrintTemplate, error)
+	UpdateTemplate(ctx context.Context, accountID, organizationID, templateID uuid.UUID, req *qrcodemodel.SavePrintTemplateRequest) (*qrcodemodel.PrintTemplate, error)
+	DeleteTemplate(ctx context.Context, accountID, organizationID, templateID uuid.UUID) error
+	// Print writes a PDF of the organization's QR codes, in the order given,
+	// laid out by a saved template or the default one for a layout.
+	Print(ctx context.Context, accountID, organizationID uuid.UUID, req *qrcodemodel.PrintQRCodesRequest) ([]byte, error)
+}
@@ -2,33 +2,64 @@ package qrcodeinterface
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	qrcodemodel "kyooar/internal/qrcode/model"
 )
 
 type UpdateQRCodeRequest struct {
-	IsActive *bool   `json:"is_active"`
-	Label    *string `json:"label"`
-	Location *string `json:"location"`
+	IsActive  *bool      `json:"is_active"`
+	Label     *string    `json:"label"`
+	Location  *string    `json:"location"`
+	StartsAt  *time.Time `json:"starts_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	// ClearSchedule removes the start and expiry times.
+	ClearSchedule   bool `json:"clear_schedule"`
+	RotationSeconds *int `json:"rotation_seconds"`
 }
 
 type QRCodeRepository interface {
 	Create(ctx context.Context, qrCode *qrcodemodel.QRCode) error
+	// CreateBatch creates all of the QR codes in one transaction, or none.
+	// It first passes the number of QR codes the account already has to
+	// check, holding a per-account lock until the codes are created so
+	// concurrent batches cannot both pass a plan limit; an error from check
+	// is returned without creating anything.
+	CreateBatch(ctx context.Context, accountID uuid.UUID, qrCodes []*qrcodemodel.QRCode, check func(count int64) error) error
 	FindByID(ctx context.Context, id uuid.UUID, preloads ...string) (*qrcodemodel.QRCode, error)
 	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]qrcodemodel.QRCode, error)
 	FindByCode(ctx context.Context, code string) (*qrcodemodel.QRCode, error)
 	FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]qrcodemodel.QRCode, error)
 	Update(ctx context.Context, qrCode *qrcodemodel.QRCode) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	IncrementScanCount(ctx context.Context, id uuid.UUID) error
 }
 
 type QRCodeService interface {
 	Generate(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, qrType qrcodemodel.QRCodeType, label string, location *string) (*qrcodemodel.QRCode, error)
-	GetByCode(ctx context.Context, code string) (*qrcodemodel.QRCode, error)
+	// BulkGenerate creates a QR code of the type for each entry, all or none,
+	// when the plan has room for every one of them.
+	BulkGenerate(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, qrType qrcodemodel.QRCodeType, entries []qrcodemodel.BulkQRCodeEntry) ([]*qrcodemodel.QRCode, error)
+	// GetByCode returns the code a customer scanned, with the token from its
+	// URL when it rotates. A code that cannot be used is refused with a
+	// qrcodemodel.RejectedError giving the reason.
+	GetByCode(ctx context.Context, code string, token string) (*qrcodemodel.QRCode, error)
+	// CheckAccess refuses feedback for a code that cannot be used, allowing
+	// rotating codes' tokens RotatingTokenGrace to finish the form.
+	CheckAccess(qrCode *qrcodemodel.QRCode, token string) error
 	GetByOrganizationID(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID) ([]qrcodemodel.QRCode, error)
 	Update(ctx context.Context, accountID uuid.UUID, qrCodeID uuid.UUID, updateReq *UpdateQRCodeRequest) (*qrcodemodel.QRCode, error)
 	Delete(ctx context.Context, accountID uuid.UUID, qrCodeID uuid.UUID) error
-	RecordScan(ctx context.Context, code string) error
+	// RecordScan logs a scan of the code for analytics and bumps its scan
+	// counter.
+	RecordScan(ctx context.Context, qrCode *qrcodemodel.QRCode, visit qrcodemodel.ScanVisit) error
+	// CleanupOldScanEvents deletes scan events older than the retention
+	// period. The codes' scan counters keep their totals.
+	CleanupOldScanEvents(ctx context.Context, retentionDays int) error
+	// PublicURL is the address the code sends customers to, carrying the
+	// current token when the code rotates.
+	PublicURL(qrCode *qrcodemodel.QRCode) string
+	// GetImage renders the QR code, returning an image without data when
+	// ifNoneMatch already names the current version.
+	GetImage(ctx context.Context, accountID uuid.UUID, qrCodeID uuid.UUID, opts qrcodemodel.ImageOptions, ifNoneMatch string) (*qrcodemodel.QRCodeImage, error)
 }
\ No newline at end of file
@@ -0,0 +1,24 @@
+package qrcodeinterface
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	qrcodemodel "kyooar/internal/qrcode/model"
+)
+
+type ScanEventRepository interface {
+	// Record saves the scan and bumps the code's scan counter in one
+	// transaction.
+	Record(ctx context.Context, event *qrcodemodel.QRScanEvent) error
+	// CountByQRCodeIDs counts the scans of each code since the time, leaving
+	// out codes without any.
+	CountByQRCodeIDs(ctx context.Context, qrCodeIDs []uuid.UUID, since time.Time) (map[uuid.UUID]qrcodemodel.ScanCounts, error)
+	// CountByDay counts the organization's scans per day since the time,
+	// oldest first.
+	CountByDay(ctx context.Context, organizationID uuid.UUID, since time.Time) ([]qrcodemodel.DailyScanCounts, error)
+	// DeleteBefore deletes up to limit scans older than the time and returns
+	// how many were deleted.
+	DeleteBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+}
@@ -0,0 +1,151 @@
+package qrcodemodel
+
+import (
+	"encoding/csv"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxBulkQRCodes bounds how many QR codes one bulk request creates.
+	MaxBulkQRCodes = 500
+	// MaxBulkFileSize bounds the size of an uploaded CSV of labels in bytes.
+	MaxBulkFileSize = 1 << 20
+	MaxLabelLength    = 100
+	MaxLocationLength = 200
+)
+
+// BulkQRCodeEntry is one QR code to create in a batch.
+type BulkQRCodeEntry struct {
+	Label    string  `json:"label"`
+	Location *string `json:"location"`
+}
+
+type BulkGenerateRequest struct {
+	Type QRCodeType `json:"type"`
+	// Pattern is a label with one numeric range, such as "Table {1..50}".
+	Pattern string `json:"pattern"`
+	// Location is given to every code in the batch.
+	Location *string `json:"location"`
+}
+
+var patternRange = regexp.MustCompile(`\{(\d+)\.\.(\d+)\}`)
+
+// ExpandPattern returns the labels a pattern stands for: "Table {1..3}" is
+// Table 1, Table 2 and Table 3. A range can count down, and a start with a
+// leading zero such as {01..12} pads the numbers to its width.
+func ExpandPattern(pattern string) ([]string, error) {
+	pattern = strings.TrimSpace(pattern)
+	matches := patternRange.FindAllStringSubmatchIndex(pattern, -1)
+	if len(matches) != 1 {
+		return nil, fmt.Errorf("Pattern must contain one range such as {1..50}")
+	}
+	m := matches[0]
+	from, errFrom := strconv.Atoi(pattern[m[2]:m[3]])
+	to, errTo := strconv.Atoi(pattern[m[4]:m[5]])
+	if errFrom != nil || errTo != nil {
+		return nil, fmt.Errorf("Pattern range is too large")
+	}
+
+	step := 1
+	if to < from {
+		step = -1
+	}
+	// Both ends are non-negative, so the span cannot overflow, but adding one
+	// to it could.
+	if span := (to - from) * step; span > MaxBulkQRCodes-1 {
+		return nil, fmt.Errorf("A batch can create at most %d QR codes", MaxBulkQRCodes)
+	}
+	width := 0
+	if start := pattern[m[2]:m[3]]; len(start) > 1 && start[0] == '0' {
+		width = len(start)
+	}
+
+	prefix, suffix := pattern[:m[0]], pattern[m[1]:]
+	var labels []string
+	for n := from; ; n += step {
+		label := fmt.Sprintf("%s%0*d%s", prefix, width, n, suffix)
+		if len([]rune(label)) > MaxLabelLength {
+			return nil, fmt.Errorf("Labels must be at most %d characters", MaxLabelLength)
+		}
+		labels = append(labels, label)
+		if n == to {
+			break
+		}
+	}
+	return labels, nil
+}
+
+// ParseBulkCSV reads QR codes from a CSV file whose header row names a label
+// column and, optionally, a location column. Any invalid row fails the whole
+// file.
+func ParseBulkCSV(r io.Reader) ([]BulkQRCodeEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("The file must be a CSV file with a header row")
+	}
+	labelColumn, locationColumn := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		case "label":
+			labelColumn = i
+		case "location":
+			locationColumn = i
+		}
+	}
+	if labelColumn == -1 {
+		return nil, fmt.Errorf("The file must have a label column")
+	}
+
+	var entries []BulkQRCodeEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if stderrors.As(err, &parseErr) {
+				return nil, fmt.Errorf("Row %d: %v", parseErr.Line, parseErr.Err)
+			}
+			return nil, err
+		}
+		// Blank lines are skipped, so take the row number from the reader.
+		row, _ := reader.FieldPos(0)
+		if len(entries) == MaxBulkQRCodes {
+			return nil, fmt.Errorf("A batch can create at most %d QR codes", MaxBulkQRCodes)
+		}
+
+		var entry BulkQRCodeEntry
+		if labelColumn < len(record) {
+			entry.Label = strings.TrimSpace(record[labelColumn])
+		}
+		if entry.Label == "" {
+			return nil, fmt.Errorf("Row %d: label is required", row)
+		}
+		if len([]rune(entry.Label)) > MaxLabelLength {
+			return nil, fmt.Errorf("Row %d: label must be at most %d characters", row, MaxLabelLength)
+		}
+		if locationColumn != -1 && locationColumn < len(record) {
+			if location := strings.TrimSpace(record[locationColumn]); location != "" {
+				if len([]rune(location)) > MaxLocationLength {
+					return nil, fmt.Errorf("Row %d: location must be at most %d characters", row, MaxLocationLength)
+				}
+				entry.Location = &location
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("The file has no QR codes to create")
+	}
+	return entries, nil
+}
@@ -0,0 +1,91 @@
+package qrcodemodel
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	DefaultImageSize   = 512
+	MinImageSize       = 64
+	MaxImageSize       = 4096
+	DefaultImageMargin = 4
+	MaxImageMargin     = 16
+	DefaultImageLevel  = "M"
+)
+
+type ImageFormat string
+
+const (
+	ImageFormatPNG ImageFormat = "png"
+	ImageFormatSVG ImageFormat = "svg"
+)
+
+func (f ImageFormat) ContentType() string {
+	if f == ImageFormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// ImageOptions control how a QR code image is rendered.
+type ImageOptions struct {
+	Format ImageFormat
+	// Size is the width and height in pixels.
+	Size int
+	// Level is the error correction level: L, M, Q or H.
+	Level string
+	// Margin is the quiet zone in modules. Scanners need at least 4.
+	Margin *int
+	// Logo embeds the organization logo in the center, which needs the
+	// custom branding feature. It raises the level to H.
+	Logo bool
+}
+
+// Normalize fills in defaults and checks the options.
+func (o *ImageOptions) Normalize() error {
+	o.Format = ImageFormat(strings.ToLower(string(o.Format)))
+	switch o.Format {
+	case "":
+		o.Format = ImageFormatPNG
+	case ImageFormatPNG, ImageFormatSVG:
+	default:
+		return fmt.Errorf("Format must be png or svg")
+	}
+
+	if o.Size == 0 {
+		o.Size = DefaultImageSize
+	}
+	if o.Size < MinImageSize || o.Size > MaxImageSize {
+		return fmt.Errorf("Size must be between %d and %d", MinImageSize, MaxImageSize)
+	}
+
+	o.Level = strings.ToUpper(o.Level)
+	switch o.Level {
+	case "":
+		o.Level = DefaultImageLevel
+	case "L", "M", "Q", "H":
+	default:
+		return fmt.Errorf("Error correction level must be L, M, Q or H")
+	}
+	if o.Logo {
+		o.Level = "H"
+	}
+
+	if o.Margin == nil {
+		margin := DefaultImageMargin
+		o.Margin = &margin
+	}
+	if *o.Margin < 0 || *o.Margin > MaxImageMargin {
+		return fmt.Errorf("Margin must be between 0 and %d", MaxImageMargin)
+	}
+	return nil
+}
+
+// QRCodeImage is a rendered QR code. Data is nil when the client's cached
+// copy, identified by ETag, is still current.
+type QRCodeImage struct {
+	ContentType string
+	ETag        string
+	Data        []byte
+}
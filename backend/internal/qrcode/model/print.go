@@ -0,0 +1,136 @@
+package qrcodemodel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	sharedModels "kyooar/internal/shared/models"
+)
+
+const (
+	// MaxPrintCodes bounds how many QR codes one PDF holds.
+	MaxPrintCodes              = 500
+	MaxPrintTemplateNameLength = 100
+	MaxCallToActionLength      = 120
+	DefaultCallToAction        = "Scan to share your feedback"
+	// MaxPrintGridColumns and MaxPrintGridRows bound the A4 grid so each
+	// cell leaves room for a code with modules of at least half a millimetre
+	// beside two lines of label and call to action.
+	MaxPrintGridColumns = 4
+	MaxPrintGridRows    = 5
+)
+
+type PrintLayout string
+
+const (
+	// PrintLayoutA4Grid fills A4 pages with a grid of codes to cut apart.
+	PrintLayoutA4Grid PrintLayout = "a4_grid"
+	// PrintLayoutTableTent prints one code per A4 page, folded across the
+	// middle into a card that stands on a table and reads from both sides.
+	PrintLayoutTableTent PrintLayout = "table_tent"
+	// PrintLayoutStickerRoll prints one code per page sized to the sticker,
+	// for label printers.
+	PrintLayoutStickerRoll PrintLayout = "sticker_roll"
+)
+
+func (l PrintLayout) IsValid() bool {
+	return l == PrintLayoutA4Grid || l == PrintLayoutTableTent || l == PrintLayoutStickerRoll
+}
+
+// PrintTemplate is an organization's saved layout for printing QR codes.
+type PrintTemplate struct {
+	sharedModels.BaseModel
+	OrganizationID uuid.UUID   `gorm:"not null" json:"organization_id"`
+	Name           string      `gorm:"not null" json:"name"`
+	Layout         PrintLayout `gorm:"not null" json:"layout"`
+	CallToAction   string      `json:"call_to_action"`
+	// Columns and Rows size the A4 grid.
+	Columns int `json:"columns"`
+	Rows    int `json:"rows"`
+	// StickerWidthMM and StickerHeightMM size the sticker roll.
+	StickerWidthMM  int `gorm:"column:sticker_width_mm" json:"sticker_width_mm"`
+	StickerHeightMM int `gorm:"column:sticker_height_mm" json:"sticker_height_mm"`
+}
+
+// DefaultPrintTemplate is the template used when printing without a saved
+// one.
+func DefaultPrintTemplate(layout PrintLayout) *PrintTemplate {
+	template := &PrintTemplate{Layout: layout}
+	template.applyDefaults()
+	return template
+}
+
+func (t *PrintTemplate) applyDefaults() {
+	if t.CallToAction == "" {
+		t.CallToAction = DefaultCallToAction
+	}
+	if t.Columns == 0 {
+		t.Columns = 3
+	}
+	if t.Rows == 0 {
+		t.Rows = 4
+	}
+	if t.StickerWidthMM == 0 {
+		t.StickerWidthMM = 50
+	}
+	if t.StickerHeightMM == 0 {
+		t.StickerHeightMM = 70
+	}
+}
+
+type SavePrintTemplateRequest struct {
+	Name            string      `json:"name" validate:"required"`
+	Layout          PrintLayout `json:"layout" validate:"required"`
+	CallToAction    string      `json:"call_to_action"`
+	Columns         int         `json:"columns"`
+	Rows            int         `json:"rows"`
+	StickerWidthMM  int         `json:"sticker_width_mm"`
+	StickerHeightMM int         `json:"sticker_height_mm"`
+}
+
+// Template checks the request and returns it as a template with defaults
+// filled in.
+func (r *SavePrintTemplateRequest) Template() (*PrintTemplate, error) {
+	name := strings.TrimSpace(r.Name)
+	if name == "" {
+		return nil, fmt.Errorf("Name is required")
+	}
+	if len([]rune(name)) > MaxPrintTemplateNameLength {
+		return nil, fmt.Errorf("Name must be at most %d characters", MaxPrintTemplateNameLength)
+	}
+	if !r.Layout.IsValid() {
+		return nil, fmt.Errorf("Layout must be one of a4_grid, table_tent or sticker_roll")
+	}
+	callToAction := strings.TrimSpace(r.CallToAction)
+	if len([]rune(callToAction)) > MaxCallToActionLength {
+		return nil, fmt.Errorf("Call to action must be at most %d characters", MaxCallToActionLength)
+	}
+	if r.Columns < 0 || r.Columns > MaxPrintGridColumns || r.Rows < 0 || r.Rows > MaxPrintGridRows {
+		return nil, fmt.Errorf("A grid can have up to %d columns and %d rows", MaxPrintGridColumns, MaxPrintGridRows)
+	}
+	if (r.StickerWidthMM != 0 && (r.StickerWidthMM < 25 || r.StickerWidthMM > 150)) ||
+		(r.StickerHeightMM != 0 && (r.StickerHeightMM < 25 || r.StickerHeightMM > 200)) {
+		return nil, fmt.Errorf("Stickers must be 25 to 150 mm wide and 25 to 200 mm high")
+	}
+
+	template := &PrintTemplate{
+		Name:            name,
+		Layout:          r.Layout,
+		CallToAction:    callToAction,
+		Columns:         r.Columns,
+		Rows:            r.Rows,
+		StickerWidthMM:  r.StickerWidthMM,
+		StickerHeightMM: r.StickerHeightMM,
+	}
+	template.applyDefaults()
+	return template, nil
+}
+
+type PrintQRCodesRequest struct {
+	QRCodeIDs []uuid.UUID `json:"qr_code_ids" validate:"required,min=1"`
+	// TemplateID picks a saved template. Without one, Layout is printed with
+	// the default settings.
+	TemplateID *uuid.UUID  `json:"template_id"`
+	Layout     PrintLayout `json:"layout"`
+}
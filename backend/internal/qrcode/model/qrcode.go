@@ -1,14 +1,25 @@
 package qrcodemodel
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 	organizationmodel "kyooar/internal/organization/model"
 	qrcodeconstants "kyooar/internal/qrcode/constants"
+	"kyooar/internal/shared/errors"
 	sharedModels "kyooar/internal/shared/models"
 )
 
+const (
+	MinRotationSeconds = 30
+	MaxRotationSeconds = 24 * 60 * 60
+	// RotatingTokenGrace is how long after a rotating code's token is
+	// replaced that feedback started from it is still accepted, so customers
+	// can finish the form they opened.
+	RotatingTokenGrace = time.Hour
+)
+
 type QRCodeType = qrcodeconstants.QRCodeType
 
 type QRCode struct {
@@ -22,9 +33,53 @@ type QRCode struct {
 	IsActive     bool        `gorm:"default:true" json:"is_active"`
 	ScansCount   int         `gorm:"default:0" json:"scans_count"`
 	LastScannedAt *time.Time `json:"last_scanned_at"`
+	StartsAt     *time.Time  `json:"starts_at"`
 	ExpiresAt    *time.Time  `json:"expires_at"`
+	// RotationSeconds is how long each signed token in a rotating code's URL
+	// lasts. Zero means the code is static.
+	RotationSeconds int `gorm:"default:0" json:"rotation_seconds"`
 }
 
 func (q *QRCode) IsValid() bool {
-	return q.IsActive && (q.ExpiresAt == nil || time.Now().Before(*q.ExpiresAt))
+	return q.Rejection(time.Now()) == ""
+}
+
+func (q *QRCode) IsRotating() bool {
+	return q.RotationSeconds > 0
+}
+
+// RejectionReason says why a scanned QR code was refused.
+type RejectionReason string
+
+const (
+	// RejectionInactive is a code switched off or not started yet.
+	RejectionInactive RejectionReason = "inactive"
+	RejectionExpired  RejectionReason = "expired"
+	// RejectionRotated is a rotating code scanned without a current token,
+	// such as from a photo of an old one.
+	RejectionRotated RejectionReason = "rotated"
+)
+
+var rejectionMessages = map[RejectionReason]string{
+	RejectionInactive: "This QR code is not active",
+	RejectionExpired:  "This QR code has expired",
+	RejectionRotated:  "This QR code has changed, please scan the one currently on display",
+}
+
+// Rejection returns why the code cannot be used at now, leaving rotation
+// tokens aside, or an empty reason when it can.
+func (q *QRCode) Rejection(now time.Time) RejectionReason {
+	if !q.IsActive || (q.StartsAt != nil && now.Before(*q.StartsAt)) {
+		return RejectionInactive
+	}
+	if q.ExpiresAt != nil && !now.Before(*q.ExpiresAt) {
+		return RejectionExpired
+	}
+	return ""
+}
+
+// RejectedError is returned for a code that exists but was refused, with
+// the reason in its details.
+func RejectedError(reason RejectionReason) *errors.AppError {
+	return errors.NewWithDetails("QR_CODE_REJECTED", rejectionMessages[reason], http.StatusGone, map[string]RejectionReason{"reason": reason})
 }
\ No newline at end of file
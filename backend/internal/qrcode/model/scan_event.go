@@ -0,0 +1,95 @@
+package qrcodemodel
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	DeviceTypeMobile  = "mobile"
+	DeviceTypeTablet  = "tablet"
+	DeviceTypeDesktop = "desktop"
+)
+
+// QRScanEvent is one scan of a QR code. It keeps the parsed device but not
+// the IP address or user agent; VisitorHash only tells repeat scans by the
+// same device apart from new visitors.
+type QRScanEvent struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	QRCodeID       uuid.UUID `gorm:"not null" json:"qr_code_id"`
+	OrganizationID uuid.UUID `gorm:"not null" json:"organization_id"`
+	ScannedAt      time.Time `gorm:"not null" json:"scanned_at"`
+	Platform       string    `json:"platform"`
+	Browser        string    `json:"browser"`
+	DeviceType     string    `json:"device_type"`
+	// Referrer is the host of the page that linked to the code, if any.
+	Referrer    string `json:"referrer"`
+	VisitorHash string `gorm:"not null" json:"-"`
+}
+
+func (QRScanEvent) TableName() string {
+	return "qr_scan_events"
+}
+
+func (e *QRScanEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// ScanVisit is what the request of a scan tells about the visitor.
+type ScanVisit struct {
+	IP        string
+	UserAgent string
+	Platform  string
+	Browser   string
+	Referrer  string
+}
+
+// ScanCounts are a QR code's scans, counting each visitor once in Unique.
+type ScanCounts struct {
+	QRCodeID      uuid.UUID  `gorm:"column:qr_code_id"`
+	Total         int64      `gorm:"column:total"`
+	Unique        int64      `gorm:"column:unique_visitors"`
+	LastScannedAt *time.Time `gorm:"column:last_scanned_at"`
+}
+
+// DailyScanCounts are an organization's scans on one day.
+type DailyScanCounts struct {
+	Day    time.Time `gorm:"column:day"`
+	Total  int64     `gorm:"column:total"`
+	Unique int64     `gorm:"column:unique_visitors"`
+}
+
+// DeviceType classifies a user agent as a phone, tablet or desktop.
+func DeviceType(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet") ||
+		(strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		return DeviceTypeTablet
+	case strings.Contains(ua, "mobi") || strings.Contains(ua, "iphone") || strings.Contains(ua, "ipod"):
+		return DeviceTypeMobile
+	default:
+		return DeviceTypeDesktop
+	}
+}
+
+// ReferrerHost reduces a Referer header to its host, dropping the path and
+// query that may identify the visitor.
+func ReferrerHost(referrer string) string {
+	if referrer == "" {
+		return ""
+	}
+	parsed, err := url.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
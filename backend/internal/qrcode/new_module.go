@@ -10,7 +10,10 @@ import (
 	qrcodeinterface "kyooar/internal/qrcode/interface"
 	gormqrcode "kyooar/internal/qrcode/repository/gorm"
 	qrcodeservice "kyooar/internal/qrcode/service"
+	"kyooar/internal/shared/config"
 	sharedMiddleware "kyooar/internal/shared/middleware"
+	sharedServices "kyooar/internal/shared/services"
+	subscriptioninterface "kyooar/internal/subscription/interface"
 )
 
 func ProvideQRCodeRepository(i *do.Injector) (qrcodeinterface.QRCodeRepository, error) {
@@ -18,13 +21,45 @@ func ProvideQRCodeRepository(i *do.Injector) (qrcodeinterface.QRCodeRepository,
 	return gormqrcode.NewQRCodeRepository(db), nil
 }
 
+func ProvidePrintTemplateRepository(i *do.Injector) (qrcodeinterface.PrintTemplateRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return gormqrcode.NewPrintTemplateRepository(db), nil
+}
+
+func ProvideScanEventRepository(i *do.Injector) (qrcodeinterface.ScanEventRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return gormqrcode.NewScanEventRepository(db), nil
+}
+
 func ProvideQRCodeService(i *do.Injector) (qrcodeinterface.QRCodeService, error) {
 	qrCodeRepo := do.MustInvoke[qrcodeinterface.QRCodeRepository](i)
+	scanEventRepo := do.MustInvoke[qrcodeinterface.ScanEventRepository](i)
 	organizationRepo := do.MustInvoke[organizationinterface.OrganizationRepository](i)
+	subscriptionRepo := do.MustInvoke[subscriptioninterface.SubscriptionRepository](i)
+	cfg := do.MustInvoke[*config.Config](i)
 
 	return qrcodeservice.NewQRCodeService(
 		qrCodeRepo,
+		scanEventRepo,
 		organizationRepo,
+		subscriptionRepo,
+		cfg.App.FrontendURL,
+		cfg.Abuse.QRTokenSecret,
+		cfg.Abuse.ScanVisitorSecret,
+	), nil
+}
+
+func ProvidePrintService(i *do.Injector) (qrcodeinterface.PrintService, error) {
+	printTemplateRepo := do.MustInvoke[qrcodeinterface.PrintTemplateRepository](i)
+	qrCodeRepo := do.MustInvoke[qrcodeinterface.QRCodeRepository](i)
+	organizationRepo := do.MustInvoke[organizationinterface.OrganizationRepository](i)
+	qrCodeService := do.MustInvoke[qrcodeinterface.QRCodeService](i)
+
+	return qrcodeservice.NewPrintService(
+		printTemplateRepo,
+		qrCodeRepo,
+		organizationRepo,
+		qrCodeService,
 	), nil
 }
 
@@ -33,9 +68,15 @@ func ProvideQRCodeController(i *do.Injector) (*qrcodecontroller.QRCodeController
 	return qrcodecontroller.NewQRCodeController(qrCodeService), nil
 }
 
+func ProvidePrintController(i *do.Injector) (*qrcodecontroller.PrintController, error) {
+	printService := do.MustInvoke[qrcodeinterface.PrintService](i)
+	return qrcodecontroller.NewPrintController(printService), nil
+}
+
 func ProvidePublicController(i *do.Injector) (*qrcodecontroller.PublicController, error) {
 	qrCodeService := do.MustInvoke[qrcodeinterface.QRCodeService](i)
-	return qrcodecontroller.NewPublicController(qrCodeService), nil
+	proofOfWork := do.MustInvoke[sharedServices.ProofOfWork](i)
+	return qrcodecontroller.NewPublicController(qrCodeService, proofOfWork), nil
 }
 
 type QRCodeModule struct {
@@ -59,14 +100,19 @@ func (m *QRCodeModule) RegisterRoutes(v1 *echo.Group) {
 	qrCodes := v1.Group("/qr-codes")
 	qrCodes.Use(middlewareProvider.AuthMiddleware())
 	qrCodes.Use(middlewareProvider.TeamAwareMiddleware())
+	qrCodes.GET("/:id/image", qrCodeController.GetImage)
 	qrCodes.PATCH("/:id", qrCodeController.Update)
 	qrCodes.DELETE("/:id", qrCodeController.Delete)
 }
 
 func RegisterNewModule(container *do.Injector) error {
 	do.Provide(container, ProvideQRCodeRepository)
+	do.Provide(container, ProvidePrintTemplateRepository)
+	do.Provide(container, ProvideScanEventRepository)
 	do.Provide(container, ProvideQRCodeService)
+	do.Provide(container, ProvidePrintService)
 	do.Provide(container, ProvideQRCodeController)
+	do.Provide(container, ProvidePrintController)
 	do.Provide(container, ProvidePublicController)
 
 	return nil
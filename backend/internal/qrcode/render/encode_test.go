@@ -0,0 +1,589 @@
+package qrcoderender_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"testing"
+
+	qrcoderender "kyooar/internal/qrcode/render"
+)
+
+// The decoder below reads symbols the way a scanner does, using the tables
+// of ISO/IEC 18004 rather than anything from the encoder, so a mistake in the
+// encoder cannot be mirrored by the test.
+
+// ecBlockGroup is a run of error correction blocks of the same size.
+type ecBlockGroup struct {
+	count     int
+	total     int
+	dataWords int
+}
+
+type symbolSpec struct {
+	version   int
+	level     qrcoderender.ECLevel
+	capacity  int // bytes in byte mode, ISO/IEC 18004 table 7
+	groups    []ecBlockGroup
+	alignment []int
+	remainder int
+}
+
+// Block structure from table 9 and alignment pattern centers from annex E.
+var symbolSpecs = []symbolSpec{
+	{1, qrcoderender.ECLow, 17, []ecBlockGroup{{1, 26, 19}}, nil, 0},
+	{1, qrcoderender.ECMedium, 14, []ecBlockGroup{{1, 26, 16}}, nil, 0},
+	{1, qrcoderender.ECQuartile, 11, []ecBlockGroup{{1, 26, 13}}, nil, 0},
+	{1, qrcoderender.ECHigh, 7, []ecBlockGroup{{1, 26, 9}}, nil, 0},
+	{2, qrcoderender.ECMedium, 26, []ecBlockGroup{{1, 44, 28}}, []int{6, 18}, 7},
+	{5, qrcoderender.ECMedium, 84, []ecBlockGroup{{2, 67, 43}}, []int{6, 30}, 7},
+	{5, qrcoderender.ECQuartile, 60, []ecBlockGroup{{2, 33, 15}, {2, 34, 16}}, []int{6, 30}, 7},
+	{5, qrcoderender.ECHigh, 44, []ecBlockGroup{{2, 33, 11}, {2, 34, 12}}, []int{6, 30}, 7},
+	{7, qrcoderender.ECLow, 154, []ecBlockGroup{{2, 98, 78}}, []int{6, 22, 38}, 0},
+	{7, qrcoderender.ECHigh, 64, []ecBlockGroup{{4, 39, 13}, {1, 40, 14}}, []int{6, 22, 38}, 0},
+	{10, qrcoderender.ECMedium, 213, []ecBlockGroup{{4, 69, 43}, {1, 70, 44}}, []int{6, 28, 50}, 0},
+	{10, qrcoderender.ECHigh, 119, []ecBlockGroup{{6, 43, 15}, {2, 44, 16}}, []int{6, 28, 50}, 0},
+	{15, qrcoderender.ECQuartile, 292, []ecBlockGroup{{5, 54, 24}, {7, 55, 25}}, []int{6, 26, 48, 70}, 3},
+	{40, qrcoderender.ECLow, 2953, []ecBlockGroup{{19, 148, 118}, {6, 149, 119}}, []int{6, 30, 58, 86, 114, 142, 170}, 0},
+}
+
+func TestEncodeDecodesAtCapacity(t *testing.T) {
+	for _, spec := range symbolSpecs {
+		t.Run(fmt.Sprintf("%d-%s", spec.version, spec.level), func(t *testing.T) {
+			data := testPayload(spec.capacity, spec.version)
+			code, err := qrcoderender.Encode(data, spec.level)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if code.Version != spec.version {
+				t.Fatalf("version = %d, want %d", code.Version, spec.version)
+			}
+
+			got, err := decodeSymbol(darkGrid(code), spec)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatalf("decoded %q, want %q", got, data)
+			}
+
+			// One more byte no longer fits this version.
+			if spec.version < 40 {
+				bigger, err := qrcoderender.Encode(testPayload(spec.capacity+1, spec.version), spec.level)
+				if err != nil {
+					t.Fatalf("Encode one byte over: %v", err)
+				}
+				if bigger.Version <= spec.version {
+					t.Fatalf("one byte over capacity gave version %d", bigger.Version)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeRejectsDataTooLong(t *testing.T) {
+	_, err := qrcoderender.Encode(make([]byte, 2954), qrcoderender.ECLow)
+	if !errors.Is(err, qrcoderender.ErrDataTooLong) {
+		t.Fatalf("err = %v, want ErrDataTooLong", err)
+	}
+}
+
+func TestPNGDecodes(t *testing.T) {
+	data := []byte("https://app.kyooar.com/qr/7f3c2a9e-4b1d-4c8a-9f21-0d6e5b3a1c44?t=AbC-123_xyz")
+	code, err := qrcoderender.Encode(data, qrcoderender.ECMedium)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	spec, ok := findSpec(code.Version, code.Level)
+	if !ok {
+		t.Fatalf("no spec for version %d-%s", code.Version, code.Level)
+	}
+
+	var buf bytes.Buffer
+	if err := qrcoderender.PNG(&buf, code, qrcoderender.Options{Size: 300, Margin: 4}); err != nil {
+		t.Fatalf("PNG: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode: %v", err)
+	}
+
+	grid, err := sampleImage(img, code.Size)
+	if err != nil {
+		t.Fatalf("sample: %v", err)
+	}
+	got, err := decodeSymbol(grid, spec)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("decoded %q, want %q", got, data)
+	}
+}
+
+func TestParseECLevel(t *testing.T) {
+	for s, want := range map[string]qrcoderender.ECLevel{"L": qrcoderender.ECLow, "m": qrcoderender.ECMedium, "Q": qrcoderender.ECQuartile, "h": qrcoderender.ECHigh} {
+		if got, ok := qrcoderender.ParseECLevel(s); !ok || got != want {
+			t.Errorf("ParseECLevel(%q) = %v, %v", s, got, ok)
+		}
+	}
+	if _, ok := qrcoderender.ParseECLevel("X"); ok {
+		t.Error("ParseECLevel accepted X")
+	}
+}
+
+// testPayload covers every byte value, shifted per version so that symbols
+// do not all carry the same data.
+func testPayload(n, seed int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i*37 + seed*11)
+	}
+	return data
+}
+
+func findSpec(version int, level qrcoderender.ECLevel) (symbolSpec, bool) {
+	for _, spec := range symbolSpecs {
+		if spec.version == version && spec.level == level {
+			return spec, true
+		}
+	}
+	return symbolSpec{}, false
+}
+
+func darkGrid(code *qrcoderender.Code) [][]bool {
+	grid := make([][]bool, code.Size)
+	for y := range grid {
+		grid[y] = make([]bool, code.Size)
+		for x := range grid[y] {
+			grid[y][x] = code.Dark(x, y)
+		}
+	}
+	return grid
+}
+
+// sampleImage finds the symbol by its top left finder pattern and reads the
+// center pixel of every module.
+func sampleImage(img image.Image, size int) ([][]bool, error) {
+	bounds := img.Bounds()
+	dark := func(x, y int) bool {
+		r, g, b, _ := img.At(x, y).RGBA()
+		return r+g+b < 3*0x8000
+	}
+
+	left, top := -1, -1
+	for y := bounds.Min.Y; y < bounds.Max.Y && top < 0; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if dark(x, y) {
+				left, top = x, y
+				break
+			}
+		}
+	}
+	if top < 0 {
+		return nil, fmt.Errorf("no dark pixels")
+	}
+
+	// The finder pattern's top edge is 7 modules of dark pixels.
+	width := 0
+	for x := left; x < bounds.Max.X && dark(x, top); x++ {
+		width++
+	}
+	if width%7 != 0 {
+		return nil, fmt.Errorf("finder pattern is %d pixels wide", width)
+	}
+	module := width / 7
+
+	grid := make([][]bool, size)
+	for y := range grid {
+		grid[y] = make([]bool, size)
+		for x := range grid[y] {
+			grid[y][x] = dark(left+x*module+module/2, top+y*module+module/2)
+		}
+	}
+	return grid, nil
+}
+
+func decodeSymbol(grid [][]bool, spec symbolSpec) ([]byte, error) {
+	size := len(grid)
+	if size != 17+4*spec.version {
+		return nil, fmt.Errorf("size %d does not match version %d", size, spec.version)
+	}
+	if err := checkFunctionPatterns(grid, spec); err != nil {
+		return nil, err
+	}
+
+	level, mask, err := readFormat(grid)
+	if err != nil {
+		return nil, err
+	}
+	if level != spec.level {
+		return nil, fmt.Errorf("format says level %s, want %s", level, spec.level)
+	}
+
+	if spec.version >= 7 {
+		if err := checkVersionInfo(grid, spec.version); err != nil {
+			return nil, err
+		}
+	}
+
+	bits := readDataBits(grid, spec, mask)
+	total := 0
+	for _, group := range spec.groups {
+		total += group.count * group.total
+	}
+	if len(bits) != total*8+spec.remainder {
+		return nil, fmt.Errorf("read %d data modules, want %d", len(bits), total*8+spec.remainder)
+	}
+
+	codewords := make([]byte, total)
+	for i := range codewords {
+		for j := 0; j < 8; j++ {
+			if bits[i*8+j] {
+				codewords[i] |= 0x80 >> j
+			}
+		}
+	}
+
+	data, err := deinterleave(codewords, spec.groups)
+	if err != nil {
+		return nil, err
+	}
+	return parseByteSegment(data, spec.version)
+}
+
+func checkFunctionPatterns(grid [][]bool, spec symbolSpec) error {
+	size := len(grid)
+	finder := func(cx, cy int) error {
+		for dy := -3; dy <= 3; dy++ {
+			for dx := -3; dx <= 3; dx++ {
+				ring := max(abs(dx), abs(dy))
+				if want := ring != 2; grid[cy+dy][cx+dx] != want {
+					return fmt.Errorf("finder at (%d,%d) is wrong at (%d,%d)", cx, cy, cx+dx, cy+dy)
+				}
+			}
+		}
+		return nil
+	}
+	for _, center := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		if err := finder(center[0], center[1]); err != nil {
+			return err
+		}
+	}
+
+	for i := 8; i < size-8; i++ {
+		if grid[6][i] != (i%2 == 0) || grid[i][6] != (i%2 == 0) {
+			return fmt.Errorf("timing pattern is wrong at %d", i)
+		}
+	}
+
+	for _, cx := range spec.alignment {
+		for _, cy := range spec.alignment {
+			if overlapsFinder(cx, cy, size) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					if want := max(abs(dx), abs(dy)) != 1; grid[cy+dy][cx+dx] != want {
+						return fmt.Errorf("alignment pattern at (%d,%d) is wrong", cx, cy)
+					}
+				}
+			}
+		}
+	}
+
+	if !grid[size-8][8] {
+		return fmt.Errorf("dark module is light")
+	}
+	return nil
+}
+
+func overlapsFinder(cx, cy, size int) bool {
+	return (cx < 9 && cy < 9) || (cx > size-9 && cy < 9) || (cx < 9 && cy > size-9)
+}
+
+// bchCode appends the BCH check bits of data, as used by the format and
+// version information.
+func bchCode(data, generator, degree int) int {
+	value := data << degree
+	for i := bitLength(value) - 1; i >= degree; i-- {
+		if value>>i&1 == 1 {
+			value ^= generator << (i - degree)
+		}
+	}
+	return data<<degree | value
+}
+
+func bitLength(v int) int {
+	n := 0
+	for ; v > 0; v >>= 1 {
+		n++
+	}
+	return n
+}
+
+// readFormat reads both copies of the format information and returns the
+// level and mask they agree on.
+func readFormat(grid [][]bool) (qrcoderender.ECLevel, int, error) {
+	size := len(grid)
+	var first, second int
+	read := func(word *int, x, y int) {
+		*word <<= 1
+		if grid[y][x] {
+			*word |= 1
+		}
+	}
+
+	for x := 0; x <= 5; x++ {
+		read(&first, x, 8)
+	}
+	read(&first, 7, 8)
+	read(&first, 8, 8)
+	read(&first, 8, 7)
+	for y := 5; y >= 0; y-- {
+		read(&first, 8, y)
+	}
+
+	for y := size - 1; y >= size-7; y-- {
+		read(&second, 8, y)
+	}
+	for x := size - 8; x < size; x++ {
+		read(&second, x, 8)
+	}
+
+	if first != second {
+		return 0, 0, fmt.Errorf("format copies differ: %015b and %015b", first, second)
+	}
+
+	levels := map[int]qrcoderender.ECLevel{1: qrcoderender.ECLow, 0: qrcoderender.ECMedium, 3: qrcoderender.ECQuartile, 2: qrcoderender.ECHigh}
+	for data := 0; data < 32; data++ {
+		if bchCode(data, 0x537, 10)^0x5412 == first {
+			return levels[data>>3], data & 7, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("format %015b is not a valid code word", first)
+}
+
+func checkVersionInfo(grid [][]bool, version int) error {
+	size := len(grid)
+	var topRight, bottomLeft int
+	for y := 5; y >= 0; y-- {
+		for x := size - 9; x >= size-11; x-- {
+			topRight <<= 1
+			if grid[y][x] {
+				topRight |= 1
+			}
+		}
+	}
+	for x := 5; x >= 0; x-- {
+		for y := size - 9; y >= size-11; y-- {
+			bottomLeft <<= 1
+			if grid[y][x] {
+				bottomLeft |= 1
+			}
+		}
+	}
+
+	want := bchCode(version, 0x1F25, 12)
+	if topRight != want || bottomLeft != want {
+		return fmt.Errorf("version information %018b and %018b, want %018b", topRight, bottomLeft, want)
+	}
+	return nil
+}
+
+func isFunctionModule(x, y int, spec symbolSpec, size int) bool {
+	switch {
+	case x < 9 && y < 9, x >= size-8 && y < 9, x < 9 && y >= size-8:
+		return true
+	case x == 6 || y == 6:
+		return true
+	}
+	if spec.version >= 7 {
+		if (x >= size-11 && x < size-8 && y < 6) || (y >= size-11 && y < size-8 && x < 6) {
+			return true
+		}
+	}
+	for _, cx := range spec.alignment {
+		for _, cy := range spec.alignment {
+			if !overlapsFinder(cx, cy, size) && abs(x-cx) <= 2 && abs(y-cy) <= 2 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// maskInverts is the data mask condition for row i and column j.
+func maskInverts(mask, i, j int) bool {
+	switch mask {
+	case 0:
+		return (i+j)%2 == 0
+	case 1:
+		return i%2 == 0
+	case 2:
+		return j%3 == 0
+	case 3:
+		return (i+j)%3 == 0
+	case 4:
+		return (i/2+j/3)%2 == 0
+	case 5:
+		return (i*j)%2+(i*j)%3 == 0
+	case 6:
+		return ((i*j)%2+(i*j)%3)%2 == 0
+	default:
+		return ((i+j)%2+(i*j)%3)%2 == 0
+	}
+}
+
+// readDataBits walks the two-module-wide columns from the bottom right,
+// alternating up and down and skipping the vertical timing pattern.
+func readDataBits(grid [][]bool, spec symbolSpec, mask int) []bool {
+	size := len(grid)
+	var bits []bool
+	up := true
+	for right := size - 1; right > 0; right -= 2 {
+		if right == 6 {
+			right--
+		}
+		for count := 0; count < size; count++ {
+			row := count
+			if up {
+				row = size - 1 - count
+			}
+			for col := 0; col < 2; col++ {
+				x := right - col
+				if isFunctionModule(x, row, spec, size) {
+					continue
+				}
+				bits = append(bits, grid[row][x] != maskInverts(mask, row, x))
+			}
+		}
+		up = !up
+	}
+	return bits
+}
+
+// deinterleave splits the codewords into their blocks, checks each block's
+// Reed-Solomon syndromes and returns the data codewords in order.
+func deinterleave(codewords []byte, groups []ecBlockGroup) ([]byte, error) {
+	var blocks [][]byte
+	var dataLens []int
+	for _, group := range groups {
+		for i := 0; i < group.count; i++ {
+			blocks = append(blocks, make([]byte, 0, group.total))
+			dataLens = append(dataLens, group.dataWords)
+		}
+	}
+	ecLen := groups[0].total - groups[0].dataWords
+
+	k := 0
+	for i := 0; i < dataLens[len(dataLens)-1]; i++ {
+		for b := range blocks {
+			if i < dataLens[b] {
+				blocks[b] = append(blocks[b], codewords[k])
+				k++
+			}
+		}
+	}
+	for i := 0; i < ecLen; i++ {
+		for b := range blocks {
+			blocks[b] = append(blocks[b], codewords[k])
+			k++
+		}
+	}
+
+	var data []byte
+	for b, block := range blocks {
+		for j := 0; j < ecLen; j++ {
+			var syndrome byte
+			for _, c := range block {
+				syndrome = gfMul(syndrome, gfExp[j]) ^ c
+			}
+			if syndrome != 0 {
+				return nil, fmt.Errorf("block %d has syndrome %d = %d", b, j, syndrome)
+			}
+		}
+		data = append(data, block[:dataLens[b]]...)
+	}
+	return data, nil
+}
+
+var gfExp, gfLog = func() ([512]byte, [256]int) {
+	var exp [512]byte
+	var log [256]int
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(x)
+		log[x] = i
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11D
+		}
+	}
+	for i := 255; i < 512; i++ {
+		exp[i] = exp[i-255]
+	}
+	return exp, log
+}()
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[gfLog[a]+gfLog[b]]
+}
+
+// parseByteSegment reads a single byte mode segment, its terminator and the
+// padding after it.
+func parseByteSegment(data []byte, version int) ([]byte, error) {
+	pos := 0
+	readBits := func(n int) int {
+		v := 0
+		for i := 0; i < n; i++ {
+			v <<= 1
+			if data[pos/8]>>(7-pos%8)&1 == 1 {
+				v |= 1
+			}
+			pos++
+		}
+		return v
+	}
+
+	if mode := readBits(4); mode != 0x4 {
+		return nil, fmt.Errorf("mode %04b is not byte mode", mode)
+	}
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	count := readBits(countBits)
+	if pos+count*8 > len(data)*8 {
+		return nil, fmt.Errorf("count %d overruns the data", count)
+	}
+	out := make([]byte, count)
+	for i := range out {
+		out[i] = byte(readBits(8))
+	}
+
+	if terminator := min(4, len(data)*8-pos); readBits(terminator) != 0 {
+		return nil, fmt.Errorf("terminator is not zero")
+	}
+	if pos%8 != 0 && readBits(8-pos%8) != 0 {
+		return nil, fmt.Errorf("bit padding is not zero")
+	}
+	for i, pad := pos/8, byte(0xEC); i < len(data); i, pad = i+1, pad^0xEC^0x11 {
+		if data[i] != pad {
+			return nil, fmt.Errorf("pad codeword %d is %#x, want %#x", i, data[i], pad)
+		}
+	}
+	return out, nil
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,259 @@
+package qrcoderender
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// PointsPerMM converts millimetres to PDF points.
+const PointsPerMM = 72 / 25.4
+
+// Font is one of the standard PDF fonts, which viewers provide so nothing
+// needs embedding.
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+// Glyph widths of printable ASCII in thousandths of the font size, from the
+// Adobe font metrics. Other characters use averageGlyphWidth, except the
+// ellipsis added by WrapText.
+var glyphWidths = [...][95]int{
+	Helvetica: {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	HelveticaBold: {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+const (
+	averageGlyphWidth  = 556
+	ellipsisGlyphWidth = 1000
+)
+
+// TextWidth returns the width of s in points.
+func TextWidth(font Font, size float64, s string) float64 {
+	total := 0
+	for _, r := range s {
+		if r >= 32 && r < 127 {
+			total += glyphWidths[font][r-32]
+		} else if r == '…' {
+			total += ellipsisGlyphWidth
+		} else {
+			total += averageGlyphWidth
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// WrapText breaks s into lines no wider than width, ending the last line
+// with an ellipsis when more than maxLines are needed.
+func WrapText(font Font, size float64, s string, width float64, maxLines int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line == "" || TextWidth(font, size, candidate) <= width {
+			line = candidate
+			continue
+		}
+		lines = append(lines, line)
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		lines[maxLines-1] += "…"
+	}
+	for i, l := range lines {
+		lines[i] = truncate(font, size, l, width)
+	}
+	return lines
+}
+
+// truncate shortens a single word line that is still too wide.
+func truncate(font Font, size float64, s string, width float64) string {
+	if TextWidth(font, size, s) <= width {
+		return s
+	}
+	for s != "" && TextWidth(font, size, s+"…") > width {
+		_, n := utf8.DecodeLastRuneInString(s)
+		s = s[:len(s)-n]
+	}
+	return s + "…"
+}
+
+// Document is a minimal PDF writer for print sheets: pages of text in the
+// standard Helvetica fonts, lines and QR symbols drawn as vector squares.
+type Document struct {
+	pages []*Page
+}
+
+func NewDocument() *Document {
+	return &Document{}
+}
+
+// Page is a page being drawn. Coordinates are in points from the top left
+// corner, with text positioned by its baseline.
+type Page struct {
+	Width, Height float64
+	content       bytes.Buffer
+}
+
+// AddPage starts a new page of the given size in points.
+func (d *Document) AddPage(width, height float64) *Page {
+	page := &Page{Width: width, Height: height}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+func (p *Page) y(y float64) float64 {
+	return p.Height - y
+}
+
+// Text draws s with its left end at x.
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F%d %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font+1, size, x, p.y(y), encodeText(s))
+}
+
+// CenteredText draws s centered on x.
+func (p *Page) CenteredText(x, y float64, font Font, size float64, s string) {
+	p.Text(x-TextWidth(font, size, s)/2, y, font, size, s)
+}
+
+// Line draws a thin gray line, dashed for cut and fold guides.
+func (p *Page) Line(x0, y0, x1, y1 float64, dashed bool) {
+	dash := "[] 0"
+	if dashed {
+		dash = "[3 3] 0"
+	}
+	fmt.Fprintf(&p.content, "q 0.6 G 0.5 w %s d %.2f %.2f m %.2f %.2f l S Q\n", dash, x0, p.y(y0), x1, p.y(y1))
+}
+
+// QR draws the symbol with its top left corner at x, y and the given side,
+// leaving the quiet zone to the caller.
+func (p *Page) QR(code *Code, x, y, side float64) {
+	module := side / float64(code.Size)
+	p.content.WriteString("q 0 g\n")
+	for row := 0; row < code.Size; row++ {
+		for col := 0; col < code.Size; {
+			if !code.Dark(col, row) {
+				col++
+				continue
+			}
+			run := 1
+			for col+run < code.Size && code.Dark(col+run, row) {
+				run++
+			}
+			fmt.Fprintf(&p.content, "%.3f %.3f %.3f %.3f re\n", x+float64(col)*module, p.y(y+float64(row+1)*module), float64(run)*module, module)
+			col += run
+		}
+	}
+	p.content.WriteString("f Q\n")
+}
+
+// Rotated draws with everything turned 180 degrees around the point cx, cy,
+// so text on the top half of a folded card reads the right way up.
+func (p *Page) Rotated(cx, cy float64, draw func()) {
+	fmt.Fprintf(&p.content, "q -1 0 0 -1 %.2f %.2f cm\n", 2*cx, 2*p.y(cy))
+	draw()
+	p.content.WriteString("Q\n")
+}
+
+// encodeText converts s to the fonts' WinAnsi encoding, escaping the string
+// delimiters. Characters outside Latin-1 become question marks.
+func encodeText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '…':
+			b.WriteString(`\205`)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, `\%03o`, r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// Write writes the document as a PDF file.
+func (d *Document) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	offset := 0
+	var offsets []int
+	write := func(s string) {
+		n, _ := bw.WriteString(s)
+		offset += n
+	}
+	object := func(body string) {
+		offsets = append(offsets, offset)
+		write(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", len(offsets), body))
+	}
+
+	write("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 to 4 are the catalog, page tree and fonts; each page then
+	// takes two objects, the page and its content stream.
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			page.Width, page.Height, 6+2*i))
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(page.content.Bytes()); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.String()))
+	}
+
+	xref := offset
+	write(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1))
+	for _, o := range offsets {
+		write(fmt.Sprintf("%010d 00000 n \n", o))
+	}
+	write(fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref))
+
+	return bw.Flush()
+}
@@ -0,0 +1,189 @@
+package qrcoderender_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	qrcoderender "kyooar/internal/qrcode/render"
+)
+
+func TestDocumentStructure(t *testing.T) {
+	doc := qrcoderender.NewDocument()
+	for i := 0; i < 3; i++ {
+		page := doc.AddPage(200, 300)
+		page.Text(10, 20, qrcoderender.Helvetica, 12, fmt.Sprintf("Page %d", i+1))
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	pdf := parsePDF(t, buf.Bytes())
+
+	if pdf.pageCount != 3 {
+		t.Fatalf("page count = %d, want 3", pdf.pageCount)
+	}
+	for i, content := range pdf.contents {
+		if want := fmt.Sprintf("(Page %d) Tj", i+1); !strings.Contains(content, want) {
+			t.Errorf("page %d content %q lacks %q", i+1, content, want)
+		}
+		if !strings.Contains(content, "10.00 280.00 Td") {
+			t.Errorf("page %d text is not placed from the top left: %q", i+1, content)
+		}
+	}
+}
+
+func TestTextEscaping(t *testing.T) {
+	doc := qrcoderender.NewDocument()
+	page := doc.AddPage(200, 300)
+	page.Text(0, 0, qrcoderender.HelveticaBold, 10, `Café (table 4) \ 5€ …`)
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	pdf := parsePDF(t, buf.Bytes())
+
+	want := `/F2 10.00 Tf 0.00 300.00 Td (Caf\351 \(table 4\) \\ 5? \205) Tj`
+	if !strings.Contains(pdf.contents[0], want) {
+		t.Fatalf("content %q lacks %q", pdf.contents[0], want)
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	width := qrcoderender.TextWidth(qrcoderender.Helvetica, 10, "Scan to share")
+	lines := qrcoderender.WrapText(qrcoderender.Helvetica, 10, "Scan to share your feedback with us today", width, 2)
+	if len(lines) != 2 || lines[0] != "Scan to share" || !strings.HasSuffix(lines[1], "…") {
+		t.Fatalf("lines = %q", lines)
+	}
+	for _, line := range lines {
+		if w := qrcoderender.TextWidth(qrcoderender.Helvetica, 10, line); w > width {
+			t.Errorf("line %q is %.2f wide, over %.2f", line, w, width)
+		}
+	}
+
+	long := qrcoderender.WrapText(qrcoderender.Helvetica, 10, "Supercalifragilistic", 30, 1)
+	if len(long) != 1 || !strings.HasSuffix(long[0], "…") || qrcoderender.TextWidth(qrcoderender.Helvetica, 10, long[0]) > 30 {
+		t.Fatalf("long word = %q", long)
+	}
+}
+
+// parsedPDF is what the tests check of a written document.
+type parsedPDF struct {
+	pageCount int
+	// contents holds each page's decompressed content stream, in page order.
+	contents []string
+}
+
+var (
+	startxrefPattern = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+	trailerPattern   = regexp.MustCompile(`trailer\n<< /Size (\d+) /Root 1 0 R >>`)
+	countPattern     = regexp.MustCompile(`/Type /Pages /Kids \[([^\]]*)\] /Count (\d+)`)
+	contentsPattern  = regexp.MustCompile(`/Type /Page /Parent 2 0 R .*/Contents (\d+) 0 R`)
+	streamPattern    = regexp.MustCompile(`^<< /Length (\d+) /Filter /FlateDecode >>\nstream\n`)
+)
+
+// parsePDF follows the cross-reference table the way a viewer does, failing
+// the test if any offset does not land on the object it names.
+func parsePDF(t *testing.T, data []byte) parsedPDF {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) {
+		t.Fatalf("missing PDF header")
+	}
+
+	m := startxrefPattern.FindSubmatch(data)
+	if m == nil {
+		t.Fatalf("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+
+	lines := strings.Split(string(data[xref:]), "\n")
+	var first, count int
+	if _, err := fmt.Sscanf(lines[1], "%d %d", &first, &count); err != nil || first != 0 {
+		t.Fatalf("bad xref subsection %q", lines[1])
+	}
+	if lines[2] != "0000000000 65535 f " {
+		t.Fatalf("bad free entry %q", lines[2])
+	}
+
+	objects := make(map[int][]byte)
+	for n := 1; n < count; n++ {
+		entry := lines[2+n]
+		if len(entry) != 19 || !strings.HasSuffix(entry, " 00000 n ") {
+			t.Fatalf("bad xref entry %d: %q", n, entry)
+		}
+		offset, _ := strconv.Atoi(entry[:10])
+		header := fmt.Sprintf("%d 0 obj\n", n)
+		if !bytes.HasPrefix(data[offset:], []byte(header)) {
+			t.Fatalf("xref entry %d points at %q", n, data[offset:min(offset+20, len(data))])
+		}
+		body := data[offset+len(header):]
+		end := bytes.Index(body, []byte("\nendobj\n"))
+		if end < 0 {
+			t.Fatalf("object %d is not terminated", n)
+		}
+		objects[n] = body[:end]
+	}
+
+	m = trailerPattern.FindSubmatch(data[xref:])
+	if m == nil {
+		t.Fatalf("missing trailer")
+	}
+	if size, _ := strconv.Atoi(string(m[1])); size != count {
+		t.Fatalf("trailer size %d, xref has %d entries", size, count)
+	}
+
+	pages := countPattern.FindSubmatch(objects[2])
+	if pages == nil {
+		t.Fatalf("object 2 is not the page tree: %q", objects[2])
+	}
+	pageCount, _ := strconv.Atoi(string(pages[2]))
+	kids := strings.Fields(string(pages[1]))
+	if len(kids) != 3*pageCount {
+		t.Fatalf("page tree lists %d kids, count says %d", len(kids)/3, pageCount)
+	}
+
+	result := parsedPDF{pageCount: pageCount}
+	for i := 0; i < pageCount; i++ {
+		page, _ := strconv.Atoi(kids[3*i])
+		m := contentsPattern.FindSubmatch(objects[page])
+		if m == nil {
+			t.Fatalf("object %d is not a page: %q", page, objects[page])
+		}
+		stream, _ := strconv.Atoi(string(m[1]))
+		result.contents = append(result.contents, readStream(t, objects[stream]))
+	}
+	return result
+}
+
+func readStream(t *testing.T, object []byte) string {
+	t.Helper()
+	m := streamPattern.FindSubmatch(object)
+	if m == nil {
+		t.Fatalf("not a stream: %q", object[:min(40, len(object))])
+	}
+	length, _ := strconv.Atoi(string(m[1]))
+	start := len(m[0])
+	if !bytes.Equal(object[start+length:], []byte("\nendstream")) {
+		t.Fatalf("stream length %d does not end at endstream", length)
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(object[start : start+length]))
+	if err != nil {
+		t.Fatalf("zlib: %v", err)
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("zlib: %v", err)
+	}
+	return string(content)
+}
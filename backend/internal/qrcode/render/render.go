@@ -0,0 +1,155 @@
+package qrcoderender
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"kyooar/internal/shared/utils"
+)
+
+// logoFraction is the share of the symbol's width covered by a logo. With
+// its light border that hides about 6% of the modules, well within what
+// levels Q and H recover.
+const logoFraction = 0.2
+
+// Options control how a symbol is drawn.
+type Options struct {
+	// Size is the width and height of the image in pixels. Modules are drawn
+	// a whole number of pixels wide and centered, so the quiet zone may grow
+	// by a few pixels.
+	Size int
+	// Margin is the quiet zone around the symbol, in modules.
+	Margin int
+	// Logo, when set, is drawn over the center of the symbol.
+	Logo image.Image
+}
+
+// layout places the symbol in an image of the requested size.
+type layout struct {
+	size       int
+	moduleSize int
+	offset     int
+}
+
+func newLayout(code *Code, opts Options) layout {
+	modules := code.Size + 2*opts.Margin
+	moduleSize := max(1, opts.Size/modules)
+	size := max(opts.Size, modules*moduleSize)
+	return layout{
+		size:       size,
+		moduleSize: moduleSize,
+		offset:     (size-code.Size*moduleSize)/2,
+	}
+}
+
+// logoBox returns the area covered by the logo, in modules, with a light
+// border of one module.
+func logoBox(code *Code) (int, int) {
+	side := int(float64(code.Size) * logoFraction)
+	if side%2 != code.Size%2 {
+		side++
+	}
+	start := (code.Size - side) / 2
+	return start, start + side
+}
+
+// PNG draws the symbol as a black on white PNG.
+func PNG(w io.Writer, code *Code, opts Options) error {
+	l := newLayout(code, opts)
+	bounds := image.Rect(0, 0, l.size, l.size)
+	var dst draw.Image
+	if opts.Logo == nil {
+		// Two colors keep the file small.
+		dst = image.NewPaletted(bounds, color.Palette{color.White, color.Black})
+	} else {
+		rgba := image.NewRGBA(bounds)
+		draw.Draw(rgba, bounds, image.White, image.Point{}, draw.Src)
+		dst = rgba
+	}
+
+	boxStart, boxEnd := -1, -1
+	if opts.Logo != nil {
+		boxStart, boxEnd = logoBox(code)
+	}
+
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if !code.Dark(x, y) || (x >= boxStart && x < boxEnd && y >= boxStart && y < boxEnd) {
+				continue
+			}
+			rect := image.Rect(l.offset+x*l.moduleSize, l.offset+y*l.moduleSize, l.offset+(x+1)*l.moduleSize, l.offset+(y+1)*l.moduleSize)
+			draw.Draw(dst, rect, image.Black, image.Point{}, draw.Src)
+		}
+	}
+
+	if opts.Logo != nil {
+		inner := (boxEnd - boxStart - 2) * l.moduleSize
+		logo := utils.Thumbnail(opts.Logo, inner)
+		logoBounds := logo.Bounds()
+		center := l.offset + (boxStart+boxEnd)*l.moduleSize/2
+		at := image.Pt(center-logoBounds.Dx()/2, center-logoBounds.Dy()/2)
+		draw.Draw(dst, image.Rectangle{Min: at, Max: at.Add(logoBounds.Size())}, logo, logoBounds.Min, draw.Over)
+	}
+
+	return png.Encode(w, dst)
+}
+
+// SVG draws the symbol as an SVG whose coordinates are modules. The logo is
+// embedded as a PNG so that no markup from the original file is carried over.
+func SVG(w io.Writer, code *Code, opts Options) error {
+	l := newLayout(code, opts)
+	modules := code.Size + 2*opts.Margin
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n", l.size, l.size, modules, modules)
+	bw.WriteString(`<rect width="100%" height="100%" fill="#FFFFFF"/>` + "\n")
+
+	boxStart, boxEnd := -1, -1
+	if opts.Logo != nil {
+		boxStart, boxEnd = logoBox(code)
+	}
+	inBox := func(x, y int) bool {
+		return x >= boxStart && x < boxEnd && y >= boxStart && y < boxEnd
+	}
+
+	bw.WriteString(`<path fill="#000000" d="`)
+	for y := 0; y < code.Size; y++ {
+		// Join runs of dark modules in a row into one rectangle.
+		for x := 0; x < code.Size; {
+			if !code.Dark(x, y) || inBox(x, y) {
+				x++
+				continue
+			}
+			run := 1
+			for x+run < code.Size && code.Dark(x+run, y) && !inBox(x+run, y) {
+				run++
+			}
+			fmt.Fprintf(bw, "M%d %dh%dv1h-%dz", x+opts.Margin, y+opts.Margin, run, run)
+			x += run
+		}
+	}
+	bw.WriteString(`"/>` + "\n")
+
+	if opts.Logo != nil {
+		// Embed the logo at twice the resolution it is shown at.
+		side := boxEnd - boxStart - 2
+		logo := utils.Thumbnail(opts.Logo, side*l.moduleSize*2)
+		var encoded bytes.Buffer
+		if err := png.Encode(&encoded, logo); err != nil {
+			return err
+		}
+		fmt.Fprintf(bw, `<image x="%d" y="%d" width="%d" height="%d" preserveAspectRatio="xMidYMid meet" href="data:image/png;base64,%s"/>`+"\n",
+			boxStart+1+opts.Margin, boxStart+1+opts.Margin, side, side, base64.StdEncoding.EncodeToString(encoded.Bytes()))
+	}
+
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
@@ -0,0 +1,218 @@
+package qrcoderender
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	qrcodemodel "kyooar/internal/qrcode/model"
+)
+
+const (
+	a4Width  = 210 * PointsPerMM
+	a4Height = 297 * PointsPerMM
+
+	// printQuietZone is the light border kept around each printed code, in
+	// modules.
+	printQuietZone = 4
+	lineHeight     = 1.3
+)
+
+// MinModuleMM is the smallest module printed, in millimetres. Smaller
+// modules blur together in phone cameras held at a comfortable distance.
+const MinModuleMM = 0.5
+
+// ModuleTooSmallError reports a code whose space on the sheet would only fit
+// it with modules smaller than MinModuleMM.
+type ModuleTooSmallError struct {
+	Label    string
+	ModuleMM float64
+}
+
+func (e *ModuleTooSmallError) Error() string {
+	return fmt.Sprintf("%s would print with %.2f mm modules, below the %.1f mm minimum", e.Label, e.ModuleMM, MinModuleMM)
+}
+
+// SheetItem is one QR code to print with the text shown beside it.
+type SheetItem struct {
+	Code     *Code
+	Label    string
+	Location string
+}
+
+// Sheet writes a PDF laying out the items as the template describes, each
+// with its label and location, the organization name and the template's
+// call to action. It returns a *ModuleTooSmallError, writing nothing, when a
+// code would print too small to scan.
+func Sheet(w io.Writer, template *qrcodemodel.PrintTemplate, organizationName string, items []SheetItem) error {
+	doc := NewDocument()
+	var err error
+	switch template.Layout {
+	case qrcodemodel.PrintLayoutTableTent:
+		err = tableTents(doc, template, organizationName, items)
+	case qrcodemodel.PrintLayoutStickerRoll:
+		err = stickers(doc, template, organizationName, items)
+	default:
+		err = grid(doc, template, organizationName, items)
+	}
+	if err != nil {
+		return err
+	}
+	return doc.Write(w)
+}
+
+func grid(doc *Document, template *qrcodemodel.PrintTemplate, organizationName string, items []SheetItem) error {
+	margin := 10 * PointsPerMM
+	cellW := (a4Width - 2*margin) / float64(template.Columns)
+	cellH := (a4Height - 2*margin) / float64(template.Rows)
+	base := math.Max(6, math.Min(12, math.Min(cellW, cellH)/18))
+	style := cardStyle{
+		padding:      4 * PointsPerMM,
+		orgSize:      base * 0.85,
+		ctaSize:      base,
+		labelSize:    base * 1.25,
+		locationSize: base * 0.9,
+	}
+
+	perPage := template.Columns * template.Rows
+	for start := 0; start < len(items); start += perPage {
+		page := doc.AddPage(a4Width, a4Height)
+		for i, item := range items[start:min(start+perPage, len(items))] {
+			x := margin + float64(i%template.Columns)*cellW
+			y := margin + float64(i/template.Columns)*cellH
+			if err := style.draw(page, x, y, cellW, cellH, item, organizationName, template.CallToAction); err != nil {
+				return err
+			}
+		}
+
+		// Cut guides between the cells.
+		for col := 0; col <= template.Columns; col++ {
+			x := margin + float64(col)*cellW
+			page.Line(x, margin, x, a4Height-margin, true)
+		}
+		for row := 0; row <= template.Rows; row++ {
+			y := margin + float64(row)*cellH
+			page.Line(margin, y, a4Width-margin, y, true)
+		}
+	}
+	return nil
+}
+
+// tableTents prints each code on both halves of an A4 page, the top half
+// upside down, so the page folded along the middle stands as a tent.
+func tableTents(doc *Document, template *qrcodemodel.PrintTemplate, organizationName string, items []SheetItem) error {
+	style := cardStyle{
+		padding:      15 * PointsPerMM,
+		orgSize:      14,
+		ctaSize:      20,
+		labelSize:    18,
+		locationSize: 12,
+		ctaFirst:     true,
+	}
+
+	half := a4Height / 2
+	for _, item := range items {
+		page := doc.AddPage(a4Width, a4Height)
+		if err := style.draw(page, 0, half, a4Width, half, item, organizationName, template.CallToAction); err != nil {
+			return err
+		}
+		// The top half is the same card, so it fits whenever the bottom does.
+		page.Rotated(a4Width/2, half, func() {
+			style.draw(page, 0, half, a4Width, half, item, organizationName, template.CallToAction)
+		})
+		page.Line(0, half, a4Width, half, true)
+	}
+	return nil
+}
+
+// stickers prints each code on its own page the size of one sticker.
+func stickers(doc *Document, template *qrcodemodel.PrintTemplate, organizationName string, items []SheetItem) error {
+	width := float64(template.StickerWidthMM) * PointsPerMM
+	height := float64(template.StickerHeightMM) * PointsPerMM
+	base := math.Max(5, math.Min(10, width/16))
+	style := cardStyle{
+		padding:      3 * PointsPerMM,
+		orgSize:      base * 0.85,
+		ctaSize:      base * 0.9,
+		labelSize:    base * 1.2,
+		locationSize: base * 0.85,
+	}
+
+	for _, item := range items {
+		page := doc.AddPage(width, height)
+		if err := style.draw(page, 0, 0, width, height, item, organizationName, template.CallToAction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cardStyle sets the text sizes of a printed code, in points.
+type cardStyle struct {
+	padding      float64
+	orgSize      float64
+	ctaSize      float64
+	labelSize    float64
+	locationSize float64
+	// ctaFirst puts the call to action above the code as a headline rather
+	// than below the label.
+	ctaFirst bool
+}
+
+type textBlock struct {
+	font  Font
+	size  float64
+	lines []string
+}
+
+func (b textBlock) height() float64 {
+	return float64(len(b.lines)) * b.size * lineHeight
+}
+
+// draw fills the box at x, y with the organization name, the code, its
+// label and location and the call to action, centered and with the code as
+// large as the text leaves room for. Nothing is drawn when that makes the
+// modules smaller than MinModuleMM.
+func (s cardStyle) draw(page *Page, x, y, w, h float64, item SheetItem, organizationName, callToAction string) error {
+	innerW := w - 2*s.padding
+
+	org := textBlock{Helvetica, s.orgSize, WrapText(Helvetica, s.orgSize, organizationName, innerW, 1)}
+	cta := textBlock{HelveticaBold, s.ctaSize, WrapText(HelveticaBold, s.ctaSize, callToAction, innerW, 2)}
+	label := textBlock{HelveticaBold, s.labelSize, WrapText(HelveticaBold, s.labelSize, item.Label, innerW, 2)}
+	location := textBlock{Helvetica, s.locationSize, WrapText(Helvetica, s.locationSize, item.Location, innerW, 1)}
+
+	above := []textBlock{org}
+	below := []textBlock{label, location}
+	if s.ctaFirst {
+		above = append(above, cta)
+	} else {
+		below = append(below, cta)
+	}
+
+	textH := 0.0
+	for _, block := range append(above, below...) {
+		textH += block.height()
+	}
+	qrSide := math.Max(0, math.Min(innerW, h-2*s.padding-textH))
+	module := qrSide / float64(item.Code.Size+2*printQuietZone)
+	if moduleMM := module / PointsPerMM; moduleMM < MinModuleMM {
+		return &ModuleTooSmallError{Label: item.Label, ModuleMM: moduleMM}
+	}
+
+	cx := x + w/2
+	cursor := y + (h-textH-qrSide)/2
+	drawBlocks := func(blocks []textBlock) {
+		for _, block := range blocks {
+			for _, line := range block.lines {
+				page.CenteredText(cx, cursor+block.size, block.font, block.size, line)
+				cursor += block.size * lineHeight
+			}
+		}
+	}
+
+	drawBlocks(above)
+	page.QR(item.Code, cx-qrSide/2+printQuietZone*module, cursor+printQuietZone*module, module*float64(item.Code.Size))
+	cursor += qrSide
+	drawBlocks(below)
+	return nil
+}
@@ -0,0 +1,147 @@
+package qrcoderender_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	qrcodemodel "kyooar/internal/qrcode/model"
+	qrcoderender "kyooar/internal/qrcode/render"
+)
+
+// samplePrintURL is as long as the public URL of a code, which prints at
+// version 5 with quartile error correction.
+const samplePrintURL = "https://app.kyooar.com/qr/7f3c2a9e-4b1d-4c8a-9f21-0d6e5b3a1c44"
+
+func sheetItems(t *testing.T, n int) []qrcoderender.SheetItem {
+	t.Helper()
+	code, err := qrcoderender.Encode([]byte(samplePrintURL), qrcoderender.ECQuartile)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	items := make([]qrcoderender.SheetItem, n)
+	for i := range items {
+		items[i] = qrcoderender.SheetItem{
+			Code: code,
+			// Labels long enough to wrap onto a second line in small cells.
+			Label:    fmt.Sprintf("Table %d (terrace) by the window overlooking the garden", i+1),
+			Location: "Main dining room",
+		}
+	}
+	return items
+}
+
+func TestSheetLayouts(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  *qrcodemodel.PrintTemplate
+		items     int
+		wantPages int
+	}{
+		{"default grid", qrcodemodel.DefaultPrintTemplate(qrcodemodel.PrintLayoutA4Grid), 13, 2},
+		{"largest grid", &qrcodemodel.PrintTemplate{
+			Layout: qrcodemodel.PrintLayoutA4Grid, CallToAction: qrcodemodel.DefaultCallToAction,
+			Columns: qrcodemodel.MaxPrintGridColumns, Rows: qrcodemodel.MaxPrintGridRows,
+		}, 21, 2},
+		{"table tents", qrcodemodel.DefaultPrintTemplate(qrcodemodel.PrintLayoutTableTent), 2, 2},
+		{"stickers", qrcodemodel.DefaultPrintTemplate(qrcodemodel.PrintLayoutStickerRoll), 3, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			items := sheetItems(t, tt.items)
+			if err := qrcoderender.Sheet(&buf, tt.template, "Bistro (Le Petit)", items); err != nil {
+				t.Fatalf("Sheet: %v", err)
+			}
+			pdf := parsePDF(t, buf.Bytes())
+			if pdf.pageCount != tt.wantPages {
+				t.Fatalf("page count = %d, want %d", pdf.pageCount, tt.wantPages)
+			}
+
+			all := strings.Join(pdf.contents, "")
+			if !strings.Contains(all, `(Bistro \(Le Petit\)) Tj`) {
+				t.Error("organization name is missing or unescaped")
+			}
+			for i := range items {
+				if !strings.Contains(all, fmt.Sprintf(`(Table %d \(terrace\)`, i+1)) {
+					t.Errorf("label of item %d is missing", i+1)
+				}
+			}
+			for i, content := range pdf.contents {
+				if module := smallestModuleMM(content); module < qrcoderender.MinModuleMM {
+					t.Errorf("page %d prints %.2f mm modules", i+1, module)
+				}
+			}
+		})
+	}
+}
+
+func TestSheetRejectsSmallModules(t *testing.T) {
+	tests := []struct {
+		name     string
+		template *qrcodemodel.PrintTemplate
+	}{
+		{"dense grid", &qrcodemodel.PrintTemplate{
+			Layout: qrcodemodel.PrintLayoutA4Grid, CallToAction: qrcodemodel.DefaultCallToAction,
+			Columns: 5, Rows: 7,
+		}},
+		{"small sticker", &qrcodemodel.PrintTemplate{
+			Layout: qrcodemodel.PrintLayoutStickerRoll, CallToAction: qrcodemodel.DefaultCallToAction,
+			StickerWidthMM: 25, StickerHeightMM: 25,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := qrcoderender.Sheet(&buf, tt.template, "Bistro", sheetItems(t, 1))
+			var tooSmall *qrcoderender.ModuleTooSmallError
+			if !errors.As(err, &tooSmall) {
+				t.Fatalf("err = %v, want ModuleTooSmallError", err)
+			}
+			if tooSmall.Label != "Table 1 (terrace) by the window overlooking the garden" || tooSmall.ModuleMM >= qrcoderender.MinModuleMM {
+				t.Fatalf("error = %+v", tooSmall)
+			}
+			if buf.Len() != 0 {
+				t.Fatalf("wrote %d bytes", buf.Len())
+			}
+		})
+	}
+}
+
+func TestSavePrintTemplateRejectsDenseGrids(t *testing.T) {
+	req := &qrcodemodel.SavePrintTemplateRequest{
+		Name:    "Dense",
+		Layout:  qrcodemodel.PrintLayoutA4Grid,
+		Columns: qrcodemodel.MaxPrintGridColumns + 1,
+		Rows:    qrcodemodel.MaxPrintGridRows,
+	}
+	if _, err := req.Template(); err == nil {
+		t.Fatal("accepted a grid with too many columns")
+	}
+
+	req.Columns, req.Rows = qrcodemodel.MaxPrintGridColumns, qrcodemodel.MaxPrintGridRows+1
+	if _, err := req.Template(); err == nil {
+		t.Fatal("accepted a grid with too many rows")
+	}
+}
+
+var rectPattern = regexp.MustCompile(`[\d.]+ [\d.]+ [\d.]+ ([\d.]+) re\n`)
+
+// smallestModuleMM returns the height of the smallest filled rectangle on a
+// page, which for the codes' row runs is one module.
+func smallestModuleMM(content string) float64 {
+	smallest := 0.0
+	for _, m := range rectPattern.FindAllStringSubmatch(content, -1) {
+		h, _ := strconv.ParseFloat(m[1], 64)
+		if smallest == 0 || h < smallest {
+			smallest = h
+		}
+	}
+	return smallest / qrcoderender.PointsPerMM
+}
@@ -0,0 +1,52 @@
+package gormqrcode
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	qrcodeinterface "kyooar/internal/qrcode/interface"
+	qrcodemodel "kyooar/internal/qrcode/model"
+	sharedRepos "kyooar/internal/shared/repositories"
+	"gorm.io/gorm"
+)
+
+type printTemplateRepository struct {
+	DB *gorm.DB
+}
+
+func NewPrintTemplateRepository(db *gorm.DB) qrcodeinterface.PrintTemplateRepository {
+	return &printTemplateRepository{DB: db}
+}
+
+func (r *printTemplateRepository) Create(ctx context.Context, template *qrcodemodel.PrintTemplate) error {
+	return r.DB.WithContext(ctx).Create(template).Error
+}
+
+func (r *printTemplateRepository) Update(ctx context.Context, template *qrcodemodel.PrintTemplate) error {
+	return r.DB.WithContext(ctx).Save(template).Error
+}
+
+func (r *printTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.DB.WithContext(ctx).Delete(&qrcodemodel.PrintTemplate{}, "id = ?", id).Error
+}
+
+func (r *printTemplateRepository) FindByID(ctx context.Context, id uuid.UUID) (*qrcodemodel.PrintTemplate, error) {
+	var template qrcodemodel.PrintTemplate
+	if err := r.DB.WithContext(ctx).Where("id = ?", id).First(&template).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, sharedRepos.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *printTemplateRepository) FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]qrcodemodel.PrintTemplate, error) {
+	var templates []qrcodemodel.PrintTemplate
+	err := r.DB.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("name ASC").
+		Find(&templates).Error
+	return templates, err
+}
@@ -3,13 +3,13 @@ package gormqrcode
 import (
 	"context"
 	"errors"
-	"time"
 
 	"github.com/google/uuid"
 	qrcodeinterface "kyooar/internal/qrcode/interface"
 	qrcodemodel "kyooar/internal/qrcode/model"
 	sharedRepos "kyooar/internal/shared/repositories"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type qrCodeRepository struct {
@@ -22,6 +22,24 @@ func NewQRCodeRepository(db *gorm.DB) qrcodeinterface.QRCodeRepository {
 	}
 }
 
+func (r *qrCodeRepository) CreateBatch(ctx context.Context, accountID uuid.UUID, qrCodes []*qrcodemodel.QRCode, check func(count int64) error) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "qr_codes:"+accountID.String()).Error; err != nil {
+			return err
+		}
+
+		count, err := countByAccountID(tx, accountID)
+		if err != nil {
+			return err
+		}
+		if err := check(count); err != nil {
+			return err
+		}
+
+		return tx.Omit(clause.Associations).CreateInBatches(qrCodes, 100).Error
+	})
+}
+
 func (r *qrCodeRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]qrcodemodel.QRCode, error) {
 	if len(ids) == 0 {
 		return []qrcodemodel.QRCode{}, nil
@@ -56,12 +74,12 @@ func (r *qrCodeRepository) FindByOrganizationID(ctx context.Context, organizatio
 	return qrCodes, err
 }
 
-func (r *qrCodeRepository) IncrementScanCount(ctx context.Context, id uuid.UUID) error {
-	now := time.Now()
-	return r.DB.WithContext(ctx).Model(&qrcodemodel.QRCode{}).
-		Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"scans_count":     gorm.Expr("scans_count + ?", 1),
-			"last_scanned_at": now,
-		}).Error
+// countByAccountID counts the QR codes across the account's organizations.
+func countByAccountID(db *gorm.DB, accountID uuid.UUID) (int64, error) {
+	var count int64
+	err := db.Model(&qrcodemodel.QRCode{}).
+		Joins("JOIN organizations ON organizations.id = qr_codes.organization_id AND organizations.deleted_at IS NULL").
+		Where("organizations.account_id = ?", accountID).
+		Count(&count).Error
+	return count, err
 }
\ No newline at end of file
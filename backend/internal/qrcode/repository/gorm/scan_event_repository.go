@@ -0,0 +1,83 @@
+package gormqrcode
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	qrcodeinterface "kyooar/internal/qrcode/interface"
+	qrcodemodel "kyooar/internal/qrcode/model"
+	"gorm.io/gorm"
+)
+
+const scanCountColumns = `
+	COUNT(*) as total,
+	COUNT(DISTINCT visitor_hash) as unique_visitors,
+	MAX(scanned_at) as last_scanned_at`
+
+type scanEventRepository struct {
+	DB *gorm.DB
+}
+
+func NewScanEventRepository(db *gorm.DB) qrcodeinterface.ScanEventRepository {
+	return &scanEventRepository{DB: db}
+}
+
+func (r *scanEventRepository) Record(ctx context.Context, event *qrcodemodel.QRScanEvent) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		return tx.Model(&qrcodemodel.QRCode{}).
+			Where("id = ?", event.QRCodeID).
+			Updates(map[string]interface{}{
+				"scans_count":     gorm.Expr("scans_count + ?", 1),
+				"last_scanned_at": event.ScannedAt,
+			}).Error
+	})
+}
+
+func (r *scanEventRepository) CountByQRCodeIDs(ctx context.Context, qrCodeIDs []uuid.UUID, since time.Time) (map[uuid.UUID]qrcodemodel.ScanCounts, error) {
+	counts := make(map[uuid.UUID]qrcodemodel.ScanCounts)
+	if len(qrCodeIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []qrcodemodel.ScanCounts
+	err := r.DB.WithContext(ctx).Model(&qrcodemodel.QRScanEvent{}).
+		Select("qr_code_id,"+scanCountColumns).
+		Where("qr_code_id IN ? AND scanned_at >= ?", qrCodeIDs, since).
+		Group("qr_code_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.QRCodeID] = row
+	}
+	return counts, nil
+}
+
+func (r *scanEventRepository) CountByDay(ctx context.Context, organizationID uuid.UUID, since time.Time) ([]qrcodemodel.DailyScanCounts, error) {
+	var days []qrcodemodel.DailyScanCounts
+	err := r.DB.WithContext(ctx).Model(&qrcodemodel.QRScanEvent{}).
+		Select(`
+			DATE_TRUNC('day', scanned_at) as day,
+			COUNT(*) as total,
+			COUNT(DISTINCT visitor_hash) as unique_visitors
+		`).
+		Where("organization_id = ? AND scanned_at >= ?", organizationID, since).
+		Group("day").
+		Order("day ASC").
+		Scan(&days).Error
+	return days, err
+}
+
+func (r *scanEventRepository) DeleteBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result := r.DB.WithContext(ctx).Exec(`
+		DELETE FROM qr_scan_events WHERE id IN (
+			SELECT id FROM qr_scan_events WHERE scanned_at < ? LIMIT ?
+		)`, before, limit)
+	return result.RowsAffected, result.Error
+}
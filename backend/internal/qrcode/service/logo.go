@@ -0,0 +1,103 @@
+package qrcodeservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxLogoBytes bounds the size of a logo download.
+const maxLogoBytes = 2 << 20
+
+// logoClient fetches organization logos. Logos are URLs entered by users, so
+// it refuses to connect to loopback, private and link-local addresses.
+var logoClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: publicAddressOnly,
+		}).DialContext,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 3 {
+			return fmt.Errorf("too many redirects")
+		}
+		return nil
+	},
+}
+
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return fmt.Errorf("logo address %s is not public", host)
+	}
+	return nil
+}
+
+// fetchLogo loads a PNG, JPEG or GIF logo from an http(s) or base64 data URL.
+func fetchLogo(ctx context.Context, logoURL string) (image.Image, error) {
+	var data []byte
+	switch {
+	case strings.HasPrefix(logoURL, "data:"):
+		meta, encoded, ok := strings.Cut(strings.TrimPrefix(logoURL, "data:"), ",")
+		if !ok || !strings.HasSuffix(meta, ";base64") {
+			return nil, fmt.Errorf("logo data URL is not base64 encoded")
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, err
+		}
+		data = decoded
+	case strings.HasPrefix(logoURL, "https://"), strings.HasPrefix(logoURL, "http://"):
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, logoURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := logoClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("logo request returned %s", resp.Status)
+		}
+		data, err = io.ReadAll(io.LimitReader(resp.Body, maxLogoBytes+1))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported logo URL")
+	}
+
+	if len(data) > maxLogoBytes {
+		return nil, fmt.Errorf("logo is larger than %d bytes", maxLogoBytes)
+	}
+	// Check the dimensions before decoding so a small file cannot claim a
+	// huge image.
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > 4096*4096 {
+		return nil, fmt.Errorf("logo is %dx%d pixels, which is too large", config.Width, config.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
@@ -0,0 +1,179 @@
+package qrcodeservice
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	organizationinterface "kyooar/internal/organization/interface"
+	organizationmodel "kyooar/internal/organization/model"
+	qrcodeinterface "kyooar/internal/qrcode/interface"
+	qrcodemodel "kyooar/internal/qrcode/model"
+	qrcoderender "kyooar/internal/qrcode/render"
+	"kyooar/internal/shared/errors"
+)
+
+type printService struct {
+	printTemplateRepo qrcodeinterface.PrintTemplateRepository
+	qrCodeRepo        qrcodeinterface.QRCodeRepository
+	organizationRepo  organizationinterface.OrganizationRepository
+	qrCodeService     qrcodeinterface.QRCodeService
+}
+
+func NewPrintService(
+	printTemplateRepo qrcodeinterface.PrintTemplateRepository,
+	qrCodeRepo qrcodeinterface.QRCodeRepository,
+	organizationRepo organizationinterface.OrganizationRepository,
+	qrCodeService qrcodeinterface.QRCodeService,
+) qrcodeinterface.PrintService {
+	return &printService{
+		printTemplateRepo: printTemplateRepo,
+		qrCodeRepo:        qrCodeRepo,
+		organizationRepo:  organizationRepo,
+		qrCodeService:     qrCodeService,
+	}
+}
+
+func (s *printService) CreateTemplate(ctx context.Context, accountID, organizationID uuid.UUID, req *qrcodemodel.SavePrintTemplateRequest) (*qrcodemodel.PrintTemplate, error) {
+	template, err := req.Template()
+	if err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+	if _, err := s.authorize(ctx, accountID, organizationID); err != nil {
+		return nil, err
+	}
+
+	template.OrganizationID = organizationID
+	if err := s.printTemplateRepo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *printService) ListTemplates(ctx context.Context, accountID, organizationID uuid.UUID) ([]qrcodemodel.PrintTemplate, error) {
+	if _, err := s.authorize(ctx, accountID, organizationID); err != nil {
+		return nil, err
+	}
+	return s.printTemplateRepo.FindByOrganizationID(ctx, organizationID)
+}
+
+func (s *printService) UpdateTemplate(ctx context.Context, accountID, organizationID, templateID uuid.UUID, req *qrcodemodel.SavePrintTemplateRequest) (*qrcodemodel.PrintTemplate, error) {
+	updated, err := req.Template()
+	if err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+	if _, err := s.authorize(ctx, accountID, organizationID); err != nil {
+		return nil, err
+	}
+	template, err := s.findTemplate(ctx, organizationID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated.BaseModel = template.BaseModel
+	updated.OrganizationID = organizationID
+	if err := s.printTemplateRepo.Update(ctx, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *printService) DeleteTemplate(ctx context.Context, accountID, organizationID, templateID uuid.UUID) error {
+	if _, err := s.authorize(ctx, accountID, organizationID); err != nil {
+		return err
+	}
+	if _, err := s.findTemplate(ctx, organizationID, templateID); err != nil {
+		return err
+	}
+	return s.printTemplateRepo.Delete(ctx, templateID)
+}
+
+func (s *printService) Print(ctx context.Context, accountID, organizationID uuid.UUID, req *qrcodemodel.PrintQRCodesRequest) ([]byte, error) {
+	if len(req.QRCodeIDs) > qrcodemodel.MaxPrintCodes {
+		return nil, errors.BadRequest(fmt.Sprintf("At most %d QR codes can be printed at once", qrcodemodel.MaxPrintCodes))
+	}
+	organization, err := s.authorize(ctx, accountID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var template *qrcodemodel.PrintTemplate
+	if req.TemplateID != nil {
+		if template, err = s.findTemplate(ctx, organizationID, *req.TemplateID); err != nil {
+			return nil, err
+		}
+	} else {
+		layout := req.Layout
+		if layout == "" {
+			layout = qrcodemodel.PrintLayoutA4Grid
+		}
+		if !layout.IsValid() {
+			return nil, errors.BadRequest("Layout must be one of a4_grid, table_tent or sticker_roll")
+		}
+		template = qrcodemodel.DefaultPrintTemplate(layout)
+	}
+
+	qrCodes, err := s.qrCodeRepo.FindByIDs(ctx, req.QRCodeIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*qrcodemodel.QRCode, len(qrCodes))
+	for i := range qrCodes {
+		if qrCodes[i].OrganizationID == organizationID {
+			byID[qrCodes[i].ID] = &qrCodes[i]
+		}
+	}
+
+	items := make([]qrcoderender.SheetItem, 0, len(req.QRCodeIDs))
+	for _, id := range req.QRCodeIDs {
+		qrCode, ok := byID[id]
+		if !ok {
+			return nil, errors.NotFound("QR code")
+		}
+		if qrCode.IsRotating() {
+			return nil, errors.BadRequest(fmt.Sprintf("%s is a rotating QR code, which changes too often to print", qrCode.Label))
+		}
+		// Printed codes get scuffed and stained, so they use a higher error
+		// correction level than the default for images.
+		code, err := qrcoderender.Encode([]byte(s.qrCodeService.PublicURL(qrCode)), qrcoderender.ECQuartile)
+		if err != nil {
+			return nil, err
+		}
+		item := qrcoderender.SheetItem{Code: code, Label: qrCode.Label}
+		if qrCode.Location != nil {
+			item.Location = *qrCode.Location
+		}
+		items = append(items, item)
+	}
+
+	var buf bytes.Buffer
+	if err := qrcoderender.Sheet(&buf, template, organization.Name, items); err != nil {
+		var tooSmall *qrcoderender.ModuleTooSmallError
+		if stderrors.As(err, &tooSmall) {
+			return nil, errors.BadRequest(fmt.Sprintf("%s would print too small to scan. Use fewer columns or rows, larger stickers or a shorter label", tooSmall.Label))
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *printService) authorize(ctx context.Context, accountID, organizationID uuid.UUID) (*organizationmodel.Organization, error) {
+	organization, err := s.organizationRepo.FindByID(ctx, organizationID)
+	if err != nil {
+		return nil, errors.NotFound("Organization")
+	}
+	if organization.AccountID != accountID {
+		return nil, errors.Forbidden("print QR codes for this organization")
+	}
+	return organization, nil
+}
+
+func (s *printService) findTemplate(ctx context.Context, organizationID, templateID uuid.UUID) (*qrcodemodel.PrintTemplate, error) {
+	template, err := s.printTemplateRepo.FindByID(ctx, templateID)
+	if err != nil || template.OrganizationID != organizationID {
+		return nil, errors.NotFound("Print template")
+	}
+	return template, nil
+}
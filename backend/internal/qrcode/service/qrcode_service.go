@@ -1,31 +1,58 @@
 package qrcodeservice
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 	organizationinterface "kyooar/internal/organization/interface"
 	qrcodeinterface "kyooar/internal/qrcode/interface"
 	qrcodemodel "kyooar/internal/qrcode/model"
+	qrcoderender "kyooar/internal/qrcode/render"
+	"kyooar/internal/shared/errors"
+	"kyooar/internal/shared/logger"
 	sharedRepos "kyooar/internal/shared/repositories"
+	subscriptionconstants "kyooar/internal/subscription/constants"
+	subscriptioninterface "kyooar/internal/subscription/interface"
 )
 
 type qrCodeService struct {
 	qrCodeRepo       qrcodeinterface.QRCodeRepository
+	scanEventRepo    qrcodeinterface.ScanEventRepository
 	organizationRepo organizationinterface.OrganizationRepository
+	subscriptionRepo subscriptioninterface.SubscriptionRepository
+	frontendURL      string
+	tokens           tokenSigner
+	// visitorSecret keys the hash that tells scan visitors apart.
+	visitorSecret []byte
 }
 
 func NewQRCodeService(
 	qrCodeRepo qrcodeinterface.QRCodeRepository,
+	scanEventRepo qrcodeinterface.ScanEventRepository,
 	organizationRepo organizationinterface.OrganizationRepository,
+	subscriptionRepo subscriptioninterface.SubscriptionRepository,
+	frontendURL string,
+	tokenSecret string,
+	visitorSecret string,
 ) qrcodeinterface.QRCodeService {
 	return &qrCodeService{
 		qrCodeRepo:       qrCodeRepo,
+		scanEventRepo:    scanEventRepo,
 		organizationRepo: organizationRepo,
+		subscriptionRepo: subscriptionRepo,
+		frontendURL:      strings.TrimRight(frontendURL, "/"),
+		tokens:           tokenSigner{secret: []byte(tokenSecret)},
+		visitorSecret:    []byte(visitorSecret),
 	}
 }
 
@@ -60,19 +87,99 @@ func (s *qrCodeService) Generate(ctx context.Context, accountID uuid.UUID, organ
 	return qrCode, nil
 }
 
-func (s *qrCodeService) GetByCode(ctx context.Context, code string) (*qrcodemodel.QRCode, error) {
-	qrCode, err := s.qrCodeRepo.FindByCode(ctx, code)
+func (s *qrCodeService) BulkGenerate(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID, qrType qrcodemodel.QRCodeType, entries []qrcodemodel.BulkQRCodeEntry) ([]*qrcodemodel.QRCode, error) {
+	if len(entries) == 0 {
+		return nil, errors.BadRequest("There are no QR codes to create")
+	}
+	if len(entries) > qrcodemodel.MaxBulkQRCodes {
+		return nil, errors.BadRequest(fmt.Sprintf("A batch can create at most %d QR codes", qrcodemodel.MaxBulkQRCodes))
+	}
+
+	organization, err := s.organizationRepo.FindByID(ctx, organizationID)
 	if err != nil {
 		return nil, err
 	}
 
-	if !qrCode.IsValid() {
+	if organization.AccountID != accountID {
 		return nil, sharedRepos.ErrRecordNotFound
 	}
 
+	subscription, err := s.subscriptionRepo.FindByAccountID(ctx, accountID)
+	if err != nil || subscription == nil {
+		return nil, errors.ErrNoSubscriptionFound
+	}
+	withinPlan := func(count int64) error {
+		limit := subscription.Plan.MaxQRCodes
+		if limit == -1 {
+			return nil
+		}
+		remaining := max(int64(limit)-count, 0)
+		if int64(len(entries)) > remaining {
+			return errors.New("SUBSCRIPTION_LIMIT",
+				fmt.Sprintf("Generating %d QR codes would exceed your plan, which allows %d more", len(entries), remaining),
+				http.StatusPaymentRequired)
+		}
+		return nil
+	}
+
+	// Codes made in the same second differ only in their random part, so
+	// make sure the batch does not repeat one.
+	seen := make(map[string]bool, len(entries))
+	qrCodes := make([]*qrcodemodel.QRCode, len(entries))
+	for i, entry := range entries {
+		code, err := generateUniqueCode()
+		for err == nil && seen[code] {
+			code, err = generateUniqueCode()
+		}
+		if err != nil {
+			return nil, err
+		}
+		seen[code] = true
+		qrCodes[i] = &qrcodemodel.QRCode{
+			OrganizationID: organizationID,
+			Code:           code,
+			Type:           qrType,
+			Label:          entry.Label,
+			Location:       entry.Location,
+			IsActive:       true,
+		}
+	}
+
+	if err := s.qrCodeRepo.CreateBatch(ctx, accountID, qrCodes, withinPlan); err != nil {
+		return nil, err
+	}
+
+	return qrCodes, nil
+}
+
+func (s *qrCodeService) GetByCode(ctx context.Context, code string, token string) (*qrcodemodel.QRCode, error) {
+	qrCode, err := s.qrCodeRepo.FindByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAccess(qrCode, token, 0); err != nil {
+		return nil, err
+	}
+
 	return qrCode, nil
 }
 
+func (s *qrCodeService) CheckAccess(qrCode *qrcodemodel.QRCode, token string) error {
+	return s.checkAccess(qrCode, token, qrcodemodel.RotatingTokenGrace)
+}
+
+func (s *qrCodeService) checkAccess(qrCode *qrcodemodel.QRCode, token string, grace time.Duration) error {
+	now := time.Now()
+	if reason := qrCode.Rejection(now); reason != "" {
+		return qrcodemodel.RejectedError(reason)
+	}
+	if qrCode.IsRotating() && !s.tokens.verify(qrCode, token, now, grace) {
+		return qrcodemodel.RejectedError(qrcodemodel.RejectionRotated)
+	}
+	return nil
+}
+
 func (s *qrCodeService) GetByOrganizationID(ctx context.Context, accountID uuid.UUID, organizationID uuid.UUID) ([]qrcodemodel.QRCode, error) {
 	organization, err := s.organizationRepo.FindByID(ctx, organizationID)
 	if err != nil {
@@ -110,6 +217,26 @@ func (s *qrCodeService) Update(ctx context.Context, accountID uuid.UUID, qrCodeI
 	if updateReq.Location != nil {
 		qrCode.Location = updateReq.Location
 	}
+	if updateReq.ClearSchedule {
+		qrCode.StartsAt = nil
+		qrCode.ExpiresAt = nil
+	}
+	if updateReq.StartsAt != nil {
+		qrCode.StartsAt = updateReq.StartsAt
+	}
+	if updateReq.ExpiresAt != nil {
+		qrCode.ExpiresAt = updateReq.ExpiresAt
+	}
+	if qrCode.StartsAt != nil && qrCode.ExpiresAt != nil && !qrCode.ExpiresAt.After(*qrCode.StartsAt) {
+		return nil, errors.BadRequest("The expiry time must be after the start time")
+	}
+	if updateReq.RotationSeconds != nil {
+		rotation := *updateReq.RotationSeconds
+		if rotation != 0 && (rotation < qrcodemodel.MinRotationSeconds || rotation > qrcodemodel.MaxRotationSeconds) {
+			return nil, errors.BadRequest(fmt.Sprintf("Rotation must be 0 or between %d and %d seconds", qrcodemodel.MinRotationSeconds, qrcodemodel.MaxRotationSeconds))
+		}
+		qrCode.RotationSeconds = rotation
+	}
 
 	qrCode.UpdatedAt = time.Now()
 
@@ -138,17 +265,105 @@ func (s *qrCodeService) Delete(ctx context.Context, accountID uuid.UUID, qrCodeI
 	return s.qrCodeRepo.Delete(ctx, qrCodeID)
 }
 
-func (s *qrCodeService) RecordScan(ctx context.Context, code string) error {
-	qrCode, err := s.qrCodeRepo.FindByCode(ctx, code)
+func (s *qrCodeService) PublicURL(qrCode *qrcodemodel.QRCode) string {
+	publicURL := s.frontendURL + "/qr/" + url.PathEscape(qrCode.Code)
+	if qrCode.IsRotating() {
+		token, _ := s.tokens.issue(qrCode, time.Now())
+		publicURL += "?t=" + url.QueryEscape(token)
+	}
+	return publicURL
+}
+
+func (s *qrCodeService) GetImage(ctx context.Context, accountID uuid.UUID, qrCodeID uuid.UUID, opts qrcodemodel.ImageOptions, ifNoneMatch string) (*qrcodemodel.QRCodeImage, error) {
+	qrCode, err := s.qrCodeRepo.FindByID(ctx, qrCodeID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if !qrCode.IsValid() {
-		return sharedRepos.ErrRecordNotFound
+	organization, err := s.organizationRepo.FindByID(ctx, qrCode.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if organization.AccountID != accountID {
+		return nil, sharedRepos.ErrRecordNotFound
 	}
 
-	return s.qrCodeRepo.IncrementScanCount(ctx, qrCode.ID)
+	logoURL := ""
+	if opts.Logo {
+		subscription, err := s.subscriptionRepo.FindByAccountID(ctx, accountID)
+		if err != nil || subscription == nil {
+			return nil, errors.ErrNoSubscriptionFound
+		}
+		if !subscription.Plan.GetFlag(subscriptionconstants.FlagCustomBranding) {
+			return nil, errors.Forbidden("add your logo to QR codes on your current plan")
+		}
+		if organization.Logo == "" {
+			return nil, errors.BadRequest("The organization has no logo")
+		}
+		logoURL = organization.Logo
+	}
+
+	content := s.PublicURL(qrCode)
+
+	// The image depends only on what it encodes and how it is drawn, plus the
+	// logo, which may change without its URL changing when the organization
+	// is updated.
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%d\n%s\n%d\n%s", content, opts.Format, opts.Size, opts.Level, *opts.Margin, logoURL)
+	if logoURL != "" {
+		fmt.Fprintf(hash, "\n%d", organization.UpdatedAt.UnixNano())
+	}
+	image := &qrcodemodel.QRCodeImage{
+		ContentType: opts.Format.ContentType(),
+		ETag:        `"` + hex.EncodeToString(hash.Sum(nil))[:32] + `"`,
+	}
+	if etagMatches(ifNoneMatch, image.ETag) {
+		return image, nil
+	}
+
+	level, _ := qrcoderender.ParseECLevel(opts.Level)
+	code, err := qrcoderender.Encode([]byte(content), level)
+	if err != nil {
+		return nil, err
+	}
+
+	renderOpts := qrcoderender.Options{Size: opts.Size, Margin: *opts.Margin}
+	if logoURL != "" {
+		logo, err := fetchLogo(ctx, logoURL)
+		if err != nil {
+			return nil, errors.BadRequest(fmt.Sprintf("The organization logo could not be loaded: %v", err))
+		}
+		renderOpts.Logo = logo
+	}
+
+	var buf bytes.Buffer
+	if opts.Format == qrcodemodel.ImageFormatSVG {
+		err = qrcoderender.SVG(&buf, code, renderOpts)
+	} else {
+		err = qrcoderender.PNG(&buf, code, renderOpts)
+	}
+	if err != nil {
+		logger.Error("Failed to render QR code image", err, logrus.Fields{
+			"qr_code_id": qrCodeID,
+			"format":     opts.Format,
+		})
+		return nil, err
+	}
+
+	image.Data = buf.Bytes()
+	return image, nil
+}
+
+// etagMatches reports whether an If-None-Match header lists the ETag.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
 }
 
 func generateUniqueCode() (string, error) {
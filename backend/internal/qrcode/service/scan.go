@@ -0,0 +1,65 @@
+package qrcodeservice
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	qrcodemodel "kyooar/internal/qrcode/model"
+	"kyooar/internal/shared/logger"
+)
+
+// scanEventDeleteBatch bounds how many scan events one delete statement
+// removes, so the cleanup does not hold long locks.
+const scanEventDeleteBatch = 5000
+
+func (s *qrCodeService) RecordScan(ctx context.Context, qrCode *qrcodemodel.QRCode, visit qrcodemodel.ScanVisit) error {
+	event := &qrcodemodel.QRScanEvent{
+		QRCodeID:       qrCode.ID,
+		OrganizationID: qrCode.OrganizationID,
+		ScannedAt:      time.Now(),
+		Platform:       visit.Platform,
+		Browser:        visit.Browser,
+		DeviceType:     qrcodemodel.DeviceType(visit.UserAgent),
+		Referrer:       qrcodemodel.ReferrerHost(visit.Referrer),
+		VisitorHash:    s.visitorHash(qrCode, visit),
+	}
+	return s.scanEventRepo.Record(ctx, event)
+}
+
+// visitorHash identifies the device behind a scan without storing its IP
+// address or user agent. The hash is keyed with a server secret so it cannot
+// be reversed by trying addresses, and includes the organization so the same
+// device cannot be followed across organizations.
+func (s *qrCodeService) visitorHash(qrCode *qrcodemodel.QRCode, visit qrcodemodel.ScanVisit) string {
+	mac := hmac.New(sha256.New, s.visitorSecret)
+	mac.Write([]byte("qr-scan-visitor\x00" + qrCode.OrganizationID.String() + "\x00" + visit.IP + "\x00" + visit.UserAgent))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func (s *qrCodeService) CleanupOldScanEvents(ctx context.Context, retentionDays int) error {
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+
+	var total int64
+	for {
+		deleted, err := s.scanEventRepo.DeleteBefore(ctx, cutoff, scanEventDeleteBatch)
+		if err != nil {
+			return err
+		}
+		total += deleted
+		if deleted < scanEventDeleteBatch {
+			break
+		}
+	}
+
+	if total > 0 {
+		logger.Info("Deleted old QR scan events", logrus.Fields{
+			"deleted": total,
+			"before":  cutoff,
+		})
+	}
+	return nil
+}
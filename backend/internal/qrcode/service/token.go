@@ -0,0 +1,53 @@
+package qrcodeservice
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	qrcodemodel "kyooar/internal/qrcode/model"
+)
+
+// tokenSigner signs the tokens in rotating codes' URLs. A token names the
+// rotation window it was issued in, counted in RotationSeconds since the
+// Unix epoch, so it can be checked without storing anything.
+type tokenSigner struct {
+	secret []byte
+}
+
+// issue returns the token for the current window and when it is replaced.
+func (t tokenSigner) issue(qrCode *qrcodemodel.QRCode, now time.Time) (string, time.Time) {
+	interval := int64(qrCode.RotationSeconds)
+	window := now.Unix() / interval
+	token := strconv.FormatInt(window, 36) + "." + t.sign(qrCode, window)
+	return token, time.Unix((window+1)*interval, 0)
+}
+
+// verify reports whether the token belongs to the code and was issued for
+// the current or previous window, so a code scanned just as the display
+// changes still works. Grace extends that for feedback already under way.
+func (t tokenSigner) verify(qrCode *qrcodemodel.QRCode, token string, now time.Time, grace time.Duration) bool {
+	encodedWindow, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	window, err := strconv.ParseInt(encodedWindow, 36, 64)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(t.sign(qrCode, window))) {
+		return false
+	}
+
+	interval := int64(qrCode.RotationSeconds)
+	current := now.Unix() / interval
+	replacedAt := time.Unix((window+2)*interval, 0)
+	return window <= current && now.Before(replacedAt.Add(grace))
+}
+
+func (t tokenSigner) sign(qrCode *qrcodemodel.QRCode, window int64) string {
+	mac := hmac.New(sha256.New, t.secret)
+	// The interval is signed too so shortening it voids longer-lived tokens.
+	mac.Write([]byte(qrCode.Code + "." + strconv.Itoa(qrCode.RotationSeconds) + "." + strconv.FormatInt(window, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:12])
+}
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -9,13 +12,18 @@ import (
 )
 
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	Stripe   StripeConfig
-	SMTP     *SMTPConfig
-	AI       AIConfig
+	App       AppConfig
+	Database  DatabaseConfig
+	Redis     RedisConfig
+	JWT       JWTConfig
+	Stripe    StripeConfig
+	SMTP      *SMTPConfig
+	AI        AIConfig
+	Storage   StorageConfig
+	Abuse     AbuseConfig
+	Retention RetentionConfig
+	Search    SearchConfig
+	Export    ExportConfig
 }
 
 type AppConfig struct {
@@ -66,6 +74,68 @@ type AIConfig struct {
 	Model    string
 }
 
+type StorageConfig struct {
+	Driver        string
+	LocalPath     string
+	SigningSecret string
+	URLExpiration time.Duration
+	MaxUploadSize int64
+	// UnclaimedUploadTTL is how long a photo may wait to be attached to
+	// feedback before it is deleted.
+	UnclaimedUploadTTL time.Duration
+	// Hourly photo upload limits per QR code and per device. Zero disables
+	// the limit.
+	QRUploadsPerHour     int
+	DeviceUploadsPerHour int
+}
+
+type AbuseConfig struct {
+	QRSubmissionsPerHour     int
+	DeviceSubmissionsPerHour int
+	DuplicateWindow          time.Duration
+	ChallengeDifficulty      int
+	ChallengeTTL             time.Duration
+	ChallengeSecret          string
+	// OfflineChallengeGrace is how long before it reaches the server queued
+	// feedback may have had its challenge solved. Capture times come from
+	// the client, so they cannot stretch a challenge's life any further.
+	OfflineChallengeGrace time.Duration
+	// QRTokenSecret signs the tokens in rotating QR codes' URLs.
+	QRTokenSecret string
+	// ScanVisitorSecret keys the hash that counts unique QR code scan
+	// visitors. Changing it restarts the unique visitor counts.
+	ScanVisitorSecret string
+}
+
+// RetentionConfig tunes the nightly retention job. Organization policies
+// decide what is removed; these settings only bound how it runs.
+type RetentionConfig struct {
+	BatchSize   int
+	MetricsDays int
+	// ScanEventDays is how long individual QR code scans are kept for
+	// analytics. Codes keep their total scan counts after that.
+	ScanEventDays int
+}
+
+// SearchConfig selects the Postgres text search configuration, such as
+// "english" or "spanish", used to index and query feedback. "simple" does no
+// stemming and suits organizations that collect feedback in many languages.
+type SearchConfig struct {
+	Language string
+}
+
+// ExportConfig decides when feedback exports stream straight to the caller
+// and when they run in the background and are stored for download.
+type ExportConfig struct {
+	MaxStreamRows int
+	BatchSize     int
+	FileTTL       time.Duration
+	// Workers is how many background exports run at once and QueueSize how
+	// many more may wait for a worker.
+	Workers   int
+	QueueSize int
+}
+
 func Load() (*Config, error) {
 	_ = godotenv.Load()
 
@@ -83,6 +153,28 @@ func Load() (*Config, error) {
 	viper.SetDefault("JWT_EXPIRATION", "24h")
 	viper.SetDefault("AI_PROVIDER", "anthropic")
 	viper.SetDefault("AI_MODEL", "claude-3-haiku-20240307")
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_LOCAL_PATH", "./uploads")
+	viper.SetDefault("STORAGE_URL_EXPIRATION", "15m")
+	viper.SetDefault("UPLOAD_MAX_SIZE", 5*1024*1024)
+	viper.SetDefault("UPLOAD_UNCLAIMED_TTL", "72h")
+	viper.SetDefault("UPLOAD_QR_LIMIT_PER_HOUR", 300)
+	viper.SetDefault("UPLOAD_DEVICE_LIMIT_PER_HOUR", 30)
+	viper.SetDefault("FEEDBACK_QR_LIMIT_PER_HOUR", 300)
+	viper.SetDefault("FEEDBACK_DEVICE_LIMIT_PER_HOUR", 10)
+	viper.SetDefault("FEEDBACK_DUPLICATE_WINDOW", "24h")
+	viper.SetDefault("FEEDBACK_POW_DIFFICULTY", 0)
+	viper.SetDefault("FEEDBACK_POW_TTL", "10m")
+	viper.SetDefault("FEEDBACK_POW_OFFLINE_GRACE", "2h")
+	viper.SetDefault("RETENTION_BATCH_SIZE", 500)
+	viper.SetDefault("RETENTION_METRICS_DAYS", 730)
+	viper.SetDefault("RETENTION_SCAN_EVENT_DAYS", 395)
+	viper.SetDefault("SEARCH_LANGUAGE", "simple")
+	viper.SetDefault("EXPORT_MAX_STREAM_ROWS", 5000)
+	viper.SetDefault("EXPORT_BATCH_SIZE", 500)
+	viper.SetDefault("EXPORT_FILE_TTL", "72h")
+	viper.SetDefault("EXPORT_WORKERS", 2)
+	viper.SetDefault("EXPORT_QUEUE_SIZE", 20)
 
 	viper.AutomaticEnv()
 
@@ -91,6 +183,44 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid JWT_EXPIRATION: %w", err)
 	}
 
+	storageURLExpiration, err := time.ParseDuration(viper.GetString("STORAGE_URL_EXPIRATION"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STORAGE_URL_EXPIRATION: %w", err)
+	}
+
+	unclaimedUploadTTL, err := time.ParseDuration(viper.GetString("UPLOAD_UNCLAIMED_TTL"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid UPLOAD_UNCLAIMED_TTL: %w", err)
+	}
+
+	storageSigningSecret := secretOrDerived("STORAGE_SIGNING_SECRET", "storage-signing")
+
+	duplicateWindow, err := time.ParseDuration(viper.GetString("FEEDBACK_DUPLICATE_WINDOW"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid FEEDBACK_DUPLICATE_WINDOW: %w", err)
+	}
+
+	challengeTTL, err := time.ParseDuration(viper.GetString("FEEDBACK_POW_TTL"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid FEEDBACK_POW_TTL: %w", err)
+	}
+
+	offlineChallengeGrace, err := time.ParseDuration(viper.GetString("FEEDBACK_POW_OFFLINE_GRACE"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid FEEDBACK_POW_OFFLINE_GRACE: %w", err)
+	}
+
+	challengeSecret := secretOrDerived("FEEDBACK_POW_SECRET", "feedback-pow")
+
+	qrTokenSecret := secretOrDerived("QR_TOKEN_SECRET", "qr-token")
+
+	scanVisitorSecret := secretOrDerived("QR_SCAN_VISITOR_SECRET", "qr-scan-visitor")
+
+	exportFileTTL, err := time.ParseDuration(viper.GetString("EXPORT_FILE_TTL"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORT_FILE_TTL: %w", err)
+	}
+
 	var smtpConfig *SMTPConfig
 	if viper.GetString("SMTP_HOST") != "" {
 		smtpConfig = &SMTPConfig{
@@ -138,11 +268,60 @@ func Load() (*Config, error) {
 			APIKey:   viper.GetString("AI_API_KEY"),
 			Model:    viper.GetString("AI_MODEL"),
 		},
+		Storage: StorageConfig{
+			Driver:        viper.GetString("STORAGE_DRIVER"),
+			LocalPath:     viper.GetString("STORAGE_LOCAL_PATH"),
+			SigningSecret: storageSigningSecret,
+			URLExpiration: storageURLExpiration,
+			MaxUploadSize: viper.GetInt64("UPLOAD_MAX_SIZE"),
+			UnclaimedUploadTTL:   unclaimedUploadTTL,
+			QRUploadsPerHour:     viper.GetInt("UPLOAD_QR_LIMIT_PER_HOUR"),
+			DeviceUploadsPerHour: viper.GetInt("UPLOAD_DEVICE_LIMIT_PER_HOUR"),
+		},
+		Abuse: AbuseConfig{
+			QRSubmissionsPerHour:     viper.GetInt("FEEDBACK_QR_LIMIT_PER_HOUR"),
+			DeviceSubmissionsPerHour: viper.GetInt("FEEDBACK_DEVICE_LIMIT_PER_HOUR"),
+			DuplicateWindow:          duplicateWindow,
+			ChallengeDifficulty:      viper.GetInt("FEEDBACK_POW_DIFFICULTY"),
+			ChallengeTTL:             challengeTTL,
+			ChallengeSecret:          challengeSecret,
+			OfflineChallengeGrace:    offlineChallengeGrace,
+			QRTokenSecret:            qrTokenSecret,
+			ScanVisitorSecret:        scanVisitorSecret,
+		},
+		Retention: RetentionConfig{
+			BatchSize:     viper.GetInt("RETENTION_BATCH_SIZE"),
+			MetricsDays:   viper.GetInt("RETENTION_METRICS_DAYS"),
+			ScanEventDays: viper.GetInt("RETENTION_SCAN_EVENT_DAYS"),
+		},
+		Search: SearchConfig{
+			Language: viper.GetString("SEARCH_LANGUAGE"),
+		},
+		Export: ExportConfig{
+			MaxStreamRows: viper.GetInt("EXPORT_MAX_STREAM_ROWS"),
+			BatchSize:     viper.GetInt("EXPORT_BATCH_SIZE"),
+			FileTTL:       exportFileTTL,
+			Workers:       viper.GetInt("EXPORT_WORKERS"),
+			QueueSize:     viper.GetInt("EXPORT_QUEUE_SIZE"),
+		},
 	}
 
 	return config, nil
 }
 
+// secretOrDerived returns the secret in the variable key or, when it is
+// unset, one derived from JWT_SECRET for the given purpose. Each purpose gets
+// its own key, so a leaked scan hash key or storage signature never exposes
+// the key that signs login tokens.
+func secretOrDerived(key, purpose string) string {
+	if secret := viper.GetString(key); secret != "" {
+		return secret
+	}
+	mac := hmac.New(sha256.New, []byte(viper.GetString("JWT_SECRET")))
+	mac.Write([]byte(purpose))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 func (c *Config) DSN() string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		c.Database.Host, c.Database.Port, c.Database.User, c.Database.Password, c.Database.Name, c.Database.SSLMode)
@@ -0,0 +1,24 @@
+package cron
+
+import (
+	"context"
+	"log"
+
+	feedbackinterface "kyooar/internal/feedback/interface"
+	"github.com/robfig/cron/v3"
+)
+
+// AddUnclaimedUploadCleanupJob schedules the hourly removal of feedback
+// photos that were uploaded but never attached to feedback.
+func AddUnclaimedUploadCleanupJob(c *cron.Cron, attachmentService feedbackinterface.AttachmentService) {
+	_, err := c.AddFunc("45 * * * *", func() {
+		ctx := context.Background()
+
+		if err := attachmentService.CleanupUnclaimed(ctx); err != nil {
+			log.Printf("Error cleaning up unclaimed feedback photos: %v", err)
+		}
+	})
+	if err != nil {
+		log.Printf("Failed to schedule unclaimed upload cleanup cron job: %v", err)
+	}
+}
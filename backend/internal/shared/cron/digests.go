@@ -0,0 +1,27 @@
+package cron
+
+import (
+	"context"
+	"log"
+
+	feedbackinterface "kyooar/internal/feedback/interface"
+	"github.com/robfig/cron/v3"
+)
+
+// AddFeedbackDigestJob schedules the morning email digests of saved
+// feedback views. Weekly digests go out with Monday's run.
+func AddFeedbackDigestJob(c *cron.Cron, viewService feedbackinterface.ViewService) {
+	_, err := c.AddFunc("0 7 * * *", func() {
+		ctx := context.Background()
+		log.Println("Sending feedback view digests...")
+
+		if err := viewService.SendDigests(ctx); err != nil {
+			log.Printf("Error sending feedback view digests: %v", err)
+		} else {
+			log.Println("Feedback view digests sent")
+		}
+	})
+	if err != nil {
+		log.Printf("Failed to schedule feedback digest cron job: %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package cron
+
+import (
+	"context"
+	"log"
+
+	feedbackinterface "kyooar/internal/feedback/interface"
+	"github.com/robfig/cron/v3"
+)
+
+// AddExportCleanupJob schedules the hourly removal of expired feedback
+// export files.
+func AddExportCleanupJob(c *cron.Cron, exportService feedbackinterface.ExportService) {
+	_, err := c.AddFunc("15 * * * *", func() {
+		ctx := context.Background()
+
+		if err := exportService.CleanupExpired(ctx); err != nil {
+			log.Printf("Error cleaning up expired feedback exports: %v", err)
+		}
+	})
+	if err != nil {
+		log.Printf("Failed to schedule export cleanup cron job: %v", err)
+	}
+}
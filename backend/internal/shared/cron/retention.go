@@ -0,0 +1,66 @@
+package cron
+
+import (
+	"context"
+	"log"
+
+	analyticsinterface "kyooar/internal/analytics/interface"
+	privacyinterface "kyooar/internal/privacy/interface"
+	qrcodeinterface "kyooar/internal/qrcode/interface"
+	"github.com/robfig/cron/v3"
+)
+
+// AddRetentionJobs schedules the nightly enforcement of organization
+// retention policies and the cleanup of old time series metrics.
+func AddRetentionJobs(c *cron.Cron, retentionService privacyinterface.RetentionService, timeSeriesService analyticsinterface.TimeSeriesService, metricsRetentionDays int) {
+	_, err := c.AddFunc("0 3 * * *", func() {
+		ctx := context.Background()
+		log.Println("Running data retention job...")
+
+		if err := retentionService.Enforce(ctx); err != nil {
+			log.Printf("Error enforcing data retention: %v", err)
+		} else {
+			log.Println("Data retention job completed successfully")
+		}
+	})
+	if err != nil {
+		log.Printf("Failed to schedule data retention cron job: %v", err)
+	}
+
+	if metricsRetentionDays <= 0 {
+		return
+	}
+
+	_, err = c.AddFunc("30 3 * * *", func() {
+		ctx := context.Background()
+		log.Println("Running metrics cleanup job...")
+
+		if err := timeSeriesService.CleanupOldMetrics(ctx, metricsRetentionDays); err != nil {
+			log.Printf("Error cleaning up old metrics: %v", err)
+		} else {
+			log.Println("Metrics cleanup job completed successfully")
+		}
+	})
+	if err != nil {
+		log.Printf("Failed to schedule metrics cleanup cron job: %v", err)
+	}
+}
+
+// AddScanEventCleanupJob schedules the nightly removal of QR code scan
+// events older than the retention period. A period of zero keeps them.
+func AddScanEventCleanupJob(c *cron.Cron, qrCodeService qrcodeinterface.QRCodeService, retentionDays int) {
+	if retentionDays <= 0 {
+		return
+	}
+
+	_, err := c.AddFunc("45 3 * * *", func() {
+		ctx := context.Background()
+
+		if err := qrCodeService.CleanupOldScanEvents(ctx, retentionDays); err != nil {
+			log.Printf("Error cleaning up old QR scan events: %v", err)
+		}
+	})
+	if err != nil {
+		log.Printf("Failed to schedule QR scan event cleanup cron job: %v", err)
+	}
+}
@@ -1,11 +1,28 @@
 package models
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor is returned for a cursor that was not issued for the
+// requested list order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 type PageRequest struct {
 	Page    int    `json:"page" query:"page"`
 	Limit   int    `json:"limit" query:"limit"`
 	Sort    string `json:"sort" query:"sort"`
 	Order   string `json:"order" query:"order"`
 	Filters map[string]interface{} `json:"filters"`
+	// Cursor continues a list after the last row of a previous page and takes
+	// precedence over Page. Lists paged this way do not slow down as clients
+	// page deeper.
+	Cursor string `json:"cursor" query:"cursor"`
+	// IncludeTotal asks for Total and TotalPages, which take a separate count
+	// of every matching row.
+	IncludeTotal bool `json:"include_total" query:"include_total"`
 }
 
 type PageResponse[T any] struct {
@@ -14,6 +31,9 @@ type PageResponse[T any] struct {
 	Limit      int   `json:"limit"`
 	Total      int   `json:"total"`
 	TotalPages int   `json:"total_pages"`
+	// NextCursor fetches the page after this one and is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
 }
 
 func NewPageRequest() *PageRequest {
@@ -27,12 +47,34 @@ func (p *PageRequest) Offset() int {
 	return (p.Page - 1) * p.Limit
 }
 
+// Normalize fills in the default page and limit.
+func (p *PageRequest) Normalize() {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.Limit < 1 {
+		p.Limit = 20
+	}
+}
+
+// SetTotal sets Total and TotalPages from a count of every matching row.
+func (p *PageResponse[T]) SetTotal(total int64) {
+	p.Total = int(total)
+	p.TotalPages = 0
+	if p.Limit > 0 {
+		p.TotalPages = int(total) / p.Limit
+		if int(total)%p.Limit > 0 {
+			p.TotalPages++
+		}
+	}
+}
+
 func NewPageResponse[T any](data []T, page, limit int, total int64) *PageResponse[T] {
 	totalPages := int(total) / limit
 	if int(total)%limit > 0 {
 		totalPages++
 	}
-	
+
 	return &PageResponse[T]{
 		Data:       data,
 		Page:       page,
@@ -40,4 +82,50 @@ func NewPageResponse[T any](data []T, page, limit int, total int64) *PageRespons
 		Total:      int(total),
 		TotalPages: totalPages,
 	}
-}
\ No newline at end of file
+}
+
+type cursor struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// EncodeCursor returns an opaque cursor holding the sort key values of the
+// last row of a page. sort names the list order the values belong to.
+func EncodeCursor(sort string, values ...interface{}) (string, error) {
+	c := cursor{Sort: sort, Values: make([]json.RawMessage, len(values))}
+	for i, value := range values {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		c.Values[i] = raw
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor reads the sort key values of a cursor made by EncodeCursor
+// into the given pointers. It returns ErrInvalidCursor when the cursor is
+// malformed or was made for another sort.
+func DecodeCursor(encoded, sort string, values ...interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return ErrInvalidCursor
+	}
+	if c.Sort != sort || len(c.Values) != len(values) {
+		return ErrInvalidCursor
+	}
+	for i, value := range values {
+		if err := json.Unmarshal(c.Values[i], value); err != nil {
+			return ErrInvalidCursor
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// KeysetColumn is one sort key of a keyset paginated list. Expr is a column
+// or SQL expression, with Args filling its placeholders. The last column
+// must make the order unique, usually the primary key.
+type KeysetColumn struct {
+	Expr string
+	Args []interface{}
+	Desc bool
+}
+
+// OrderByKeyset orders the query by the columns.
+func OrderByKeyset(query *gorm.DB, columns []KeysetColumn) *gorm.DB {
+	terms := make([]string, len(columns))
+	var args []interface{}
+	for i, column := range columns {
+		terms[i] = column.Expr + " ASC"
+		if column.Desc {
+			terms[i] = column.Expr + " DESC"
+		}
+		args = append(args, column.Args...)
+	}
+	return query.Clauses(clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(terms, ", "), Vars: args, WithoutParentheses: true}})
+}
+
+// AfterKeyset limits the query to the rows ordered after the row whose sort
+// key values are given, one value per column.
+func AfterKeyset(query *gorm.DB, columns []KeysetColumn, values []interface{}) *gorm.DB {
+	sameDirection := true
+	for _, column := range columns[1:] {
+		if column.Desc != columns[0].Desc {
+			sameDirection = false
+		}
+	}
+
+	// A row comparison can be answered from a matching index, but only when
+	// every column sorts the same way.
+	if sameDirection {
+		op := ">"
+		if columns[0].Desc {
+			op = "<"
+		}
+		exprs := make([]string, len(columns))
+		placeholders := make([]string, len(columns))
+		var args []interface{}
+		for i, column := range columns {
+			exprs[i] = column.Expr
+			placeholders[i] = "?"
+			args = append(args, column.Args...)
+		}
+		args = append(args, values...)
+		return query.Where("("+strings.Join(exprs, ", ")+") "+op+" ("+strings.Join(placeholders, ", ")+")", args...)
+	}
+
+	// Otherwise: (a > ?) OR (a = ? AND b < ?) OR ...
+	var conditions []string
+	var args []interface{}
+	for i, column := range columns {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, columns[j].Expr+" = ?")
+			args = append(args, columns[j].Args...)
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if column.Desc {
+			op = " < ?"
+		}
+		parts = append(parts, column.Expr+op)
+		args = append(args, column.Args...)
+		args = append(args, values[i])
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+	return query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -19,11 +20,17 @@ import (
 	productModule "kyooar/internal/product"
 	feedbackModule "kyooar/internal/feedback"
 	qrcodeModule "kyooar/internal/qrcode"
+	kioskModule "kyooar/internal/kiosk"
 	analyticsModule "kyooar/internal/analytics"
+	privacyModule "kyooar/internal/privacy"
 	subscriptionModule "kyooar/internal/subscription"
 	aiModule "kyooar/internal/ai"
 	
 	authinterface "kyooar/internal/auth/interface"
+	analyticsinterface "kyooar/internal/analytics/interface"
+	feedbackinterface "kyooar/internal/feedback/interface"
+	privacyinterface "kyooar/internal/privacy/interface"
+	qrcodeinterface "kyooar/internal/qrcode/interface"
 	
 	"github.com/samber/do"
 	"github.com/sirupsen/logrus"
@@ -93,9 +100,11 @@ func (s *Server) setupRoutes() {
 	authModule.RegisterNewModule(s.injector)
 	subscriptionModule.RegisterNewModule(s.injector)
 	qrcodeModule.RegisterNewModule(s.injector)
+	kioskModule.RegisterNewModule(s.injector)
 	productModule.RegisterNewModule(s.injector)
 	feedbackModule.RegisterNewModule(s.injector)
 	analyticsModule.RegisterNewModule(s.injector)
+	privacyModule.RegisterNewModule(s.injector)
 	
 	subscriptionMod := subscriptionModule.NewSubscriptionModule(s.injector)
 	subscriptionMod.RegisterRoutes(v1)
@@ -106,6 +115,9 @@ func (s *Server) setupRoutes() {
 	qrcodeMod := qrcodeModule.NewQRCodeModule(s.injector)
 	qrcodeMod.RegisterRoutes(v1)
 	
+	kioskMod := kioskModule.NewKioskModule(s.injector)
+	kioskMod.RegisterRoutes(v1)
+	
 	organizationMod := organizationModule.NewOrganizationModule(s.injector)
 	organizationMod.RegisterRoutes(v1)
 	
@@ -117,13 +129,33 @@ func (s *Server) setupRoutes() {
 	
 	analyticsMod := analyticsModule.NewAnalyticsModule(s.injector)
 	analyticsMod.RegisterRoutes(v1)
+	
+	privacyMod := privacyModule.NewPrivacyModule(s.injector)
+	privacyMod.RegisterRoutes(v1)
 }
 
 func (s *Server) setupCronJobs() {
 	authService := do.MustInvoke[authinterface.AuthService](s.injector)
 	s.cron = cron.SetupDeactivationCron(authService)
+
+	retentionService := do.MustInvoke[privacyinterface.RetentionService](s.injector)
+	timeSeriesService := do.MustInvoke[analyticsinterface.TimeSeriesService](s.injector)
+	cron.AddRetentionJobs(s.cron, retentionService, timeSeriesService, s.config.Retention.MetricsDays)
+
+	qrCodeService := do.MustInvoke[qrcodeinterface.QRCodeService](s.injector)
+	cron.AddScanEventCleanupJob(s.cron, qrCodeService, s.config.Retention.ScanEventDays)
+
+	exportService := do.MustInvoke[feedbackinterface.ExportService](s.injector)
+	cron.AddExportCleanupJob(s.cron, exportService)
+
+	attachmentService := do.MustInvoke[feedbackinterface.AttachmentService](s.injector)
+	cron.AddUnclaimedUploadCleanupJob(s.cron, attachmentService)
+
+	viewService := do.MustInvoke[feedbackinterface.ViewService](s.injector)
+	cron.AddFeedbackDigestJob(s.cron, viewService)
+
 	logger.Info("Cron jobs initialized", logrus.Fields{
-		"job": "account_deactivation",
+		"jobs": []string{"account_deactivation", "data_retention", "metrics_cleanup", "scan_event_cleanup", "export_cleanup", "unclaimed_upload_cleanup", "feedback_digests"},
 	})
 }
 
@@ -145,6 +177,11 @@ func (s *Server) Start() error {
 	if s.cron != nil {
 		s.cron.Start()
 	}
+
+	exportService := do.MustInvoke[feedbackinterface.ExportService](s.injector)
+	if err := exportService.Start(context.Background()); err != nil {
+		return fmt.Errorf("failed to start feedback exports: %w", err)
+	}
 	
 	return s.echo.Start(addr)
 }
@@ -153,7 +190,11 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	if s.cron != nil {
 		s.cron.Stop()
 	}
-	return s.echo.Shutdown(ctx)
+	err := s.echo.Shutdown(ctx)
+
+	// Requests can no longer start exports, so wait for the running ones
+	exportService := do.MustInvoke[feedbackinterface.ExportService](s.injector)
+	return errors.Join(err, exportService.Shutdown(ctx))
 }
 
 func setupMiddleware(e *echo.Echo, cfg *config.Config) {
@@ -166,7 +207,8 @@ func setupMiddleware(e *echo.Echo, cfg *config.Config) {
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{echo.GET, echo.POST, echo.PUT, echo.DELETE, echo.OPTIONS, echo.PATCH},
-		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAuthorization},
+		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAuthorization, "X-Feedback-Challenge", "X-Feedback-Challenge-Solution", "Idempotency-Key", "X-Device-Token", "X-QR-Token"},
+		ExposeHeaders:    []string{"Idempotent-Replayed"},
 		AllowCredentials: true,
 	}))
 }
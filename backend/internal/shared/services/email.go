@@ -3,10 +3,12 @@ package services
 import (
 	"context"
 	"fmt"
+	"html"
 	"log"
 	"net/smtp"
 	"regexp"
 	"strings"
+	"time"
 
 	"kyooar/internal/shared/config"
 	"github.com/samber/do"
@@ -20,6 +22,26 @@ type EmailService interface {
 	SendDeactivationRequest(ctx context.Context, email string, deactivationDate string) error
 	SendDeactivationCancelled(ctx context.Context, email string) error
 	SendAccountDeactivated(ctx context.Context, email string) error
+	SendFeedbackReply(ctx context.Context, email, organizationName, message, token string) error
+	SendFeedbackDigest(ctx context.Context, email string, digest FeedbackDigestEmail) error
+}
+
+// FeedbackDigestEmail summarizes the new feedback matching a saved view.
+type FeedbackDigestEmail struct {
+	OrganizationName string
+	ViewName         string
+	ViewID           string
+	Since            time.Time
+	Total            int
+	Items            []FeedbackDigestItem
+}
+
+type FeedbackDigestItem struct {
+	ProductName string
+	Rating      int
+	Customer    string
+	Excerpt     string
+	CreatedAt   time.Time
 }
 
 type emailService struct {
@@ -175,6 +197,78 @@ func (s *emailService) SendAccountDeactivated(ctx context.Context, email string)
 	return s.sendEmail(email, subject, body)
 }
 
+func (s *emailService) SendFeedbackReply(ctx context.Context, email, organizationName, message, token string) error {
+	subject := fmt.Sprintf("%s replied to your feedback", strings.NewReplacer("\r", " ", "\n", " ").Replace(organizationName))
+	frontendURL := s.config.App.FrontendURL
+	if frontendURL == "" {
+		frontendURL = "http://localhost:5173"
+	}
+	replyURL := fmt.Sprintf("%s/feedback/reply/%s", frontendURL, token)
+
+	body := fmt.Sprintf(`
+	<html>
+	<body>
+		<h2>%s replied to your feedback</h2>
+		<p>%s</p>
+		<p><a href="%s">Reply to %s</a></p>
+		<p>This link will expire in 30 days.</p>
+	</body>
+	</html>
+	`, html.EscapeString(organizationName), strings.ReplaceAll(html.EscapeString(message), "\n", "<br>"), replyURL, html.EscapeString(organizationName))
+
+	return s.sendEmail(email, subject, body)
+}
+
+func (s *emailService) SendFeedbackDigest(ctx context.Context, email string, digest FeedbackDigestEmail) error {
+	subject := fmt.Sprintf("%d new feedback in %s", digest.Total, strings.NewReplacer("\r", " ", "\n", " ").Replace(digest.ViewName))
+	frontendURL := s.config.App.FrontendURL
+	if frontendURL == "" {
+		frontendURL = "http://localhost:5173"
+	}
+	viewURL := fmt.Sprintf("%s/feedback/manage?view=%s", frontendURL, digest.ViewID)
+
+	var items strings.Builder
+	for _, item := range digest.Items {
+		rating := "No rating"
+		if item.Rating > 0 {
+			rating = fmt.Sprintf("%d/5", item.Rating)
+		}
+		customer := item.Customer
+		if customer == "" {
+			customer = "Anonymous"
+		}
+		fmt.Fprintf(&items, `
+		<li>
+			<strong>%s</strong> · %s · %s · %s
+			<br>%s
+		</li>`,
+			rating, html.EscapeString(item.ProductName), html.EscapeString(customer), item.CreatedAt.Format("Jan 2, 15:04 MST"),
+			html.EscapeString(item.Excerpt))
+	}
+
+	more := ""
+	if digest.Total > len(digest.Items) {
+		more = fmt.Sprintf("<p>And %d more.</p>", digest.Total-len(digest.Items))
+	}
+
+	body := fmt.Sprintf(`
+	<html>
+	<body>
+		<h2>%s</h2>
+		<p>%d new feedback for %s since %s.</p>
+		<ul>%s
+		</ul>
+		%s
+		<p><a href="%s">Open the view</a></p>
+		<p>You receive this email because you subscribed to this view. Unsubscribe from the view to stop it.</p>
+	</body>
+	</html>
+	`, html.EscapeString(digest.ViewName), digest.Total, html.EscapeString(digest.OrganizationName), digest.Since.Format("Jan 2, 15:04 MST"),
+		items.String(), more, viewURL)
+
+	return s.sendEmail(email, subject, body)
+}
+
 func (s *emailService) sendEmail(to, subject, body string) error {
 	if s.config.App.Env == "development" {
 		log.Printf("=== EMAIL ===\nTo: %s\nSubject: %s\nBody: %s\n=============", to, subject, body)
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samber/do"
+	"kyooar/internal/shared/config"
+)
+
+var (
+	ErrChallengeMissing  = fmt.Errorf("challenge is missing")
+	ErrChallengeInvalid  = fmt.Errorf("challenge is invalid")
+	ErrChallengeExpired  = fmt.Errorf("challenge has expired")
+	ErrChallengeUnsolved = fmt.Errorf("challenge solution is incorrect")
+)
+
+// Challenge asks the client to find a solution such that
+// sha256(token + ":" + solution) starts with Difficulty zero bits.
+type Challenge struct {
+	Token      string    `json:"token"`
+	Difficulty int       `json:"difficulty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ProofOfWork issues stateless, HMAC-signed challenges bound to a subject
+// such as a QR code ID. It is disabled when the difficulty is zero.
+type ProofOfWork interface {
+	Enabled() bool
+	Issue(subject string) (*Challenge, error)
+	Verify(subject, token, solution string) error
+	// VerifyAt checks the challenge as if it were solved at the given time,
+	// for work done by an offline client that is only sent later.
+	VerifyAt(subject, token, solution string, at time.Time) error
+}
+
+type proofOfWork struct {
+	difficulty int
+	ttl        time.Duration
+	secret     []byte
+}
+
+func NewProofOfWork(i *do.Injector) (ProofOfWork, error) {
+	cfg := do.MustInvoke[*config.Config](i)
+	return NewHMACProofOfWork(cfg.Abuse.ChallengeDifficulty, cfg.Abuse.ChallengeTTL, cfg.Abuse.ChallengeSecret), nil
+}
+
+func NewHMACProofOfWork(difficulty int, ttl time.Duration, secret string) ProofOfWork {
+	return &proofOfWork{
+		difficulty: difficulty,
+		ttl:        ttl,
+		secret:     []byte(secret),
+	}
+}
+
+func (p *proofOfWork) Enabled() bool {
+	return p.difficulty > 0
+}
+
+func (p *proofOfWork) Issue(subject string) (*Challenge, error) {
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(p.ttl)
+	payload := fmt.Sprintf("%d.%d.%s", p.difficulty, expiresAt.Unix(), hex.EncodeToString(nonce))
+	return &Challenge{
+		Token:      payload + "." + p.sign(subject, payload),
+		Difficulty: p.difficulty,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+func (p *proofOfWork) Verify(subject, token, solution string) error {
+	return p.VerifyAt(subject, token, solution, time.Now())
+}
+
+func (p *proofOfWork) VerifyAt(subject, token, solution string, at time.Time) error {
+	if token == "" || solution == "" {
+		return ErrChallengeMissing
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return ErrChallengeInvalid
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(p.sign(subject, payload)), []byte(parts[3])) {
+		return ErrChallengeInvalid
+	}
+
+	difficulty, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return ErrChallengeInvalid
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return ErrChallengeInvalid
+	}
+	if at.Unix() > expires {
+		return ErrChallengeExpired
+	}
+
+	sum := sha256.Sum256([]byte(token + ":" + solution))
+	if leadingZeroBits(sum[:]) < difficulty {
+		return ErrChallengeUnsolved
+	}
+	return nil
+}
+
+func (p *proofOfWork) sign(subject, payload string) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(subject + ":" + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func leadingZeroBits(sum []byte) int {
+	zeros := 0
+	for _, b := range sum {
+		if b == 0 {
+			zeros += 8
+			continue
+		}
+		return zeros + bits.LeadingZeros8(b)
+	}
+	return zeros
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samber/do"
+	"kyooar/internal/shared/config"
+)
+
+var ErrInvalidStorageKey = fmt.Errorf("invalid storage key")
+
+// Storage stores uploaded files by key. Files are never exposed directly;
+// clients get short-lived signed URLs instead.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+	VerifySignature(key string, expires int64, signature string) bool
+}
+
+type localStorage struct {
+	root    string
+	baseURL string
+	secret  []byte
+}
+
+func NewStorage(i *do.Injector) (Storage, error) {
+	cfg := do.MustInvoke[*config.Config](i)
+
+	switch cfg.Storage.Driver {
+	case "", "local":
+		return NewLocalStorage(cfg.Storage.LocalPath, cfg.App.URL, cfg.Storage.SigningSecret)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver %q", cfg.Storage.Driver)
+	}
+}
+
+func NewLocalStorage(root, baseURL, secret string) (Storage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &localStorage{
+		root:    root,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  []byte(secret),
+	}, nil
+}
+
+func (s *localStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	return file.Close()
+}
+
+func (s *localStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (s *localStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *localStorage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+	expires := time.Now().Add(ttl).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.sign(key, expires))
+	return fmt.Sprintf("%s/api/v1/public/files/%s?%s", s.baseURL, key, query.Encode()), nil
+}
+
+func (s *localStorage) VerifySignature(key string, expires int64, signature string) bool {
+	if time.Now().Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(s.sign(key, expires)), []byte(signature))
+}
+
+func (s *localStorage) sign(key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s:%d", key, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path maps a key to a file under root, rejecting keys that would escape it.
+func (s *localStorage) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidStorageKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", ErrInvalidStorageKey
+		}
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
@@ -47,6 +47,14 @@ func getClientIP(r *http.Request) string {
 func extractPlatform(userAgent string) string {
 	ua := strings.ToLower(userAgent)
 
+	// Mobile user agents also name the desktop system they derive from, so
+	// they are checked first.
+	if strings.Contains(ua, "android") {
+		return "Android"
+	}
+	if strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad") || strings.Contains(ua, "ipod") {
+		return "iOS"
+	}
 	if strings.Contains(ua, "windows") {
 		return "Windows"
 	}
@@ -56,12 +64,6 @@ func extractPlatform(userAgent string) string {
 	if strings.Contains(ua, "linux") {
 		return "Linux"
 	}
-	if strings.Contains(ua, "android") {
-		return "Android"
-	}
-	if strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad") || strings.Contains(ua, "ipod") {
-		return "iOS"
-	}
 
 	return "Unknown"
 }
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS "public"."idx_feedbacks_is_complete_last_activity_at";
+DROP INDEX IF EXISTS "public"."idx_feedbacks_session_token_hash";
+
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "completed_at";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "last_activity_at";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "session_token_hash";
@@ -0,0 +1,9 @@
+-- Track partially answered feedback so it can be resumed and finalized later
+ALTER TABLE "public"."feedbacks" ADD COLUMN "session_token_hash" character varying(64) NULL;
+ALTER TABLE "public"."feedbacks" ADD COLUMN "last_activity_at" timestamptz NULL;
+ALTER TABLE "public"."feedbacks" ADD COLUMN "completed_at" timestamptz NULL;
+
+UPDATE "public"."feedbacks" SET "completed_at" = "created_at", "last_activity_at" = "created_at" WHERE "is_complete" = true;
+
+CREATE UNIQUE INDEX "idx_feedbacks_session_token_hash" ON "public"."feedbacks" ("session_token_hash") WHERE "session_token_hash" IS NOT NULL;
+CREATE INDEX "idx_feedbacks_is_complete_last_activity_at" ON "public"."feedbacks" ("is_complete", "last_activity_at");
@@ -0,0 +1 @@
+ALTER TABLE "public"."questions" DROP COLUMN IF EXISTS "display_rules";
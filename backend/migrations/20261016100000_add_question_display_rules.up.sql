@@ -0,0 +1,2 @@
+-- Conditional display rules evaluated against earlier answers
+ALTER TABLE "public"."questions" ADD COLUMN "display_rules" jsonb NOT NULL DEFAULT '[]';
@@ -0,0 +1,26 @@
+-- Create "feedback_attachments" table for customer photos
+CREATE TABLE "public"."feedback_attachments" (
+  "id" uuid NOT NULL DEFAULT public.uuid_generate_v4(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "organization_id" uuid NOT NULL,
+  "qr_code_id" uuid NOT NULL,
+  "feedback_id" uuid NULL,
+  "question_id" uuid NULL,
+  "storage_key" character varying(500) NOT NULL,
+  "thumbnail_key" character varying(500) NOT NULL,
+  "content_type" character varying(100) NOT NULL,
+  "size" bigint NOT NULL DEFAULT 0,
+  "width" integer NOT NULL DEFAULT 0,
+  "height" integer NOT NULL DEFAULT 0,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_feedback_attachments_organization_id" ON "public"."feedback_attachments" ("organization_id");
+CREATE INDEX "idx_feedback_attachments_feedback_id" ON "public"."feedback_attachments" ("feedback_id");
+CREATE INDEX "idx_feedback_attachments_deleted_at" ON "public"."feedback_attachments" ("deleted_at");
+
+ALTER TABLE "public"."feedback_attachments" ADD CONSTRAINT "feedback_attachments_organization_id_fkey" FOREIGN KEY ("organization_id") REFERENCES "public"."organizations" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+ALTER TABLE "public"."feedback_attachments" ADD CONSTRAINT "feedback_attachments_qr_code_id_fkey" FOREIGN KEY ("qr_code_id") REFERENCES "public"."qr_codes" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+ALTER TABLE "public"."feedback_attachments" ADD CONSTRAINT "feedback_attachments_feedback_id_fkey" FOREIGN KEY ("feedback_id") REFERENCES "public"."feedbacks" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
@@ -0,0 +1,5 @@
+ALTER TABLE "public"."feedbacks" DROP CONSTRAINT IF EXISTS "feedbacks_questionnaire_version_id_fkey";
+DROP INDEX IF EXISTS "public"."idx_feedbacks_questionnaire_version_id";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "questionnaire_version_id";
+
+DROP TABLE IF EXISTS "public"."questionnaire_versions";
@@ -0,0 +1,26 @@
+-- Create "questionnaire_versions" table holding immutable snapshots of each product's questions
+CREATE TABLE "public"."questionnaire_versions" (
+  "id" uuid NOT NULL DEFAULT public.uuid_generate_v4(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "organization_id" uuid NOT NULL,
+  "product_id" uuid NOT NULL,
+  "version" integer NOT NULL,
+  "hash" character varying(64) NOT NULL,
+  "question_count" integer NOT NULL DEFAULT 0,
+  "questions" jsonb NOT NULL DEFAULT '[]',
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "idx_questionnaire_versions_product_id_version" ON "public"."questionnaire_versions" ("product_id", "version");
+CREATE INDEX "idx_questionnaire_versions_organization_id" ON "public"."questionnaire_versions" ("organization_id");
+CREATE INDEX "idx_questionnaire_versions_deleted_at" ON "public"."questionnaire_versions" ("deleted_at");
+
+ALTER TABLE "public"."questionnaire_versions" ADD CONSTRAINT "questionnaire_versions_organization_id_fkey" FOREIGN KEY ("organization_id") REFERENCES "public"."organizations" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+ALTER TABLE "public"."questionnaire_versions" ADD CONSTRAINT "questionnaire_versions_product_id_fkey" FOREIGN KEY ("product_id") REFERENCES "public"."products" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+
+-- Pin each feedback to the version it answered
+ALTER TABLE "public"."feedbacks" ADD COLUMN "questionnaire_version_id" uuid NULL;
+CREATE INDEX "idx_feedbacks_questionnaire_version_id" ON "public"."feedbacks" ("questionnaire_version_id");
+ALTER TABLE "public"."feedbacks" ADD CONSTRAINT "feedbacks_questionnaire_version_id_fkey" FOREIGN KEY ("questionnaire_version_id") REFERENCES "public"."questionnaire_versions" ("id") ON UPDATE NO ACTION ON DELETE SET NULL;
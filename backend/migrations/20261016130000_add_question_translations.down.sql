@@ -0,0 +1,2 @@
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "language";
+ALTER TABLE "public"."questions" DROP COLUMN IF EXISTS "translations";
@@ -0,0 +1,3 @@
+-- Per-language wording of questions and the language each feedback was answered in
+ALTER TABLE "public"."questions" ADD COLUMN "translations" jsonb NOT NULL DEFAULT '{}';
+ALTER TABLE "public"."feedbacks" ADD COLUMN "language" character varying(20) NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS "public"."idx_feedbacks_qr_code_body_hash";
+DROP INDEX IF EXISTS "public"."idx_feedbacks_device_hash_created_at";
+DROP INDEX IF EXISTS "public"."idx_feedbacks_qr_code_created_at";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "body_hash";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "device_hash";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "reviewed_by";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "reviewed_at";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "quarantine_reasons";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "is_quarantined";
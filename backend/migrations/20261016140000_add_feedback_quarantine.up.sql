@@ -0,0 +1,10 @@
+-- Quarantine of suspicious public submissions and the fingerprints used to spot them
+ALTER TABLE "public"."feedbacks" ADD COLUMN "is_quarantined" boolean NOT NULL DEFAULT false;
+ALTER TABLE "public"."feedbacks" ADD COLUMN "quarantine_reasons" text[];
+ALTER TABLE "public"."feedbacks" ADD COLUMN "reviewed_at" timestamptz NULL;
+ALTER TABLE "public"."feedbacks" ADD COLUMN "reviewed_by" uuid NULL;
+ALTER TABLE "public"."feedbacks" ADD COLUMN "device_hash" character varying(64) NOT NULL DEFAULT '';
+ALTER TABLE "public"."feedbacks" ADD COLUMN "body_hash" character varying(64) NOT NULL DEFAULT '';
+CREATE INDEX "idx_feedbacks_qr_code_created_at" ON "public"."feedbacks" ("qr_code_id", "created_at");
+CREATE INDEX "idx_feedbacks_device_hash_created_at" ON "public"."feedbacks" ("device_hash", "created_at");
+CREATE INDEX "idx_feedbacks_qr_code_body_hash" ON "public"."feedbacks" ("qr_code_id", "body_hash");
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "public"."idx_feedbacks_qr_code_idempotency_key";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "captured_at";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "idempotency_key";
@@ -0,0 +1,5 @@
+-- Idempotent submissions from offline clients and the time feedback was captured on the device
+ALTER TABLE "public"."feedbacks" ADD COLUMN "idempotency_key" character varying(255) NOT NULL DEFAULT '';
+ALTER TABLE "public"."feedbacks" ADD COLUMN "captured_at" timestamptz NULL;
+UPDATE "public"."feedbacks" SET "captured_at" = "created_at";
+CREATE UNIQUE INDEX "idx_feedbacks_qr_code_idempotency_key" ON "public"."feedbacks" ("qr_code_id", "idempotency_key") WHERE (idempotency_key <> '');
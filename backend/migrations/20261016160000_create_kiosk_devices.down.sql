@@ -0,0 +1,5 @@
+ALTER TABLE "public"."feedbacks" DROP CONSTRAINT IF EXISTS "feedbacks_kiosk_device_id_fkey";
+DROP INDEX IF EXISTS "public"."idx_feedbacks_kiosk_device_id_created_at";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "kiosk_device_id";
+
+DROP TABLE IF EXISTS "public"."kiosk_devices";
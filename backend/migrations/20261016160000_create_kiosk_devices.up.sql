@@ -0,0 +1,30 @@
+-- Create "kiosk_devices" table for tablets paired with an organization
+CREATE TABLE "public"."kiosk_devices" (
+  "id" uuid NOT NULL DEFAULT public.uuid_generate_v4(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "organization_id" uuid NOT NULL,
+  "qr_code_id" uuid NULL,
+  "name" character varying(100) NOT NULL,
+  "pairing_code_hash" character varying(64) NULL,
+  "pairing_expires_at" timestamptz NULL,
+  "token_hash" character varying(64) NULL,
+  "paired_at" timestamptz NULL,
+  "last_seen_at" timestamptz NULL,
+  "revoked_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_kiosk_devices_organization_id" ON "public"."kiosk_devices" ("organization_id");
+CREATE INDEX "idx_kiosk_devices_deleted_at" ON "public"."kiosk_devices" ("deleted_at");
+CREATE UNIQUE INDEX "idx_kiosk_devices_pairing_code_hash" ON "public"."kiosk_devices" ("pairing_code_hash") WHERE "pairing_code_hash" IS NOT NULL;
+CREATE UNIQUE INDEX "idx_kiosk_devices_token_hash" ON "public"."kiosk_devices" ("token_hash") WHERE "token_hash" IS NOT NULL;
+
+ALTER TABLE "public"."kiosk_devices" ADD CONSTRAINT "kiosk_devices_organization_id_fkey" FOREIGN KEY ("organization_id") REFERENCES "public"."organizations" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+ALTER TABLE "public"."kiosk_devices" ADD CONSTRAINT "kiosk_devices_qr_code_id_fkey" FOREIGN KEY ("qr_code_id") REFERENCES "public"."qr_codes" ("id") ON UPDATE NO ACTION ON DELETE SET NULL;
+
+-- Attach the kiosk each feedback was left on
+ALTER TABLE "public"."feedbacks" ADD COLUMN "kiosk_device_id" uuid NULL;
+CREATE INDEX "idx_feedbacks_kiosk_device_id_created_at" ON "public"."feedbacks" ("kiosk_device_id", "created_at");
+ALTER TABLE "public"."feedbacks" ADD CONSTRAINT "feedbacks_kiosk_device_id_fkey" FOREIGN KEY ("kiosk_device_id") REFERENCES "public"."kiosk_devices" ("id") ON UPDATE NO ACTION ON DELETE SET NULL;
@@ -0,0 +1,26 @@
+-- Create "feedback_messages" table for conversations with customers about a feedback
+CREATE TABLE "public"."feedback_messages" (
+  "id" uuid NOT NULL DEFAULT public.uuid_generate_v4(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "feedback_id" uuid NOT NULL,
+  "organization_id" uuid NOT NULL,
+  "direction" character varying(20) NOT NULL,
+  "author_member_id" uuid NULL,
+  "body" text NOT NULL,
+  "delivery_status" character varying(20) NOT NULL,
+  "delivery_error" text NOT NULL DEFAULT '',
+  "sent_at" timestamptz NULL,
+  "reply_token_hash" character varying(64) NULL,
+  "reply_token_expires_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_feedback_messages_feedback_id_created_at" ON "public"."feedback_messages" ("feedback_id", "created_at");
+CREATE INDEX "idx_feedback_messages_deleted_at" ON "public"."feedback_messages" ("deleted_at");
+CREATE UNIQUE INDEX "idx_feedback_messages_reply_token_hash" ON "public"."feedback_messages" ("reply_token_hash") WHERE "reply_token_hash" IS NOT NULL;
+
+ALTER TABLE "public"."feedback_messages" ADD CONSTRAINT "feedback_messages_feedback_id_fkey" FOREIGN KEY ("feedback_id") REFERENCES "public"."feedbacks" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+ALTER TABLE "public"."feedback_messages" ADD CONSTRAINT "feedback_messages_organization_id_fkey" FOREIGN KEY ("organization_id") REFERENCES "public"."organizations" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+ALTER TABLE "public"."feedback_messages" ADD CONSTRAINT "feedback_messages_author_member_id_fkey" FOREIGN KEY ("author_member_id") REFERENCES "public"."accounts" ("id") ON UPDATE NO ACTION ON DELETE SET NULL;
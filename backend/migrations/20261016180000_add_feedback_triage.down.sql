@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS "public"."feedback_activities";
+DROP TABLE IF EXISTS "public"."feedback_notes";
+
+ALTER TABLE "public"."feedbacks" DROP CONSTRAINT IF EXISTS "feedbacks_assignee_id_fkey";
+DROP INDEX IF EXISTS "public"."idx_feedbacks_tags";
+DROP INDEX IF EXISTS "public"."idx_feedbacks_assignee_id";
+DROP INDEX IF EXISTS "public"."idx_feedbacks_organization_id_status";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "tags";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "assignee_id";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "status";
@@ -0,0 +1,50 @@
+-- Follow-up workflow on feedback: status, assignee and tags
+ALTER TABLE "public"."feedbacks" ADD COLUMN "status" character varying(20) NOT NULL DEFAULT 'new';
+ALTER TABLE "public"."feedbacks" ADD COLUMN "assignee_id" uuid NULL;
+ALTER TABLE "public"."feedbacks" ADD COLUMN "tags" text[];
+CREATE INDEX "idx_feedbacks_organization_id_status" ON "public"."feedbacks" ("organization_id", "status");
+CREATE INDEX "idx_feedbacks_assignee_id" ON "public"."feedbacks" ("assignee_id");
+CREATE INDEX "idx_feedbacks_tags" ON "public"."feedbacks" USING gin ("tags");
+ALTER TABLE "public"."feedbacks" ADD CONSTRAINT "feedbacks_assignee_id_fkey" FOREIGN KEY ("assignee_id") REFERENCES "public"."team_members" ("id") ON UPDATE NO ACTION ON DELETE SET NULL;
+
+-- Create "feedback_notes" table for internal notes on feedback
+CREATE TABLE "public"."feedback_notes" (
+  "id" uuid NOT NULL DEFAULT public.uuid_generate_v4(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "feedback_id" uuid NOT NULL,
+  "organization_id" uuid NOT NULL,
+  "author_id" uuid NOT NULL,
+  "body" text NOT NULL,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_feedback_notes_feedback_id_created_at" ON "public"."feedback_notes" ("feedback_id", "created_at");
+CREATE INDEX "idx_feedback_notes_deleted_at" ON "public"."feedback_notes" ("deleted_at");
+
+ALTER TABLE "public"."feedback_notes" ADD CONSTRAINT "feedback_notes_feedback_id_fkey" FOREIGN KEY ("feedback_id") REFERENCES "public"."feedbacks" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+ALTER TABLE "public"."feedback_notes" ADD CONSTRAINT "feedback_notes_organization_id_fkey" FOREIGN KEY ("organization_id") REFERENCES "public"."organizations" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+
+-- Create "feedback_activities" table for the history of triage changes
+CREATE TABLE "public"."feedback_activities" (
+  "id" uuid NOT NULL DEFAULT public.uuid_generate_v4(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "feedback_id" uuid NOT NULL,
+  "organization_id" uuid NOT NULL,
+  "actor_id" uuid NULL,
+  "action" character varying(30) NOT NULL,
+  "from_value" text NOT NULL DEFAULT '',
+  "to_value" text NOT NULL DEFAULT '',
+  "note_id" uuid NULL,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_feedback_activities_feedback_id_created_at" ON "public"."feedback_activities" ("feedback_id", "created_at");
+CREATE INDEX "idx_feedback_activities_deleted_at" ON "public"."feedback_activities" ("deleted_at");
+
+ALTER TABLE "public"."feedback_activities" ADD CONSTRAINT "feedback_activities_feedback_id_fkey" FOREIGN KEY ("feedback_id") REFERENCES "public"."feedbacks" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+ALTER TABLE "public"."feedback_activities" ADD CONSTRAINT "feedback_activities_organization_id_fkey" FOREIGN KEY ("organization_id") REFERENCES "public"."organizations" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+ALTER TABLE "public"."feedback_activities" ADD CONSTRAINT "feedback_activities_note_id_fkey" FOREIGN KEY ("note_id") REFERENCES "public"."feedback_notes" ("id") ON UPDATE NO ACTION ON DELETE SET NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "anonymized_at";
+
+DROP TABLE IF EXISTS "public"."data_subject_requests";
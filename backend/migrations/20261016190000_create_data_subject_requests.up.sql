@@ -0,0 +1,29 @@
+-- Create "data_subject_requests" table auditing customer data exports and erasures
+CREATE TABLE "public"."data_subject_requests" (
+  "id" uuid NOT NULL DEFAULT public.uuid_generate_v4(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "account_id" uuid NOT NULL,
+  "type" character varying(20) NOT NULL,
+  "identifier_type" character varying(20) NOT NULL,
+  "identifier_hash" character varying(64) NOT NULL,
+  "masked_identifier" character varying(255) NOT NULL,
+  "source" character varying(20) NOT NULL,
+  "requested_by" uuid NULL,
+  "reason" text NOT NULL DEFAULT '',
+  "status" character varying(20) NOT NULL,
+  "matched_feedbacks" integer NOT NULL DEFAULT 0,
+  "error" text NOT NULL DEFAULT '',
+  "completed_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_data_subject_requests_account_id_created_at" ON "public"."data_subject_requests" ("account_id", "created_at");
+CREATE INDEX "idx_data_subject_requests_identifier_hash" ON "public"."data_subject_requests" ("identifier_hash");
+CREATE INDEX "idx_data_subject_requests_deleted_at" ON "public"."data_subject_requests" ("deleted_at");
+
+ALTER TABLE "public"."data_subject_requests" ADD CONSTRAINT "data_subject_requests_account_id_fkey" FOREIGN KEY ("account_id") REFERENCES "public"."accounts" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+
+-- Mark feedback whose customer details were erased
+ALTER TABLE "public"."feedbacks" ADD COLUMN "anonymized_at" timestamptz NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "public"."retention_runs";
+
+DROP INDEX IF EXISTS "public"."idx_feedbacks_organization_id_created_at";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "text_removed_at";
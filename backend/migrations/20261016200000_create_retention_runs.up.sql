@@ -0,0 +1,27 @@
+-- Track feedback whose free text was removed by a retention policy
+ALTER TABLE "public"."feedbacks" ADD COLUMN "text_removed_at" timestamptz NULL;
+CREATE INDEX "idx_feedbacks_organization_id_created_at" ON "public"."feedbacks" ("organization_id", "created_at");
+
+-- Create "retention_runs" table recording each nightly retention run per organization
+CREATE TABLE "public"."retention_runs" (
+  "id" uuid NOT NULL DEFAULT public.uuid_generate_v4(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "organization_id" uuid NOT NULL,
+  "anonymize_after_days" integer NOT NULL DEFAULT 0,
+  "delete_text_after_days" integer NOT NULL DEFAULT 0,
+  "started_at" timestamptz NOT NULL,
+  "finished_at" timestamptz NULL,
+  "anonymized_feedbacks" integer NOT NULL DEFAULT 0,
+  "text_removed_feedbacks" integer NOT NULL DEFAULT 0,
+  "deleted_messages" bigint NOT NULL DEFAULT 0,
+  "deleted_attachments" bigint NOT NULL DEFAULT 0,
+  "error" text NOT NULL DEFAULT '',
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_retention_runs_organization_id_started_at" ON "public"."retention_runs" ("organization_id", "started_at");
+CREATE INDEX "idx_retention_runs_deleted_at" ON "public"."retention_runs" ("deleted_at");
+
+ALTER TABLE "public"."retention_runs" ADD CONSTRAINT "retention_runs_organization_id_fkey" FOREIGN KEY ("organization_id") REFERENCES "public"."organizations" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "public"."idx_feedbacks_search_vector";
+DROP TRIGGER IF EXISTS "feedbacks_search_vector_update" ON "public"."feedbacks";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "search_vector", DROP COLUMN IF EXISTS "search_language";
+DROP FUNCTION IF EXISTS "public"."feedbacks_search_vector_update"();
+DROP FUNCTION IF EXISTS "public"."feedback_search_text"(text, text, text, jsonb);
@@ -0,0 +1,36 @@
+-- Text that feedback search covers: customer fields and written answers
+CREATE OR REPLACE FUNCTION "public"."feedback_search_text"(customer_name text, customer_email text, customer_phone text, responses jsonb)
+RETURNS text
+LANGUAGE sql IMMUTABLE AS $$
+  SELECT concat_ws(' ',
+    customer_name,
+    customer_email,
+    customer_phone,
+    (SELECT string_agg(response->>'answer', ' ')
+       FROM jsonb_array_elements(CASE WHEN jsonb_typeof(responses) = 'array' THEN responses ELSE '[]'::jsonb END) AS response
+      WHERE jsonb_typeof(response->'answer') = 'string')
+  )
+$$;
+
+-- Customer fields rank above answers so searching for a name finds that customer first
+CREATE OR REPLACE FUNCTION "public"."feedbacks_search_vector_update"()
+RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+  NEW.search_vector :=
+    setweight(to_tsvector(NEW.search_language, concat_ws(' ', NEW.customer_name, NEW.customer_email, NEW.customer_phone)), 'A') ||
+    setweight(to_tsvector(NEW.search_language, "public"."feedback_search_text"(NULL, NULL, NULL, NEW.responses)), 'B');
+  RETURN NEW;
+END
+$$;
+
+-- Modify "feedbacks" table
+ALTER TABLE "public"."feedbacks" ADD COLUMN "search_language" regconfig NOT NULL DEFAULT 'simple', ADD COLUMN "search_vector" tsvector NULL;
+
+CREATE TRIGGER "feedbacks_search_vector_update" BEFORE INSERT OR UPDATE OF "customer_name", "customer_email", "customer_phone", "responses", "search_language"
+ON "public"."feedbacks" FOR EACH ROW EXECUTE FUNCTION "public"."feedbacks_search_vector_update"();
+
+-- Index existing feedback
+UPDATE "public"."feedbacks" SET "search_language" = "search_language";
+
+CREATE INDEX "idx_feedbacks_search_vector" ON "public"."feedbacks" USING GIN ("search_vector");
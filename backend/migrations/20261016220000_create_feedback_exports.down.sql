@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "public"."idx_feedbacks_organization_id_created_at_id";
+CREATE INDEX "idx_feedbacks_organization_id_created_at" ON "public"."feedbacks" ("organization_id", "created_at");
+DROP TABLE IF EXISTS "public"."feedback_exports";
@@ -0,0 +1,30 @@
+-- Create "feedback_exports" table for exports written in the background
+CREATE TABLE "public"."feedback_exports" (
+  "id" uuid NOT NULL DEFAULT public.uuid_generate_v4(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "organization_id" uuid NOT NULL,
+  "requested_by" uuid NOT NULL,
+  "format" text NOT NULL,
+  "filters" jsonb NOT NULL DEFAULT '{}',
+  "status" text NOT NULL DEFAULT 'pending',
+  "row_count" integer NOT NULL DEFAULT 0,
+  "file_name" text NOT NULL DEFAULT '',
+  "file_key" text NOT NULL DEFAULT '',
+  "error" text NOT NULL DEFAULT '',
+  "completed_at" timestamptz NULL,
+  "expires_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_feedback_exports_organization_id_created_at" ON "public"."feedback_exports" ("organization_id", "created_at");
+CREATE INDEX "idx_feedback_exports_expires_at" ON "public"."feedback_exports" ("expires_at");
+CREATE INDEX "idx_feedback_exports_deleted_at" ON "public"."feedback_exports" ("deleted_at");
+
+ALTER TABLE "public"."feedback_exports" ADD CONSTRAINT "feedback_exports_organization_id_fkey" FOREIGN KEY ("organization_id") REFERENCES "public"."organizations" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+ALTER TABLE "public"."feedback_exports" ADD CONSTRAINT "feedback_exports_requested_by_fkey" FOREIGN KEY ("requested_by") REFERENCES "public"."accounts" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+
+-- Keyset pagination used to stream exports; covers the retention index as well
+DROP INDEX IF EXISTS "public"."idx_feedbacks_organization_id_created_at";
+CREATE INDEX "idx_feedbacks_organization_id_created_at_id" ON "public"."feedbacks" ("organization_id", "created_at" DESC, "id" DESC);
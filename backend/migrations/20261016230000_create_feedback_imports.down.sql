@@ -0,0 +1,5 @@
+ALTER TABLE "public"."feedbacks" DROP CONSTRAINT IF EXISTS "feedbacks_import_id_fkey";
+DROP INDEX IF EXISTS "public"."idx_feedbacks_import_id";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "excluded_from_analytics";
+ALTER TABLE "public"."feedbacks" DROP COLUMN IF EXISTS "import_id";
+DROP TABLE IF EXISTS "public"."feedback_imports";
@@ -0,0 +1,29 @@
+-- Create "feedback_imports" table for historical feedback imported from CSV files
+CREATE TABLE "public"."feedback_imports" (
+  "id" uuid NOT NULL DEFAULT public.uuid_generate_v4(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "organization_id" uuid NOT NULL,
+  "requested_by" uuid NOT NULL,
+  "file_name" text NOT NULL DEFAULT '',
+  "mapping" jsonb NOT NULL DEFAULT '{}',
+  "row_count" integer NOT NULL DEFAULT 0,
+  "count_toward_limit" boolean NOT NULL DEFAULT false,
+  "include_in_analytics" boolean NOT NULL DEFAULT true,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_feedback_imports_organization_id_created_at" ON "public"."feedback_imports" ("organization_id", "created_at");
+CREATE INDEX "idx_feedback_imports_deleted_at" ON "public"."feedback_imports" ("deleted_at");
+
+ALTER TABLE "public"."feedback_imports" ADD CONSTRAINT "feedback_imports_organization_id_fkey" FOREIGN KEY ("organization_id") REFERENCES "public"."organizations" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+ALTER TABLE "public"."feedback_imports" ADD CONSTRAINT "feedback_imports_requested_by_fkey" FOREIGN KEY ("requested_by") REFERENCES "public"."accounts" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+
+-- Link imported feedback to its import and let it be left out of analytics
+ALTER TABLE "public"."feedbacks" ADD COLUMN "import_id" uuid NULL;
+ALTER TABLE "public"."feedbacks" ADD COLUMN "excluded_from_analytics" boolean NOT NULL DEFAULT false;
+
+CREATE INDEX "idx_feedbacks_import_id" ON "public"."feedbacks" ("import_id");
+
+ALTER TABLE "public"."feedbacks" ADD CONSTRAINT "feedbacks_import_id_fkey" FOREIGN KEY ("import_id") REFERENCES "public"."feedback_imports" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "public"."feedback_view_subscriptions";
+DROP TABLE IF EXISTS "public"."feedback_views";
@@ -0,0 +1,43 @@
+-- Create "feedback_views" table for saved feedback filters, sort order and columns
+CREATE TABLE "public"."feedback_views" (
+  "id" uuid NOT NULL DEFAULT public.uuid_generate_v4(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "organization_id" uuid NOT NULL,
+  "created_by" uuid NOT NULL,
+  "name" text NOT NULL,
+  "shared" boolean NOT NULL DEFAULT false,
+  "filters" jsonb NOT NULL DEFAULT '{}',
+  "period" text NOT NULL DEFAULT '',
+  "sort" text NOT NULL DEFAULT '',
+  "columns" text[] NOT NULL DEFAULT '{}',
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_feedback_views_organization_id" ON "public"."feedback_views" ("organization_id");
+CREATE INDEX "idx_feedback_views_created_by" ON "public"."feedback_views" ("created_by");
+CREATE INDEX "idx_feedback_views_deleted_at" ON "public"."feedback_views" ("deleted_at");
+
+ALTER TABLE "public"."feedback_views" ADD CONSTRAINT "feedback_views_organization_id_fkey" FOREIGN KEY ("organization_id") REFERENCES "public"."organizations" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+ALTER TABLE "public"."feedback_views" ADD CONSTRAINT "feedback_views_created_by_fkey" FOREIGN KEY ("created_by") REFERENCES "public"."accounts" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+
+-- Create "feedback_view_subscriptions" table for email digests of saved views
+CREATE TABLE "public"."feedback_view_subscriptions" (
+  "id" uuid NOT NULL DEFAULT public.uuid_generate_v4(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "view_id" uuid NOT NULL,
+  "member_id" uuid NOT NULL,
+  "frequency" text NOT NULL DEFAULT 'daily',
+  "last_sent_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "idx_feedback_view_subscriptions_view_id_member_id" ON "public"."feedback_view_subscriptions" ("view_id", "member_id") WHERE "deleted_at" IS NULL;
+CREATE INDEX "idx_feedback_view_subscriptions_member_id" ON "public"."feedback_view_subscriptions" ("member_id");
+CREATE INDEX "idx_feedback_view_subscriptions_deleted_at" ON "public"."feedback_view_subscriptions" ("deleted_at");
+
+ALTER TABLE "public"."feedback_view_subscriptions" ADD CONSTRAINT "feedback_view_subscriptions_view_id_fkey" FOREIGN KEY ("view_id") REFERENCES "public"."feedback_views" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+ALTER TABLE "public"."feedback_view_subscriptions" ADD CONSTRAINT "feedback_view_subscriptions_member_id_fkey" FOREIGN KEY ("member_id") REFERENCES "public"."accounts" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
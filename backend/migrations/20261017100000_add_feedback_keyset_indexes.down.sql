@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "public"."idx_feedbacks_product_id_created_at_id";
+DROP INDEX IF EXISTS "public"."idx_feedbacks_organization_id_overall_rating_created_at_id";
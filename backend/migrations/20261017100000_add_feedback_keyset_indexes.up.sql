@@ -0,0 +1,4 @@
+-- Indexes matching the keyset order of feedback lists. Newest-first lists use
+-- idx_feedbacks_organization_id_created_at_id from the feedback exports migration.
+CREATE INDEX "idx_feedbacks_organization_id_overall_rating_created_at_id" ON "public"."feedbacks" ("organization_id", "overall_rating", "created_at", "id");
+CREATE INDEX "idx_feedbacks_product_id_created_at_id" ON "public"."feedbacks" ("product_id", "created_at", "id");
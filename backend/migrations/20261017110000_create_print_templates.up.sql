@@ -0,0 +1,21 @@
+-- Create "print_templates" table for organizations' saved QR code print layouts
+CREATE TABLE "public"."print_templates" (
+  "id" uuid NOT NULL DEFAULT public.uuid_generate_v4(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "organization_id" uuid NOT NULL,
+  "name" text NOT NULL,
+  "layout" text NOT NULL,
+  "call_to_action" text NOT NULL DEFAULT '',
+  "columns" integer NOT NULL DEFAULT 3,
+  "rows" integer NOT NULL DEFAULT 4,
+  "sticker_width_mm" integer NOT NULL DEFAULT 50,
+  "sticker_height_mm" integer NOT NULL DEFAULT 70,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_print_templates_organization_id" ON "public"."print_templates" ("organization_id");
+CREATE INDEX "idx_print_templates_deleted_at" ON "public"."print_templates" ("deleted_at");
+
+ALTER TABLE "public"."print_templates" ADD CONSTRAINT "print_templates_organization_id_fkey" FOREIGN KEY ("organization_id") REFERENCES "public"."organizations" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+ALTER TABLE "public"."qr_codes" DROP COLUMN IF EXISTS "rotation_seconds";
+ALTER TABLE "public"."qr_codes" DROP COLUMN IF EXISTS "starts_at";
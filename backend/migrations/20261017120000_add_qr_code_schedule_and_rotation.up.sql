@@ -0,0 +1,3 @@
+-- Let QR codes start working at a set time and rotate the token in their URL
+ALTER TABLE "public"."qr_codes" ADD COLUMN "starts_at" timestamptz NULL;
+ALTER TABLE "public"."qr_codes" ADD COLUMN "rotation_seconds" integer NOT NULL DEFAULT 0;
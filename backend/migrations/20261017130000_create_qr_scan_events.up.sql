@@ -0,0 +1,21 @@
+-- Create "qr_scan_events" table recording each QR code scan for analytics
+CREATE TABLE "public"."qr_scan_events" (
+  "id" uuid NOT NULL DEFAULT public.uuid_generate_v4(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "qr_code_id" uuid NOT NULL,
+  "organization_id" uuid NOT NULL,
+  "scanned_at" timestamptz NOT NULL DEFAULT now(),
+  "platform" text NOT NULL DEFAULT '',
+  "browser" text NOT NULL DEFAULT '',
+  "device_type" text NOT NULL DEFAULT '',
+  "referrer" text NOT NULL DEFAULT '',
+  "visitor_hash" text NOT NULL,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_qr_scan_events_qr_code_id_scanned_at" ON "public"."qr_scan_events" ("qr_code_id", "scanned_at");
+CREATE INDEX "idx_qr_scan_events_organization_id_scanned_at" ON "public"."qr_scan_events" ("organization_id", "scanned_at");
+CREATE INDEX "idx_qr_scan_events_scanned_at" ON "public"."qr_scan_events" ("scanned_at");
+
+ALTER TABLE "public"."qr_scan_events" ADD CONSTRAINT "qr_scan_events_qr_code_id_fkey" FOREIGN KEY ("qr_code_id") REFERENCES "public"."qr_codes" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+ALTER TABLE "public"."qr_scan_events" ADD CONSTRAINT "qr_scan_events_organization_id_fkey" FOREIGN KEY ("organization_id") REFERENCES "public"."organizations" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
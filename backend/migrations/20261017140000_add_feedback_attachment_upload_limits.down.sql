@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "public"."idx_feedback_attachments_unclaimed_created_at";
+DROP INDEX IF EXISTS "public"."idx_feedback_attachments_device_hash_created_at";
+DROP INDEX IF EXISTS "public"."idx_feedback_attachments_qr_code_id_created_at";
+ALTER TABLE "public"."feedback_attachments" DROP COLUMN IF EXISTS "device_hash";
@@ -0,0 +1,7 @@
+-- Record who uploaded each photo so uploads can be capped per device and QR code,
+-- and find uploads never attached to feedback so they can be cleaned up
+ALTER TABLE "public"."feedback_attachments" ADD COLUMN "device_hash" text NOT NULL DEFAULT '';
+
+CREATE INDEX "idx_feedback_attachments_qr_code_id_created_at" ON "public"."feedback_attachments" ("qr_code_id", "created_at");
+CREATE INDEX "idx_feedback_attachments_device_hash_created_at" ON "public"."feedback_attachments" ("device_hash", "created_at") WHERE "device_hash" <> '';
+CREATE INDEX "idx_feedback_attachments_unclaimed_created_at" ON "public"."feedback_attachments" ("created_at") WHERE "feedback_id" IS NULL;
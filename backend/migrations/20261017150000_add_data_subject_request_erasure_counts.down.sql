@@ -0,0 +1,5 @@
+ALTER TABLE "public"."data_subject_requests"
+  DROP COLUMN IF EXISTS "deleted_activities",
+  DROP COLUMN IF EXISTS "deleted_notes",
+  DROP COLUMN IF EXISTS "deleted_attachments",
+  DROP COLUMN IF EXISTS "deleted_messages";
@@ -0,0 +1,6 @@
+-- Record in the audit log what each erasure deleted besides the customer's details
+ALTER TABLE "public"."data_subject_requests"
+  ADD COLUMN "deleted_messages" bigint NOT NULL DEFAULT 0,
+  ADD COLUMN "deleted_attachments" bigint NOT NULL DEFAULT 0,
+  ADD COLUMN "deleted_notes" bigint NOT NULL DEFAULT 0,
+  ADD COLUMN "deleted_activities" bigint NOT NULL DEFAULT 0;
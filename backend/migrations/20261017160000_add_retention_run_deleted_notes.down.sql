@@ -0,0 +1 @@
+ALTER TABLE "public"."retention_runs" DROP COLUMN IF EXISTS "deleted_notes";
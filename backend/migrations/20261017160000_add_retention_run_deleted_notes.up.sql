@@ -0,0 +1,2 @@
+-- Count the internal notes each retention run deleted with the feedback text
+ALTER TABLE "public"."retention_runs" ADD COLUMN "deleted_notes" bigint NOT NULL DEFAULT 0;
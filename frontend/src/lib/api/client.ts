@@ -1,6 +1,7 @@
 import { auth } from '$lib/stores/auth';
 import { get } from 'svelte/store';
 import { Api } from '$lib/api/api';
+import type { QrcodemodelQRCode, RequestParams, ResponseResponse } from '$lib/api/api';
 import { APP_CONFIG } from '$lib/constants/config';
 
 // Export the API client from auth store for easy access
@@ -23,6 +24,26 @@ export function getServerSideApiClient(fetch?: typeof globalThis.fetch) {
   });
 }
 
+// Rotating QR codes carry a token in their URL (?t=...). It has to be sent
+// when the code is looked up and with everything submitted through it.
+export const QR_TOKEN_PARAM = 't';
+const QR_TOKEN_HEADER = 'X-QR-Token';
+
+// Look up a public QR code, passing the token of a rotating code along
+export function getPublicQRCode(api: Api<unknown>, code: string, token?: string | null) {
+  return api.request<ResponseResponse & { data?: QrcodemodelQRCode }, ResponseResponse>({
+    path: `/api/v1/public/qr/${encodeURIComponent(code)}`,
+    method: 'GET',
+    query: token ? { [QR_TOKEN_PARAM]: token } : undefined,
+    format: 'json',
+  });
+}
+
+// Request params sending the token of a rotating QR code with a submission
+export function qrTokenParams(token?: string | null): RequestParams {
+  return token ? { headers: { [QR_TOKEN_HEADER]: token } } : {};
+}
+
 // Helper function to handle API errors consistently
 export function handleApiError(error: any): string {
   if (error.response?.data?.error?.message) {